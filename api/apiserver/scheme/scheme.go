@@ -0,0 +1,33 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheme contains the scheme of all the API types served by leona-apiserver.
+package scheme
+
+import (
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	v2 "github.com/dairongpeng/leona/api/apiserver/v2"
+	"github.com/dairongpeng/leona/pkg/scheme"
+	utilruntime "github.com/dairongpeng/leona/pkg/util/runtime"
+)
+
+// Scheme maps the group version kinds of the API types to their Go types, and converts
+// the objects between the versions.
+var Scheme = scheme.NewScheme()
+
+//nolint:gochecknoinits
+func init() {
+	utilruntime.Must(v1.AddToScheme(Scheme))
+	utilruntime.Must(v2.AddToScheme(Scheme))
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/util/idutil"
+	"gorm.io/gorm"
+)
+
+// NamespacePhase is the current lifecycle phase of the namespace.
+type NamespacePhase string
+
+const (
+	// NamespaceActive means the namespace is available for use in the system.
+	NamespaceActive NamespacePhase = "Active"
+
+	// NamespaceTerminating means the namespace is undergoing graceful termination,
+	// all resources scoped to it are being removed.
+	NamespaceTerminating NamespacePhase = "Terminating"
+)
+
+// Namespace provides a scope for names, it is used to isolate the resources
+// which belong to different business units. It is also used as gorm model.
+type Namespace struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Description describes the usage of the namespace.
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+
+	// Phase is the current lifecycle phase of the namespace.
+	// Populated by the system.
+	// Read-only.
+	Phase NamespacePhase `json:"phase,omitempty" gorm:"column:phase;type:varchar(16)" validate:"omitempty"`
+}
+
+// NamespaceList is the whole list of all namespaces which have been stored in stroage.
+type NamespaceList struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
+
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Namespace `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (n *Namespace) TableName() string {
+	return "namespace"
+}
+
+// AfterCreate run after create database record.
+func (n *Namespace) AfterCreate(tx *gorm.DB) (err error) {
+	n.InstanceID = idutil.GetInstanceID(n.ID, "namespace-")
+
+	// NOTICE: tx.Save will trigger n.BeforeUpdate
+	return tx.Save(n).Error
+}
+
+// BeforeUpdate run before update database record.
+func (n *Namespace) BeforeUpdate(tx *gorm.DB) (err error) {
+	n.ExtendShadow = n.Extend.String()
+
+	return err
+}
+
+// AfterFind run after find to unmarshal a extend shadown string into metav1.Extend struct.
+func (n *Namespace) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(n.ExtendShadow), &n.Extend); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PrepareForExport clears the fields populated by the system.
+func (n *Namespace) PrepareForExport(opts metav1.ExportOptions) {
+	n.ObjectMeta.PrepareForExport(opts)
+
+	if !opts.Exact {
+		n.Phase = ""
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/util/idutil"
+	"gorm.io/gorm"
+)
+
+// The verbs of the requests, they are derived from the http methods.
+const (
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+
+	// VerbAll matches all the verbs.
+	VerbAll = "*"
+)
+
+// ResourceAll matches all the resources and their subresources.
+const ResourceAll = "*"
+
+// PolicyRule grants the verbs on the resources. The subresources are named in the form of
+// `<resource>/<subresource>`, e.g. `users/sessions`.
+type PolicyRule struct {
+	// Verbs are the verbs granted by the rule, `*` means all verbs.
+	Verbs []string `json:"verbs" validate:"required,min=1,dive,required"`
+
+	// Resources are the resources the rule applies to, `*` means all resources.
+	Resources []string `json:"resources" validate:"required,min=1,dive,required"`
+
+	// ResourceNames limits the rule to the resources of these names, the rule applies to
+	// all the resources if it is empty.
+	// +optional
+	ResourceNames []string `json:"resourceNames,omitempty" validate:"omitempty"`
+}
+
+// Role is a set of rules granted to the users bound to it in the namespace of the role.
+// It is also used as gorm model.
+type Role struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Rules are the rules granted by the role.
+	Rules []PolicyRule `json:"rules" gorm:"-" validate:"required,min=1,dive"`
+
+	// RulesShadow is the shadow of Rules. DO NOT modify directly.
+	RulesShadow string `json:"-" gorm:"column:rules" validate:"omitempty"`
+}
+
+// RoleList is the whole list of all roles which have been stored in stroage.
+type RoleList struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
+
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Role `json:"items"`
+}
+
+// RoleBinding grants the rules of a role to the users. The role and the users are in the namespace
+// of the role binding. The role bindings in the default namespace grant the rules in all namespaces.
+// It is also used as gorm model.
+type RoleBinding struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// RoleRef is the name of the role, either a role in the namespace or a bootstrap role.
+	RoleRef string `json:"roleRef" gorm:"column:roleRef" validate:"required"`
+
+	// Users are the names of the users the role is granted to.
+	Users []string `json:"users" gorm:"-" validate:"required,min=1,dive,required"`
+
+	// UsersShadow is the shadow of Users. DO NOT modify directly.
+	UsersShadow string `json:"-" gorm:"column:users" validate:"omitempty"`
+}
+
+// RoleBindingList is the whole list of all role bindings which have been stored in stroage.
+type RoleBindingList struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
+
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:",inline"`
+
+	Items []*RoleBinding `json:"items"`
+}
+
+// Permission is a rule in effect for an user, together with where it comes from.
+type Permission struct {
+	PolicyRule `json:",inline"`
+
+	// Namespace is where the rule applies, `*` means all namespaces.
+	Namespace string `json:"namespace"`
+
+	// Role is the role granting the rule, it is empty for the rules every user has on itself.
+	Role string `json:"role,omitempty"`
+
+	// RoleBinding is the role binding granting the role, it is empty for the roles granted
+	// by the isAdmin field of the user.
+	RoleBinding string `json:"roleBinding,omitempty"`
+}
+
+// PermissionList is the whole list of the permissions of an user.
+type PermissionList struct {
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Permission `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (r *Role) TableName() string {
+	return "role"
+}
+
+// BeforeCreate run before create database record.
+func (r *Role) BeforeCreate(tx *gorm.DB) (err error) {
+	return r.BeforeUpdate(tx)
+}
+
+// AfterCreate run after create database record.
+func (r *Role) AfterCreate(tx *gorm.DB) (err error) {
+	r.InstanceID = idutil.GetInstanceID(r.ID, "role-")
+
+	// NOTICE: tx.Save will trigger r.BeforeUpdate
+	return tx.Save(r).Error
+}
+
+// BeforeUpdate run before update database record.
+func (r *Role) BeforeUpdate(tx *gorm.DB) (err error) {
+	r.ExtendShadow = r.Extend.String()
+
+	rules, err := json.Marshal(r.Rules)
+	if err != nil {
+		return err
+	}
+	r.RulesShadow = string(rules)
+
+	return nil
+}
+
+// AfterFind run after find to unmarshal the shadow strings.
+func (r *Role) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(r.ExtendShadow), &r.Extend); err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(r.RulesShadow), &r.Rules)
+}
+
+// TableName maps to mysql table name.
+func (b *RoleBinding) TableName() string {
+	return "rolebinding"
+}
+
+// BeforeCreate run before create database record.
+func (b *RoleBinding) BeforeCreate(tx *gorm.DB) (err error) {
+	return b.BeforeUpdate(tx)
+}
+
+// AfterCreate run after create database record.
+func (b *RoleBinding) AfterCreate(tx *gorm.DB) (err error) {
+	b.InstanceID = idutil.GetInstanceID(b.ID, "rolebinding-")
+
+	// NOTICE: tx.Save will trigger b.BeforeUpdate
+	return tx.Save(b).Error
+}
+
+// BeforeUpdate run before update database record.
+func (b *RoleBinding) BeforeUpdate(tx *gorm.DB) (err error) {
+	b.ExtendShadow = b.Extend.String()
+
+	users, err := json.Marshal(b.Users)
+	if err != nil {
+		return err
+	}
+	b.UsersShadow = string(users)
+
+	return nil
+}
+
+// AfterFind run after find to unmarshal the shadow strings.
+func (b *RoleBinding) AfterFind(tx *gorm.DB) (err error) {
+	if err := json.Unmarshal([]byte(b.ExtendShadow), &b.Extend); err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(b.UsersShadow), &b.Users)
+}
+
+// Grants reports whether the role binding grants its role to the user.
+func (b *RoleBinding) Grants(username string) bool {
+	for _, user := range b.Users {
+		if user == username {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allows reports whether the rule grants the verb on the resource of the name, an empty name
+// stands for the collection of the resources.
+func (r PolicyRule) Allows(verb, resource, name string) bool {
+	return matches(r.Verbs, verb, VerbAll) && matches(r.Resources, resource, ResourceAll) &&
+		(len(r.ResourceNames) == 0 || (name != "" && matches(r.ResourceNames, name, "")))
+}
+
+func matches(values []string, value, all string) bool {
+	for _, v := range values {
+		if v == value || (all != "" && v == all) {
+			return true
+		}
+	}
+
+	return false
+}
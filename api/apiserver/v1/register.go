@@ -0,0 +1,42 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/dairongpeng/leona/pkg/scheme"
+)
+
+// GroupName is the group name use in this package.
+const GroupName = ""
+
+// SchemeGroupVersion is group version used to register these objects.
+var SchemeGroupVersion = scheme.GroupVersion{Group: GroupName, Version: "v1"}
+
+// AddToScheme adds the types of this group version into the given scheme.
+func AddToScheme(s *scheme.Scheme) error {
+	s.AddKnownTypes(SchemeGroupVersion,
+		&User{},
+		&UserList{},
+		&Namespace{},
+		&NamespaceList{},
+		&Role{},
+		&RoleList{},
+		&RoleBinding{},
+		&RoleBindingList{},
+		&PermissionList{},
+	)
+
+	return nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"strings"
+	"time"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+var userColumnDefinitions = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name", Description: "Name of the user."},
+	{Name: "Namespace", Type: "string", Description: "Namespace the user belongs to."},
+	{Name: "Nickname", Type: "string", Description: "Nickname of the user."},
+	{Name: "Email", Type: "string", Description: "Email address of the user."},
+	{Name: "Phone", Type: "string", Description: "Phone number of the user.", Priority: 1},
+	{Name: "Admin", Type: "boolean", Description: "Whether the user is an administrator."},
+	{Name: "Created", Type: "string", Format: "date-time", Description: "Time when the user was created."},
+}
+
+var namespaceColumnDefinitions = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name", Description: "Name of the namespace."},
+	{Name: "Phase", Type: "string", Description: "Current lifecycle phase of the namespace."},
+	{Name: "Description", Type: "string", Description: "Usage of the namespace.", Priority: 1},
+	{Name: "Created", Type: "string", Format: "date-time", Description: "Time when the namespace was created."},
+}
+
+var roleColumnDefinitions = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name", Description: "Name of the role."},
+	{Name: "Namespace", Type: "string", Description: "Namespace the role belongs to."},
+	{Name: "Rules", Type: "integer", Description: "Number of the rules granted by the role."},
+	{Name: "Created", Type: "string", Format: "date-time", Description: "Time when the role was created."},
+}
+
+var roleBindingColumnDefinitions = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name", Description: "Name of the role binding."},
+	{Name: "Namespace", Type: "string", Description: "Namespace the role binding belongs to."},
+	{Name: "Role", Type: "string", Description: "Role granted by the role binding."},
+	{Name: "Users", Type: "string", Description: "Users the role is granted to."},
+	{Name: "Created", Type: "string", Format: "date-time", Description: "Time when the role binding was created."},
+}
+
+// ConvertToTable renders the user as a table with a single row.
+func (u *User) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(userColumnDefinitions, opts)
+	if u != nil {
+		table.Rows = append(table.Rows, u.tableRow())
+	}
+
+	return table
+}
+
+// ConvertToTable renders the users as a table.
+func (l *UserList) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(userColumnDefinitions, opts)
+	if l == nil {
+		return table
+	}
+
+	table.TotalCount = l.TotalCount
+	for _, u := range l.Items {
+		table.Rows = append(table.Rows, u.tableRow())
+	}
+
+	return table
+}
+
+func (u *User) tableRow() metav1.TableRow {
+	return metav1.TableRow{
+		Cells: []interface{}{
+			u.Name, u.Namespace, u.Nickname, u.Email, u.Phone, u.IsAdmin == 1, formatTime(u.CreatedAt),
+		},
+	}
+}
+
+// ConvertToTable renders the namespace as a table with a single row.
+func (n *Namespace) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(namespaceColumnDefinitions, opts)
+	if n != nil {
+		table.Rows = append(table.Rows, n.tableRow())
+	}
+
+	return table
+}
+
+// ConvertToTable renders the namespaces as a table.
+func (l *NamespaceList) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(namespaceColumnDefinitions, opts)
+	if l == nil {
+		return table
+	}
+
+	table.TotalCount = l.TotalCount
+	for _, n := range l.Items {
+		table.Rows = append(table.Rows, n.tableRow())
+	}
+
+	return table
+}
+
+func (n *Namespace) tableRow() metav1.TableRow {
+	return metav1.TableRow{
+		Cells: []interface{}{n.Name, string(n.Phase), n.Description, formatTime(n.CreatedAt)},
+	}
+}
+
+// ConvertToTable renders the role as a table with a single row.
+func (r *Role) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(roleColumnDefinitions, opts)
+	if r != nil {
+		table.Rows = append(table.Rows, r.tableRow())
+	}
+
+	return table
+}
+
+// ConvertToTable renders the roles as a table.
+func (l *RoleList) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(roleColumnDefinitions, opts)
+	if l == nil {
+		return table
+	}
+
+	table.TotalCount = l.TotalCount
+	for _, r := range l.Items {
+		table.Rows = append(table.Rows, r.tableRow())
+	}
+
+	return table
+}
+
+func (r *Role) tableRow() metav1.TableRow {
+	return metav1.TableRow{
+		Cells: []interface{}{r.Name, r.Namespace, len(r.Rules), formatTime(r.CreatedAt)},
+	}
+}
+
+// ConvertToTable renders the role binding as a table with a single row.
+func (b *RoleBinding) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(roleBindingColumnDefinitions, opts)
+	if b != nil {
+		table.Rows = append(table.Rows, b.tableRow())
+	}
+
+	return table
+}
+
+// ConvertToTable renders the role bindings as a table.
+func (l *RoleBindingList) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := newTable(roleBindingColumnDefinitions, opts)
+	if l == nil {
+		return table
+	}
+
+	table.TotalCount = l.TotalCount
+	for _, b := range l.Items {
+		table.Rows = append(table.Rows, b.tableRow())
+	}
+
+	return table
+}
+
+func (b *RoleBinding) tableRow() metav1.TableRow {
+	return metav1.TableRow{
+		Cells: []interface{}{b.Name, b.Namespace, b.RoleRef, strings.Join(b.Users, ","), formatTime(b.CreatedAt)},
+	}
+}
+
+func newTable(columns []metav1.TableColumnDefinition, opts metav1.TableOptions) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: "meta/v1"},
+		Rows:     []metav1.TableRow{},
+	}
+	if !opts.NoHeaders {
+		table.ColumnDefinitions = columns
+	}
+
+	return table
+}
+
+func formatTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
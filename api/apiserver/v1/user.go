@@ -26,8 +26,8 @@ import (
 
 // User represents a user restful resource. It is also used as gorm model.
 type User struct {
-	// May add TypeMeta in the future.
-	// metav1.TypeMeta `json:",inline"`
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
 
 	// Standard object's metadata.
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -50,12 +50,63 @@ type User struct {
 	TotalPolicy int64 `json:"totalPolicy" gorm:"-" validate:"omitempty"`
 
 	LoginedAt time.Time `json:"loginedAt,omitempty" gorm:"column:loginedAt"`
+
+	// PasswordChangedAt is the time when the password was changed lastly, the password expires
+	// once it is older than the max age of the password policy.
+	PasswordChangedAt time.Time `json:"passwordChangedAt,omitempty" gorm:"column:passwordChangedAt"`
+
+	// PasswordHistory holds the hashes of the previous passwords, they can not be reused.
+	// It is never responded, only the exact export keeps it.
+	PasswordHistory []string `json:"-" gorm:"-" validate:"omitempty"`
+
+	// PasswordHistoryShadow is the shadow of PasswordHistory. DO NOT modify directly.
+	PasswordHistoryShadow string `json:"-" gorm:"column:passwordHistory" validate:"omitempty"`
+
+	// EmailVerifiedAt is the time when the email was verified, it is zero until the email is verified
+	// and is reset once the email is changed.
+	EmailVerifiedAt time.Time `json:"emailVerifiedAt,omitempty" gorm:"column:emailVerifiedAt"`
+
+	// MFAEnabledAt is the time when the TOTP authenticator was enrolled, the user logs in with
+	// the TOTP code since then.
+	MFAEnabledAt time.Time `json:"mfaEnabledAt,omitempty" gorm:"column:mfaEnabledAt"`
+
+	// TOTPSecret is the secret of the enrolled TOTP authenticator, encrypted by the mfa key.
+	// It is never responded, only the exact export keeps it.
+	TOTPSecret string `json:"-" gorm:"column:totpSecret" validate:"omitempty"`
+
+	// RecoveryCodes holds the salted hashes of the unused recovery codes, each of them replaces the TOTP
+	// code once. It is never responded, only the exact export keeps it.
+	RecoveryCodes []string `json:"-" gorm:"-" validate:"omitempty"`
+
+	// RecoveryCodesShadow is the shadow of RecoveryCodes. DO NOT modify directly.
+	RecoveryCodesShadow string `json:"-" gorm:"column:recoveryCodes" validate:"omitempty"`
+
+	// Source is the identity source which the user is mirrored from, e.g. ldap, it is empty for the
+	// local users. The source fields are maintained by the server, the requests never change them.
+	Source string `json:"source,omitempty" gorm:"column:source" validate:"omitempty"`
+
+	// SourceID identifies the user in the source, it is the DN of the directory entry of the ldap users.
+	SourceID string `json:"sourceID,omitempty" gorm:"column:sourceID" validate:"omitempty"`
+
+	// SourceRemoved marks the user disabled because it was removed from the source, the user is enabled
+	// again once it is back.
+	SourceRemoved bool `json:"sourceRemoved,omitempty" gorm:"column:sourceRemoved"`
+}
+
+// storedUser is the JSON form of the user kept by the key-value stores and the exact export, it
+// carries the fields which are never responded by the API.
+type storedUser struct {
+	*User
+
+	PasswordHistory []string `json:"passwordHistory,omitempty"`
+	TOTPSecret      string   `json:"totpSecret,omitempty"`
+	RecoveryCodes   []string `json:"recoveryCodes,omitempty"`
 }
 
 // UserList is the whole list of all users which have been stored in stroage.
 type UserList struct {
-	// May add TypeMeta in the future.
-	// metav1.TypeMeta `json:",inline"`
+	// Populated on responses, it is not persisted.
+	metav1.TypeMeta `json:",inline" gorm:"-"`
 
 	// Standard list metadata.
 	// +optional
@@ -96,6 +147,18 @@ func (u *User) AfterCreate(tx *gorm.DB) (err error) {
 func (u *User) BeforeUpdate(tx *gorm.DB) (err error) {
 	u.ExtendShadow = u.Extend.String()
 
+	history, err := json.Marshal(u.PasswordHistory)
+	if err != nil {
+		return err
+	}
+	u.PasswordHistoryShadow = string(history)
+
+	recoveryCodes, err := json.Marshal(u.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+	u.RecoveryCodesShadow = string(recoveryCodes)
+
 	return err
 }
 
@@ -105,5 +168,80 @@ func (u *User) AfterFind(tx *gorm.DB) (err error) {
 		return err
 	}
 
+	// the users created before the password history was introduced have no history.
+	if u.PasswordHistoryShadow != "" {
+		if err := json.Unmarshal([]byte(u.PasswordHistoryShadow), &u.PasswordHistory); err != nil {
+			return err
+		}
+	}
+
+	if u.RecoveryCodesShadow != "" {
+		if err := json.Unmarshal([]byte(u.RecoveryCodesShadow), &u.RecoveryCodes); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// MarshalStored returns the JSON form of the user kept by the key-value stores and the exact export,
+// the fields which are never responded by the API are included.
+func (u *User) MarshalStored() ([]byte, error) {
+	return json.Marshal(storedUser{
+		User:            u,
+		PasswordHistory: u.PasswordHistory,
+		TOTPSecret:      u.TOTPSecret,
+		RecoveryCodes:   u.RecoveryCodes,
+	})
+}
+
+// UnmarshalStored parses the JSON form of the user returned by MarshalStored.
+func (u *User) UnmarshalStored(data []byte) error {
+	stored := storedUser{User: u}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	u.PasswordHistory = stored.PasswordHistory
+	u.TOTPSecret = stored.TOTPSecret
+	u.RecoveryCodes = stored.RecoveryCodes
+
+	return nil
+}
+
+// MFAEnabled returns true if the user logs in with the TOTP code.
+func (u *User) MFAEnabled() bool {
+	return !u.MFAEnabledAt.IsZero() && u.TOTPSecret != ""
+}
+
+// PrepareForCreate clears the fields maintained by the server from the user sent on a create request.
+// The new user is not an administrator, and has neither the second factor, the password history, the
+// verified email nor the identity source.
+func (u *User) PrepareForCreate() {
+	u.IsAdmin = 0
+	u.PasswordHistory = nil
+	u.EmailVerifiedAt = time.Time{}
+	u.MFAEnabledAt = time.Time{}
+	u.TOTPSecret = ""
+	u.RecoveryCodes = nil
+	u.Source = ""
+	u.SourceID = ""
+	u.SourceRemoved = false
+}
+
+// PrepareForExport clears the fields populated by the system, the password hashes and the second factor
+// are only kept by exact export.
+func (u *User) PrepareForExport(opts metav1.ExportOptions) {
+	u.ObjectMeta.PrepareForExport(opts)
+	u.LoginedAt = time.Time{}
+	u.TotalPolicy = 0
+
+	if !opts.Exact {
+		u.Password = ""
+		u.PasswordChangedAt = time.Time{}
+		u.PasswordHistory = nil
+		u.EmailVerifiedAt = time.Time{}
+		u.MFAEnabledAt = time.Time{}
+		u.TOTPSecret = ""
+		u.RecoveryCodes = nil
+	}
+}
@@ -15,6 +15,8 @@
 package v1
 
 import (
+	"strings"
+
 	"github.com/dairongpeng/leona/pkg/validation"
 	"github.com/dairongpeng/leona/pkg/validation/field"
 )
@@ -24,9 +26,7 @@ func (u *User) Validate() field.ErrorList {
 	val := validation.NewValidator(u)
 	allErrs := val.Validate()
 
-	if err := validation.IsValidPassword(u.Password); err != nil {
-		allErrs = append(allErrs, field.Invalid(field.NewPath("password"), err.Error(), ""))
-	}
+	allErrs = append(allErrs, validation.ValidatePassword(u.Password, field.NewPath("password"))...)
 
 	return allErrs
 }
@@ -40,6 +40,42 @@ func (u *User) ValidateUpdate() field.ErrorList {
 	return allErrs
 }
 
+// Validate validates that a namespace object is valid.
+func (n *Namespace) Validate() field.ErrorList {
+	val := validation.NewValidator(n)
+	allErrs := val.Validate()
+
+	if errs := validation.IsDNS1123Label(n.Name); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), n.Name, strings.Join(errs, "; ")))
+	}
+
+	return allErrs
+}
+
+// Validate validates that a role object is valid.
+func (r *Role) Validate() field.ErrorList {
+	val := validation.NewValidator(r)
+	allErrs := val.Validate()
+
+	if errs := validation.IsDNS1123Label(r.Name); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), r.Name, strings.Join(errs, "; ")))
+	}
+
+	return allErrs
+}
+
+// Validate validates that a role binding object is valid.
+func (b *RoleBinding) Validate() field.ErrorList {
+	val := validation.NewValidator(b)
+	allErrs := val.Validate()
+
+	if errs := validation.IsDNS1123Label(b.Name); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), b.Name, strings.Join(errs, "; ")))
+	}
+
+	return allErrs
+}
+
 //// Validate validates that a secret object is valid.
 //func (s *Secret) Validate() field.ErrorList {
 //	val := validation.NewValidator(s)
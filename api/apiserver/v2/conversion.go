@@ -0,0 +1,149 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/scheme"
+)
+
+// addConversionFuncs registers the conversions between the v1 and v2 users.
+func addConversionFuncs(s *scheme.Scheme) {
+	s.AddConversionFunc((*v1.User)(nil), (*User)(nil), func(in, out interface{}) error {
+		return convertV1UserToV2User(in.(*v1.User), out.(*User))
+	})
+	s.AddConversionFunc((*User)(nil), (*v1.User)(nil), func(in, out interface{}) error {
+		return convertV2UserToV1User(in.(*User), out.(*v1.User))
+	})
+	s.AddConversionFunc((*v1.UserList)(nil), (*UserList)(nil), func(in, out interface{}) error {
+		return convertV1UserListToV2UserList(in.(*v1.UserList), out.(*UserList))
+	})
+	s.AddConversionFunc((*UserList)(nil), (*v1.UserList)(nil), func(in, out interface{}) error {
+		return convertV2UserListToV1UserList(in.(*UserList), out.(*v1.UserList))
+	})
+}
+
+// convertV1UserToV2User converts a stored user into v2, the password hash is never exposed.
+func convertV1UserToV2User(in *v1.User, out *User) error {
+	if in == nil {
+		return fmt.Errorf("can not convert nil user")
+	}
+
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = UserSpec{
+		Profile: UserProfile{
+			DisplayName: in.Nickname,
+			Email:       in.Email,
+			Phone:       in.Phone,
+		},
+		Admin: in.IsAdmin == 1,
+	}
+	out.Status = UserStatus{
+		Enabled:     in.Status == 1,
+		TotalPolicy: in.TotalPolicy,
+	}
+	if !in.LoginedAt.IsZero() {
+		loginedAt := in.LoginedAt
+		out.Status.LastLoginTime = &loginedAt
+	}
+	if !in.PasswordChangedAt.IsZero() {
+		passwordChangedAt := in.PasswordChangedAt
+		out.Status.PasswordChangeTime = &passwordChangedAt
+	}
+	if !in.EmailVerifiedAt.IsZero() {
+		emailVerifiedAt := in.EmailVerifiedAt
+		out.Status.EmailVerifyTime = &emailVerifiedAt
+	}
+	if !in.MFAEnabledAt.IsZero() {
+		mfaEnabledAt := in.MFAEnabledAt
+		out.Status.MFAEnableTime = &mfaEnabledAt
+	}
+
+	return nil
+}
+
+// convertV2UserToV1User converts a v2 user into the stored schema.
+func convertV2UserToV1User(in *User, out *v1.User) error {
+	if in == nil {
+		return fmt.Errorf("can not convert nil user")
+	}
+
+	out.ObjectMeta = in.ObjectMeta
+	out.Nickname = in.Spec.Profile.DisplayName
+	out.Email = in.Spec.Profile.Email
+	out.Phone = in.Spec.Profile.Phone
+	out.Password = in.Spec.Password
+	out.IsAdmin = 0
+	if in.Spec.Admin {
+		out.IsAdmin = 1
+	}
+	out.Status = 0
+	if in.Status.Enabled {
+		out.Status = 1
+	}
+	out.TotalPolicy = in.Status.TotalPolicy
+	if in.Status.LastLoginTime != nil {
+		out.LoginedAt = *in.Status.LastLoginTime
+	}
+	if in.Status.PasswordChangeTime != nil {
+		out.PasswordChangedAt = *in.Status.PasswordChangeTime
+	}
+	if in.Status.EmailVerifyTime != nil {
+		out.EmailVerifiedAt = *in.Status.EmailVerifyTime
+	}
+	if in.Status.MFAEnableTime != nil {
+		out.MFAEnabledAt = *in.Status.MFAEnableTime
+	}
+
+	return nil
+}
+
+func convertV1UserListToV2UserList(in *v1.UserList, out *UserList) error {
+	if in == nil {
+		return fmt.Errorf("can not convert nil user list")
+	}
+
+	out.ListMeta = in.ListMeta
+	out.Items = make([]*User, 0, len(in.Items))
+	for _, item := range in.Items {
+		user := &User{}
+		if err := convertV1UserToV2User(item, user); err != nil {
+			return err
+		}
+		out.Items = append(out.Items, user)
+	}
+
+	return nil
+}
+
+func convertV2UserListToV1UserList(in *UserList, out *v1.UserList) error {
+	if in == nil {
+		return fmt.Errorf("can not convert nil user list")
+	}
+
+	out.ListMeta = in.ListMeta
+	out.Items = make([]*v1.User, 0, len(in.Items))
+	for _, item := range in.Items {
+		user := &v1.User{}
+		if err := convertV2UserToV1User(item, user); err != nil {
+			return err
+		}
+		out.Items = append(out.Items, user)
+	}
+
+	return nil
+}
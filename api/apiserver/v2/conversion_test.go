@@ -0,0 +1,83 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/api/apiserver/scheme"
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	v2 "github.com/dairongpeng/leona/api/apiserver/v2"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+func TestUserConversion(t *testing.T) {
+	loginedAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	in := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin", Namespace: metav1.NamespaceDefault, ID: 1},
+		Status:     1,
+		Nickname:   "colin",
+		Password:   "hash",
+		Email:      "colin@foxmail.com",
+		Phone:      "1812884xxxx",
+		IsAdmin:    1,
+		LoginedAt:  loginedAt,
+	}
+
+	out, err := scheme.Scheme.ConvertToVersion(in, v2.SchemeGroupVersion)
+	assert.Nil(t, err)
+
+	user := out.(*v2.User)
+	assert.Equal(t, "v2", user.APIVersion)
+	assert.Equal(t, "User", user.Kind)
+	assert.Equal(t, in.ObjectMeta, user.ObjectMeta)
+	assert.Equal(t, v2.UserSpec{
+		Profile: v2.UserProfile{DisplayName: "colin", Email: "colin@foxmail.com", Phone: "1812884xxxx"},
+		Admin:   true,
+	}, user.Spec)
+	assert.Equal(t, v2.UserStatus{Enabled: true, LastLoginTime: &loginedAt}, user.Status)
+
+	user.Spec.Password = "hash"
+	back := &v1.User{}
+	assert.Nil(t, scheme.Scheme.Convert(user, back))
+	assert.Equal(t, in, back)
+}
+
+func TestUserListConversion(t *testing.T) {
+	in := &v1.UserList{
+		ListMeta: metav1.ListMeta{TotalCount: 2},
+		Items: []*v1.User{
+			{ObjectMeta: metav1.ObjectMeta{Name: "admin"}, IsAdmin: 1},
+			{ObjectMeta: metav1.ObjectMeta{Name: "colin"}},
+		},
+	}
+
+	out, err := scheme.Scheme.ConvertToVersion(in, v2.SchemeGroupVersion)
+	assert.Nil(t, err)
+
+	list := out.(*v2.UserList)
+	assert.Equal(t, "UserList", list.Kind)
+	assert.Equal(t, int64(2), list.TotalCount)
+	assert.Len(t, list.Items, 2)
+	assert.True(t, list.Items[0].Spec.Admin)
+	assert.Nil(t, list.Items[1].Status.LastLoginTime)
+
+	back, err := scheme.Scheme.ConvertToVersion(list, v1.SchemeGroupVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", back.(*v1.UserList).Items[0].Name)
+}
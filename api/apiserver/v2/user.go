@@ -0,0 +1,94 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"time"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// User represents a user restful resource in v2. The fields set by clients are grouped into spec,
+// the fields populated by the system are grouped into status.
+type User struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of the user.
+	Spec UserSpec `json:"spec"`
+
+	// Status is the observed state of the user.
+	// Populated by the system.
+	// Read-only.
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// UserSpec defines the desired state of a user.
+type UserSpec struct {
+	// Profile contains the personal information of the user.
+	Profile UserProfile `json:"profile"`
+
+	// Password is only accepted on creation, it is never returned.
+	Password string `json:"password,omitempty" validate:"omitempty"`
+
+	// Admin grants the user permission to manage the other users.
+	Admin bool `json:"admin,omitempty"`
+}
+
+// UserProfile contains the personal information of a user.
+type UserProfile struct {
+	// Required: true
+	DisplayName string `json:"displayName" validate:"required,min=1,max=30"`
+
+	// Required: true
+	Email string `json:"email" validate:"required,email,min=1,max=100"`
+
+	Phone string `json:"phone,omitempty" validate:"omitempty"`
+}
+
+// UserStatus is the observed state of a user.
+type UserStatus struct {
+	// Enabled is false once the user is disabled.
+	Enabled bool `json:"enabled"`
+
+	// LastLoginTime is the time when the user logged in lastly.
+	LastLoginTime *time.Time `json:"lastLoginTime,omitempty"`
+
+	// PasswordChangeTime is the time when the password was changed lastly.
+	PasswordChangeTime *time.Time `json:"passwordChangeTime,omitempty"`
+
+	// EmailVerifyTime is the time when the email was verified, it is empty until the email is verified.
+	EmailVerifyTime *time.Time `json:"emailVerifyTime,omitempty"`
+
+	// MFAEnableTime is the time when the TOTP authenticator was enrolled, it is empty unless the
+	// user logs in with the TOTP code.
+	MFAEnableTime *time.Time `json:"mfaEnableTime,omitempty"`
+
+	// TotalPolicy is the number of policies the user owns.
+	TotalPolicy int64 `json:"totalPolicy,omitempty"`
+}
+
+// UserList is the whole list of all users which have been stored in stroage.
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:",inline"`
+
+	Items []*User `json:"items"`
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"github.com/dairongpeng/leona/pkg/validation"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// Validate validates that a user object is valid.
+func (u *User) Validate() field.ErrorList {
+	val := validation.NewValidator(u)
+	allErrs := val.Validate()
+
+	allErrs = append(allErrs, validation.ValidatePassword(u.Spec.Password, field.NewPath("spec", "password"))...)
+
+	return allErrs
+}
+
+// ValidateUpdate validates that a user object is valid when update.
+// Like User.Validate but not validate password.
+func (u *User) ValidateUpdate() field.ErrorList {
+	val := validation.NewValidator(u)
+	allErrs := val.Validate()
+
+	return allErrs
+}
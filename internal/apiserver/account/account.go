@@ -0,0 +1,289 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package account verifies the email addresses of the users and resets their forgotten passwords
+// by the tokens sent to the email addresses. The tokens are signed, expiring and single-use.
+package account
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/mail"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the account keys in redis.
+const KeyPrefix = "account-"
+
+// secretLabel separates the key signing the tokens from the secret it is derived from, the secret may
+// be shared with the jwt tokens.
+const secretLabel = "leona account token"
+
+// The purposes of the tokens, they are also the names of the templates.
+const (
+	PurposeVerifyEmail   = "verify-email"
+	PurposePasswordReset = "password-reset"
+)
+
+// Claims are the claims of a token, the token is only valid for the user while its email address
+// and its password are the same as when the token was issued.
+type Claims struct {
+	ID        string `json:"jti"`
+	Purpose   string `json:"pur"`
+	Namespace string `json:"ns"`
+	Username  string `json:"sub"`
+	Email     string `json:"email"`
+	Password  string `json:"pwd"` // the fingerprint of the password hash
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Store defines the redis operations used to keep the issued tokens.
+// It is implemented by storage.RedisCluster.
+type Store interface {
+	GetRawKey(key string) (string, error)
+	SetRawKey(key, value string, timeout time.Duration) error
+	DeleteRawKey(key string) bool
+	IncrememntWithExpire(key string, expire int64) int64
+}
+
+// Manager issues the tokens and mails them to the users.
+// A nil Manager sends no mails, and no token is valid.
+type Manager struct {
+	options   *MailOptions
+	store     Store
+	mailer    mail.Mailer
+	secret    []byte
+	templates map[string]*template.Template
+	now       func() time.Time
+}
+
+var manager *Manager
+
+// NewManager returns a new account manager sending the mails by the mailer.
+func NewManager(opts *MailOptions, store Store, mailer mail.Mailer) (*Manager, error) {
+	templates, err := parseTemplates(opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manager = &Manager{
+		options:   opts,
+		store:     store,
+		mailer:    mailer,
+		secret:    deriveSecret(opts.Secret),
+		templates: templates,
+		now:       time.Now,
+	}
+
+	return manager, nil
+}
+
+// GetManager returns the existed account manager.
+// It is nil when the manager is not initialized.
+func GetManager() *Manager {
+	return manager
+}
+
+// storeFor returns the store running the commands in ctx, so that they are traced as a part of it.
+func (m *Manager) storeFor(ctx context.Context) Store {
+	if s, ok := m.store.(*storage.RedisCluster); ok {
+		return s.WithContext(ctx)
+	}
+
+	return m.store
+}
+
+// SendVerification mails a token verifying the email address of the user.
+func (m *Manager) SendVerification(ctx context.Context, user *v1.User) error {
+	return m.send(ctx, PurposeVerifyEmail, user)
+}
+
+// SendPasswordReset mails a token resetting the password of the user.
+func (m *Manager) SendPasswordReset(ctx context.Context, user *v1.User) error {
+	return m.send(ctx, PurposePasswordReset, user)
+}
+
+// Verify checks the signature and the expiration of the token issued for the purpose.
+// The token is not consumed.
+func (m *Manager) Verify(purpose, token string) (*Claims, error) {
+	if m == nil {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "mail is disabled")
+	}
+
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "malformed token")
+	}
+	payload, signature := token[:i], token[i+1:]
+
+	expected := m.sign(payload)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "invalid signature")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "malformed token")
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "malformed token")
+	}
+
+	if claims.Purpose != purpose {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "the token is not issued for %s", purpose)
+	}
+
+	if m.now().Unix() >= claims.ExpiresAt {
+		return nil, errors.WithCode(code.ErrAccountTokenInvalid, "the token expired")
+	}
+
+	return &claims, nil
+}
+
+// Consume makes sure the token is issued for the user and uses it up, it can not be used again.
+func (m *Manager) Consume(ctx context.Context, claims *Claims, user *v1.User) error {
+	if m == nil {
+		return errors.WithCode(code.ErrAccountTokenInvalid, "mail is disabled")
+	}
+
+	if claims.Namespace != user.Namespace || claims.Username != user.Name {
+		return errors.WithCode(code.ErrAccountTokenInvalid, "the token is not issued for the user")
+	}
+
+	// the token is invalidated once the email address or the password is changed
+	if claims.Email != user.Email || claims.Password != m.fingerprint(user.Password) {
+		return errors.WithCode(code.ErrAccountTokenInvalid, "the account changed after the token was issued")
+	}
+
+	if !m.storeFor(ctx).DeleteRawKey(tokenKey(claims.ID)) {
+		return errors.WithCode(code.ErrAccountTokenInvalid, "the token was used")
+	}
+
+	return nil
+}
+
+func (m *Manager) send(ctx context.Context, purpose string, user *v1.User) error {
+	if m == nil || user.Email == "" {
+		return nil
+	}
+
+	ttl := m.options.VerifyEmailTTL
+	if purpose == PurposePasswordReset {
+		ttl = m.options.PasswordResetTTL
+	}
+
+	// the mails are not sent again within the resend interval
+	if interval := int64(m.options.ResendInterval / time.Second); interval > 0 {
+		if m.storeFor(ctx).IncrememntWithExpire(sentKey(purpose, user), interval) > 1 {
+			log.L(ctx).Infof("%s mail was sent to user `%s/%s` recently", purpose, user.Namespace, user.Name)
+
+			return nil
+		}
+	}
+
+	expiresAt := m.now().Add(ttl)
+	token, err := m.issue(ctx, purpose, user, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	data := &templateData{User: user, Token: token, ExpiresAt: expiresAt}
+	if m.options.BaseURL != "" {
+		data.URL = strings.TrimSuffix(m.options.BaseURL, "/") + "/" + purpose + "?token=" + url.QueryEscape(token)
+	}
+
+	subject, body, err := render(m.templates[purpose], data)
+	if err != nil {
+		return err
+	}
+
+	return m.mailer.Send(ctx, &mail.Message{
+		From:    m.options.From,
+		To:      []string{user.Email},
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// issue signs a new token, it is kept until it expires or is consumed.
+func (m *Manager) issue(ctx context.Context, purpose string, user *v1.User, expiresAt time.Time) (string, error) {
+	claims := Claims{
+		ID:        uuid.Must(uuid.NewV4()).String(),
+		Purpose:   purpose,
+		Namespace: user.Namespace,
+		Username:  user.Name,
+		Email:     user.Email,
+		Password:  m.fingerprint(user.Password),
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	value := user.Namespace + "/" + user.Name
+	if err := m.storeFor(ctx).SetRawKey(tokenKey(claims.ID), value, expiresAt.Sub(m.now())); err != nil {
+		return "", errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	return payload + "." + m.sign(payload), nil
+}
+
+func (m *Manager) sign(payload string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// deriveSecret derives the key signing the tokens from the secret, so that a signature made by the
+// secret itself, e.g. of a jwt token, never validates as a token.
+func deriveSecret(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(secretLabel))
+
+	return mac.Sum(nil)
+}
+
+// fingerprint identifies the password hash without exposing it.
+func (m *Manager) fingerprint(password string) string {
+	return m.sign(password)[:16]
+}
+
+func tokenKey(id string) string {
+	return KeyPrefix + "token-" + id
+}
+
+func sentKey(purpose string, user *v1.User) string {
+	return KeyPrefix + purpose + "-sent-" + user.Namespace + "/" + user.Name
+}
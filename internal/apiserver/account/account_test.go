@@ -0,0 +1,225 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package account
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/mail"
+	"github.com/dairongpeng/leona/pkg/mail/mailtest"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// fakeStore keeps the keys in memory, the expirations are ignored.
+type fakeStore struct {
+	values map[string]string
+	counts map[string]int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[string]string{}, counts: map[string]int64{}}
+}
+
+func (s *fakeStore) GetRawKey(key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *fakeStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *fakeStore) DeleteRawKey(key string) bool {
+	_, ok := s.values[key]
+	delete(s.values, key)
+
+	return ok
+}
+
+func (s *fakeStore) IncrememntWithExpire(key string, expire int64) int64 {
+	s.counts[key]++
+
+	return s.counts[key]
+}
+
+func newUser() *v1.User {
+	return &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "user1", Namespace: metav1.NamespaceDefault},
+		Nickname:   "user1",
+		Email:      "user1@example.com",
+		Password:   "hash1",
+	}
+}
+
+func newTestManager(t *testing.T, opts *MailOptions) (*Manager, *mailtest.Server) {
+	server := mailtest.NewServer()
+	t.Cleanup(server.Close)
+
+	opts.Secret = "secret"
+	m, err := NewManager(opts, newFakeStore(), mail.NewSMTP(mail.SMTPConfig{Host: server.Host, Port: server.Port}))
+	assert.Nil(t, err)
+
+	return m, server
+}
+
+// lastToken returns the token in the last line of the mail, following the built-in templates.
+func lastToken(t *testing.T, server *mailtest.Server) string {
+	messages := server.Messages()
+	if !assert.NotEmpty(t, messages) {
+		return ""
+	}
+
+	lines := strings.Split(messages[len(messages)-1].Body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "It expires at") {
+			return lines[i-2]
+		}
+	}
+	t.Fatalf("no token in the mail: %s", messages[len(messages)-1].Body)
+
+	return ""
+}
+
+func TestManager_VerifyEmail(t *testing.T) {
+	m, server := newTestManager(t, NewMailOptions())
+	user := newUser()
+
+	assert.Nil(t, m.SendVerification(context.TODO(), user))
+	messages := server.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, []string{"user1@example.com"}, messages[0].To)
+		assert.Equal(t, "Verify your email address", messages[0].Subject)
+	}
+	token := lastToken(t, server)
+
+	_, err := m.Verify(PurposePasswordReset, token)
+	assert.True(t, errors.IsCode(err, code.ErrAccountTokenInvalid))
+
+	claims, err := m.Verify(PurposeVerifyEmail, token)
+	assert.Nil(t, err)
+	assert.Equal(t, "user1", claims.Username)
+
+	assert.Nil(t, m.Consume(context.TODO(), claims, user))
+	// the token is single-use
+	assert.True(t, errors.IsCode(m.Consume(context.TODO(), claims, user), code.ErrAccountTokenInvalid))
+}
+
+func TestManager_Verify_Invalid(t *testing.T) {
+	m, server := newTestManager(t, NewMailOptions())
+	assert.Nil(t, m.SendPasswordReset(context.TODO(), newUser()))
+	token := lastToken(t, server)
+
+	for _, invalid := range []string{"", "abc", token + "x", "x" + token} {
+		_, err := m.Verify(PurposePasswordReset, invalid)
+		assert.True(t, errors.IsCode(err, code.ErrAccountTokenInvalid), invalid)
+	}
+
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err := m.Verify(PurposePasswordReset, token)
+	assert.True(t, errors.IsCode(err, code.ErrAccountTokenInvalid))
+
+	// the signatures made by the secret itself are not valid, e.g. when it is shared with the jwt tokens
+	payload := token[:strings.LastIndexByte(token, '.')]
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+	_, err = m.Verify(PurposePasswordReset, payload+"."+base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+	assert.True(t, errors.IsCode(err, code.ErrAccountTokenInvalid))
+
+	var nilManager *Manager
+	_, err = nilManager.Verify(PurposePasswordReset, token)
+	assert.True(t, errors.IsCode(err, code.ErrAccountTokenInvalid))
+	assert.Nil(t, nilManager.SendPasswordReset(context.TODO(), newUser()))
+}
+
+func TestManager_Consume_AccountChanged(t *testing.T) {
+	opts := NewMailOptions()
+	opts.ResendInterval = 0
+	m, server := newTestManager(t, opts)
+
+	tests := []struct {
+		name   string
+		change func(user *v1.User)
+	}{
+		{name: "email changed", change: func(user *v1.User) { user.Email = "user1@example.org" }},
+		{name: "password changed", change: func(user *v1.User) { user.Password = "hash2" }},
+		{name: "another user", change: func(user *v1.User) { user.Name = "user2" }},
+		{name: "another namespace", change: func(user *v1.User) { user.Namespace = "tenant-a" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newUser()
+			assert.Nil(t, m.SendPasswordReset(context.TODO(), user))
+			claims, err := m.Verify(PurposePasswordReset, lastToken(t, server))
+			assert.Nil(t, err)
+
+			tt.change(user)
+			assert.True(t, errors.IsCode(m.Consume(context.TODO(), claims, user), code.ErrAccountTokenInvalid))
+		})
+	}
+}
+
+func TestManager_ResendInterval(t *testing.T) {
+	m, server := newTestManager(t, NewMailOptions())
+
+	assert.Nil(t, m.SendVerification(context.TODO(), newUser()))
+	assert.Nil(t, m.SendVerification(context.TODO(), newUser()))
+	assert.Nil(t, m.SendPasswordReset(context.TODO(), newUser()))
+	assert.Len(t, server.Messages(), 2)
+}
+
+func TestManager_Templates(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := `{{define "subject"}}Welcome {{.User.Nickname}}{{end}}{{define "body"}}{{.URL}}{{end}}`
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, PurposeVerifyEmail+".tmpl"), []byte(tmpl), 0o600))
+
+	opts := NewMailOptions()
+	opts.TemplateDir = dir
+	opts.BaseURL = "https://leona.example.com/"
+	m, server := newTestManager(t, opts)
+
+	assert.Nil(t, m.SendVerification(context.TODO(), newUser()))
+	assert.Nil(t, m.SendPasswordReset(context.TODO(), newUser()))
+
+	messages := server.Messages()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "Welcome user1", messages[0].Subject)
+		assert.True(t, strings.HasPrefix(messages[0].Body, "https://leona.example.com/verify-email?token="))
+		assert.Equal(t, "Reset your password", messages[1].Subject)
+		assert.Contains(t, messages[1].Body, "https://leona.example.com/password-reset?token=")
+	}
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, PurposePasswordReset+".tmpl"), []byte(`{{define "body"}`), 0o600))
+	_, err := NewManager(opts, newFakeStore(), mail.NewLog())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package account
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/dairongpeng/leona/pkg/mail"
+)
+
+// The mail drivers.
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// MailOptions contains configuration items related to the email verification and the password reset mails.
+type MailOptions struct {
+	Enable                 bool          `json:"enable"                    mapstructure:"enable"`
+	Driver                 string        `json:"driver"                    mapstructure:"driver"`
+	From                   string        `json:"from"                      mapstructure:"from"`
+	SMTPHost               string        `json:"smtp-host"                 mapstructure:"smtp-host"`
+	SMTPPort               int           `json:"smtp-port"                 mapstructure:"smtp-port"`
+	SMTPUsername           string        `json:"smtp-username"             mapstructure:"smtp-username"`
+	SMTPPassword           string        `json:"-"                         mapstructure:"smtp-password"`
+	SMTPInsecureSkipVerify bool          `json:"smtp-insecure-skip-verify" mapstructure:"smtp-insecure-skip-verify"`
+	FileDir                string        `json:"file-dir"                  mapstructure:"file-dir"`
+	TemplateDir            string        `json:"template-dir"              mapstructure:"template-dir"`
+	BaseURL                string        `json:"base-url"                  mapstructure:"base-url"`
+	Secret                 string        `json:"-"                         mapstructure:"secret"`
+	VerifyEmailTTL         time.Duration `json:"verify-email-ttl"          mapstructure:"verify-email-ttl"`
+	PasswordResetTTL       time.Duration `json:"password-reset-ttl"        mapstructure:"password-reset-ttl"`
+	ResendInterval         time.Duration `json:"resend-interval"           mapstructure:"resend-interval"`
+	Timeout                time.Duration `json:"timeout"                   mapstructure:"timeout"`
+}
+
+// NewMailOptions creates a MailOptions object with default parameters.
+func NewMailOptions() *MailOptions {
+	return &MailOptions{
+		Enable:           false,
+		Driver:           DriverLog,
+		From:             "Leona <noreply@leona.local>",
+		SMTPPort:         587,
+		FileDir:          "/var/spool/leona/mail",
+		VerifyEmailTTL:   24 * time.Hour,
+		PasswordResetTTL: time.Hour,
+		ResendInterval:   time.Minute,
+		Timeout:          10 * time.Second,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *MailOptions) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	var errors []error
+
+	switch o.Driver {
+	case DriverSMTP:
+		if o.SMTPHost == "" {
+			errors = append(errors, fmt.Errorf("--mail.smtp-host must be specified with the smtp driver"))
+		}
+		if o.SMTPPort <= 0 || o.SMTPPort > 65535 {
+			errors = append(errors, fmt.Errorf("--mail.smtp-port %v must be between 1 and 65535", o.SMTPPort))
+		}
+	case DriverFile:
+		if o.FileDir == "" {
+			errors = append(errors, fmt.Errorf("--mail.file-dir must be specified with the file driver"))
+		}
+	case DriverLog:
+	default:
+		errors = append(errors, fmt.Errorf("--mail.driver %q must be one of smtp, file and log", o.Driver))
+	}
+
+	if o.From == "" {
+		errors = append(errors, fmt.Errorf("--mail.from must be specified"))
+	}
+
+	if o.BaseURL != "" {
+		if u, err := url.Parse(o.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errors = append(errors, fmt.Errorf("--mail.base-url %q must be an absolute url", o.BaseURL))
+		}
+	}
+
+	if o.VerifyEmailTTL < time.Minute {
+		errors = append(errors, fmt.Errorf("--mail.verify-email-ttl %v must be at least 1m", o.VerifyEmailTTL))
+	}
+
+	if o.PasswordResetTTL < time.Minute {
+		errors = append(errors, fmt.Errorf("--mail.password-reset-ttl %v must be at least 1m", o.PasswordResetTTL))
+	}
+
+	if o.ResendInterval < 0 {
+		errors = append(errors, fmt.Errorf("--mail.resend-interval %v must not be negative", o.ResendInterval))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the email verification and the password reset mails for a specific
+// api server to the specified FlagSet.
+func (o *MailOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "mail.enable", o.Enable, ""+
+		"Send the email verification and the password reset mails.")
+
+	fs.StringVar(&o.Driver, "mail.driver", o.Driver, ""+
+		"How the mails are sent, one of smtp, file and log.")
+
+	fs.StringVar(&o.From, "mail.from", o.From,
+		"The sender of the mails.")
+
+	fs.StringVar(&o.SMTPHost, "mail.smtp-host", o.SMTPHost,
+		"The host of the SMTP server.")
+
+	fs.IntVar(&o.SMTPPort, "mail.smtp-port", o.SMTPPort,
+		"The port of the SMTP server.")
+
+	fs.StringVar(&o.SMTPUsername, "mail.smtp-username", o.SMTPUsername, ""+
+		"The username authenticating to the SMTP server, there is no authentication if it is empty.")
+
+	fs.StringVar(&o.SMTPPassword, "mail.smtp-password", o.SMTPPassword,
+		"The password authenticating to the SMTP server.")
+
+	fs.BoolVar(&o.SMTPInsecureSkipVerify, "mail.smtp-insecure-skip-verify", o.SMTPInsecureSkipVerify,
+		"Skip verifying the certificate of the SMTP server on STARTTLS.")
+
+	fs.StringVar(&o.FileDir, "mail.file-dir", o.FileDir,
+		"The directory the mails are written into by the file driver.")
+
+	fs.StringVar(&o.TemplateDir, "mail.template-dir", o.TemplateDir, ""+
+		"The directory of verify-email.tmpl and password-reset.tmpl overriding the built-in templates.")
+
+	fs.StringVar(&o.BaseURL, "mail.base-url", o.BaseURL, ""+
+		"The url of the frontend, the mails link to <base-url>/verify-email and <base-url>/password-reset "+
+		"with the token. The mails only contain the token if it is empty.")
+
+	fs.StringVar(&o.Secret, "mail.secret", o.Secret, ""+
+		"The secret the key signing the tokens is derived from, the jwt key is used if it is empty.")
+
+	fs.DurationVar(&o.VerifyEmailTTL, "mail.verify-email-ttl", o.VerifyEmailTTL,
+		"How long the email verification token is valid.")
+
+	fs.DurationVar(&o.PasswordResetTTL, "mail.password-reset-ttl", o.PasswordResetTTL,
+		"How long the password reset token is valid.")
+
+	fs.DurationVar(&o.ResendInterval, "mail.resend-interval", o.ResendInterval,
+		"The minimal interval between the mails of the same kind sent to an user.")
+
+	fs.DurationVar(&o.Timeout, "mail.timeout", o.Timeout,
+		"The timeout of sending a mail.")
+}
+
+// Mailer returns the mailer of the driver.
+func (o *MailOptions) Mailer() mail.Mailer {
+	switch o.Driver {
+	case DriverSMTP:
+		return mail.NewSMTP(mail.SMTPConfig{
+			Host:               o.SMTPHost,
+			Port:               o.SMTPPort,
+			Username:           o.SMTPUsername,
+			Password:           o.SMTPPassword,
+			InsecureSkipVerify: o.SMTPInsecureSkipVerify,
+			Timeout:            o.Timeout,
+		})
+	case DriverFile:
+		return mail.NewFile(o.FileDir)
+	default:
+		return mail.NewLog()
+	}
+}
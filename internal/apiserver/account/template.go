@@ -0,0 +1,109 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package account
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+)
+
+// The templates define the `subject` and the `body` templates, the data is templateData.
+var defaultTemplates = map[string]string{
+	PurposeVerifyEmail: `{{define "subject"}}Verify your email address{{end}}
+{{- define "body"}}Hello {{.User.Nickname}},
+
+Please verify the email address of the account {{.User.Namespace}}/{{.User.Name}}
+{{- if .URL}} by opening the link:
+
+{{.URL}}
+{{- else}} with the token:
+
+{{.Token}}
+{{- end}}
+
+It expires at {{.ExpiresAt.Format "2006-01-02 15:04:05 MST"}}.
+If you did not sign up or change the email address, please ignore this mail.
+{{end}}`,
+	PurposePasswordReset: `{{define "subject"}}Reset your password{{end}}
+{{- define "body"}}Hello {{.User.Nickname}},
+
+A password reset was requested for the account {{.User.Namespace}}/{{.User.Name}}.
+{{- if .URL}} Please reset the password by opening the link:
+
+{{.URL}}
+{{- else}} Please reset the password with the token:
+
+{{.Token}}
+{{- end}}
+
+It expires at {{.ExpiresAt.Format "2006-01-02 15:04:05 MST"}}.
+If you did not request it, please ignore this mail, the password is not changed.
+{{end}}`,
+}
+
+// templateData is the data the templates are executed with.
+type templateData struct {
+	User      *v1.User
+	Token     string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// parseTemplates parses the built-in templates, they are overridden by the `<purpose>.tmpl` files in dir.
+func parseTemplates(dir string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(defaultTemplates))
+	for purpose, text := range defaultTemplates {
+		if dir != "" {
+			data, err := ioutil.ReadFile(filepath.Join(dir, purpose+".tmpl"))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			if err == nil {
+				text = string(data)
+			}
+		}
+
+		tmpl, err := template.New(purpose).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		templates[purpose] = tmpl
+	}
+
+	return templates, nil
+}
+
+// render executes the template into the subject and the body of a mail.
+func render(tmpl *template.Template, data *templateData) (subject, body string, err error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(&buf, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return subject, buf.String(), nil
+}
@@ -20,41 +20,26 @@ import (
 	"sync/atomic"
 	"time"
 
-	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+	uuid "github.com/satori/go.uuid"
 
+	schema "github.com/dairongpeng/leona/pkg/analytics"
 	"github.com/dairongpeng/leona/pkg/log"
 	"github.com/dairongpeng/leona/pkg/storage"
 )
 
-const analyticsKeyName = "leona-system-analytics"
+const analyticsKeyName = schema.KeyName
 
 const (
 	recordsBufferForcedFlushInterval = 1 * time.Second
+	// spillReplayInterval is how often the spilled records are tried to be replayed.
+	spillReplayInterval = 5 * time.Second
 )
 
-// AnalyticsRecord encodes the details of a authorization request.
-type AnalyticsRecord struct {
-	TimeStamp int64     `json:"timestamp"`
-	Username  string    `json:"username"`
-	Effect    string    `json:"effect"`
-	ExpireAt  time.Time `json:"expireAt"   bson:"expireAt"`
-}
+// AnalyticsRecord encodes the details of a authorization request, the schema is shared with leona-gstash.
+type AnalyticsRecord = schema.Record
 
 var analytics *Analytics
 
-// SetExpiry set expiration time to a key.
-func (a *AnalyticsRecord) SetExpiry(expiresInSeconds int64) {
-	expiry := time.Duration(expiresInSeconds) * time.Second
-	if expiresInSeconds == 0 {
-		// Expiry is set to 100 years
-		expiry = 24 * 365 * 100 * time.Hour
-	}
-
-	t := time.Now()
-	t2 := t.Add(expiry)
-	a.ExpireAt = t2
-}
-
 // Analytics will record analytics data to a redis back end as defined in the Config object.
 type Analytics struct {
 	store                      storage.AnalyticsHandler
@@ -64,6 +49,11 @@ type Analytics struct {
 	recordsBufferFlushInterval uint64
 	shouldStop                 uint32
 	poolWg                     sync.WaitGroup
+	overflowPolicy             string
+	codec                      byte
+	spill                      *spill
+	stopCh                     chan struct{}
+	replayWg                   sync.WaitGroup
 }
 
 // NewAnalytics returns a new analytics instance.
@@ -81,13 +71,30 @@ func NewAnalytics(options *AnalyticsOptions, store storage.AnalyticsHandler) *An
 		recordsChan:                recordsChan,
 		workerBufferSize:           workerBufferSize,
 		recordsBufferFlushInterval: options.FlushInterval,
+		overflowPolicy:             options.OverflowPolicy,
+		codec:                      schema.CodecMsgpack,
+	}
+
+	if codec, err := schema.LookupCodec(options.Codec); err == nil {
+		analytics.codec = codec
+	}
+
+	// 开启本地暂存，redis 不可用时记录写入磁盘，恢复后重新投递
+	if options.SpillDir != "" {
+		s, err := newSpill(options.SpillDir, int64(options.SpillMaxSize)<<20)
+		if err != nil {
+			log.Errorf("Error opening analytics spill directory, the records are dropped while redis is unavailable: %s",
+				err.Error())
+		} else {
+			analytics.spill = s
+		}
 	}
 
 	return analytics
 }
 
 // GetAnalytics returns the existed analytics instance.
-// Need to initialize `analytics` instance before calling GetAnalytics.
+// It is nil when the analytics is not enabled, the records are dropped then.
 func GetAnalytics() *Analytics {
 	return analytics
 }
@@ -102,6 +109,12 @@ func (r *Analytics) Start() {
 		r.poolWg.Add(1)
 		go r.recordWorker()
 	}
+
+	if r.spill != nil {
+		r.stopCh = make(chan struct{})
+		r.replayWg.Add(1)
+		go r.replayWorker()
+	}
 }
 
 // Stop stop the analytics service.
@@ -114,18 +127,60 @@ func (r *Analytics) Stop() {
 
 	// wait for all workers to be done
 	r.poolWg.Wait()
+
+	// the records left are replayed by the next run
+	if r.stopCh != nil {
+		close(r.stopCh)
+		r.replayWg.Wait()
+	}
 }
 
 // RecordHit will store an AnalyticsRecord in Redis.
 func (r *Analytics) RecordHit(record *AnalyticsRecord) error {
+	if r == nil {
+		return nil
+	}
+
+	recordsTotal.Inc()
+
+	if record.ID == "" {
+		record.ID = uuid.Must(uuid.NewV4()).String()
+	}
+
 	// check if we should stop sending records 1st
 	if atomic.LoadUint32(&r.shouldStop) > 0 {
+		recordsDropped.WithLabelValues(dropReasonStopped).Inc()
+
 		return nil
 	}
 
 	// just send record to channel consumed by pool of workers
 	// leave all data crunching and Redis I/O work for pool workers
-	r.recordsChan <- record
+	switch r.overflowPolicy {
+	case OverflowDropNewest:
+		select {
+		case r.recordsChan <- record:
+		default:
+			recordsDropped.WithLabelValues(dropReasonOverflow).Inc()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case r.recordsChan <- record:
+				return nil
+			default:
+			}
+
+			// make room for the record, the oldest one may have been taken by a worker meanwhile
+			select {
+			case <-r.recordsChan:
+				recordsDropped.WithLabelValues(dropReasonOverflow).Inc()
+			default:
+			}
+		}
+	default:
+		r.recordsChan <- record
+	}
 
 	return nil
 }
@@ -146,15 +201,16 @@ func (r *Analytics) recordWorker() {
 			// check if channel was closed and it is time to exit from worker
 			if !ok {
 				// send what is left in buffer
-				r.store.AppendToSetPipelined(analyticsKeyName, recordsBuffer)
+				r.flush(recordsBuffer)
 
 				return
 			}
 
 			// we have new record - prepare it and add to buffer
 
-			if encoded, err := msgpack.Marshal(record); err != nil {
+			if encoded, err := schema.Encode(r.codec, record); err != nil {
 				log.Errorf("Error encoding analytics data: %s", err.Error())
+				encodeErrors.Inc()
 			} else {
 				recordsBuffer = append(recordsBuffer, encoded)
 			}
@@ -170,13 +226,68 @@ func (r *Analytics) recordWorker() {
 
 		// send data to Redis and reset buffer
 		if len(recordsBuffer) > 0 && (readyToSend || time.Since(lastSentTS) >= recordsBufferForcedFlushInterval) {
-			r.store.AppendToSetPipelined(analyticsKeyName, recordsBuffer)
+			r.flush(recordsBuffer)
 			recordsBuffer = recordsBuffer[:0]
 			lastSentTS = time.Now()
 		}
 	}
 }
 
+// flush sends a batch of the encoded records to redis, the batch is spilled to the disk if
+// redis is unavailable.
+func (r *Analytics) flush(recordsBuffer [][]byte) {
+	if len(recordsBuffer) == 0 {
+		return
+	}
+
+	start := time.Now()
+	err := r.store.AppendToSetPipelined(analyticsKeyName, recordsBuffer)
+	flushDuration.Observe(time.Since(start).Seconds())
+	flushBatchSize.Observe(float64(len(recordsBuffer)))
+	if err == nil {
+		return
+	}
+
+	if r.spill == nil {
+		recordsDropped.WithLabelValues(dropReasonUnavailable).Add(float64(len(recordsBuffer)))
+
+		return
+	}
+
+	if err := r.spill.Write(recordsBuffer); err != nil {
+		log.Errorf("Error spilling analytics data: %s", err.Error())
+		recordsDropped.WithLabelValues(dropReasonUnavailable).Add(float64(len(recordsBuffer)))
+
+		return
+	}
+	recordsSpilled.Add(float64(len(recordsBuffer)))
+}
+
+// replayWorker sends the spilled records to redis once it is available again.
+func (r *Analytics) replayWorker() {
+	defer r.replayWg.Done()
+
+	ticker := time.NewTicker(spillReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			if r.spill.Empty() {
+				continue
+			}
+
+			if err := r.spill.Replay(func(batch [][]byte) error {
+				return r.store.AppendToSetPipelined(analyticsKeyName, batch)
+			}); err != nil {
+				log.Debugf("Replaying the spilled analytics data failed, retry later: %s", err.Error())
+			}
+		}
+	}
+}
+
 // DurationToMillisecond convert time duration type to float64.
 func DurationToMillisecond(d time.Duration) float64 {
 	return float64(d) / 1e6
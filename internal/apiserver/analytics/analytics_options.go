@@ -19,6 +19,18 @@ import (
 	"time"
 
 	"github.com/spf13/pflag"
+
+	schema "github.com/dairongpeng/leona/pkg/analytics"
+)
+
+// The policies applied when the records buffer is full.
+const (
+	// OverflowBlock blocks the request until the buffer has room.
+	OverflowBlock = "block"
+	// OverflowDropNewest drops the record being recorded.
+	OverflowDropNewest = "drop-newest"
+	// OverflowDropOldest drops the oldest record in the buffer to make room.
+	OverflowDropOldest = "drop-oldest"
 )
 
 // AnalyticsOptions contains configuration items related to analytics.
@@ -29,6 +41,10 @@ type AnalyticsOptions struct {
 	StorageExpirationTime   time.Duration `json:"storage-expiration-time"   mapstructure:"storage-expiration-time"`
 	Enable                  bool          `json:"enable"                    mapstructure:"enable"`
 	EnableDetailedRecording bool          `json:"enable-detailed-recording" mapstructure:"enable-detailed-recording"`
+	OverflowPolicy          string        `json:"overflow-policy"           mapstructure:"overflow-policy"`
+	SpillDir                string        `json:"spill-dir"                 mapstructure:"spill-dir"`
+	SpillMaxSize            int           `json:"spill-max-size"            mapstructure:"spill-max-size"`
+	Codec                   string        `json:"codec"                     mapstructure:"codec"`
 }
 
 // NewAnalyticsOptions creates a AnalyticsOptions object with default parameters.
@@ -40,6 +56,10 @@ func NewAnalyticsOptions() *AnalyticsOptions {
 		FlushInterval:           200,
 		EnableDetailedRecording: true,
 		StorageExpirationTime:   time.Duration(24) * time.Hour,
+		OverflowPolicy:          OverflowBlock,
+		SpillDir:                "",
+		SpillMaxSize:            100,
+		Codec:                   "msgpack",
 	}
 }
 
@@ -55,6 +75,21 @@ func (o *AnalyticsOptions) Validate() []error {
 		errors = append(errors, fmt.Errorf("--analytics.flush-interval %v must be between 1 and 1000", o.FlushInterval))
 	}
 
+	switch o.OverflowPolicy {
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+	default:
+		errors = append(errors, fmt.Errorf("--analytics.overflow-policy %q must be one of %s, %s or %s",
+			o.OverflowPolicy, OverflowBlock, OverflowDropNewest, OverflowDropOldest))
+	}
+
+	if _, err := schema.LookupCodec(o.Codec); err != nil {
+		errors = append(errors, fmt.Errorf("--analytics.codec %q must be one of msgpack, json or protobuf", o.Codec))
+	}
+
+	if o.SpillDir != "" && o.SpillMaxSize <= 0 {
+		errors = append(errors, fmt.Errorf("--analytics.spill-max-size %v must be greater than 0", o.SpillMaxSize))
+	}
+
 	return errors
 }
 
@@ -80,4 +115,19 @@ func (o *AnalyticsOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&o.StorageExpirationTime, "analytics.storage-expiration-time", o.StorageExpirationTime, ""+
 		"Set to a value larger than the Gstash's purge_delay. "+
 		"This allows the analytics data to exist long enough in Redis to be processed by the Gstash.")
+
+	fs.StringVar(&o.OverflowPolicy, "analytics.overflow-policy", o.OverflowPolicy, ""+
+		"What happens when the records buffer is full, one of block, drop-newest or drop-oldest. "+
+		"The requests are delayed until the buffer has room if block.")
+
+	fs.StringVar(&o.SpillDir, "analytics.spill-dir", o.SpillDir, ""+
+		"The directory the records are spilled to while Redis is unavailable, they are replayed when "+
+		"it reconnects. The records are dropped while Redis is unavailable if empty.")
+
+	fs.IntVar(&o.SpillMaxSize, "analytics.spill-max-size", o.SpillMaxSize, ""+
+		"The size in megabytes the spilled records take at most, the records are dropped once exceeded.")
+
+	fs.StringVar(&o.Codec, "analytics.codec", o.Codec, ""+
+		"How the records are encoded, one of msgpack, json or protobuf. "+
+		"leona-gstash decodes the records by the codec they are written with.")
 }
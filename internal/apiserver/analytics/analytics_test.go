@@ -0,0 +1,179 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+type fakeStore struct {
+	lock    sync.Mutex
+	records [][]byte
+	down    bool
+}
+
+func (s *fakeStore) setDown(down bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.down = down
+}
+
+func (s *fakeStore) Connect() bool { return true }
+
+func (s *fakeStore) AppendToSetPipelined(key string, values [][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.down {
+		return storage.ErrRedisIsDown
+	}
+	s.records = append(s.records, values...)
+
+	return nil
+}
+
+func (s *fakeStore) GetAndDeleteSet(string) []interface{} { return nil }
+
+func (s *fakeStore) SetExp(string, time.Duration) error { return nil }
+
+func (s *fakeStore) GetExp(string) (int64, error) { return -1, nil }
+
+func TestAnalytics_Metrics(t *testing.T) {
+	store := &fakeStore{}
+	options := NewAnalyticsOptions()
+	options.PoolSize = 2
+	options.RecordsBufferSize = 10
+
+	total := testutil.ToFloat64(recordsTotal)
+	dropped := testutil.ToFloat64(recordsDropped.WithLabelValues(dropReasonStopped))
+
+	a := NewAnalytics(options, store)
+	assert.Equal(t, float64(10), testutil.ToFloat64(queueCapacity))
+
+	a.Start()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, a.RecordHit(&AnalyticsRecord{Username: "admin"}))
+	}
+	a.Stop()
+	assert.Nil(t, a.RecordHit(&AnalyticsRecord{Username: "admin"}))
+
+	assert.Len(t, store.records, 3)
+	assert.Equal(t, total+4, testutil.ToFloat64(recordsTotal))
+	assert.Equal(t, dropped+1, testutil.ToFloat64(recordsDropped.WithLabelValues(dropReasonStopped)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(queueDepth))
+	assert.Equal(t, 1, testutil.CollectAndCount(flushBatchSize))
+}
+
+func TestAnalytics_RecordHitOverflow(t *testing.T) {
+	options := NewAnalyticsOptions()
+	options.PoolSize = 1
+	options.RecordsBufferSize = 2
+
+	// the workers are not started, the buffer is not drained
+	options.OverflowPolicy = OverflowDropNewest
+	a := NewAnalytics(options, &fakeStore{})
+	for _, name := range []string{"a", "b", "c"} {
+		assert.Nil(t, a.RecordHit(&AnalyticsRecord{Username: name}))
+	}
+	assert.Equal(t, "a", (<-a.recordsChan).Username)
+	assert.Equal(t, "b", (<-a.recordsChan).Username)
+
+	options.OverflowPolicy = OverflowDropOldest
+	a = NewAnalytics(options, &fakeStore{})
+	for _, name := range []string{"a", "b", "c"} {
+		assert.Nil(t, a.RecordHit(&AnalyticsRecord{Username: name}))
+	}
+	assert.Equal(t, "b", (<-a.recordsChan).Username)
+	assert.Equal(t, "c", (<-a.recordsChan).Username)
+}
+
+func TestAnalytics_Spill(t *testing.T) {
+	store := &fakeStore{down: true}
+	options := NewAnalyticsOptions()
+	options.PoolSize = 1
+	options.RecordsBufferSize = 10
+	options.SpillDir = t.TempDir()
+
+	a := NewAnalytics(options, store)
+	a.Start()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, a.RecordHit(&AnalyticsRecord{Username: "admin"}))
+	}
+	a.Stop()
+
+	assert.Empty(t, store.records)
+	assert.False(t, a.spill.Empty())
+
+	// the records spilled by the previous run are replayed once redis is available
+	store.setDown(false)
+	a = NewAnalytics(options, store)
+	assert.False(t, a.spill.Empty())
+	assert.Nil(t, a.spill.Replay(func(batch [][]byte) error {
+		return store.AppendToSetPipelined(analyticsKeyName, batch)
+	}))
+
+	assert.True(t, a.spill.Empty())
+	assert.Len(t, store.records, 3)
+}
+
+func TestSpill_MaxSize(t *testing.T) {
+	s, err := newSpill(t.TempDir(), 10)
+	assert.Nil(t, err)
+
+	assert.Nil(t, s.Write([][]byte{[]byte("abcd")}))
+	assert.Equal(t, errSpillFull, s.Write([][]byte{[]byte("ef")}))
+
+	var replayed [][]byte
+	assert.Nil(t, s.Replay(func(batch [][]byte) error {
+		replayed = append(replayed, batch...)
+
+		return nil
+	}))
+	assert.Equal(t, [][]byte{[]byte("abcd")}, replayed)
+	assert.Nil(t, s.Write([][]byte{[]byte("ef")}))
+}
+
+func TestSpill_WriteWhileReplaying(t *testing.T) {
+	s, err := newSpill(t.TempDir(), 100)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Write([][]byte{[]byte("abcd")}))
+
+	// the batches are spilled while the replay is sending, they are left to the next replay
+	var replayed [][]byte
+	assert.Nil(t, s.Replay(func(batch [][]byte) error {
+		replayed = append(replayed, batch...)
+
+		return s.Write([][]byte{[]byte("ef")})
+	}))
+	assert.Equal(t, [][]byte{[]byte("abcd")}, replayed)
+	assert.False(t, s.Empty())
+
+	replayed = nil
+	assert.Nil(t, s.Replay(func(batch [][]byte) error {
+		replayed = append(replayed, batch...)
+
+		return nil
+	}))
+	assert.Equal(t, [][]byte{[]byte("ef")}, replayed)
+	assert.True(t, s.Empty())
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "leona"
+	metricsSubsystem = "apiserver_analytics"
+
+	// dropReasonStopped is the reason of the records dropped after the analytics is stopped.
+	dropReasonStopped = "stopped"
+	// dropReasonOverflow is the reason of the records dropped when the buffer is full.
+	dropReasonOverflow = "overflow"
+	// dropReasonUnavailable is the reason of the records dropped when redis is unavailable and
+	// they can not be spilled.
+	dropReasonUnavailable = "unavailable"
+	// dropReasonCorrupted is the reason of the spilled records which can not be read back.
+	dropReasonCorrupted = "corrupted"
+)
+
+// The metrics are registered to the default registry, they are served by the `/metrics` endpoint
+// of the api server.
+var (
+	recordsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_total",
+		Help:      "The number of the analytics records received.",
+	})
+
+	recordsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_dropped_total",
+		Help:      "The number of the analytics records dropped, by the reason.",
+	}, []string{"reason"})
+
+	encodeErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "encode_errors_total",
+		Help:      "The number of the analytics records failed to be encoded.",
+	})
+
+	queueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "queue_depth",
+		Help:      "The number of the analytics records waiting for the workers.",
+	}, func() float64 {
+		if a := GetAnalytics(); a != nil {
+			return float64(len(a.recordsChan))
+		}
+
+		return 0
+	})
+
+	queueCapacity = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "queue_capacity",
+		Help:      "The number of the analytics records the queue holds at most.",
+	}, func() float64 {
+		if a := GetAnalytics(); a != nil {
+			return float64(cap(a.recordsChan))
+		}
+
+		return 0
+	})
+
+	recordsSpilled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_spilled_total",
+		Help:      "The number of the analytics records spilled to the disk while redis is unavailable.",
+	})
+
+	recordsReplayed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_replayed_total",
+		Help:      "The number of the spilled analytics records sent to redis.",
+	})
+
+	spillBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "spill_bytes",
+		Help:      "The size of the analytics records spilled to the disk.",
+	})
+
+	flushDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "flush_duration_seconds",
+		Help:      "The time taken to flush a batch of analytics records to redis.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	flushBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "flush_batch_size",
+		Help:      "The number of the analytics records flushed to redis in a batch.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		recordsTotal,
+		recordsDropped,
+		encodeErrors,
+		queueDepth,
+		queueCapacity,
+		recordsSpilled,
+		recordsReplayed,
+		spillBytes,
+		flushDuration,
+		flushBatchSize,
+	)
+}
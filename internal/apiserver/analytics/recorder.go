@@ -0,0 +1,62 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recorderKey is the key of the records of a request in the gin context.
+const recorderKey = "analytics-recorder"
+
+// recorder keeps the records of a request until it is responded.
+type recorder struct {
+	start   time.Time
+	records []*AnalyticsRecord
+}
+
+// Recorder is a middleware recording the analytics records of the request once it is responded,
+// the latency and the response status of the request are filled into the records.
+func Recorder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := &recorder{start: time.Now()}
+		c.Set(recorderKey, r)
+
+		c.Next()
+
+		latency := time.Since(r.start).Milliseconds()
+		for _, record := range r.records {
+			record.Latency = latency
+			record.Status = c.Writer.Status()
+			_ = GetAnalytics().RecordHit(record)
+		}
+	}
+}
+
+// RecordRequest records the analytics record of the request once it is responded. The record is
+// stored at once, without the latency and the status, if the request is not served by Recorder.
+func RecordRequest(c *gin.Context, record *AnalyticsRecord) {
+	if v, ok := c.Get(recorderKey); ok {
+		if r, ok := v.(*recorder); ok {
+			r.records = append(r.records, record)
+
+			return
+		}
+	}
+
+	_ = GetAnalytics().RecordHit(record)
+}
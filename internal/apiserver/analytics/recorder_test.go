@@ -0,0 +1,65 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the workers are not started, the records are left in the buffer
+	a := NewAnalytics(NewAnalyticsOptions(), &fakeStore{})
+
+	g := gin.New()
+	g.Use(Recorder())
+	g.POST("/users", func(c *gin.Context) {
+		RecordRequest(c, &AnalyticsRecord{Username: "admin", Effect: "create"})
+		assert.Empty(t, a.recordsChan, "the record is stored once the request is responded")
+
+		time.Sleep(10 * time.Millisecond)
+		c.JSON(http.StatusCreated, nil)
+	})
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	assert.Len(t, a.recordsChan, 1)
+	record := <-a.recordsChan
+	assert.Equal(t, "admin", record.Username)
+	assert.Equal(t, http.StatusCreated, record.Status)
+	assert.GreaterOrEqual(t, record.Latency, int64(10))
+}
+
+func TestRecordRequest_WithoutRecorder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	a := NewAnalytics(NewAnalyticsOptions(), &fakeStore{})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	RecordRequest(c, &AnalyticsRecord{Username: "admin"})
+	assert.Len(t, a.recordsChan, 1)
+	record := <-a.recordsChan
+	assert.Zero(t, record.Status)
+	assert.Zero(t, record.Latency)
+}
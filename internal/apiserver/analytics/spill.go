@@ -0,0 +1,250 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+const (
+	spillFileSuffix = ".spill"
+	spillTempSuffix = ".tmp"
+)
+
+// errSpillFull is returned when a batch does not fit in the size of the spill directory.
+var errSpillFull = errors.New("the spilled analytics records exceed the maximum size")
+
+// spill is a write-ahead buffer on the local disk, the batches failed to be sent to redis are
+// kept there until they are replayed. Every batch is a file of the length-prefixed records, the
+// files are named by the time they are written, so that they are replayed in order.
+type spill struct {
+	dir     string
+	maxSize int64
+
+	lock sync.Mutex
+	size int64
+	seq  uint64
+
+	// replaying serializes the replays, the batches are sent without holding the lock, so that the
+	// batches are still spilled while a slow replay is sending.
+	replaying sync.Mutex
+}
+
+// newSpill opens the spill directory, the batches left by the previous run are replayed.
+func newSpill(dir string, maxSize int64) (*spill, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	s := &spill{dir: dir, maxSize: maxSize}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		switch {
+		case strings.HasSuffix(f.Name(), spillTempSuffix):
+			// interrupted while writing, the batch was not spilled
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+		case strings.HasSuffix(f.Name(), spillFileSuffix):
+			s.size += f.Size()
+		}
+	}
+	spillBytes.Set(float64(s.size))
+
+	return s, nil
+}
+
+// Write keeps the batch on the disk, errSpillFull is returned if it does not fit.
+func (s *spill) Write(batch [][]byte) error {
+	size := int64(0)
+	for _, record := range batch {
+		size += int64(4 + len(record))
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.size+size > s.maxSize {
+		return errSpillFull
+	}
+
+	s.seq++
+	name := filepath.Join(s.dir, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq%1e6, spillFileSuffix))
+	if err := writeBatch(name+spillTempSuffix, batch); err != nil {
+		_ = os.Remove(name + spillTempSuffix)
+
+		return err
+	}
+	if err := os.Rename(name+spillTempSuffix, name); err != nil {
+		_ = os.Remove(name + spillTempSuffix)
+
+		return err
+	}
+
+	s.size += size
+	spillBytes.Set(float64(s.size))
+
+	return nil
+}
+
+// Replay sends the spilled batches in order, it stops at the first batch failed to be sent. Only
+// the batches spilled before it is called are replayed, the later ones are left to the next replay.
+func (s *spill) Replay(send func(batch [][]byte) error) error {
+	s.replaying.Lock()
+	defer s.replaying.Unlock()
+
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		name := filepath.Join(s.dir, f.Name())
+		batch, err := readBatch(name)
+		if err != nil {
+			// the number of the records in the file is unknown, it is counted as one
+			log.Errorf("Dropping corrupted analytics spill file %s: %s", name, err.Error())
+			recordsDropped.WithLabelValues(dropReasonCorrupted).Inc()
+		} else {
+			if err := send(batch); err != nil {
+				return err
+			}
+			recordsReplayed.Add(float64(len(batch)))
+		}
+
+		if err := s.remove(name, f.Size()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// files returns the spilled batches in the order they are written.
+func (s *spill) files() ([]os.FileInfo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	all, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]os.FileInfo, 0, len(all))
+	for _, f := range all {
+		if strings.HasSuffix(f.Name(), spillFileSuffix) {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	return files, nil
+}
+
+// remove removes the replayed batch.
+func (s *spill) remove(name string, size int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+	s.size -= size
+	spillBytes.Set(float64(s.size))
+
+	return nil
+}
+
+// Empty returns true if there are no spilled batches.
+func (s *spill) Empty() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.size == 0
+}
+
+func writeBatch(name string, batch [][]byte) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	var length [4]byte
+	for _, record := range batch {
+		binary.BigEndian.PutUint32(length[:], uint32(len(record)))
+		if _, err := w.Write(length[:]); err != nil {
+			_ = f.Close()
+
+			return err
+		}
+		if _, err := w.Write(record); err != nil {
+			_ = f.Close()
+
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func readBatch(name string) ([][]byte, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch [][]byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		n := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) < uint64(n) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		batch = append(batch, data[:n])
+		data = data[n:]
+	}
+
+	return batch, nil
+}
@@ -38,6 +38,7 @@ func NewApp(basename string) *app.App {
 		app.WithDescription(commandDesc),
 		app.WithDefaultValidArgs(),
 		app.WithRunFunc(run(opts)),
+		app.WithCommands(newExportCommand(), newImportCommand()),
 	)
 
 	return application
@@ -15,9 +15,10 @@
 package apiserver
 
 import (
-	"context"
 	"encoding/base64"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,10 +28,23 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 
+	"github.com/dairongpeng/leona/internal/apiserver/keyring"
+	"github.com/dairongpeng/leona/internal/apiserver/ldapuser"
+	"github.com/dairongpeng/leona/internal/apiserver/lockout"
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	"github.com/dairongpeng/leona/internal/apiserver/password"
+	"github.com/dairongpeng/leona/internal/apiserver/session"
 	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
 	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/internal/pkg/middleware/auth"
+	authutil "github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/directory"
+	"github.com/dairongpeng/leona/pkg/errors"
 	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/oidc"
+	"github.com/dairongpeng/leona/pkg/util/idutil"
 )
 
 const (
@@ -39,45 +53,79 @@ const (
 
 	// APIServerIssuer defines the value of jwt issuer field.
 	APIServerIssuer = "leona-apiserver"
+
+	// maxNicknameLength is the max length of the nickname of the provisioned user.
+	maxNicknameLength = 30
+
+	// loginInterval is how often the login of the users authenticated by every request is recorded.
+	loginInterval = time.Minute
 )
 
 type loginInfo struct {
-	Username string `form:"username" json:"username" binding:"required,username"`
-	Password string `form:"password" json:"password" binding:"required,password"`
+	Namespace string `form:"namespace" json:"namespace" binding:"omitempty"`
+	Username  string `form:"username" json:"username" binding:"required,username"`
+	Password  string `form:"password" json:"password" binding:"required,password"`
 }
 
 func newBasicAuth() middleware.AuthStrategy {
-	return auth.NewBasicStrategy(func(username string, password string) bool {
+	return auth.NewBasicStrategy(func(c *gin.Context, namespace string, username string, pwd string) error {
+		failed := errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong.")
+
+		if err := checkLockout(c, namespace, username); err != nil {
+			return err
+		}
+
 		// fetch user from database
-		user, err := store.Client().Users().Get(context.TODO(), username, metav1.GetOptions{})
+		user, err := store.Client().Users().Get(c, namespace, username, metav1.GetOptions{})
 		if err != nil {
-			return false
+			if errors.IsCode(err, code.ErrUserNotFound) {
+				lockout.GetLockout().Fail(c, namespace, username, c.ClientIP())
+			}
+
+			return failed
 		}
 
 		// Compare the login password with the user password.
-		if err := user.Compare(password); err != nil {
-			return false
+		if err := user.Compare(pwd); err != nil {
+			lockout.GetLockout().Fail(c, namespace, username, c.ClientIP())
+
+			return failed
 		}
 
-		user.LoginedAt = time.Now()
-		_ = store.Client().Users().Update(context.TODO(), user, metav1.UpdateOptions{})
+		// the second factor can not be sent by the basic authentication, the failed attempts are
+		// kept since the password alone does not log the user in
+		if user.MFAEnabled() {
+			return errors.WithCode(code.ErrMFARequired, "user `%s` must log in with the second factor", username)
+		}
+		lockout.GetLockout().Succeed(c, namespace, username)
+
+		// The expired password must be changed before logging in, it is the only request authenticated.
+		if password.GetPolicy().Expired(user, time.Now()) {
+			if !changingPassword(c) {
+				return errors.WithCode(code.ErrPasswordExpired, "the password of user `%s` expired", user.Name)
+			}
+			c.Set(middleware.PasswordExpiredKey, true)
+		}
+
+		// the credentials are sent with every request, the login is recorded at most once per interval
+		if now := time.Now(); now.Sub(user.LoginedAt) >= loginInterval {
+			user.LoginedAt = now
+			_ = store.Client().Users().UpdateLoginedAt(c, user.Namespace, user.Name, now)
+		}
 
-		return true
+		return nil
 	})
 }
 
 func newJWTAuth() middleware.AuthStrategy {
 	ginjwt, _ := jwt.New(&jwt.GinJWTMiddleware{
-		Realm:            viper.GetString("jwt.Realm"),
-		SigningAlgorithm: "HS256",
-		Key:              []byte(viper.GetString("jwt.key")),
-		Timeout:          viper.GetDuration("jwt.timeout"),
-		MaxRefresh:       viper.GetDuration("jwt.max-refresh"),
-		Authenticator:    authenticator(),
-		LoginResponse:    loginResponse(),
-		LogoutResponse: func(c *gin.Context, code int) {
-			c.JSON(http.StatusOK, nil)
-		},
+		Realm:           viper.GetString("jwt.Realm"),
+		Key:             []byte(viper.GetString("jwt.key")),
+		Timeout:         viper.GetDuration("jwt.timeout"),
+		MaxRefresh:      viper.GetDuration("jwt.max-refresh"),
+		Authenticator:   authenticator(),
+		LoginResponse:   loginResponse(),
+		LogoutResponse:  logoutResponse(),
 		RefreshResponse: refreshResponse(),
 		PayloadFunc:     payloadFunc(),
 		IdentityHandler: func(c *gin.Context) interface{} {
@@ -85,25 +133,181 @@ func newJWTAuth() middleware.AuthStrategy {
 
 			return claims[jwt.IdentityKey]
 		},
-		IdentityKey:  middleware.UsernameKey,
-		Authorizator: authorizator(),
-		Unauthorized: func(c *gin.Context, code int, message string) {
-			c.JSON(code, gin.H{
-				"message": message,
-			})
-		},
-		TokenLookup:   "header: Authorization, query: token, cookie: jwt",
-		TokenHeadName: "Bearer",
-		SendCookie:    true,
-		TimeFunc:      time.Now,
-		// TODO: HTTPStatusMessageFunc:
+		IdentityKey:           middleware.UsernameKey,
+		Authorizator:          authorizator(),
+		HTTPStatusMessageFunc: httpStatusMessage(),
+		Unauthorized:          unauthorized(),
+		TokenLookup:           "header: Authorization, query: token, cookie: jwt",
+		TokenHeadName:         "Bearer",
+		SendCookie:            true,
+		TimeFunc:              time.Now,
+	})
+
+	// the tokens are signed with jwt.key by HS256, unless the asymmetric keys are rotated by the key ring
+	var keys auth.KeySet
+	if ring := keyring.GetRing(); ring != nil {
+		keys = ring
+	}
+
+	return auth.NewJWTStrategy(*ginjwt, keys, validateSession, validatePasswordExpired)
+}
+
+// serveJWKS responds with the public keys verifying the tokens, the HS256 secret key is never published.
+func serveJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keyring.GetRing().KeySet())
+}
+
+func newOIDCAuth() auth.OIDCStrategy {
+	verifier := oidc.NewVerifier(oidc.Config{
+		IssuerURL:    viper.GetString("oidc.issuer-url"),
+		ClientID:     viper.GetString("oidc.client-id"),
+		JWKSCacheTTL: viper.GetDuration("oidc.jwks-cache-ttl"),
 	})
 
-	return auth.NewJWTStrategy(*ginjwt)
+	return auth.NewOIDCStrategy(verifier, oidcLogin)
 }
 
 func newAutoAuth() middleware.AuthStrategy {
-	return auth.NewAutoStrategy(newBasicAuth().(auth.BasicStrategy), newJWTAuth().(auth.JWTStrategy))
+	auto := auth.NewAutoStrategy(newBasicAuth().(auth.BasicStrategy), newJWTAuth().(auth.JWTStrategy))
+	if viper.GetBool("oidc.enable") {
+		auto = auto.WithOIDC(newOIDCAuth())
+	}
+	if ldapuser.GetMirror() != nil {
+		auto = auto.WithLDAP(newLDAPAuth())
+	}
+
+	return auto
+}
+
+// newLDAPAuth authenticates the basic credentials against the LDAP directory, the users not in
+// the directory are authenticated by the basic strategy.
+func newLDAPAuth() auth.LDAPStrategy {
+	mirror := ldapuser.GetMirror()
+
+	return auth.NewLDAPStrategy(mirror.Directory(), mirror.Namespace(), loginGuard{}, ldapLogin, newBasicAuth())
+}
+
+// loginGuard locks the directory users out after the failed logins, as the local users.
+type loginGuard struct{}
+
+func (loginGuard) Check(c *gin.Context, namespace, username string) error {
+	return checkLockout(c, namespace, username)
+}
+
+func (loginGuard) Fail(c *gin.Context, namespace, username string) {
+	lockout.GetLockout().Fail(c, namespace, username, c.ClientIP())
+}
+
+func (loginGuard) Succeed(c *gin.Context, namespace, username string) {
+	lockout.GetLockout().Succeed(c, namespace, username)
+}
+
+// ldapLogin mirrors the directory user into the user store on each login.
+func ldapLogin(c *gin.Context, entry *directory.Entry) (string, string, error) {
+	user, err := ldapuser.GetMirror().Login(c, entry)
+	if err != nil {
+		return "", "", err
+	}
+
+	return user.Namespace, user.Name, nil
+}
+
+// oidcLogin maps the claims of the ID token to the local user, the unknown user is provisioned
+// when oidc.provision is enabled.
+func oidcLogin(c *gin.Context, claims oidc.Claims) (string, string, error) {
+	usernameClaim := viper.GetString("oidc.username-claim")
+	username := claims.String(usernameClaim)
+	if username == "" {
+		return "", "", errors.WithCode(code.ErrSignatureInvalid, "the ID token has no `%s` claim", usernameClaim)
+	}
+
+	namespace := metav1.NamespaceDefault
+	if claim := viper.GetString("oidc.namespace-claim"); claim != "" && claims.String(claim) != "" {
+		namespace = claims.String(claim)
+	}
+
+	// the disabled users are looked up as well, so that they are rejected rather than provisioned again
+	user, err := store.Client().Users().Get(c, namespace, username, metav1.GetOptions{IncludeDisabled: true})
+	if errors.IsCode(err, code.ErrUserNotFound) && viper.GetBool("oidc.provision") {
+		user, err = provisionUser(c, namespace, username, claims)
+	}
+	if errors.IsCode(err, code.ErrUserNotFound) {
+		return "", "", errors.WithCode(code.ErrPermissionDenied, "user `%s/%s` of the ID token does not exist",
+			namespace, username)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	if user.Status == 0 {
+		return "", "", errors.WithCode(code.ErrPermissionDenied, "user `%s/%s` is disabled", namespace, username)
+	}
+
+	// the ID token is sent with every request, the user logs in when the provider issues a new one
+	loginedAt := claims.Time("auth_time")
+	if loginedAt.IsZero() {
+		loginedAt = claims.Time("iat")
+	}
+	if loginedAt.After(user.LoginedAt) {
+		_ = store.Client().Users().UpdateLoginedAt(c, namespace, username, loginedAt)
+	}
+
+	return namespace, username, nil
+}
+
+// provisionUser creates the user of the ID token. The password of the user is random, the user
+// logs in through the provider.
+func provisionUser(c *gin.Context, namespace, username string, claims oidc.Claims) (*v1.User, error) {
+	if namespace != metav1.NamespaceDefault {
+		ns, err := store.Client().Namespaces().Get(c, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if ns.Phase == v1.NamespaceTerminating {
+			return nil, errors.WithCode(code.ErrNamespaceTerminating, "namespace %s is being terminated", namespace)
+		}
+	}
+
+	nickname := []rune(claims.String(viper.GetString("oidc.nickname-claim")))
+	if len(nickname) == 0 {
+		nickname = []rune(username)
+	}
+	if len(nickname) > maxNicknameLength {
+		nickname = nickname[:maxNicknameLength]
+	}
+
+	password, err := authutil.Encrypt(idutil.NewSecretKey())
+	if err != nil {
+		return nil, errors.WithCode(code.ErrEncrypt, err.Error())
+	}
+
+	now := time.Now()
+	user := &v1.User{
+		ObjectMeta:        metav1.ObjectMeta{Name: username, Namespace: namespace},
+		Nickname:          string(nickname),
+		Email:             claims.String(viper.GetString("oidc.email-claim")),
+		Password:          password,
+		PasswordChangedAt: now,
+		Status:            1,
+		LoginedAt:         now,
+	}
+	if errs := user.ValidateUpdate(); len(errs) != 0 {
+		return nil, errors.WrapC(errs.ToAggregate(), code.ErrValidation,
+			"the ID token can not provision user `%s/%s`", namespace, username)
+	}
+
+	if err := store.Client().Users().Create(c, user, metav1.CreateOptions{}); err != nil {
+		// the user is provisioned by a concurrent login
+		if errors.IsCode(err, code.ErrUserAlreadyExist) {
+			return store.Client().Users().Get(c, namespace, username, metav1.GetOptions{IncludeDisabled: true})
+		}
+
+		return nil, err
+	}
+	log.L(c).Infof("user `%s/%s` is provisioned by the ID token.", namespace, username)
+
+	return user, nil
 }
 
 func authenticator() func(c *gin.Context) (interface{}, error) {
@@ -121,26 +325,163 @@ func authenticator() func(c *gin.Context) (interface{}, error) {
 			return "", jwt.ErrFailedAuthentication
 		}
 
+		if login.Namespace == "" {
+			login.Namespace = metav1.NamespaceDefault
+		}
+
+		if err := checkLockout(c, login.Namespace, login.Username); err != nil {
+			return "", err
+		}
+
 		// Get the user information by the login username.
-		user, err := store.Client().Users().Get(c, login.Username, metav1.GetOptions{})
+		user, err := store.Client().Users().Get(c, login.Namespace, login.Username, metav1.GetOptions{})
 		if err != nil {
 			log.Errorf("get user information failed: %s", err.Error())
+			if errors.IsCode(err, code.ErrUserNotFound) {
+				lockout.GetLockout().Fail(c, login.Namespace, login.Username, c.ClientIP())
+			}
 
 			return "", jwt.ErrFailedAuthentication
 		}
 
 		// Compare the login password with the user password.
 		if err := user.Compare(login.Password); err != nil {
+			lockout.GetLockout().Fail(c, login.Namespace, login.Username, c.ClientIP())
+
+			return "", jwt.ErrFailedAuthentication
+		}
+
+		// the users who enrolled an authenticator log in with the second factor by the MFA token. The
+		// failed attempts are cleared once it is verified, otherwise logging in with the password again
+		// would reset the backoff of guessing the second factor.
+		if user.MFAEnabled() {
+			log.L(c).Infof("the password of user `%s/%s` is verified, the second factor is challenged.",
+				user.Namespace, user.Name)
+
+			return "", challengeMFA(c, user)
+		}
+		lockout.GetLockout().Succeed(c, login.Namespace, login.Username)
+
+		user.LoginedAt = time.Now()
+		_ = store.Client().Users().UpdateLoginedAt(c, user.Namespace, user.Name, user.LoginedAt)
+
+		return startSession(c, user), nil
+	}
+}
+
+// newMFAAuth logs in the users with the MFA token responded by the password login and the TOTP
+// code or a recovery code, the tokens are issued as the password login does.
+func newMFAAuth(jwtStrategy auth.JWTStrategy) auth.JWTStrategy {
+	jwtStrategy.Authenticator = mfaAuthenticator()
+
+	return jwtStrategy
+}
+
+type mfaLoginInfo struct {
+	MFAToken string `form:"mfaToken" json:"mfaToken" binding:"required"`
+	Code     string `form:"code"     json:"code"     binding:"required"`
+}
+
+func mfaAuthenticator() func(c *gin.Context) (interface{}, error) {
+	return func(c *gin.Context) (interface{}, error) {
+		var login mfaLoginInfo
+		if err := core.ShouldBindBody(c, &login); err != nil {
+			log.Errorf("parse mfa login parameters: %s", err.Error())
+
+			return "", jwt.ErrFailedAuthentication
+		}
+
+		namespace, username, err := mfa.GetManager().Lookup(c, login.MFAToken)
+		if err != nil {
+			return "", err
+		}
+
+		if err := checkLockout(c, namespace, username); err != nil {
+			return "", err
+		}
+
+		user, err := store.Client().Users().Get(c, namespace, username, metav1.GetOptions{})
+		if err != nil {
+			log.Errorf("get user information failed: %s", err.Error())
+
 			return "", jwt.ErrFailedAuthentication
 		}
 
+		// the wrong codes are counted as the wrong passwords
+		recovered, err := mfa.GetManager().Verify(c, user, login.Code)
+		if err != nil {
+			lockout.GetLockout().Fail(c, namespace, username, c.ClientIP())
+
+			return "", err
+		}
+		if err := mfa.GetManager().Complete(c, login.MFAToken); err != nil {
+			return "", err
+		}
+		lockout.GetLockout().Succeed(c, namespace, username)
+
 		user.LoginedAt = time.Now()
-		_ = store.Client().Users().Update(c, user, metav1.UpdateOptions{})
+		if recovered {
+			// the used recovery code must be removed
+			if err := store.Client().Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+				return "", err
+			}
+		} else {
+			_ = store.Client().Users().UpdateLoginedAt(c, user.Namespace, user.Name, user.LoginedAt)
+		}
 
-		return user, nil
+		return startSession(c, user), nil
 	}
 }
 
+// challengeMFA keeps the MFA token in the context for the unauthorized response, with which the
+// user logs in with the second factor.
+func challengeMFA(c *gin.Context, user *v1.User) error {
+	token, expire, err := mfa.GetManager().Challenge(c, user)
+	if err != nil {
+		log.L(c).Errorf("challenge the second factor of user `%s/%s` failed: %s", user.Namespace, user.Name, err.Error())
+
+		return jwt.ErrFailedAuthentication
+	}
+	c.Set(mfaChallengeKey, mfaChallenge{MFAToken: token, Expire: expire.Format(time.RFC3339)})
+
+	return errors.WithCode(code.ErrMFARequired, "user `%s` must log in with the second factor", user.Name)
+}
+
+// startSession starts the session of the logged in user.
+// The token of the user whose password expired is only authenticated to change the password.
+func startSession(c *gin.Context, user *v1.User) *loginSession {
+	// the session lasts as long as its token is refreshed
+	s := session.GetManager().Create(c, user.Namespace, user.Name, c.ClientIP(), c.Request.UserAgent(),
+		user.LoginedAt.Add(viper.GetDuration("jwt.timeout")))
+
+	expired := password.GetPolicy().Expired(user, time.Now())
+	if expired {
+		c.Set(middleware.PasswordExpiredKey, true)
+	}
+
+	return &loginSession{user: user, session: s, passwordExpired: expired}
+}
+
+// changingPassword reports whether the request changes the password of an user, which is the only
+// request the users whose passwords expired are authenticated to.
+func changingPassword(c *gin.Context) bool {
+	return strings.HasSuffix(c.FullPath(), "/change-password")
+}
+
+// checkLockout returns an error when the user or the client ip is locked out because of
+// too many failed login attempts, the `Retry-After` header is set to the remaining lockout.
+func checkLockout(c *gin.Context, namespace, username string) error {
+	remaining := lockout.GetLockout().Check(c, namespace, username, c.ClientIP())
+	if remaining <= 0 {
+		return nil
+	}
+
+	retryAfter := int64(math.Ceil(remaining.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+	return errors.WithCode(code.ErrAccountLocked, "too many failed login attempts, retry after %d seconds", retryAfter)
+}
+
 func parseWithHeader(c *gin.Context) (loginInfo, error) {
 	auth := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
 	if len(auth) != 2 || auth[0] != "Basic" {
@@ -163,15 +504,18 @@ func parseWithHeader(c *gin.Context) (loginInfo, error) {
 		return loginInfo{}, jwt.ErrFailedAuthentication
 	}
 
+	namespace, username := middleware.SplitNamespacedName(pair[0])
+
 	return loginInfo{
-		Username: pair[0],
-		Password: pair[1],
+		Namespace: namespace,
+		Username:  username,
+		Password:  pair[1],
 	}, nil
 }
 
 func parseWithBody(c *gin.Context) (loginInfo, error) {
 	var login loginInfo
-	if err := c.ShouldBindJSON(&login); err != nil {
+	if err := core.ShouldBindBody(c, &login); err != nil {
 		log.Errorf("parse login parameters: %s", err.Error())
 
 		return loginInfo{}, jwt.ErrFailedAuthentication
@@ -180,8 +524,84 @@ func parseWithBody(c *gin.Context) (loginInfo, error) {
 	return login, nil
 }
 
+// httpStatusMessage keeps the error in the context, so that unauthorized can respond
+// with its code.
+func httpStatusMessage() func(e error, c *gin.Context) string {
+	return func(e error, c *gin.Context) string {
+		_ = c.Error(e)
+
+		return e.Error()
+	}
+}
+
+// mfaChallengeKey is the context key of the MFA token responded by the password login.
+const mfaChallengeKey = "mfaChallenge"
+
+// mfaChallenge is responded when the user logs in with the password, but has enrolled an authenticator.
+type mfaChallenge struct {
+	core.ErrResponse
+
+	// MFAToken is posted to /login/mfa with the TOTP code or a recovery code.
+	MFAToken string `json:"mfaToken"`
+	Expire   string `json:"expire"`
+}
+
+// authenticatorErrors are the coded errors of the authenticators which are responded as they are.
+var authenticatorErrors = []int{
+	code.ErrAccountLocked,
+	code.ErrPasswordExpired,
+	code.ErrMFATokenInvalid,
+	code.ErrMFACodeInvalid,
+}
+
+func unauthorized() func(c *gin.Context, status int, message string) {
+	return func(c *gin.Context, status int, message string) {
+		last := c.Errors.Last()
+		if last != nil && errors.IsCode(last.Err, code.ErrMFARequired) {
+			if v, ok := c.Get(mfaChallengeKey); ok {
+				challenge, _ := v.(mfaChallenge)
+				coder := errors.ParseCoder(last.Err)
+				challenge.Code, challenge.Message = coder.Code(), coder.String()
+				c.JSON(coder.HTTPStatus(), challenge)
+
+				return
+			}
+		}
+
+		// the coded errors of the authenticator are responded as they are
+		for _, authenticatorError := range authenticatorErrors {
+			if last != nil && errors.IsCode(last.Err, authenticatorError) {
+				core.WriteResponse(c, last.Err, nil)
+
+				return
+			}
+		}
+
+		c.JSON(status, gin.H{
+			"message": message,
+		})
+	}
+}
+
+// logoutResponse revokes the session of the token.
+func logoutResponse() func(c *gin.Context, code int) {
+	return func(c *gin.Context, code int) {
+		claims := jwt.ExtractClaims(c)
+		if id, ok := claims["jti"].(string); ok {
+			namespace, username := sessionOwner(claims)
+			_ = session.GetManager().Revoke(c, namespace, username, id)
+		}
+
+		c.JSON(http.StatusOK, nil)
+	}
+}
+
 func refreshResponse() func(c *gin.Context, code int, token string, expire time.Time) {
 	return func(c *gin.Context, code int, token string, expire time.Time) {
+		if id, ok := jwt.ExtractClaims(c)["jti"].(string); ok {
+			session.GetManager().Refresh(c, id, expire)
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"token":  token,
 			"expire": expire.Format(time.RFC3339),
@@ -191,11 +611,66 @@ func refreshResponse() func(c *gin.Context, code int, token string, expire time.
 
 func loginResponse() func(c *gin.Context, code int, token string, expire time.Time) {
 	return func(c *gin.Context, code int, token string, expire time.Time) {
-		c.JSON(http.StatusOK, gin.H{
+		resp := gin.H{
 			"token":  token,
 			"expire": expire.Format(time.RFC3339),
-		})
+		}
+		// the token is only authenticated to change the password
+		if c.GetBool(middleware.PasswordExpiredKey) {
+			resp[passwordExpiredClaim] = true
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// passwordExpiredClaim is the claim of the tokens issued to the users whose passwords expired.
+const passwordExpiredClaim = "passwordExpired"
+
+// loginSession is passed from the authenticator to the payload func.
+type loginSession struct {
+	user            *v1.User
+	session         *session.Session
+	passwordExpired bool
+}
+
+// validateSession rejects the tokens of the revoked sessions.
+func validateSession(c *gin.Context, claims jwt.MapClaims) error {
+	id, _ := claims["jti"].(string)
+	iat, _ := claims["iat"].(float64)
+	namespace, username := sessionOwner(claims)
+
+	if session.GetManager().Revoked(c, namespace, username, id, time.Unix(int64(iat), 0)) {
+		return errors.WithCode(code.ErrTokenRevoked, "the session of the token has been revoked")
 	}
+
+	return nil
+}
+
+// validatePasswordExpired rejects the tokens issued with the expired passwords, unless they change
+// the passwords.
+func validatePasswordExpired(c *gin.Context, claims jwt.MapClaims) error {
+	if expired, _ := claims[passwordExpiredClaim].(bool); !expired {
+		return nil
+	}
+
+	if !changingPassword(c) {
+		return errors.WithCode(code.ErrPasswordExpired, "the password expired, it must be changed")
+	}
+	c.Set(middleware.PasswordExpiredKey, true)
+
+	return nil
+}
+
+// sessionOwner returns the user the token is issued to.
+func sessionOwner(claims jwt.MapClaims) (namespace string, username string) {
+	username, _ = claims[jwt.IdentityKey].(string)
+	namespace, _ = claims[middleware.NamespaceKey].(string)
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	return namespace, username
 }
 
 func payloadFunc() func(data interface{}) jwt.MapClaims {
@@ -204,9 +679,16 @@ func payloadFunc() func(data interface{}) jwt.MapClaims {
 			"iss": APIServerIssuer,
 			"aud": APIServerAudience,
 		}
-		if u, ok := data.(*v1.User); ok {
-			claims[jwt.IdentityKey] = u.Name
-			claims["sub"] = u.Name
+		if s, ok := data.(*loginSession); ok {
+			claims[jwt.IdentityKey] = s.user.Name
+			claims["sub"] = s.user.Name
+			claims[middleware.NamespaceKey] = s.user.Namespace
+			// the session id and the login time are kept when the token is refreshed
+			claims["jti"] = s.session.ID
+			claims["iat"] = s.session.LoginedAt.Unix()
+			if s.passwordExpired {
+				claims[passwordExpiredClaim] = true
+			}
 		}
 
 		return claims
@@ -216,7 +698,13 @@ func payloadFunc() func(data interface{}) jwt.MapClaims {
 func authorizator() func(data interface{}, c *gin.Context) bool {
 	return func(data interface{}, c *gin.Context) bool {
 		if v, ok := data.(string); ok {
-			log.L(c).Infof("user `%s` is authenticated.", v)
+			namespace, _ := jwt.ExtractClaims(c)[middleware.NamespaceKey].(string)
+			if namespace == "" {
+				namespace = metav1.NamespaceDefault
+			}
+			c.Set(middleware.NamespaceKey, namespace)
+
+			log.L(c).Infof("user `%s/%s` is authenticated.", namespace, v)
 
 			return true
 		}
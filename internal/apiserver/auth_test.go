@@ -0,0 +1,664 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/ldapuser"
+	"github.com/dairongpeng/leona/internal/apiserver/lockout"
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	"github.com/dairongpeng/leona/internal/apiserver/password"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/auth"
+	authutil "github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/directory"
+	"github.com/dairongpeng/leona/pkg/directory/directorytest"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/jwks"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/oidc"
+	"github.com/dairongpeng/leona/pkg/oidc/oidctest"
+	"github.com/dairongpeng/leona/pkg/storage"
+	"github.com/dairongpeng/leona/pkg/totp"
+)
+
+func TestOIDCAuth(t *testing.T) {
+	issuer := oidctest.NewIssuer(jwks.ES256)
+	defer issuer.Close()
+
+	storeIns, _ := fake.GetFakeFactoryOr()
+	store.SetClient(storeIns)
+
+	viper.Set("jwt.key", "oidc-test-key")
+	viper.Set("oidc.enable", true)
+	viper.Set("oidc.issuer-url", issuer.URL)
+	viper.Set("oidc.client-id", "leona")
+	viper.Set("oidc.username-claim", "preferred_username")
+	viper.Set("oidc.email-claim", "email")
+	viper.Set("oidc.nickname-claim", "name")
+	defer viper.Set("oidc.enable", false)
+
+	logined := time.Now().Add(time.Minute).Truncate(time.Second)
+	for _, user := range []*v1.User{
+		{ObjectMeta: metav1.ObjectMeta{Name: "oidc-carol"}, Email: "carol@example.com", Status: 1, LoginedAt: logined},
+		{ObjectMeta: metav1.ObjectMeta{Name: "oidc-dave"}, Email: "dave@example.com", Status: 0},
+	} {
+		user.Namespace = metav1.NamespaceDefault
+		assert.NoError(t, storeIns.Users().Create(context.Background(), user, metav1.CreateOptions{}))
+	}
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.GET("/whoami", newAutoAuth().AuthFunc(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(middleware.NamespaceKey)+"/"+c.GetString(middleware.UsernameKey))
+	})
+
+	tests := []struct {
+		name      string
+		provision bool
+		claims    map[string]interface{}
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:     "existing user",
+			claims:   map[string]interface{}{"aud": "leona", "preferred_username": "oidc-carol"},
+			wantCode: http.StatusOK,
+			wantBody: "default/oidc-carol",
+		},
+		{
+			name:      "disabled user",
+			provision: true,
+			claims:    map[string]interface{}{"aud": "leona", "preferred_username": "oidc-dave"},
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:     "unknown user",
+			claims:   map[string]interface{}{"aud": "leona", "preferred_username": "oidc-alice"},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:      "provisioned user",
+			provision: true,
+			claims: map[string]interface{}{
+				"aud": "leona", "preferred_username": "oidc-alice", "email": "alice@example.com", "name": "Alice",
+			},
+			wantCode: http.StatusOK,
+			wantBody: "default/oidc-alice",
+		},
+		{
+			name:      "invalid user",
+			provision: true,
+			claims:    map[string]interface{}{"aud": "leona", "preferred_username": "oidc-bob"},
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			name:     "no username",
+			claims:   map[string]interface{}{"aud": "leona"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "another audience",
+			claims:   map[string]interface{}{"aud": "other", "preferred_username": "user1"},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("oidc.provision", tt.provision)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.Header.Set("Authorization", "Bearer "+issuer.IDToken(tt.claims))
+			g.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	user, err := store.Client().Users().Get(context.Background(), metav1.NamespaceDefault, "oidc-alice", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Alice", user.Nickname)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Error(t, user.Compare(""))
+	}
+
+	// the tokens issued before the last login do not log the user in again
+	user, err = store.Client().Users().Get(context.Background(), metav1.NamespaceDefault, "oidc-carol", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.True(t, logined.Equal(user.LoginedAt), user.LoginedAt)
+	}
+
+	// the tokens of the other issuers are authenticated by the jwt strategy
+	other := oidctest.NewIssuer(jwks.ES256)
+	defer other.Close()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+other.IDToken(map[string]interface{}{"aud": "leona"}))
+	g.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid signing algorithm")
+}
+
+// statusStore hides the disabled users unless they are asked for, as the mysql store does.
+type statusStore struct {
+	store.Factory
+}
+
+func (s statusStore) Users() store.UserStore {
+	return statusUsers{s.Factory.Users()}
+}
+
+type statusUsers struct {
+	store.UserStore
+}
+
+func (s statusUsers) Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error) {
+	user, err := s.UserStore.Get(ctx, namespace, username, opts)
+	if err == nil && user.Status == 0 && !opts.IncludeDisabled {
+		return nil, errors.WithCode(code.ErrUserNotFound, "record not found")
+	}
+
+	return user, err
+}
+
+func TestOIDCLogin_DisabledUser(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	store.SetClient(statusStore{storeIns})
+	defer store.SetClient(storeIns)
+
+	viper.Set("oidc.username-claim", "preferred_username")
+	viper.Set("oidc.provision", true)
+	defer viper.Set("oidc.provision", false)
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "oidc-erin", Namespace: metav1.NamespaceDefault},
+		Email:      "erin@example.com",
+		Status:     0,
+	}
+	assert.NoError(t, storeIns.Users().Create(context.Background(), user, metav1.CreateOptions{}))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	_, _, err := oidcLogin(c, oidc.Claims{
+		"aud": "leona", "preferred_username": "oidc-erin", "email": "erin@example.com",
+	})
+	assert.True(t, errors.IsCode(err, code.ErrPermissionDenied), err)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "is disabled")
+}
+
+func TestLDAPAuth(t *testing.T) {
+	server := directorytest.NewServer()
+	defer server.Close()
+
+	for _, uid := range []string{"ldap-alice", "ldap-erin", "user2"} {
+		server.Add(directorytest.Entry{
+			DN:       "uid=" + uid + ",ou=people,dc=example,dc=com",
+			Password: uid + "-password",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {uid},
+				"mail":        {uid + "@example.com"},
+				"cn":          {uid},
+				"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com"},
+			},
+		})
+	}
+
+	storeIns, _ := fake.GetFakeFactoryOr()
+	store.SetClient(storeIns)
+
+	password, _ := authutil.Encrypt("Carol@2020")
+	carol := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap-carol", Namespace: "ldap-auth"},
+		Nickname:   "carol",
+		Email:      "carol@example.com",
+		Password:   password,
+		Status:     1,
+	}
+	assert.NoError(t, storeIns.Users().Create(context.Background(), carol, metav1.CreateOptions{}))
+
+	ldapuser.NewMirror(directory.New(directory.Config{
+		URL:               server.URL,
+		BaseDN:            "ou=people,dc=example,dc=com",
+		UserFilter:        "(objectClass=person)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		NicknameAttribute: "cn",
+		GroupAttribute:    "memberOf",
+		AdminGroups:       []string{"cn=admins,ou=groups,dc=example,dc=com"},
+		Timeout:           5 * time.Second,
+	}), storeIns, metav1.NamespaceDefault, 0)
+
+	viper.Set("jwt.key", "ldap-test-key")
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.GET("/whoami", newAutoAuth().AuthFunc(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(middleware.NamespaceKey)+"/"+c.GetString(middleware.UsernameKey))
+	})
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "directory user",
+			username: "ldap-alice",
+			password: "ldap-alice-password",
+			wantCode: http.StatusOK,
+			wantBody: "default/ldap-alice",
+		},
+		{
+			name:     "qualified directory user",
+			username: "default/ldap-alice",
+			password: "ldap-alice-password",
+			wantCode: http.StatusOK,
+			wantBody: "default/ldap-alice",
+		},
+		{
+			name:     "wrong password",
+			username: "ldap-alice",
+			password: "user2-password",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "local user of the same name",
+			username: "user2",
+			password: "user2-password",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "local user of another namespace",
+			username: "ldap-auth/ldap-carol",
+			password: "Carol@2020",
+			wantCode: http.StatusOK,
+			wantBody: "ldap-auth/ldap-carol",
+		},
+		{
+			name:     "unknown user",
+			username: "ldap-dave",
+			password: "ldap-dave-password",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.SetBasicAuth(tt.username, tt.password)
+			g.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	user, err := storeIns.Users().Get(context.Background(), metav1.NamespaceDefault, "ldap-alice", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ldap-alice@example.com", user.Email)
+		assert.Equal(t, 1, user.IsAdmin)
+		assert.Equal(t, ldapuser.Source, user.Source)
+	}
+
+	// the wrong passwords lock the directory users out, they are not bound until the lockout ends
+	opts := lockout.NewLockoutOptions()
+	opts.MaxAttempts = 2
+	opts.IPMaxAttempts = 0
+	lockout.NewLockout(opts, lockoutStore{mfaStore: mfaStore{}, windows: map[string]int{}})
+	defer func() { opts.MaxAttempts = 0 }()
+
+	for _, tt := range []struct {
+		password string
+		wantCode int
+	}{
+		{password: "ldap-alice-password", wantCode: http.StatusUnauthorized},
+		{password: "ldap-alice-password", wantCode: http.StatusUnauthorized},
+		{password: "ldap-erin-password", wantCode: http.StatusTooManyRequests},
+	} {
+		binds := server.Binds()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.SetBasicAuth("ldap-erin", tt.password)
+		g.ServeHTTP(w, req)
+		assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		if tt.wantCode == http.StatusTooManyRequests {
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+			assert.Equal(t, binds, server.Binds())
+		}
+	}
+}
+
+// lockoutStore keeps the lockout keys in memory, the expirations are ignored.
+type lockoutStore struct {
+	mfaStore
+	windows map[string]int
+}
+
+func (s lockoutStore) SetRollingWindow(key string, per int64, val string, pipeline bool) (int, []interface{}) {
+	n := s.windows[key]
+	s.windows[key]++
+
+	return n, nil
+}
+
+func (s lockoutStore) IncrememntWithExpire(key string, expire int64) int64 {
+	n, _ := strconv.ParseInt(s.mfaStore[key], 10, 64)
+	n++
+	s.mfaStore[key] = strconv.FormatInt(n, 10)
+
+	return n
+}
+
+func (s lockoutStore) DeleteRawKey(key string) bool {
+	_, ok := s.windows[key]
+	delete(s.windows, key)
+
+	return s.mfaStore.DeleteRawKey(key) || ok
+}
+
+// mfaStore keeps the mfa keys in memory, the expirations are ignored.
+type mfaStore map[string]string
+
+func (s mfaStore) GetRawKey(key string) (string, error) {
+	if v, ok := s[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s mfaStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s[key] = value
+
+	return nil
+}
+
+func (s mfaStore) DeleteRawKey(key string) bool {
+	_, ok := s[key]
+	delete(s, key)
+
+	return ok
+}
+
+func TestMFALogin(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	store.SetClient(storeIns)
+
+	opts := mfa.NewMFAOptions()
+	opts.Key = "mfa-test-key"
+	manager, err := mfa.NewManager(opts, mfaStore{})
+	assert.NoError(t, err)
+
+	password, _ := authutil.Encrypt("Alice@2020")
+	alice := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "mfa-alice", Namespace: metav1.NamespaceDefault},
+		Nickname:   "alice",
+		Email:      "alice@example.com",
+		Password:   password,
+		Status:     1,
+	}
+	enrollment, err := manager.Enroll(context.Background(), alice)
+	assert.NoError(t, err)
+	passcode, _ := totp.Code(enrollment.Secret, totp.Counter(time.Now()))
+	recoveryCodes, err := manager.Confirm(context.Background(), alice, passcode)
+	assert.NoError(t, err)
+	assert.NoError(t, storeIns.Users().Create(context.Background(), alice, metav1.CreateOptions{}))
+
+	viper.Set("jwt.key", "mfa-test-key")
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	jwtStrategy, _ := newJWTAuth().(auth.JWTStrategy)
+	g.POST("/login", jwtStrategy.LoginHandler)
+	g.POST("/login/mfa", newMFAAuth(jwtStrategy).LoginHandler)
+	g.GET("/whoami", newBasicAuth().AuthFunc(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(middleware.UsernameKey))
+	})
+
+	post := func(path, body string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		g.ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		return w.Code, resp
+	}
+
+	status, resp := post("/login", `{"username":"mfa-alice","password":"Alice@2020"}`)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, float64(code.ErrMFARequired), resp["code"])
+	assert.Nil(t, resp["token"])
+	mfaToken, _ := resp["mfaToken"].(string)
+	assert.NotEmpty(t, mfaToken)
+
+	status, resp = post("/login/mfa", `{"mfaToken":"`+mfaToken+`","code":"000000"}`)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, float64(code.ErrMFACodeInvalid), resp["code"])
+
+	// the wrong code does not consume the MFA token
+	status, resp = post("/login/mfa", `{"mfaToken":"`+mfaToken+`","code":"`+recoveryCodes.Codes[0]+`"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, resp["token"])
+
+	status, resp = post("/login/mfa", `{"mfaToken":"`+mfaToken+`","code":"`+recoveryCodes.Codes[1]+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, float64(code.ErrMFATokenInvalid), resp["code"])
+
+	user, err := storeIns.Users().Get(context.Background(), metav1.NamespaceDefault, "mfa-alice", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Len(t, user.RecoveryCodes, len(recoveryCodes.Codes)-1)
+	}
+
+	// the basic authentication can not send the second factor
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.SetBasicAuth("mfa-alice", "Alice@2020")
+	g.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Second factor is required")
+}
+
+func TestMFALoginLockout(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	store.SetClient(storeIns)
+
+	opts := mfa.NewMFAOptions()
+	opts.Key = "mfa-test-key"
+	manager, err := mfa.NewManager(opts, mfaStore{})
+	assert.NoError(t, err)
+
+	password, _ := authutil.Encrypt("Bob@2020")
+	bob := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "mfa-bob", Namespace: metav1.NamespaceDefault},
+		Nickname:   "bob",
+		Email:      "bob@example.com",
+		Password:   password,
+		Status:     1,
+	}
+	enrollment, err := manager.Enroll(context.Background(), bob)
+	assert.NoError(t, err)
+	passcode, _ := totp.Code(enrollment.Secret, totp.Counter(time.Now()))
+	_, err = manager.Confirm(context.Background(), bob, passcode)
+	assert.NoError(t, err)
+	assert.NoError(t, storeIns.Users().Create(context.Background(), bob, metav1.CreateOptions{}))
+
+	lockoutOpts := lockout.NewLockoutOptions()
+	lockoutOpts.MaxAttempts = 2
+	lockoutOpts.IPMaxAttempts = 0
+	lockout.NewLockout(lockoutOpts, lockoutStore{mfaStore: mfaStore{}, windows: map[string]int{}})
+	defer func() { lockoutOpts.MaxAttempts = 0 }()
+
+	viper.Set("jwt.key", "mfa-test-key")
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	jwtStrategy, _ := newJWTAuth().(auth.JWTStrategy)
+	g.POST("/login", jwtStrategy.LoginHandler)
+	g.POST("/login/mfa", newMFAAuth(jwtStrategy).LoginHandler)
+
+	post := func(path, body string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		g.ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		return w.Code, resp
+	}
+
+	// logging in with the password again before every guess does not reset the failed attempts
+	for i := 0; i < lockoutOpts.MaxAttempts; i++ {
+		status, resp := post("/login", `{"username":"mfa-bob","password":"Bob@2020"}`)
+		assert.Equal(t, float64(code.ErrMFARequired), resp["code"], status)
+		mfaToken, _ := resp["mfaToken"].(string)
+
+		status, resp = post("/login/mfa", `{"mfaToken":"`+mfaToken+`","code":"000000"}`)
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, float64(code.ErrMFACodeInvalid), resp["code"])
+	}
+
+	status, resp := post("/login", `{"username":"mfa-bob","password":"Bob@2020"}`)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, float64(code.ErrAccountLocked), resp["code"])
+}
+
+func TestBasicAuthPasswordExpired(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	store.SetClient(storeIns)
+
+	opts := password.NewPasswordOptions()
+	opts.MaxAge = time.Hour
+	policy, err := password.NewPolicy(opts)
+	assert.NoError(t, err)
+	defaultPolicy := password.GetPolicy()
+	password.SetPolicy(policy)
+	defer password.SetPolicy(defaultPolicy)
+
+	hash, _ := authutil.Encrypt("Expired@2020")
+	for name, changedAt := range map[string]time.Time{
+		"basic-expired": time.Now().Add(-2 * time.Hour),
+		"basic-fresh":   time.Now(),
+	} {
+		assert.NoError(t, storeIns.Users().Create(context.Background(), &v1.User{
+			ObjectMeta:        metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+			Nickname:          name,
+			Email:             name + "@example.com",
+			Password:          hash,
+			PasswordChangedAt: changedAt,
+			Status:            1,
+		}, metav1.CreateOptions{}))
+	}
+
+	viper.Set("jwt.key", "expired-test-key")
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	jwtStrategy, _ := newJWTAuth().(auth.JWTStrategy)
+	g.POST("/login", jwtStrategy.LoginHandler)
+	whoami := func(c *gin.Context) {
+		c.String(http.StatusOK, "%s expired=%t",
+			c.GetString(middleware.UsernameKey), c.GetBool(middleware.PasswordExpiredKey))
+	}
+	for prefix, strategy := range map[string]middleware.AuthStrategy{"basic": newBasicAuth(), "jwt": jwtStrategy} {
+		group := g.Group("/"+prefix, strategy.AuthFunc())
+		group.GET("/whoami", whoami)
+		group.PUT("/users/:name/change-password", whoami)
+	}
+
+	serve := func(method, path string, auth func(*http.Request)) (int, string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		auth(req)
+		g.ServeHTTP(w, req)
+
+		return w.Code, w.Body.String()
+	}
+
+	// the expired users are only authenticated to change the passwords
+	for _, tt := range []struct {
+		name, method, path string
+		wantCode           int
+		wantBody           string
+	}{
+		{"basic-expired", http.MethodGet, "/basic/whoami", http.StatusForbidden, ""},
+		{"basic-expired", http.MethodPut, "/basic/users/basic-expired/change-password", http.StatusOK,
+			"basic-expired expired=true"},
+		{"basic-fresh", http.MethodGet, "/basic/whoami", http.StatusOK, "basic-fresh expired=false"},
+	} {
+		status, body := serve(tt.method, tt.path, func(req *http.Request) { req.SetBasicAuth(tt.name, "Expired@2020") })
+		assert.Equal(t, tt.wantCode, status, body)
+		if tt.wantBody != "" {
+			assert.Equal(t, tt.wantBody, body)
+		}
+	}
+
+	// the token issued with the expired password is only authenticated to change the password
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login",
+		strings.NewReader(`{"username":"basic-expired","password":"Expired@2020"}`))
+	req.Header.Set("Content-Type", "application/json")
+	g.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, true, resp["passwordExpired"])
+	bearer := func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+resp["token"].(string)) }
+
+	status, body := serve(http.MethodGet, "/jwt/whoami", bearer)
+	assert.Equal(t, http.StatusForbidden, status, body)
+	assert.Contains(t, body, strconv.Itoa(code.ErrPasswordExpired))
+	status, body = serve(http.MethodPut, "/jwt/users/basic-expired/change-password", bearer)
+	assert.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "basic-expired expired=true", body)
+
+	// the login is recorded without changing the version, the preconditions of the clients still hold
+	user, err := storeIns.Users().Get(context.Background(), metav1.NamespaceDefault, "basic-fresh", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.False(t, user.LoginedAt.IsZero())
+		assert.Equal(t, uint64(1), user.Version)
+	}
+}
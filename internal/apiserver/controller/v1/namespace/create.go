@@ -0,0 +1,54 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// Create add new namespace to the storage.
+// Only administrator can call this function.
+func (n *NamespaceController) Create(c *gin.Context) {
+	log.L(c).Info("namespace create function called.")
+
+	var r v1.Namespace
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if errs := r.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	if err := n.srv.Namespaces().Create(c, &r, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, &r)
+}
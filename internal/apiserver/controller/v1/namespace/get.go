@@ -0,0 +1,37 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"github.com/dairongpeng/leona/pkg/core"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// Get get a namespace by the namespace identifier.
+func (n *NamespaceController) Get(c *gin.Context) {
+	log.L(c).Info("get namespace function called.")
+
+	namespace, err := n.srv.Namespaces().Get(c, c.Param("ns"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, namespace)
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// List list the namespaces in the storage.
+// Only administrator can call this function.
+func (n *NamespaceController) List(c *gin.Context) {
+	log.L(c).Info("list namespace function called.")
+
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	namespaces, err := n.srv.Namespaces().List(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, namespaces)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// Update update a namespace info by the namespace identifier.
+// Only administrator can call this function.
+func (n *NamespaceController) Update(c *gin.Context) {
+	log.L(c).Info("update namespace function called.")
+
+	var r v1.Namespace
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	namespace, err := n.srv.Namespaces().Get(c, c.Param("ns"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := middleware.CheckPreconditions(c, namespace); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	namespace.Description = r.Description
+	namespace.Extend = r.Extend
+
+	if errs := namespace.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	// Save changed fields.
+	if err := n.srv.Namespaces().Update(c, namespace, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, namespace)
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestNamespaceController_Update(t *testing.T) {
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tenant-a",
+			ID:   0,
+		},
+		Description: "business unit a",
+		Phase:       v1.NamespaceActive,
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	body := bytes.NewBufferString(`{"description":"business unit b"}`)
+	c.Request, _ = http.NewRequest("PUT", "/v1/namespaces/tenant-a", body)
+	c.Params = []gin.Param{{Key: "ns", Value: "tenant-a"}}
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// deep copy
+	namespace2 := new(v1.Namespace)
+	*namespace2 = *namespace
+	namespace2.Description = "business unit b"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockNamespaceSrv := srvv1.NewMockNamespaceSrv(ctrl)
+	mockNamespaceSrv.EXPECT().Get(gomock.Any(), gomock.Eq("tenant-a"), gomock.Any()).Return(namespace, nil)
+	mockNamespaceSrv.EXPECT().Update(gomock.Any(), gomock.Eq(namespace2), gomock.Any()).Return(nil)
+	mockService.EXPECT().Namespaces().Return(mockNamespaceSrv).Times(2)
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &NamespaceController{
+				srv: tt.fields.srv,
+			}
+			n.Update(tt.args.c)
+		})
+	}
+}
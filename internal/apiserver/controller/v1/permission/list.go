@@ -0,0 +1,37 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permission
+
+import (
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// List list the effective permissions of a user, merged from the roles granted to the user.
+func (p *PermissionController) List(c *gin.Context) {
+	log.L(c).Info("list permission function called.")
+
+	permissions, err := p.authorizer.Permissions(c, middleware.RequestNamespace(c), c.Param("name"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, permissions)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permission
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+)
+
+func TestPermissionController_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockUserStore := store.NewMockUserStore(ctrl)
+	mockRoleBindingStore := store.NewMockRoleBindingStore(ctrl)
+	mockFactory.EXPECT().Users().Return(mockUserStore)
+	mockFactory.EXPECT().RoleBindings().Return(mockRoleBindingStore)
+	mockUserStore.EXPECT().Get(gomock.Any(), gomock.Eq("default"), gomock.Eq("user1"), gomock.Any()).Return(&v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "user1", Namespace: metav1.NamespaceDefault},
+	}, nil)
+	mockRoleBindingStore.EXPECT().List(gomock.Any(), gomock.Eq("default"), gomock.Any()).Return(&v1.RoleBindingList{
+		Items: []*v1.RoleBinding{
+			{ObjectMeta: metav1.ObjectMeta{Name: "admins"}, RoleRef: rbac.AdminRole, Users: []string{"user1"}},
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/v1/users/user1/permissions", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "user1"}}
+
+	p := &PermissionController{authorizer: rbac.NewAuthorizer(mockFactory)}
+	p.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got v1.PermissionList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.NotEmpty(t, got.Items)
+	// the rules on itself come first
+	first := got.Items[0]
+	assert.Equal(t, []string{"user1"}, first.ResourceNames)
+	assert.Equal(t, metav1.NamespaceDefault, first.Namespace)
+	assert.Empty(t, first.Role)
+	last := got.Items[len(got.Items)-1]
+	assert.Equal(t, rbac.AdminRole, last.Role)
+	assert.Equal(t, "admins", last.RoleBinding)
+	assert.Equal(t, rbac.NamespaceAll, last.Namespace)
+}
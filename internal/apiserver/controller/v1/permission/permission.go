@@ -0,0 +1,32 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permission
+
+import (
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+)
+
+// PermissionController create a permission handler used to handle request for permission resource.
+type PermissionController struct {
+	authorizer *rbac.Authorizer
+}
+
+// NewPermissionController creates a permission handler.
+func NewPermissionController(store store.Factory) *PermissionController {
+	return &PermissionController{
+		authorizer: rbac.NewAuthorizer(store),
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestRoleController_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockRoleSrv := srvv1.NewMockRoleSrv(ctrl)
+	mockRoleSrv.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockService.EXPECT().Roles().Return(mockRoleSrv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"metadata":{"name":"viewer"},"rules":[{"verbs":["get","list"],"resources":["users"]}]}`)
+	c.Request, _ = http.NewRequest("POST", "/v1/roles", body)
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RoleController{
+				srv: tt.fields.srv,
+			}
+			r.Create(tt.args.c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var got v1.Role
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, "viewer", got.Name)
+			assert.Equal(t, metav1.NamespaceDefault, got.Namespace)
+			assert.Equal(t, []v1.PolicyRule{
+				{Verbs: []string{v1.VerbGet, v1.VerbList}, Resources: []string{"users"}},
+			}, got.Rules)
+		})
+	}
+}
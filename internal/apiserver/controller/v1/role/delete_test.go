@@ -0,0 +1,130 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestRoleController_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockRoleSrv := srvv1.NewMockRoleSrv(ctrl)
+	mockRoleSrv.EXPECT().Delete(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewer"), gomock.Any()).Return(nil)
+	mockService.EXPECT().Roles().Return(mockRoleSrv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/v1/roles/viewer", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "viewer"}}
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RoleController{
+				srv: tt.fields.srv,
+			}
+			r.Delete(tt.args.c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, "null", w.Body.String())
+		})
+	}
+}
+
+func TestRoleController_DeletePreconditions(t *testing.T) {
+	role := &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			ID:        1,
+			Name:      "viewer",
+			Namespace: metav1.NamespaceDefault,
+			UpdatedAt: time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC),
+			Version:   3,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		status  int
+		deleted bool
+	}{
+		{name: "if-match", header: "If-Match", value: `"1-3"`, status: http.StatusOK, deleted: true},
+		{name: "if-match mismatch", header: "If-Match", value: `"1-1"`, status: http.StatusPreconditionFailed},
+		{name: "unmodified", header: "If-Unmodified-Since", value: "Tue, 01 Jun 2021 08:00:00 GMT", status: http.StatusOK, deleted: true},
+		{name: "modified", header: "If-Unmodified-Since", value: "Tue, 01 Jun 2021 07:59:59 GMT", status: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := srvv1.NewMockService(ctrl)
+			mockRoleSrv := srvv1.NewMockRoleSrv(ctrl)
+			mockRoleSrv.EXPECT().Get(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewer"), gomock.Any()).Return(role, nil)
+			mockService.EXPECT().Roles().Return(mockRoleSrv)
+			if tt.deleted {
+				// the role is deleted only in the checked version
+				opts := metav1.DeleteOptions{Unscoped: true, Version: 3}
+				mockRoleSrv.EXPECT().Delete(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewer"), gomock.Eq(opts)).Return(nil)
+				mockService.EXPECT().Roles().Return(mockRoleSrv)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("DELETE", "/v1/roles/viewer", nil)
+			c.Request.Header.Set(tt.header, tt.value)
+			c.Params = []gin.Param{{Key: "name", Value: "viewer"}}
+
+			(&RoleController{srv: mockService}).Delete(c)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
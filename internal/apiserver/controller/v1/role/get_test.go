@@ -0,0 +1,91 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestRoleController_Get(t *testing.T) {
+	role := &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "viewer",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Rules: []v1.PolicyRule{
+			{Verbs: []string{v1.VerbGet, v1.VerbList}, Resources: []string{"users"}},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/v1/roles/viewer", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "viewer"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockRoleSrv := srvv1.NewMockRoleSrv(ctrl)
+	mockRoleSrv.EXPECT().Get(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewer"), gomock.Any()).Return(role, nil)
+	mockService.EXPECT().Roles().Return(mockRoleSrv)
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RoleController{
+				srv: tt.fields.srv,
+			}
+			r.Get(tt.args.c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var got v1.Role
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, role.Name, got.Name)
+			assert.Equal(t, role.Rules, got.Rules)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestRoleController_Update(t *testing.T) {
+	role := &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "viewer",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Rules: []v1.PolicyRule{
+			{Verbs: []string{v1.VerbGet, v1.VerbList}, Resources: []string{"users"}},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"rules":[{"verbs":["get"],"resources":["users"]}]}`)
+	c.Request, _ = http.NewRequest("PUT", "/v1/roles/viewer", body)
+	c.Params = []gin.Param{{Key: "name", Value: "viewer"}}
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// deep copy
+	role2 := new(v1.Role)
+	*role2 = *role
+	role2.Rules = []v1.PolicyRule{{Verbs: []string{v1.VerbGet}, Resources: []string{"users"}}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockRoleSrv := srvv1.NewMockRoleSrv(ctrl)
+	mockRoleSrv.EXPECT().Get(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewer"), gomock.Any()).Return(role, nil)
+	mockRoleSrv.EXPECT().Update(gomock.Any(), gomock.Eq(role2), gomock.Any()).Return(nil)
+	mockService.EXPECT().Roles().Return(mockRoleSrv).Times(2)
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RoleController{
+				srv: tt.fields.srv,
+			}
+			r.Update(tt.args.c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var got v1.Role
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, role2.Rules, got.Rules)
+		})
+	}
+}
+
+func TestRoleController_UpdatePreconditionFailed(t *testing.T) {
+	role := &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			ID:        1,
+			Name:      "viewer",
+			Namespace: metav1.NamespaceDefault,
+			UpdatedAt: time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC),
+			Version:   2,
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockRoleSrv := srvv1.NewMockRoleSrv(ctrl)
+	mockRoleSrv.EXPECT().Get(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewer"), gomock.Any()).Return(role, nil)
+	mockService.EXPECT().Roles().Return(mockRoleSrv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"rules":[{"verbs":["get"],"resources":["users"]}]}`)
+	c.Request, _ = http.NewRequest("PUT", "/v1/roles/viewer", body)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"1-1"`)
+	c.Params = []gin.Param{{Key: "name", Value: "viewer"}}
+
+	(&RoleController{srv: mockService}).Update(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Contains(t, w.Body.String(), "The precondition of the request failed")
+}
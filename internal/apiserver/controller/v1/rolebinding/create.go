@@ -0,0 +1,65 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolebinding
+
+import (
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// Create add new role binding to the namespace of the request.
+func (b *RoleBindingController) Create(c *gin.Context) {
+	log.L(c).Info("role binding create function called.")
+
+	var binding v1.RoleBinding
+
+	if err := core.ShouldBindBody(c, &binding); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	namespace := middleware.RequestNamespace(c)
+	if binding.Namespace != "" && binding.Namespace != namespace {
+		err := field.Invalid(field.NewPath("metadata", "namespace"), binding.Namespace,
+			"the namespace of the provided object does not match the namespace sent on the request")
+		core.WriteResponse(c, errors.WrapC(err, code.ErrValidation, err.Error()), nil)
+
+		return
+	}
+	binding.Namespace = namespace
+
+	if errs := binding.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	if err := b.srv.RoleBindings().Create(c, &binding, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, &binding)
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolebinding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestRoleBindingController_Get(t *testing.T) {
+	binding := &v1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "viewers",
+			Namespace: metav1.NamespaceDefault,
+		},
+		RoleRef: "viewer",
+		Users:   []string{"user1"},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/v1/rolebindings/viewers", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "viewers"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockRoleBindingSrv := srvv1.NewMockRoleBindingSrv(ctrl)
+	mockRoleBindingSrv.EXPECT().Get(gomock.Any(), gomock.Eq("default"), gomock.Eq("viewers"), gomock.Any()).Return(binding, nil)
+	mockService.EXPECT().RoleBindings().Return(mockRoleBindingSrv)
+
+	type fields struct {
+		srv srvv1.Service
+	}
+	type args struct {
+		c *gin.Context
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+	}{
+		{
+			name: "default",
+			fields: fields{
+				srv: mockService,
+			},
+			args: args{
+				c: c,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &RoleBindingController{
+				srv: tt.fields.srv,
+			}
+			b.Get(tt.args.c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var got v1.RoleBinding
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, binding.Name, got.Name)
+			assert.Equal(t, binding.RoleRef, got.RoleRef)
+			assert.Equal(t, binding.Users, got.Users)
+		})
+	}
+}
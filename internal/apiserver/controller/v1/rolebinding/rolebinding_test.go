@@ -0,0 +1,58 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolebinding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+)
+
+func TestNewRoleBindingController(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+
+	type args struct {
+		store store.Factory
+	}
+	tests := []struct {
+		name string
+		args args
+		want *RoleBindingController
+	}{
+		{
+			name: "default",
+			args: args{
+				store: mockFactory,
+			},
+			want: &RoleBindingController{
+				srv: srvv1.NewService(mockFactory),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRoleBindingController(tt.args.store); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewRoleBindingController() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
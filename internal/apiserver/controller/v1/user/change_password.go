@@ -16,6 +16,8 @@ package user
 
 import (
 	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	"github.com/dairongpeng/leona/internal/apiserver/password"
+	"github.com/dairongpeng/leona/internal/apiserver/session"
 	"github.com/dairongpeng/leona/pkg/auth"
 	"github.com/dairongpeng/leona/pkg/core"
 	"github.com/dairongpeng/leona/pkg/errors"
@@ -24,7 +26,9 @@ import (
 	"time"
 
 	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/validation/field"
 )
 
 // ChangePasswordRequest defines the ChangePasswordRequest data format.
@@ -33,9 +37,9 @@ type ChangePasswordRequest struct {
 	// Required: true
 	OldPassword string `json:"oldPassword" binding:"omitempty"`
 
-	// New password.
+	// New password, it must satisfy the password policy and must not be reused.
 	// Required: true
-	NewPassword string `json:"newPassword" binding:"password"`
+	NewPassword string `json:"newPassword" binding:"required"`
 }
 
 // ChangePassword change the user's password by the user identifier.
@@ -44,13 +48,22 @@ func (u *UserController) ChangePassword(c *gin.Context) {
 
 	var r ChangePasswordRequest
 
-	if err := c.ShouldBindJSON(&r); err != nil {
-		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
 
 		return
 	}
 
-	user, err := u.srv.Users().Get(c, c.Param("name"), metav1.GetOptions{})
+	// the user whose password expired is only authenticated to change its own password
+	if c.GetBool(middleware.PasswordExpiredKey) &&
+		(c.Param("name") != c.GetString(middleware.UsernameKey) ||
+			middleware.RequestNamespace(c) != c.GetString(middleware.NamespaceKey)) {
+		core.WriteResponse(c, errors.WithCode(code.ErrPasswordExpired, "the password expired, it must be changed"), nil)
+
+		return
+	}
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 
@@ -63,21 +76,36 @@ func (u *UserController) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	user.Password, _ = auth.Encrypt(r.NewPassword)
+	policy := password.GetPolicy()
+	if errs := policy.Validate(user, r.NewPassword, field.NewPath("newPassword")); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	hash, _ := auth.Encrypt(r.NewPassword)
+	policy.Change(user, hash, time.Now())
 	if err := u.srv.Users().ChangePassword(c, user); err != nil {
 		core.WriteResponse(c, err, nil)
 
 		return
 	}
 
+	// the sessions started with the old password are ended
+	if err := session.GetManager().RevokeAll(c, user.Namespace, user.Name); err != nil {
+		log.L(c).Errorf("revoke the sessions of user `%s/%s` failed: %s", user.Namespace, user.Name, err.Error())
+	}
+
 	// 收集数据
 	record := analytics.AnalyticsRecord{
+		RequestID: c.GetHeader(middleware.XRequestIDKey),
 		TimeStamp: time.Now().Unix(),
 		Username:  user.Name,
 		Effect:    "change-password",
+		ClientIP:  c.ClientIP(),
 	}
 	record.SetExpiry(0)
-	_ = analytics.GetAnalytics().RecordHit(&record)
+	analytics.RecordRequest(c, &record)
 
 	core.WriteResponse(c, nil, nil)
 }
@@ -21,10 +21,17 @@ import (
 	"testing"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 
 	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/json"
 	_ "github.com/dairongpeng/leona/pkg/validator"
 )
 
@@ -38,7 +45,7 @@ func TestUserController_ChangePassword(t *testing.T) {
 
 	mockService := srvv1.NewMockService(ctrl)
 	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
-	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq("colin"), gomock.Any()).Return(user, nil)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("colin"), gomock.Any()).Return(user, nil)
 	mockUserSrv.EXPECT().ChangePassword(gomock.Any(), gomock.Any()).Return(nil)
 	mockService.EXPECT().Users().Return(mockUserSrv).Times(2)
 
@@ -78,3 +85,78 @@ func TestUserController_ChangePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestUserController_ChangePassword_Policy(t *testing.T) {
+	reused, _ := auth.Encrypt("Colin@2021")
+
+	tests := []struct {
+		name        string
+		newPassword string
+		message     string
+	}{
+		{name: "weak", newPassword: "colin", message: "password length must be between 8 to 16 characters long"},
+		{name: "common", newPassword: "P@ssw0rd", message: "password is too common"},
+		{name: "current", newPassword: "Admin@2020", message: "must not be the current password or one of the last 5 passwords"},
+		{name: "history", newPassword: "Colin@2021", message: "must not be the current password or one of the last 5 passwords"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			user := &v1.User{
+				Password:        "$2a$10$KqZhl5WStpa2K.ddEyzyf.zXllEXP4gIG8xQUgMhU1ZvMUn/Ta5um",
+				PasswordHistory: []string{reused},
+			}
+
+			mockService := srvv1.NewMockService(ctrl)
+			mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+			mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("colin"), gomock.Any()).Return(user, nil)
+			mockService.EXPECT().Users().Return(mockUserSrv)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := bytes.NewBufferString(`{"oldPassword":"Admin@2020","newPassword":"` + tt.newPassword + `"}`)
+			c.Request, _ = http.NewRequest("PUT", "/v1/users/colin/change_password", body)
+			c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			u := &UserController{srv: mockService}
+			u.ChangePassword(c)
+
+			var resp core.ErrResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, code.ErrValidation, resp.Code)
+			if assert.NotEmpty(t, resp.Details) {
+				assert.Equal(t, "newPassword", resp.Details[0].Field)
+				assert.Equal(t, tt.message, resp.Details[0].Message)
+				assert.Nil(t, resp.Details[0].Value)
+			}
+		})
+	}
+}
+
+func TestUserController_ChangePassword_Expired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the user whose password expired can not change the password of another user
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"oldPassword":"Admin@2020","newPassword":"Colin@2021"}`)
+	c.Request, _ = http.NewRequest("PUT", "/v1/users/colin/change_password", body)
+	c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.UsernameKey, "admin")
+	c.Set(middleware.NamespaceKey, metav1.NamespaceDefault)
+	c.Set(middleware.PasswordExpiredKey, true)
+
+	u := &UserController{srv: srvv1.NewMockService(ctrl)}
+	u.ChangePassword(c)
+
+	var resp core.ErrResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, code.ErrPasswordExpired, resp.Code)
+}
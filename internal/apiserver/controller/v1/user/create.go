@@ -16,7 +16,9 @@ package user
 
 import (
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/account"
 	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
 	"github.com/dairongpeng/leona/pkg/auth"
 	"github.com/dairongpeng/leona/pkg/core"
 	"github.com/dairongpeng/leona/pkg/errors"
@@ -25,7 +27,9 @@ import (
 	"time"
 
 	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/validation/field"
 )
 
 // Create add new user to the storage.
@@ -34,19 +38,37 @@ func (u *UserController) Create(c *gin.Context) {
 
 	var r v1.User
 
-	if err := c.ShouldBindJSON(&r); err != nil {
-		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
 
 		return
 	}
 
+	namespace := middleware.RequestNamespace(c)
+	if r.Namespace != "" && r.Namespace != namespace {
+		err := field.Invalid(field.NewPath("metadata", "namespace"), r.Namespace,
+			"the namespace of the provided object does not match the namespace sent on the request")
+		core.WriteResponse(c, errors.WrapC(err, code.ErrValidation, err.Error()), nil)
+
+		return
+	}
+	r.Namespace = namespace
+
 	if errs := r.Validate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
 
 		return
 	}
 
+	// only the administrators create the other administrators
+	admin := r.IsAdmin == 1 && requestedByAdmin(c, u.srv, r.Namespace)
+	r.PrepareForCreate()
+	if admin {
+		r.IsAdmin = 1
+	}
+
 	r.Password, _ = auth.Encrypt(r.Password)
+	r.PasswordChangedAt = time.Now()
 	r.Status = 1
 	r.LoginedAt = time.Now()
 
@@ -57,14 +79,35 @@ func (u *UserController) Create(c *gin.Context) {
 		return
 	}
 
+	// the email address is verified by the token mailed to it
+	if err := account.GetManager().SendVerification(c, &r); err != nil {
+		log.L(c).Errorf("send verification mail to user `%s/%s` failed: %s", r.Namespace, r.Name, err.Error())
+	}
+
 	// 打点收集数据
 	record := analytics.AnalyticsRecord{
+		RequestID: c.GetHeader(middleware.XRequestIDKey),
 		TimeStamp: time.Now().Unix(),
 		Username:  r.Name,
 		Effect:    "create",
+		ClientIP:  c.ClientIP(),
 	}
 	record.SetExpiry(0)
-	_ = analytics.GetAnalytics().RecordHit(&record)
+	analytics.RecordRequest(c, &record)
+
+	core.WriteResponse(c, nil, &r)
+}
+
+// requestedByAdmin reports whether the request is sent by an authenticated administrator of the namespace.
+// The administrators of the default namespace administer all the namespaces, as the rbac grants them.
+func requestedByAdmin(c *gin.Context, srv srvv1.Service, namespace string) bool {
+	username := c.GetString(middleware.UsernameKey)
+	requesterNamespace := c.GetString(middleware.NamespaceKey)
+	if username == "" || (requesterNamespace != namespace && requesterNamespace != metav1.NamespaceDefault) {
+		return false
+	}
+
+	requester, err := srv.Users().Get(c, requesterNamespace, username, metav1.GetOptions{})
 
-	core.WriteResponse(c, nil, r)
+	return err == nil && requester.IsAdmin == 1
 }
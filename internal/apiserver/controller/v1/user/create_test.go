@@ -22,8 +22,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 )
 
 func TestUserController_Create(t *testing.T) {
@@ -71,3 +75,91 @@ func TestUserController_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestUserController_CreateAdmin(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		requester *v1.User
+		wantAdmin int
+	}{
+		{
+			name: "anonymous",
+		},
+		{
+			name:      "non-admin",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "user2", Namespace: metav1.NamespaceDefault}},
+		},
+		{
+			name:      "admin",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: metav1.NamespaceDefault}, IsAdmin: 1},
+			wantAdmin: 1,
+		},
+		{
+			name:      "admin of the namespace",
+			namespace: "team-b",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "team-b"}, IsAdmin: 1},
+			wantAdmin: 1,
+		},
+		{
+			name:      "admin of another namespace",
+			namespace: "team-b",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "team-a"}, IsAdmin: 1},
+		},
+		{
+			name:      "admin of the default namespace",
+			namespace: "team-b",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: metav1.NamespaceDefault}, IsAdmin: 1},
+			wantAdmin: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var created *v1.User
+			mockService := srvv1.NewMockService(ctrl)
+			mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+			mockUserSrv.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, user *v1.User, _ metav1.CreateOptions) error {
+					created = user
+
+					return nil
+				})
+			mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := bytes.NewBufferString(`{"metadata":{"name":"mallory"},"nickname":"mallory",` +
+				`"email":"mallory@qq.com","password":"Mallory@2020","isAdmin":1,"totpSecret":"secret",` +
+				`"mfaEnabledAt":"2020-01-01T00:00:00Z","emailVerifiedAt":"2020-01-01T00:00:00Z",` +
+				`"recoveryCodes":["code"],"passwordHistory":["hash"],"source":"ldap"}`)
+			c.Request, _ = http.NewRequest("POST", "/v1/users", body)
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.namespace != "" {
+				c.Params = gin.Params{{Key: middleware.NamespaceParam, Value: tt.namespace}}
+			}
+			if tt.requester != nil {
+				c.Set(middleware.UsernameKey, tt.requester.Name)
+				c.Set(middleware.NamespaceKey, tt.requester.Namespace)
+				mockUserSrv.EXPECT().Get(gomock.Any(), tt.requester.Namespace, tt.requester.Name, gomock.Any()).
+					Return(tt.requester, nil).AnyTimes()
+			}
+
+			u := &UserController{srv: mockService}
+			u.Create(c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			if assert.NotNil(t, created) {
+				assert.Equal(t, tt.wantAdmin, created.IsAdmin)
+				assert.False(t, created.MFAEnabled())
+				assert.True(t, created.MFAEnabledAt.IsZero())
+				assert.True(t, created.EmailVerifiedAt.IsZero())
+				assert.Empty(t, created.RecoveryCodes)
+				assert.Empty(t, created.PasswordHistory)
+				assert.Empty(t, created.Source)
+			}
+		})
+	}
+}
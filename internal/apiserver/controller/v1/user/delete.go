@@ -21,6 +21,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"time"
 
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -29,7 +30,26 @@ import (
 func (u *UserController) Delete(c *gin.Context) {
 	log.L(c).Info("delete user function called.")
 
-	if err := u.srv.Users().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
+	opts := metav1.DeleteOptions{Unscoped: true}
+	if middleware.HasPreconditions(c) {
+		user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+
+			return
+		}
+
+		if err := middleware.CheckPreconditions(c, user); err != nil {
+			core.WriteResponse(c, err, nil)
+
+			return
+		}
+
+		// the deletion fails if the user is modified after the preconditions are checked
+		opts.Version = user.Version
+	}
+
+	if err := u.srv.Users().Delete(c, middleware.RequestNamespace(c), c.Param("name"), opts); err != nil {
 		core.WriteResponse(c, err, nil)
 
 		return
@@ -37,12 +57,14 @@ func (u *UserController) Delete(c *gin.Context) {
 
 	// 收集数据
 	record := analytics.AnalyticsRecord{
+		RequestID: c.GetHeader(middleware.XRequestIDKey),
 		TimeStamp: time.Now().Unix(),
 		Username:  "-",
 		Effect:    "delete",
+		ClientIP:  c.ClientIP(),
 	}
 	record.SetExpiry(0)
-	_ = analytics.GetAnalytics().RecordHit(&record)
+	analytics.RecordRequest(c, &record)
 
 	core.WriteResponse(c, nil, nil)
 }
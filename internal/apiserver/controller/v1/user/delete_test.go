@@ -19,6 +19,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 
@@ -31,7 +32,7 @@ func TestUserController_Delete(t *testing.T) {
 
 	mockService := srvv1.NewMockService(ctrl)
 	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
-	mockUserSrv.EXPECT().Delete(gomock.Any(), gomock.Eq("admin"), gomock.Any()).Return(nil)
+	mockUserSrv.EXPECT().Delete(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("admin"), gomock.Any()).Return(nil)
 	mockService.EXPECT().Users().Return(mockUserSrv)
 
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
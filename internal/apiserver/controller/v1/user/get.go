@@ -19,6 +19,7 @@ import (
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -26,7 +27,7 @@ import (
 func (u *UserController) Get(c *gin.Context) {
 	log.L(c).Info("get user function called.")
 
-	user, err := u.srv.Users().Get(c, c.Param("name"), metav1.GetOptions{})
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 
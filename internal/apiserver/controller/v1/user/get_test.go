@@ -23,6 +23,7 @@ import (
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 
 	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
 )
@@ -36,9 +37,14 @@ func TestUserController_Get(t *testing.T) {
 		Nickname: "admin",
 		Password: "Admin@2020",
 		Email:    "admin@foxmail.com",
+		// the second factor and the password history are never responded
+		PasswordHistory: []string{"history-hash"},
+		TOTPSecret:      "encrypted-secret",
+		RecoveryCodes:   []string{"recovery-hash"},
 	}
 
-	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
 	c.Request, _ = http.NewRequest("GET", "/v1/users/colin", nil)
 	c.Params = []gin.Param{{Key: "name", Value: "admin"}}
 
@@ -47,7 +53,7 @@ func TestUserController_Get(t *testing.T) {
 
 	mockService := srvv1.NewMockService(ctrl)
 	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
-	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq("admin"), gomock.Any()).Return(user, nil)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("admin"), gomock.Any()).Return(user, nil)
 	mockService.EXPECT().Users().Return(mockUserSrv)
 
 	type fields struct {
@@ -77,6 +83,12 @@ func TestUserController_Get(t *testing.T) {
 				srv: tt.fields.srv,
 			}
 			u.Get(tt.args.c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), `"nickname":"admin"`)
+			for _, secret := range []string{"history-hash", "encrypted-secret", "recovery-hash"} {
+				assert.NotContains(t, w.Body.String(), secret)
+			}
 		})
 	}
 }
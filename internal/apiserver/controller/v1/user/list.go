@@ -15,12 +15,14 @@
 package user
 
 import (
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	"github.com/dairongpeng/leona/pkg/core"
 	"github.com/dairongpeng/leona/pkg/errors"
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 
 	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -31,12 +33,25 @@ func (u *UserController) List(c *gin.Context) {
 
 	var r metav1.ListOptions
 	if err := c.ShouldBindQuery(&r); err != nil {
-		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
 
 		return
 	}
 
-	users, err := u.srv.Users().List(c, r)
+	var exportOpts metav1.ExportOptions
+	if err := c.ShouldBindQuery(&exportOpts); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	var users *v1.UserList
+	var err error
+	if exportOpts.Export {
+		users, err = u.srv.Users().Export(c, middleware.RequestNamespace(c), r, exportOpts)
+	} else {
+		users, err = u.srv.Users().List(c, middleware.RequestNamespace(c), r)
+	}
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 
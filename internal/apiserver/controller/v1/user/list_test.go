@@ -19,6 +19,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 
@@ -31,7 +32,7 @@ func TestUserController_List(t *testing.T) {
 
 	mockService := srvv1.NewMockService(ctrl)
 	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
-	mockUserSrv.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockUserSrv.EXPECT().List(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Any()).Return(nil, nil)
 	mockService.EXPECT().Users().Return(mockUserSrv)
 
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
@@ -68,3 +69,23 @@ func TestUserController_List(t *testing.T) {
 		})
 	}
 }
+
+func TestUserController_List_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().
+		Export(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Any(), gomock.Eq(metav1.ExportOptions{Export: true, Exact: true})).
+		Return(nil, nil)
+	mockService.EXPECT().Users().Return(mockUserSrv)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/v1/users?export=true&exact=true", nil)
+
+	u := &UserController{
+		srv: mockService,
+	}
+	u.List(c)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// ConfirmMFARequest defines the ConfirmMFARequest data format.
+type ConfirmMFARequest struct {
+	// The code generated by the enrolling authenticator.
+	Code string `json:"code" binding:"required"`
+}
+
+// EnrollMFA starts to enroll a TOTP authenticator of the user, the secret and its key uri are
+// responded to be added into the authenticator app.
+func (u *UserController) EnrollMFA(c *gin.Context) {
+	log.L(c).Info("enroll mfa function called.")
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	enrollment, err := mfa.GetManager().Enroll(c, user)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, enrollment)
+}
+
+// ConfirmMFA enrolls the authenticator by the code it generates, the recovery codes are responded
+// only once.
+func (u *UserController) ConfirmMFA(c *gin.Context) {
+	log.L(c).Info("confirm mfa function called.")
+
+	var r ConfirmMFARequest
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	recoveryCodes, err := mfa.GetManager().Confirm(c, user, r.Code)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := u.srv.Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, recoveryCodes)
+}
+
+// ResetMFA removes the authenticator and the recovery codes of the user, e.g. when the user lost
+// the authenticator. The user logs in with the password only until another one is enrolled.
+func (u *UserController) ResetMFA(c *gin.Context) {
+	log.L(c).Info("reset mfa function called.")
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	mfa.GetManager().Reset(c, user)
+	if err := u.srv.Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
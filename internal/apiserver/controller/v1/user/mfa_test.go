@@ -0,0 +1,84 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/totp"
+)
+
+func TestUserController_MFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := mfa.NewMFAOptions()
+	opts.Key = "secret"
+	_, err := mfa.NewManager(opts, &memStore{values: map[string]string{}})
+	assert.Nil(t, err)
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin", Namespace: metav1.NamespaceDefault},
+		Nickname:   "colin",
+		Email:      "colin@example.com",
+	}
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("colin"), gomock.Any()).
+		Return(user, nil).Times(5)
+	mockUserSrv.EXPECT().Update(gomock.Any(), gomock.Eq(user), gomock.Any()).Return(nil).Times(2)
+	mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+	u := &UserController{srv: mockService}
+
+	w := postJSON(u, (*UserController).EnrollMFA, "/v1/users/colin/mfa", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var enrollment mfa.Enrollment
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+	assert.NotEmpty(t, enrollment.Secret)
+
+	w = postJSON(u, (*UserController).ConfirmMFA, "/v1/users/colin/mfa", `{"code":"000000"}`)
+	var resp core.ErrResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrMFACodeInvalid, resp.Code)
+
+	passcode, _ := totp.Code(enrollment.Secret, totp.Counter(time.Now()))
+	w = postJSON(u, (*UserController).ConfirmMFA, "/v1/users/colin/mfa", `{"code":"`+passcode+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var recoveryCodes mfa.RecoveryCodes
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &recoveryCodes))
+	assert.Len(t, recoveryCodes.Codes, opts.RecoveryCodes)
+	assert.True(t, user.MFAEnabled())
+
+	w = postJSON(u, (*UserController).EnrollMFA, "/v1/users/colin/mfa", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrMFAAlreadyEnabled, resp.Code)
+
+	w = postJSON(u, (*UserController).ResetMFA, "/v1/users/colin/mfa", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, user.MFAEnabled())
+}
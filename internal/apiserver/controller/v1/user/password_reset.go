@@ -0,0 +1,153 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/apiserver/account"
+	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	"github.com/dairongpeng/leona/internal/apiserver/lockout"
+	"github.com/dairongpeng/leona/internal/apiserver/password"
+	"github.com/dairongpeng/leona/internal/apiserver/session"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// PasswordResetRequest defines the PasswordResetRequest data format.
+type PasswordResetRequest struct {
+	// The user in the form of `<namespace>/<name>`, the namespace is default if it is omitted.
+	// Required: true
+	Username string `json:"username" binding:"required"`
+}
+
+// ConfirmPasswordResetRequest defines the ConfirmPasswordResetRequest data format.
+type ConfirmPasswordResetRequest struct {
+	// The token mailed to the user.
+	// Required: true
+	Token string `json:"token" binding:"required"`
+
+	// New password, it must satisfy the password policy and must not be reused.
+	// Required: true
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// RequestPasswordReset mails a password reset token to the user. It always succeeds, so whether
+// the user exists is not disclosed.
+func (u *UserController) RequestPasswordReset(c *gin.Context) {
+	log.L(c).Info("request password reset function called.")
+
+	var r PasswordResetRequest
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	namespace, name := middleware.SplitNamespacedName(r.Username)
+	user, err := u.srv.Users().Get(c, namespace, name, metav1.GetOptions{})
+	if err != nil {
+		log.L(c).Infof("password reset of user `%s/%s` is ignored: %s", namespace, name, err.Error())
+		core.WriteResponse(c, nil, nil)
+
+		return
+	}
+
+	if err := account.GetManager().SendPasswordReset(c, user); err != nil {
+		log.L(c).Errorf("send password reset mail to user `%s/%s` failed: %s", namespace, name, err.Error())
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// ConfirmPasswordReset resets the password of the user by the token mailed to it.
+func (u *UserController) ConfirmPasswordReset(c *gin.Context) {
+	log.L(c).Info("confirm password reset function called.")
+
+	var r ConfirmPasswordResetRequest
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	claims, err := account.GetManager().Verify(account.PurposePasswordReset, r.Token)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	user, err := u.srv.Users().Get(c, claims.Namespace, claims.Username, metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrAccountTokenInvalid, err.Error()), nil)
+
+		return
+	}
+
+	policy := password.GetPolicy()
+	if errs := policy.Validate(user, r.NewPassword, field.NewPath("newPassword")); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	// the token is only used up by a valid password, so that the user can try again
+	if err := account.GetManager().Consume(c, claims, user); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	hash, _ := auth.Encrypt(r.NewPassword)
+	policy.Change(user, hash, time.Now())
+	// receiving the token proves the email address
+	if user.EmailVerifiedAt.IsZero() {
+		user.EmailVerifiedAt = time.Now()
+	}
+	if err := u.srv.Users().ChangePassword(c, user); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// the sessions started with the old password are ended, and the lockout is lifted
+	if err := session.GetManager().RevokeAll(c, user.Namespace, user.Name); err != nil {
+		log.L(c).Errorf("revoke the sessions of user `%s/%s` failed: %s", user.Namespace, user.Name, err.Error())
+	}
+	lockout.GetLockout().Unlock(c, user.Namespace, user.Name)
+
+	record := analytics.AnalyticsRecord{
+		RequestID: c.GetHeader(middleware.XRequestIDKey),
+		TimeStamp: time.Now().Unix(),
+		Username:  user.Name,
+		Effect:    "reset-password",
+		ClientIP:  c.ClientIP(),
+	}
+	record.SetExpiry(0)
+	analytics.RecordRequest(c, &record)
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/account"
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/mail"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// memStore keeps the keys in memory, the expirations are ignored.
+type memStore struct {
+	sync.Mutex
+	values map[string]string
+}
+
+func (s *memStore) GetRawKey(key string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *memStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *memStore) DeleteRawKey(key string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	_, ok := s.values[key]
+	delete(s.values, key)
+
+	return ok
+}
+
+func (s *memStore) IncrememntWithExpire(key string, expire int64) int64 {
+	return 1
+}
+
+// recordMailer records the messages sent.
+type recordMailer struct {
+	sync.Mutex
+	messages []*mail.Message
+}
+
+func (m *recordMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+var tokenRegexp = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken returns the token in the link of the last message.
+func (m *recordMailer) lastToken(t *testing.T) string {
+	m.Lock()
+	defer m.Unlock()
+
+	if !assert.NotEmpty(t, m.messages) {
+		return ""
+	}
+	matches := tokenRegexp.FindStringSubmatch(m.messages[len(m.messages)-1].Body)
+	if !assert.Len(t, matches, 2) {
+		return ""
+	}
+	token, _ := url.QueryUnescape(matches[1])
+
+	return token
+}
+
+func newTestMailer(t *testing.T) *recordMailer {
+	opts := account.NewMailOptions()
+	opts.Secret = "secret"
+	opts.BaseURL = "https://leona.example.com"
+
+	mailer := &recordMailer{}
+	_, err := account.NewManager(opts, &memStore{values: map[string]string{}}, mailer)
+	assert.Nil(t, err)
+
+	return mailer
+}
+
+func postJSON(u *UserController, handler func(*UserController, *gin.Context), path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", path, bytes.NewBufferString(body))
+	c.Params = []gin.Param{{Key: "name", Value: "colin"}}
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(u, c)
+
+	return w
+}
+
+func TestUserController_PasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mailer := newTestMailer(t)
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin", Namespace: "tenant-a"},
+		Nickname:   "colin",
+		Email:      "colin@example.com",
+		Password:   "$2a$10$KqZhl5WStpa2K.ddEyzyf.zXllEXP4gIG8xQUgMhU1ZvMUn/Ta5um",
+	}
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq("tenant-a"), gomock.Eq("colin"), gomock.Any()).Return(user, nil).Times(3)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("nobody"), gomock.Any()).
+		Return(nil, errors.WithCode(code.ErrUserNotFound, "not found"))
+	mockUserSrv.EXPECT().ChangePassword(gomock.Any(), gomock.Eq(user)).Return(nil)
+	mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+	u := &UserController{srv: mockService}
+
+	// unknown users are not disclosed
+	w := postJSON(u, (*UserController).RequestPasswordReset, "/v1/password-reset", `{"username":"nobody"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, mailer.messages)
+
+	w = postJSON(u, (*UserController).RequestPasswordReset, "/v1/password-reset", `{"username":"tenant-a/colin"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	token := mailer.lastToken(t)
+
+	body := `{"token":"` + token + `","newPassword":"Colin@2021"}`
+	w = postJSON(u, (*UserController).ConfirmPasswordReset, "/v1/password-reset/confirm", body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, user.Compare("Colin@2021"))
+	assert.False(t, user.EmailVerifiedAt.IsZero())
+
+	// the token is single-use
+	body = `{"token":"` + token + `","newPassword":"Colin@2022"}`
+	w = postJSON(u, (*UserController).ConfirmPasswordReset, "/v1/password-reset/confirm", body)
+	var resp core.ErrResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrAccountTokenInvalid, resp.Code)
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/apiserver/session"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// ListSessions list the sessions of an user started by logging in.
+func (u *UserController) ListSessions(c *gin.Context) {
+	log.L(c).Info("list sessions function called.")
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	sessions, err := session.GetManager().List(c, user.Namespace, user.Name)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, sessions)
+}
+
+// RevokeSession revoke a session of an user by the session identifier, its token is rejected from now on.
+func (u *UserController) RevokeSession(c *gin.Context) {
+	log.L(c).Info("revoke session function called.")
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := session.GetManager().Revoke(c, user.Namespace, user.Name, c.Param("id")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// RevokeSessions revoke all the sessions of an user, the tokens issued before are rejected from now on.
+func (u *UserController) RevokeSessions(c *gin.Context) {
+	log.L(c).Info("revoke sessions function called.")
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := session.GetManager().RevokeAll(c, user.Namespace, user.Name); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+func TestUserController_Sessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("admin"), gomock.Any()).
+		Return(&v1.User{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "admin"}}, nil).
+		AnyTimes()
+
+	u := &UserController{
+		srv: mockService,
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		params  gin.Params
+		handler gin.HandlerFunc
+		want    int
+	}{
+		{
+			name:    "list",
+			method:  http.MethodGet,
+			path:    "/v1/users/admin/sessions",
+			params:  gin.Params{{Key: "name", Value: "admin"}},
+			handler: u.ListSessions,
+			want:    http.StatusOK,
+		},
+		{
+			name:    "revoke all",
+			method:  http.MethodDelete,
+			path:    "/v1/users/admin/sessions",
+			params:  gin.Params{{Key: "name", Value: "admin"}},
+			handler: u.RevokeSessions,
+			want:    http.StatusOK,
+		},
+		{
+			name:    "revoke unknown session",
+			method:  http.MethodDelete,
+			path:    "/v1/users/admin/sessions/unknown",
+			params:  gin.Params{{Key: "name", Value: "admin"}, {Key: "id", Value: "unknown"}},
+			handler: u.RevokeSession,
+			want:    http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(tt.method, tt.path, nil)
+			c.Params = tt.params
+
+			tt.handler(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
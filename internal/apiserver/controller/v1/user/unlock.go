@@ -0,0 +1,43 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/apiserver/lockout"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// Unlock lifts the lockout of an user caused by too many failed login attempts.
+// Only administrator can call this function.
+func (u *UserController) Unlock(c *gin.Context) {
+	log.L(c).Info("unlock user function called.")
+
+	namespace := middleware.RequestNamespace(c)
+	user, err := u.srv.Users().Get(c, namespace, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	lockout.GetLockout().Unlock(c, user.Namespace, user.Name)
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+func TestUserController_Unlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("admin"), gomock.Any()).
+		Return(&v1.User{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "admin"}}, nil)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("nobody"), gomock.Any()).
+		Return(nil, errors.WithCode(code.ErrUserNotFound, "record not found"))
+
+	tests := []struct {
+		name     string
+		username string
+		want     int
+	}{
+		{
+			name:     "default",
+			username: "admin",
+			want:     http.StatusOK,
+		},
+		{
+			name:     "user not found",
+			username: "nobody",
+			want:     http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("DELETE", "/v1/users/"+tt.username+"/lockout", nil)
+			c.Params = []gin.Param{{Key: "name", Value: tt.username}}
+
+			u := &UserController{
+				srv: mockService,
+			}
+			u.Unlock(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
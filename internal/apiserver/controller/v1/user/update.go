@@ -15,13 +15,17 @@
 package user
 
 import (
+	"time"
+
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	"github.com/dairongpeng/leona/pkg/core"
 	"github.com/dairongpeng/leona/pkg/errors"
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 	"github.com/gin-gonic/gin"
 
+	"github.com/dairongpeng/leona/internal/apiserver/account"
 	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -31,26 +35,36 @@ func (u *UserController) Update(c *gin.Context) {
 
 	var r v1.User
 
-	if err := c.ShouldBindJSON(&r); err != nil {
-		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
 
 		return
 	}
 
-	user, err := u.srv.Users().Get(c, c.Param("name"), metav1.GetOptions{})
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
 	if err != nil {
 		core.WriteResponse(c, err, nil)
 
 		return
 	}
 
+	if err := middleware.CheckPreconditions(c, user); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	emailChanged := user.Email != r.Email
 	user.Nickname = r.Nickname
 	user.Email = r.Email
+	if emailChanged {
+		user.EmailVerifiedAt = time.Time{}
+	}
 	user.Phone = r.Phone
 	user.Extend = r.Extend
 
 	if errs := user.ValidateUpdate(); len(errs) != 0 {
-		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
 
 		return
 	}
@@ -62,5 +76,12 @@ func (u *UserController) Update(c *gin.Context) {
 		return
 	}
 
+	// the changed email address is verified again
+	if emailChanged {
+		if err := account.GetManager().SendVerification(c, user); err != nil {
+			log.L(c).Errorf("send verification mail to user `%s/%s` failed: %s", user.Namespace, user.Name, err.Error())
+		}
+	}
+
 	core.WriteResponse(c, nil, user)
 }
@@ -0,0 +1,92 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/apiserver/account"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// VerifyEmailRequest defines the VerifyEmailRequest data format.
+type VerifyEmailRequest struct {
+	// The token mailed to the user, the verification mail is sent again if it is empty.
+	Token string `json:"token" binding:"omitempty"`
+}
+
+// VerifyEmail verifies the email address of the user by the token mailed to it.
+func (u *UserController) VerifyEmail(c *gin.Context) {
+	log.L(c).Info("verify email function called.")
+
+	var r VerifyEmailRequest
+
+	if c.Request.ContentLength != 0 {
+		if err := core.ShouldBindBody(c, &r); err != nil {
+			core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+			return
+		}
+	}
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if r.Token == "" {
+		if user.EmailVerifiedAt.IsZero() {
+			if err := account.GetManager().SendVerification(c, user); err != nil {
+				log.L(c).Errorf("send verification mail to user `%s/%s` failed: %s",
+					user.Namespace, user.Name, err.Error())
+			}
+		}
+
+		core.WriteResponse(c, nil, nil)
+
+		return
+	}
+
+	claims, err := account.GetManager().Verify(account.PurposeVerifyEmail, r.Token)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := account.GetManager().Consume(c, claims, user); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	user.EmailVerifiedAt = time.Now()
+	if err := u.srv.Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
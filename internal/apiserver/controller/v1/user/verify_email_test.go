@@ -0,0 +1,64 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"net/http"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/json"
+)
+
+func TestUserController_VerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mailer := newTestMailer(t)
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "colin", Namespace: metav1.NamespaceDefault},
+		Nickname:   "colin",
+		Email:      "colin@example.com",
+	}
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("colin"), gomock.Any()).
+		Return(user, nil).Times(3)
+	mockUserSrv.EXPECT().Update(gomock.Any(), gomock.Eq(user), gomock.Any()).Return(nil)
+	mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+	u := &UserController{srv: mockService}
+
+	w := postJSON(u, (*UserController).VerifyEmail, "/v1/users/colin/verify-email", `{"token":"invalid"}`)
+	var resp core.ErrResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, code.ErrAccountTokenInvalid, resp.Code)
+
+	// the mail is sent without the token
+	w = postJSON(u, (*UserController).VerifyEmail, "/v1/users/colin/verify-email", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	token := mailer.lastToken(t)
+
+	w = postJSON(u, (*UserController).VerifyEmail, "/v1/users/colin/verify-email", `{"token":"`+token+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, user.EmailVerifiedAt.IsZero())
+}
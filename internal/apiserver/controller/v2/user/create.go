@@ -0,0 +1,134 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/api/apiserver/scheme"
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	v2 "github.com/dairongpeng/leona/api/apiserver/v2"
+	"github.com/dairongpeng/leona/internal/apiserver/account"
+	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// Create add new user to the storage.
+func (u *UserController) Create(c *gin.Context) {
+	log.L(c).Info("user create function called.")
+
+	var r v2.User
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	namespace := middleware.RequestNamespace(c)
+	if r.Namespace != "" && r.Namespace != namespace {
+		err := field.Invalid(field.NewPath("metadata", "namespace"), r.Namespace,
+			"the namespace of the provided object does not match the namespace sent on the request")
+		core.WriteResponse(c, errors.WrapC(err, code.ErrValidation, err.Error()), nil)
+
+		return
+	}
+	r.Namespace = namespace
+
+	if errs := r.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	var user v1.User
+	if err := scheme.Scheme.Convert(&r, &user); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrDecodingFailed, err.Error()), nil)
+
+		return
+	}
+
+	// only the administrators create the other administrators
+	admin := user.IsAdmin == 1 && requestedByAdmin(c, u.srv, user.Namespace)
+	user.PrepareForCreate()
+	if admin {
+		user.IsAdmin = 1
+	}
+
+	user.Password, _ = auth.Encrypt(user.Password)
+	user.PasswordChangedAt = time.Now()
+	user.Status = 1
+	user.LoginedAt = time.Now()
+
+	// Insert the user to the storage.
+	if err := u.srv.Users().Create(c, &user, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// the email address is verified by the token mailed to it
+	if err := account.GetManager().SendVerification(c, &user); err != nil {
+		log.L(c).Errorf("send verification mail to user `%s/%s` failed: %s", user.Namespace, user.Name, err.Error())
+	}
+
+	record := analytics.AnalyticsRecord{
+		RequestID: c.GetHeader(middleware.XRequestIDKey),
+		TimeStamp: time.Now().Unix(),
+		Username:  user.Name,
+		Effect:    "create",
+		ClientIP:  c.ClientIP(),
+	}
+	record.SetExpiry(0)
+	analytics.RecordRequest(c, &record)
+
+	writeUser(c, &user)
+}
+
+// writeUser converts the stored user to v2 and writes it into the response.
+func writeUser(c *gin.Context, user *v1.User) {
+	out, err := scheme.Scheme.ConvertToVersion(user, v2.SchemeGroupVersion)
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrEncodingFailed, err.Error()), nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, out)
+}
+
+// requestedByAdmin reports whether the request is sent by an authenticated administrator of the namespace.
+// The administrators of the default namespace administer all the namespaces, as the rbac grants them.
+func requestedByAdmin(c *gin.Context, srv srvv1.Service, namespace string) bool {
+	username := c.GetString(middleware.UsernameKey)
+	requesterNamespace := c.GetString(middleware.NamespaceKey)
+	if username == "" || (requesterNamespace != namespace && requesterNamespace != metav1.NamespaceDefault) {
+		return false
+	}
+
+	requester, err := srv.Users().Get(c, requesterNamespace, username, metav1.GetOptions{})
+
+	return err == nil && requester.IsAdmin == 1
+}
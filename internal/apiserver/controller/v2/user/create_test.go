@@ -0,0 +1,114 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+)
+
+func TestUserController_Create(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		requester *v1.User
+		wantAdmin int
+	}{
+		{
+			name: "anonymous",
+		},
+		{
+			name:      "non-admin",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "user2", Namespace: metav1.NamespaceDefault}},
+		},
+		{
+			name:      "admin",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: metav1.NamespaceDefault}, IsAdmin: 1},
+			wantAdmin: 1,
+		},
+		{
+			name:      "admin of the namespace",
+			namespace: "team-b",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "team-b"}, IsAdmin: 1},
+			wantAdmin: 1,
+		},
+		{
+			name:      "admin of another namespace",
+			namespace: "team-b",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "team-a"}, IsAdmin: 1},
+		},
+		{
+			name:      "admin of the default namespace",
+			namespace: "team-b",
+			requester: &v1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: metav1.NamespaceDefault}, IsAdmin: 1},
+			wantAdmin: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var created *v1.User
+			mockService := srvv1.NewMockService(ctrl)
+			mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+			mockUserSrv.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, user *v1.User, _ metav1.CreateOptions) error {
+					created = user
+
+					return nil
+				})
+			mockService.EXPECT().Users().Return(mockUserSrv).AnyTimes()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := bytes.NewBufferString(`{"metadata":{"name":"mallory"},"spec":{"profile":{"displayName":"mallory",` +
+				`"email":"mallory@qq.com"},"password":"Mallory@2020","admin":true},` +
+				`"status":{"mfaEnableTime":"2020-01-01T00:00:00Z","emailVerifyTime":"2020-01-01T00:00:00Z"}}`)
+			c.Request, _ = http.NewRequest("POST", "/v2/users", body)
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.namespace != "" {
+				c.Params = gin.Params{{Key: middleware.NamespaceParam, Value: tt.namespace}}
+			}
+			if tt.requester != nil {
+				c.Set(middleware.UsernameKey, tt.requester.Name)
+				c.Set(middleware.NamespaceKey, tt.requester.Namespace)
+				mockUserSrv.EXPECT().Get(gomock.Any(), tt.requester.Namespace, tt.requester.Name, gomock.Any()).
+					Return(tt.requester, nil).AnyTimes()
+			}
+
+			u := &UserController{srv: mockService}
+			u.Create(c)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			if assert.NotNil(t, created) {
+				assert.Equal(t, tt.wantAdmin, created.IsAdmin)
+				assert.True(t, created.MFAEnabledAt.IsZero())
+				assert.True(t, created.EmailVerifiedAt.IsZero())
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestUserController_List(t *testing.T) {
+	users := &v1.UserList{
+		ListMeta: metav1.ListMeta{TotalCount: 1},
+		Items: []*v1.User{
+			{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Nickname: "colin", Email: "colin@foxmail.com"},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().List(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Any()).Return(users, nil)
+	mockService.EXPECT().Users().Return(mockUserSrv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/v2/users", nil)
+
+	u := &UserController{
+		srv: mockService,
+	}
+	u.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kind":"UserList","apiVersion":"v2","totalCount":1`)
+	assert.Contains(t, w.Body.String(), `"profile":{"displayName":"colin","email":"colin@foxmail.com"}`)
+}
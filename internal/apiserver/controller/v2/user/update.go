@@ -0,0 +1,103 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/api/apiserver/scheme"
+	v2 "github.com/dairongpeng/leona/api/apiserver/v2"
+	"github.com/dairongpeng/leona/internal/apiserver/account"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// Update update a user info by the user identifier.
+// Only the profile and the extend fields can be changed.
+func (u *UserController) Update(c *gin.Context) {
+	log.L(c).Info("update user function called.")
+
+	var r v2.User
+
+	if err := core.ShouldBindBody(c, &r); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	user, err := u.srv.Users().Get(c, middleware.RequestNamespace(c), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	if err := middleware.CheckPreconditions(c, user); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	var updated v2.User
+	if err := scheme.Scheme.Convert(user, &updated); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrDecodingFailed, err.Error()), nil)
+
+		return
+	}
+
+	updated.Spec.Profile = r.Spec.Profile
+	updated.Extend = r.Extend
+
+	if errs := updated.ValidateUpdate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WrapC(errs.ToAggregate(), code.ErrValidation, "validation failed"), nil)
+
+		return
+	}
+
+	// the password hash is not exposed in v2, keep the stored one.
+	password, email := user.Password, user.Email
+	if err := scheme.Scheme.Convert(&updated, user); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrDecodingFailed, err.Error()), nil)
+
+		return
+	}
+	user.Password = password
+	emailChanged := user.Email != email
+	if emailChanged {
+		user.EmailVerifiedAt = time.Time{}
+	}
+
+	// Save changed fields.
+	if err := u.srv.Users().Update(c, user, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	// the changed email address is verified again
+	if emailChanged {
+		if err := account.GetManager().SendVerification(c, user); err != nil {
+			log.L(c).Errorf("send verification mail to user `%s/%s` failed: %s", user.Namespace, user.Name, err.Error())
+		}
+	}
+
+	writeUser(c, user)
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	srvv1 "github.com/dairongpeng/leona/internal/apiserver/service/v1"
+)
+
+func TestUserController_Update(t *testing.T) {
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "admin",
+		},
+		Status:   1,
+		Nickname: "admin",
+		Password: "Admin@2020",
+		Email:    "admin@foxmail.com",
+		IsAdmin:  1,
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := bytes.NewBufferString(`{"spec":{"profile":{"displayName":"admin2","email":"admin2@foxmail.com"},"admin":false}}`)
+	c.Request, _ = http.NewRequest("PUT", "/v2/users/admin", body)
+	c.Params = []gin.Param{{Key: "name", Value: "admin"}}
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// only the profile is changed, the admin flag and password are kept.
+	user2 := new(v1.User)
+	*user2 = *user
+	user2.Nickname = "admin2"
+	user2.Email = "admin2@foxmail.com"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := srvv1.NewMockService(ctrl)
+	mockUserSrv := srvv1.NewMockUserSrv(ctrl)
+	mockUserSrv.EXPECT().Get(gomock.Any(), gomock.Eq(metav1.NamespaceDefault), gomock.Eq("admin"), gomock.Any()).Return(user, nil)
+	mockUserSrv.EXPECT().Update(gomock.Any(), gomock.Eq(user2), gomock.Any()).Return(nil)
+	mockService.EXPECT().Users().Return(mockUserSrv).Times(2)
+
+	u := &UserController{
+		srv: mockService,
+	}
+	u.Update(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"displayName":"admin2"`)
+	assert.NotContains(t, w.Body.String(), "password")
+}
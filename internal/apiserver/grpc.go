@@ -15,7 +15,10 @@
 package apiserver
 
 import (
+	"context"
+	"errors"
 	"net"
+	"sync/atomic"
 
 	"google.golang.org/grpc"
 
@@ -25,6 +28,7 @@ import (
 type grpcAPIServer struct {
 	*grpc.Server
 	address string
+	serving int32
 }
 
 // Run 启动GRPC的server
@@ -34,6 +38,8 @@ func (s *grpcAPIServer) Run() {
 		log.Fatalf("failed to listen: %s", err.Error())
 	}
 
+	atomic.StoreInt32(&s.serving, 1)
+
 	go func() {
 		if err := s.Serve(listen); err != nil {
 			log.Fatalf("failed to start grpc server: %s", err.Error())
@@ -44,6 +50,21 @@ func (s *grpcAPIServer) Run() {
 }
 
 func (s *grpcAPIServer) Close() {
+	atomic.StoreInt32(&s.serving, 0)
 	s.GracefulStop()
 	log.Infof("GRPC server on %s stopped", s.address)
 }
+
+// Name returns the name of the health check of the grpc server.
+func (s *grpcAPIServer) Name() string {
+	return "grpc"
+}
+
+// Check fails when the grpc server is not serving.
+func (s *grpcAPIServer) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&s.serving) == 0 {
+		return errors.New("grpc server is not serving")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	genericapiserver "github.com/dairongpeng/leona/internal/pkg/server"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// redisHealthz fails when the connection to redis is down.
+var redisHealthz = genericapiserver.NamedCheck("redis", func(context.Context) error {
+	if !storage.Connected() {
+		return errors.New("redis is not connected")
+	}
+
+	return nil
+})
+
+// installHealthChecks adds the dependencies of the api server to the readiness checks: the store
+// when it is able to check its backend, redis and the grpc server.
+func (s *apiServer) installHealthChecks() {
+	checks := []genericapiserver.HealthChecker{redisHealthz, s.gRPCAPIServer}
+	if checker, ok := store.Client().(genericapiserver.HealthChecker); ok {
+		checks = append([]genericapiserver.HealthChecker{checker}, checks...)
+	}
+
+	s.genericAPIServer.HealthChecks.AddReadyzChecks(checks...)
+}
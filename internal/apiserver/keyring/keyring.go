@@ -0,0 +1,406 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keyring keeps the asymmetric keys signing the JWT tokens. The keys are shared by the
+// apiserver instances through redis, and rotated on schedule: the retired key keeps verifying the
+// tokens it signed for the overlap window. The private keys are encrypted by the key encryption key
+// before they are kept in redis.
+package keyring
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/jwks"
+	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+const (
+	// KeyName is the redis key of the key ring.
+	KeyName = "jwt-keyring"
+
+	// lockName is the redis key locking the key ring while it is rotated.
+	lockName = "jwt-keyring-lock"
+
+	// lockTimeout is how long the lock is held by an instance which failed to release it, in seconds.
+	lockTimeout = 10
+
+	// reloadInterval is how often the keys rotated by the other instances are loaded.
+	reloadInterval = time.Minute
+
+	// minReloadInterval limits the reloads caused by the tokens with an unknown kid.
+	minReloadInterval = time.Second
+)
+
+// Store defines the redis operations used to share the key ring.
+// It is implemented by storage.RedisCluster.
+type Store interface {
+	GetRawKey(key string) (string, error)
+	SetRawKey(key, value string, timeout time.Duration) error
+	DeleteRawKey(key string) bool
+	IncrememntWithExpire(key string, expire int64) int64
+}
+
+// key is a signing key kept in redis.
+type key struct {
+	ID        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+
+	// EncryptedKey is the PKCS #8 form of the private key encrypted by the key encryption key.
+	EncryptedKey []byte    `json:"encryptedKey"`
+	CreatedAt    time.Time `json:"createdAt"`
+
+	// RetiredAt is when the key stopped signing, it is zero for the signing key.
+	RetiredAt time.Time `json:"retiredAt,omitempty"`
+
+	signer crypto.Signer
+}
+
+// Ring keeps the keys signing and verifying the JWT tokens, the latest key signs the new tokens.
+// A nil Ring has no keys.
+type Ring struct {
+	store     Store
+	algorithm string
+	// aead encrypts the private keys kept in redis.
+	aead cipher.AEAD
+
+	// period is how long a key signs before it is rotated.
+	period time.Duration
+	// overlap is how long a retired key keeps verifying.
+	overlap time.Duration
+
+	mu       sync.RWMutex
+	keys     []*key
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+var ring *Ring
+
+// NewRing returns a new key ring generating the keys of the signing algorithm, the private keys
+// are encrypted by kek.
+func NewRing(store Store, algorithm, kek string, period, overlap time.Duration) (*Ring, error) {
+	sum := sha256.Sum256([]byte(kek))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	ring = &Ring{
+		store:     store,
+		algorithm: algorithm,
+		aead:      aead,
+		period:    period,
+		overlap:   overlap,
+		now:       time.Now,
+	}
+
+	return ring, nil
+}
+
+// GetRing returns the key ring created by NewRing.
+func GetRing() *Ring {
+	return ring
+}
+
+// SigningKey returns the latest key, which signs the new tokens.
+func (r *Ring) SigningKey() (string, jwt.SigningMethod, interface{}, error) {
+	if r == nil {
+		return "", nil, nil, fmt.Errorf("no signing key")
+	}
+
+	r.refresh()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 || !r.keys[0].RetiredAt.IsZero() {
+		return "", nil, nil, fmt.Errorf("no signing key")
+	}
+
+	k := r.keys[0]
+
+	return k.ID, jwt.GetSigningMethod(k.Algorithm), k.signer, nil
+}
+
+// VerificationKey returns the public key verifying the tokens signed by the key identified by kid.
+// The keys rotated by the other instances are loaded if kid is unknown.
+func (r *Ring) VerificationKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	if r == nil {
+		return nil, nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	r.refresh()
+
+	k := r.find(kid)
+	if k == nil {
+		r.mu.RLock()
+		stale := r.now().Sub(r.loadedAt) >= minReloadInterval
+		r.mu.RUnlock()
+
+		if stale {
+			r.reload()
+			k = r.find(kid)
+		}
+	}
+
+	if k == nil {
+		return nil, nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return jwt.GetSigningMethod(k.Algorithm), k.signer.Public(), nil
+}
+
+// KeySet returns the public keys verifying the tokens.
+func (r *Ring) KeySet() jwks.KeySet {
+	set := jwks.KeySet{Keys: []jwks.Key{}}
+	if r == nil {
+		return set
+	}
+
+	r.refresh()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, k := range r.keys {
+		pub, err := jwks.NewKey(k.ID, k.Algorithm, k.signer.Public())
+		if err != nil {
+			log.Errorf("publish jwt key `%s` failed: %s", k.ID, err.Error())
+
+			continue
+		}
+		set.Keys = append(set.Keys, pub)
+	}
+
+	return set
+}
+
+// Rotate retires the signing key and starts signing with a new key right away.
+func (r *Ring) Rotate() error {
+	if r == nil {
+		return nil
+	}
+
+	return r.rotate(true)
+}
+
+// refresh loads the key ring every reloadInterval, and rotates the signing key when it is due.
+func (r *Ring) refresh() {
+	r.mu.RLock()
+	fresh := len(r.keys) > 0 && r.now().Sub(r.loadedAt) < reloadInterval
+	r.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	r.reload()
+
+	r.mu.RLock()
+	due := len(r.keys) == 0 || r.due(r.keys[0])
+	r.mu.RUnlock()
+	if due {
+		if err := r.rotate(false); err != nil {
+			log.Errorf("rotate jwt signing key failed: %s", err.Error())
+		}
+	}
+}
+
+// due reports whether the signing key must be rotated.
+func (r *Ring) due(k *key) bool {
+	return !k.RetiredAt.IsZero() || k.Algorithm != r.algorithm || !r.now().Before(k.CreatedAt.Add(r.period))
+}
+
+// rotate adds a new signing key to the key ring in redis, the other instances do not rotate meanwhile.
+func (r *Ring) rotate(force bool) error {
+	if r.store.IncrememntWithExpire(lockName, lockTimeout) != 1 {
+		// the key ring is being rotated by another instance
+		return nil
+	}
+	defer r.store.DeleteRawKey(lockName)
+
+	keys, err := r.load()
+	if err != nil {
+		return err
+	}
+	if !force && len(keys) > 0 && !r.due(keys[0]) {
+		r.set(keys)
+
+		return nil
+	}
+
+	signer, err := jwks.GenerateKey(r.algorithm)
+	if err != nil {
+		return err
+	}
+	id := uuid.Must(uuid.NewV4()).String()
+	encrypted, err := r.encrypt(id, signer)
+	if err != nil {
+		return err
+	}
+
+	now := r.now()
+	rotated := []*key{{
+		ID:           id,
+		Algorithm:    r.algorithm,
+		EncryptedKey: encrypted,
+		CreatedAt:    now,
+		signer:       signer,
+	}}
+	for _, k := range keys {
+		if k.RetiredAt.IsZero() {
+			k.RetiredAt = now
+		}
+
+		// the retired keys are dropped from redis after the overlap window
+		if now.Before(k.RetiredAt.Add(r.overlap)) {
+			rotated = append(rotated, k)
+		}
+	}
+
+	data, err := json.Marshal(rotated)
+	if err != nil {
+		return err
+	}
+	if err := r.store.SetRawKey(KeyName, string(data), 0); err != nil {
+		return err
+	}
+	r.set(rotated)
+
+	log.Infof("rotated jwt signing key to `%s`", rotated[0].ID)
+
+	return nil
+}
+
+// reload loads the key ring from redis.
+func (r *Ring) reload() {
+	keys, err := r.load()
+	if err != nil {
+		log.Errorf("load jwt keys failed: %s", err.Error())
+
+		return
+	}
+
+	r.set(keys)
+}
+
+func (r *Ring) load() ([]*key, error) {
+	data, err := r.store.GetRawKey(KeyName)
+	if errors.Is(err, storage.ErrKeyNotFound) {
+		// the key ring is created by the first rotation
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*key
+	if err := json.Unmarshal([]byte(data), &keys); err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		signer, err := r.decrypt(k)
+		if err != nil {
+			return nil, err
+		}
+		k.signer = signer
+	}
+
+	return keys, nil
+}
+
+// encrypt encrypts the PKCS #8 form of the private key, the key id is authenticated with it.
+func (r *Ring) encrypt(id string, signer crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, r.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return r.aead.Seal(nonce, nonce, der, []byte(id)), nil
+}
+
+// decrypt decrypts the private key, it fails if the key is encrypted by another key encryption key.
+func (r *Ring) decrypt(k *key) (crypto.Signer, error) {
+	size := r.aead.NonceSize()
+	if len(k.EncryptedKey) < size {
+		return nil, fmt.Errorf("jwt key `%s` is not encrypted", k.ID)
+	}
+
+	der, err := r.aead.Open(nil, k.EncryptedKey[:size], k.EncryptedKey[size:], []byte(k.ID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt jwt key `%s` failed, the key encryption key may be changed", k.ID)
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("jwt key `%s` is not a signing key", k.ID)
+	}
+
+	return signer, nil
+}
+
+// set keeps the keys, except the retired keys out of the overlap window.
+func (r *Ring) set(keys []*key) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var kept []*key
+	for _, k := range keys {
+		if k.RetiredAt.IsZero() || now.Before(k.RetiredAt.Add(r.overlap)) {
+			kept = append(kept, k)
+		}
+	}
+	r.keys = kept
+	r.loadedAt = now
+}
+
+func (r *Ring) find(kid string) *key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.ID == kid {
+			return k
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/jwks"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// fakeStore keeps the keys in memory, the expirations are ignored.
+type fakeStore struct {
+	values map[string]string
+	counts map[string]int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[string]string{}, counts: map[string]int64{}}
+}
+
+func (s *fakeStore) GetRawKey(key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *fakeStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *fakeStore) DeleteRawKey(key string) bool {
+	_, ok := s.counts[key]
+	delete(s.counts, key)
+
+	return ok
+}
+
+func (s *fakeStore) IncrememntWithExpire(key string, expire int64) int64 {
+	s.counts[key]++
+
+	return s.counts[key]
+}
+
+// clock is the fake time of the rings.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestRing(store Store, algorithm string, c *clock) *Ring {
+	r, _ := NewRing(store, algorithm, "keyring-test-kek", 24*time.Hour, 2*time.Hour)
+	r.now = c.Now
+
+	return r
+}
+
+// signAndVerify signs a token with the signing key of a, and verifies it with the keys of b.
+func signAndVerify(t *testing.T, a, b *Ring) (string, error) {
+	kid, method, key, err := a.SigningKey()
+	assert.NoError(t, err)
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "admin"})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+
+	_, err = jwt.Parse(signed, func(t *jwt.Token) (interface{}, error) {
+		_, key, err := b.VerificationKey(t.Header["kid"].(string))
+
+		return key, err
+	})
+
+	return kid, err
+}
+
+func TestRing_SigningKey(t *testing.T) {
+	for _, alg := range []string{jwks.RS256, jwks.ES256, jwks.EdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			store := newFakeStore()
+			r := newTestRing(store, alg, &clock{now: time.Now()})
+
+			kid, err := signAndVerify(t, r, r)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, kid)
+			assert.Contains(t, store.values, KeyName)
+			assert.NotContains(t, store.counts, lockName)
+
+			set := r.KeySet()
+			assert.Len(t, set.Keys, 1)
+			assert.Equal(t, kid, set.Keys[0].KeyID)
+			assert.Equal(t, alg, set.Keys[0].Algorithm)
+		})
+	}
+}
+
+func TestRing_Rotation(t *testing.T) {
+	c := &clock{now: time.Now()}
+	r := newTestRing(newFakeStore(), jwks.ES256, c)
+
+	old, _, _, err := r.SigningKey()
+	assert.NoError(t, err)
+
+	// the key is rotated after the period
+	c.Add(24 * time.Hour)
+	current, _, _, err := r.SigningKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, old, current)
+
+	// the retired key keeps verifying within the overlap window
+	_, _, err = r.VerificationKey(old)
+	assert.NoError(t, err)
+	assert.Len(t, r.KeySet().Keys, 2)
+
+	c.Add(2 * time.Hour)
+	_, _, err = r.VerificationKey(old)
+	assert.Error(t, err)
+	_, _, err = r.VerificationKey(current)
+	assert.NoError(t, err)
+	assert.Len(t, r.KeySet().Keys, 1)
+}
+
+func TestRing_Rotate(t *testing.T) {
+	r := newTestRing(newFakeStore(), jwks.EdDSA, &clock{now: time.Now()})
+
+	old, _, _, err := r.SigningKey()
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Rotate())
+	current, _, _, err := r.SigningKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, old, current)
+
+	_, _, err = r.VerificationKey(old)
+	assert.NoError(t, err)
+}
+
+func TestRing_AlgorithmChanged(t *testing.T) {
+	store := newFakeStore()
+	c := &clock{now: time.Now()}
+
+	old, _, _, err := newTestRing(store, jwks.RS256, c).SigningKey()
+	assert.NoError(t, err)
+
+	// the key of the former algorithm is rotated at once
+	kid, method, _, err := newTestRing(store, jwks.ES256, c).SigningKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, old, kid)
+	assert.Equal(t, jwt.SigningMethodES256, method)
+}
+
+func TestRing_SharedByInstances(t *testing.T) {
+	store := newFakeStore()
+	c := &clock{now: time.Now()}
+	a := newTestRing(store, jwks.ES256, c)
+	b := newTestRing(store, jwks.ES256, c)
+
+	// the instances sign with the same key
+	kid, err := signAndVerify(t, a, b)
+	assert.NoError(t, err)
+	current, _, _, err := b.SigningKey()
+	assert.NoError(t, err)
+	assert.Equal(t, kid, current)
+
+	// the key rotated by an instance is loaded by the other when it verifies a token
+	assert.NoError(t, a.Rotate())
+	c.Add(minReloadInterval)
+	rotated, err := signAndVerify(t, a, b)
+	assert.NoError(t, err)
+	assert.NotEqual(t, kid, rotated)
+}
+
+func TestRing_Locked(t *testing.T) {
+	store := newFakeStore()
+	r := newTestRing(store, jwks.ES256, &clock{now: time.Now()})
+
+	// another instance is rotating the key ring
+	store.IncrememntWithExpire(lockName, lockTimeout)
+	_, _, _, err := r.SigningKey()
+	assert.Error(t, err)
+	assert.NotContains(t, store.values, KeyName)
+}
+
+func TestRing_Nil(t *testing.T) {
+	var r *Ring
+
+	_, _, _, err := r.SigningKey()
+	assert.Error(t, err)
+	_, _, err = r.VerificationKey("kid")
+	assert.Error(t, err)
+	assert.Empty(t, r.KeySet().Keys)
+	assert.NoError(t, r.Rotate())
+}
+
+func TestRing_EncryptedKeys(t *testing.T) {
+	store := newFakeStore()
+	c := &clock{now: time.Now()}
+
+	_, _, _, err := newTestRing(store, jwks.ES256, c).SigningKey()
+	assert.NoError(t, err)
+
+	// the private keys are not kept in plain text
+	var keys []map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(store.values[KeyName]), &keys))
+	if assert.Len(t, keys, 1) {
+		assert.NotContains(t, keys[0], "privateKey")
+		assert.NotEmpty(t, keys[0]["encryptedKey"])
+	}
+
+	// the keys can not be loaded with another key encryption key
+	other, _ := NewRing(store, jwks.ES256, "another-kek", 24*time.Hour, 2*time.Hour)
+	other.now = c.Now
+	_, err = other.load()
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldapuser
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/dairongpeng/leona/pkg/directory"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// LDAPOptions contains configuration items related to the LDAP authentication and the directory user sync.
+type LDAPOptions struct {
+	Enable             bool          `json:"enable"               mapstructure:"enable"`
+	URL                string        `json:"url"                  mapstructure:"url"`
+	InsecureSkipVerify bool          `json:"insecure-skip-verify" mapstructure:"insecure-skip-verify"`
+	BindDN             string        `json:"bind-dn"              mapstructure:"bind-dn"`
+	BindPassword       string        `json:"-"                    mapstructure:"bind-password"`
+	BaseDN             string        `json:"base-dn"              mapstructure:"base-dn"`
+	UserFilter         string        `json:"user-filter"          mapstructure:"user-filter"`
+	UsernameAttribute  string        `json:"username-attribute"   mapstructure:"username-attribute"`
+	EmailAttribute     string        `json:"email-attribute"      mapstructure:"email-attribute"`
+	NicknameAttribute  string        `json:"nickname-attribute"   mapstructure:"nickname-attribute"`
+	GroupAttribute     string        `json:"group-attribute"      mapstructure:"group-attribute"`
+	AdminGroups        []string      `json:"admin-groups"         mapstructure:"admin-groups"`
+	Namespace          string        `json:"namespace"            mapstructure:"namespace"`
+	SyncInterval       time.Duration `json:"sync-interval"        mapstructure:"sync-interval"`
+	Timeout            time.Duration `json:"timeout"              mapstructure:"timeout"`
+	CacheTTL           time.Duration `json:"cache-ttl"            mapstructure:"cache-ttl"`
+}
+
+// NewLDAPOptions creates a LDAPOptions object with default parameters.
+func NewLDAPOptions() *LDAPOptions {
+	return &LDAPOptions{
+		Enable:            false,
+		UserFilter:        "(objectClass=person)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		NicknameAttribute: "cn",
+		GroupAttribute:    "memberOf",
+		AdminGroups:       []string{},
+		Namespace:         metav1.NamespaceDefault,
+		SyncInterval:      15 * time.Minute,
+		Timeout:           10 * time.Second,
+		CacheTTL:          time.Minute,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *LDAPOptions) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	var errors []error
+
+	if u, err := url.Parse(o.URL); err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		errors = append(errors, fmt.Errorf("--ldap.url %q must be an ldap:// or ldaps:// url", o.URL))
+	}
+
+	if o.BaseDN == "" {
+		errors = append(errors, fmt.Errorf("--ldap.base-dn must be specified"))
+	}
+
+	if o.UsernameAttribute == "" {
+		errors = append(errors, fmt.Errorf("--ldap.username-attribute must be specified"))
+	}
+
+	if o.Namespace == "" {
+		errors = append(errors, fmt.Errorf("--ldap.namespace must be specified"))
+	}
+
+	if o.SyncInterval != 0 && o.SyncInterval < time.Minute {
+		errors = append(errors, fmt.Errorf("--ldap.sync-interval %v must be 0 or at least 1m", o.SyncInterval))
+	}
+
+	if o.Timeout < time.Second {
+		errors = append(errors, fmt.Errorf("--ldap.timeout %v must be at least 1s", o.Timeout))
+	}
+
+	if o.CacheTTL < 0 {
+		errors = append(errors, fmt.Errorf("--ldap.cache-ttl %v must not be negative", o.CacheTTL))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the LDAP authentication for a specific api server to the
+// specified FlagSet.
+func (o *LDAPOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "ldap.enable", o.Enable, ""+
+		"Authenticate the basic credentials against the LDAP directory, and sync the directory users.")
+
+	fs.StringVar(&o.URL, "ldap.url", o.URL,
+		"The url of the LDAP server, e.g. ldap://ldap.example.com:389 or ldaps://ldap.example.com.")
+
+	fs.BoolVar(&o.InsecureSkipVerify, "ldap.insecure-skip-verify", o.InsecureSkipVerify,
+		"Skip verifying the certificate of the ldaps server.")
+
+	fs.StringVar(&o.BindDN, "ldap.bind-dn", o.BindDN,
+		"The DN of the service account searching the users, the search is anonymous if it is empty.")
+
+	fs.StringVar(&o.BindPassword, "ldap.bind-password", o.BindPassword,
+		"The password of the service account.")
+
+	fs.StringVar(&o.BaseDN, "ldap.base-dn", o.BaseDN,
+		"The DN where the users are searched, e.g. ou=people,dc=example,dc=com.")
+
+	fs.StringVar(&o.UserFilter, "ldap.user-filter", o.UserFilter,
+		"The filter selecting the user entries.")
+
+	fs.StringVar(&o.UsernameAttribute, "ldap.username-attribute", o.UsernameAttribute,
+		"The attribute of the user entries holding the username.")
+
+	fs.StringVar(&o.EmailAttribute, "ldap.email-attribute", o.EmailAttribute,
+		"The attribute of the user entries holding the email.")
+
+	fs.StringVar(&o.NicknameAttribute, "ldap.nickname-attribute", o.NicknameAttribute,
+		"The attribute of the user entries holding the nickname.")
+
+	fs.StringVar(&o.GroupAttribute, "ldap.group-attribute", o.GroupAttribute,
+		"The attribute of the user entries holding the DNs of their groups.")
+
+	fs.StringSliceVar(&o.AdminGroups, "ldap.admin-groups", o.AdminGroups,
+		"The DNs of the groups whose members are the administrators.")
+
+	fs.StringVar(&o.Namespace, "ldap.namespace", o.Namespace,
+		"The namespace of the directory users.")
+
+	fs.DurationVar(&o.SyncInterval, "ldap.sync-interval", o.SyncInterval, ""+
+		"The interval of mirroring the directory users into the user store, 0 disables the sync.")
+
+	fs.DurationVar(&o.Timeout, "ldap.timeout", o.Timeout,
+		"The timeout of connecting to the LDAP server and of each request.")
+
+	fs.DurationVar(&o.CacheTTL, "ldap.cache-ttl", o.CacheTTL, ""+
+		"How long a successful authentication is cached, the same credentials are not bound again "+
+		"until it expires. The cache of an user is dropped by the sync once the user is removed from "+
+		"the directory, the old password still authenticates until it expires. 0 disables the cache.")
+}
+
+// DirectoryConfig returns the config of the LDAP directory.
+func (o *LDAPOptions) DirectoryConfig() directory.Config {
+	return directory.Config{
+		URL:                o.URL,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+		BindDN:             o.BindDN,
+		BindPassword:       o.BindPassword,
+		BaseDN:             o.BaseDN,
+		UserFilter:         o.UserFilter,
+		UsernameAttribute:  o.UsernameAttribute,
+		EmailAttribute:     o.EmailAttribute,
+		NicknameAttribute:  o.NicknameAttribute,
+		GroupAttribute:     o.GroupAttribute,
+		AdminGroups:        o.AdminGroups,
+		Timeout:            o.Timeout,
+		CacheTTL:           o.CacheTTL,
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ldapuser mirrors the users of the LDAP directory into the user store. The directory users
+// are created on their first login and by the periodic sync, the users removed from the directory
+// are disabled and their sessions are revoked.
+package ldapuser
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/session"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	authutil "github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/directory"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/util/idutil"
+)
+
+const (
+	// Source is the source of the users mirrored from the directory.
+	Source = "ldap"
+
+	// loginInterval is how often the login time of an user is recorded, the basic credentials are
+	// sent with each request.
+	loginInterval = time.Minute
+
+	// maxNicknameLength is the max length of the nickname of the user.
+	maxNicknameLength = 30
+
+	// listLimit is the page size of listing the local users.
+	listLimit = 1000
+)
+
+// Mirror mirrors the users of the LDAP directory into the user store.
+// A nil Mirror means the LDAP authentication is disabled.
+type Mirror struct {
+	directory *directory.Directory
+	store     store.Factory
+	namespace string
+	interval  time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+var mirror *Mirror
+
+// NewMirror returns a new mirror, the directory users are put into the namespace and synced every
+// interval, 0 interval disables the periodic sync.
+func NewMirror(dir *directory.Directory, factory store.Factory, namespace string, interval time.Duration) *Mirror {
+	mirror = &Mirror{
+		directory: dir,
+		store:     factory,
+		namespace: namespace,
+		interval:  interval,
+		stop:      make(chan struct{}),
+	}
+
+	return mirror
+}
+
+// GetMirror returns the existed mirror instance.
+// It is nil when the LDAP authentication is not enabled.
+func GetMirror() *Mirror {
+	return mirror
+}
+
+// Directory returns the LDAP directory.
+func (m *Mirror) Directory() *directory.Directory {
+	return m.directory
+}
+
+// Namespace returns the namespace of the directory users.
+func (m *Mirror) Namespace() string {
+	return m.namespace
+}
+
+// Login mirrors the authenticated directory user into the user store, and returns the local user.
+// The user is rejected if it is disabled, or the username belongs to a local user.
+func (m *Mirror) Login(ctx context.Context, entry *directory.Entry) (*v1.User, error) {
+	user, err := m.apply(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == 0 {
+		return nil, errors.WithCode(code.ErrPermissionDenied, "user `%s/%s` is disabled", m.namespace, user.Name)
+	}
+
+	if time.Since(user.LoginedAt) >= loginInterval {
+		user.LoginedAt = time.Now()
+		_ = m.store.Users().UpdateLoginedAt(ctx, user.Namespace, user.Name, user.LoginedAt)
+	}
+
+	return user, nil
+}
+
+// Sync mirrors all the directory users into the user store, and disables the users removed from
+// the directory.
+func (m *Mirror) Sync(ctx context.Context) error {
+	entries, err := m.directory.Users()
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		present[entry.Username] = true
+
+		if _, err := m.apply(ctx, entry); err != nil {
+			log.Warnf("sync directory user `%s` failed: %s", entry.DN, err.Error())
+		}
+	}
+
+	for offset := int64(0); ; offset += listLimit {
+		limit := int64(listLimit)
+		users, err := m.store.Users().List(ctx, m.namespace, metav1.ListOptions{Offset: &offset, Limit: &limit})
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users.Items {
+			if !mirrored(user) || present[user.Name] || user.Status == 0 {
+				continue
+			}
+
+			m.disable(ctx, user)
+		}
+
+		// the stores ignoring the paging return all the users at once
+		if len(users.Items) < listLimit || offset+int64(len(users.Items)) >= users.TotalCount {
+			return nil
+		}
+	}
+}
+
+// Start starts syncing the directory users periodically.
+func (m *Mirror) Start() {
+	if m.interval <= 0 {
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := m.Sync(context.Background()); err != nil {
+				log.Errorf("sync directory users failed: %s", err.Error())
+			}
+
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops syncing the directory users.
+func (m *Mirror) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// apply creates or updates the local user of the directory entry. The disabled users are looked up as
+// well, they are never created again.
+func (m *Mirror) apply(ctx context.Context, entry *directory.Entry) (*v1.User, error) {
+	user, err := m.store.Users().Get(ctx, m.namespace, entry.Username, metav1.GetOptions{IncludeDisabled: true})
+	if errors.IsCode(err, code.ErrUserNotFound) {
+		return m.create(ctx, entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the local users are never taken over by the directory users of the same name
+	if !mirrored(user) {
+		return nil, errors.WithCode(code.ErrPermissionDenied, "user `%s/%s` is not a directory user",
+			m.namespace, user.Name)
+	}
+
+	updated := *user
+	m.fill(&updated, entry)
+	if updated.SourceRemoved {
+		updated.SourceRemoved = false
+		updated.Status = 1
+	}
+
+	if updated.Email == user.Email && updated.Nickname == user.Nickname && updated.IsAdmin == user.IsAdmin &&
+		updated.Status == user.Status && updated.SourceID == user.SourceID &&
+		updated.SourceRemoved == user.SourceRemoved {
+		return user, nil
+	}
+
+	if errs := updated.ValidateUpdate(); len(errs) != 0 {
+		return nil, errors.WrapC(errs.ToAggregate(), code.ErrValidation,
+			"directory entry `%s` can not update user `%s/%s`", entry.DN, m.namespace, user.Name)
+	}
+	if err := m.store.Users().Update(ctx, &updated, metav1.UpdateOptions{}); err != nil {
+		return nil, err
+	}
+	log.Infof("user `%s/%s` is updated from directory entry `%s`.", m.namespace, user.Name, entry.DN)
+
+	return &updated, nil
+}
+
+// create creates the local user of the directory entry. The password of the user is random, the user
+// logs in by the directory.
+func (m *Mirror) create(ctx context.Context, entry *directory.Entry) (*v1.User, error) {
+	password, err := authutil.Encrypt(idutil.NewSecretKey())
+	if err != nil {
+		return nil, errors.WithCode(code.ErrEncrypt, err.Error())
+	}
+
+	user := &v1.User{
+		ObjectMeta:        metav1.ObjectMeta{Name: entry.Username, Namespace: m.namespace},
+		Password:          password,
+		PasswordChangedAt: time.Now(),
+		Status:            1,
+	}
+	m.fill(user, entry)
+	if errs := user.ValidateUpdate(); len(errs) != 0 {
+		return nil, errors.WrapC(errs.ToAggregate(), code.ErrValidation,
+			"directory entry `%s` can not create user `%s/%s`", entry.DN, m.namespace, entry.Username)
+	}
+
+	if err := m.store.Users().Create(ctx, user, metav1.CreateOptions{}); err != nil {
+		// the user is created by a concurrent login or sync
+		if errors.IsCode(err, code.ErrUserAlreadyExist) {
+			return m.store.Users().Get(ctx, m.namespace, entry.Username, metav1.GetOptions{IncludeDisabled: true})
+		}
+
+		return nil, err
+	}
+	log.Infof("user `%s/%s` is created from directory entry `%s`.", m.namespace, user.Name, entry.DN)
+
+	return user, nil
+}
+
+// disable disables the user removed from the directory, and revokes its sessions.
+func (m *Mirror) disable(ctx context.Context, user *v1.User) {
+	user.Status = 0
+	user.SourceRemoved = true
+	if err := m.store.Users().Update(ctx, user, metav1.UpdateOptions{}); err != nil {
+		log.Warnf("disable user `%s/%s` failed: %s", m.namespace, user.Name, err.Error())
+
+		return
+	}
+
+	if err := session.GetManager().RevokeAll(ctx, m.namespace, user.Name); err != nil {
+		log.Warnf("revoke the sessions of user `%s/%s` failed: %s", m.namespace, user.Name, err.Error())
+	}
+	log.Infof("user `%s/%s` is disabled because it was removed from the directory.", m.namespace, user.Name)
+}
+
+// fill copies the attributes of the directory entry into the user.
+func (m *Mirror) fill(user *v1.User, entry *directory.Entry) {
+	nickname := []rune(entry.Nickname)
+	if len(nickname) == 0 {
+		nickname = []rune(entry.Username)
+	}
+	if len(nickname) > maxNicknameLength {
+		nickname = nickname[:maxNicknameLength]
+	}
+
+	user.Nickname = string(nickname)
+	user.Email = entry.Email
+	user.IsAdmin = 0
+	if entry.IsAdmin {
+		user.IsAdmin = 1
+	}
+	user.Source = Source
+	user.SourceID = entry.DN
+}
+
+func mirrored(user *v1.User) bool {
+	return user.Source == Source
+}
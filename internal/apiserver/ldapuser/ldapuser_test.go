@@ -0,0 +1,250 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldapuser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/directory"
+	"github.com/dairongpeng/leona/pkg/directory/directorytest"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+const (
+	namespace = "ldap-test"
+	adminsDN  = "cn=admins,ou=groups,dc=example,dc=com"
+)
+
+func person(uid string, groups ...string) directorytest.Entry {
+	return directorytest.Entry{
+		DN:       "uid=" + uid + ",ou=people,dc=example,dc=com",
+		Password: uid + "-password",
+		Attributes: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {uid},
+			"mail":        {uid + "@example.com"},
+			"cn":          {uid},
+			"memberOf":    groups,
+		},
+	}
+}
+
+func newTestMirror(t *testing.T) (*Mirror, *directorytest.Server) {
+	t.Helper()
+
+	server := directorytest.NewServer()
+	t.Cleanup(server.Close)
+	server.Add(person("alice", adminsDN))
+	server.Add(person("bob"))
+
+	factory, err := fake.GetFakeFactoryOr()
+	require.NoError(t, err)
+
+	opts := NewLDAPOptions()
+	opts.URL = server.URL
+	opts.BaseDN = "ou=people,dc=example,dc=com"
+	opts.AdminGroups = []string{adminsDN}
+	// the changes in the directory are mirrored on the next bind
+	opts.CacheTTL = 0
+
+	return NewMirror(directory.New(opts.DirectoryConfig()), factory, namespace, time.Minute), server
+}
+
+func getUser(t *testing.T, m *Mirror, username string) *v1.User {
+	t.Helper()
+
+	user, err := m.store.Users().Get(context.Background(), namespace, username, metav1.GetOptions{IncludeDisabled: true})
+	require.NoError(t, err)
+
+	return user
+}
+
+func TestMirror_Login(t *testing.T) {
+	m, server := newTestMirror(t)
+	ctx := context.Background()
+
+	entry, err := m.Directory().Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+
+	user, err := m.Login(ctx, entry)
+	require.NoError(t, err)
+	assert.Equal(t, namespace, user.Namespace)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, 1, user.IsAdmin)
+	assert.Equal(t, 1, user.Status)
+	assert.False(t, user.LoginedAt.IsZero())
+	assert.Error(t, user.Compare(""))
+
+	// the changes in the directory are mirrored on the next login
+	alice := person("alice")
+	alice.Attributes["mail"] = []string{"alice@example.org"}
+	server.Add(alice)
+
+	entry, err = m.Directory().Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	_, err = m.Login(ctx, entry)
+	require.NoError(t, err)
+
+	user = getUser(t, m, "alice")
+	assert.Equal(t, "alice@example.org", user.Email)
+	assert.Equal(t, 0, user.IsAdmin)
+}
+
+func TestMirror_LoginLocalUser(t *testing.T) {
+	m, server := newTestMirror(t)
+	ctx := context.Background()
+
+	// the extend field is set by the requests, it does not mark the directory users
+	local := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "carol", Namespace: namespace, Extend: metav1.Extend{"source": Source}},
+		Nickname:   "carol",
+		Email:      "carol@local.com",
+		Password:   "Carol@2020",
+		Status:     1,
+	}
+	require.NoError(t, m.store.Users().Create(ctx, local, metav1.CreateOptions{}))
+	server.Add(person("carol", adminsDN))
+
+	entry, err := m.Directory().Authenticate("carol", "carol-password")
+	require.NoError(t, err)
+
+	_, err = m.Login(ctx, entry)
+	assert.True(t, errors.IsCode(err, code.ErrPermissionDenied))
+
+	require.NoError(t, m.Sync(ctx))
+	user := getUser(t, m, "carol")
+	assert.Equal(t, "carol@local.com", user.Email)
+	assert.Equal(t, 0, user.IsAdmin)
+}
+
+func TestMirror_Sync(t *testing.T) {
+	m, server := newTestMirror(t)
+	ctx := context.Background()
+
+	require.NoError(t, m.Sync(ctx))
+	assert.Equal(t, 1, getUser(t, m, "alice").IsAdmin)
+	assert.Equal(t, 1, getUser(t, m, "bob").Status)
+
+	// the removed users are disabled, and can not log in
+	server.Delete("uid=bob,ou=people,dc=example,dc=com")
+	require.NoError(t, m.Sync(ctx))
+	assert.Equal(t, 0, getUser(t, m, "bob").Status)
+	assert.Equal(t, 1, getUser(t, m, "alice").Status)
+
+	// the users are enabled again once they are back
+	server.Add(person("bob"))
+	require.NoError(t, m.Sync(ctx))
+	bob := getUser(t, m, "bob")
+	assert.Equal(t, 1, bob.Status)
+	assert.False(t, bob.SourceRemoved)
+}
+
+func TestMirror_LoginDisabledUser(t *testing.T) {
+	m, _ := newTestMirror(t)
+	ctx := context.Background()
+
+	entry, err := m.Directory().Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	_, err = m.Login(ctx, entry)
+	require.NoError(t, err)
+
+	// the users disabled by the administrator stay disabled
+	user := getUser(t, m, "alice")
+	user.Status = 0
+	require.NoError(t, m.store.Users().Update(ctx, user, metav1.UpdateOptions{}))
+
+	_, err = m.Login(ctx, entry)
+	assert.True(t, errors.IsCode(err, code.ErrPermissionDenied))
+
+	require.NoError(t, m.Sync(ctx))
+	assert.Equal(t, 0, getUser(t, m, "alice").Status)
+}
+
+// statusStore hides the disabled users unless they are asked for, as the mysql store does.
+type statusStore struct {
+	store.Factory
+}
+
+func (s statusStore) Users() store.UserStore {
+	return statusUsers{s.Factory.Users()}
+}
+
+type statusUsers struct {
+	store.UserStore
+}
+
+func (s statusUsers) Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error) {
+	user, err := s.UserStore.Get(ctx, namespace, username, opts)
+	if err == nil && user.Status == 0 && !opts.IncludeDisabled {
+		return nil, errors.WithCode(code.ErrUserNotFound, "record not found")
+	}
+
+	return user, err
+}
+
+func TestMirror_DisabledUserStatusStore(t *testing.T) {
+	m, server := newTestMirror(t)
+	m.store = statusStore{m.store}
+	ctx := context.Background()
+	server.Add(person("dave"))
+
+	entry, err := m.Directory().Authenticate("dave", "dave-password")
+	require.NoError(t, err)
+	_, err = m.Login(ctx, entry)
+	require.NoError(t, err)
+
+	// the disabled users are not created again
+	user := getUser(t, m, "dave")
+	user.Status = 0
+	require.NoError(t, m.store.Users().Update(ctx, user, metav1.UpdateOptions{}))
+
+	_, err = m.Login(ctx, entry)
+	assert.True(t, errors.IsCode(err, code.ErrPermissionDenied))
+	require.NoError(t, m.Sync(ctx))
+
+	// the users removed from the directory are enabled again once they are back
+	user = getUser(t, m, "dave")
+	user.Status = 1
+	require.NoError(t, m.store.Users().Update(ctx, user, metav1.UpdateOptions{}))
+	server.Delete("uid=dave,ou=people,dc=example,dc=com")
+	require.NoError(t, m.Sync(ctx))
+	assert.Equal(t, 0, getUser(t, m, "dave").Status)
+
+	server.Add(person("dave"))
+	require.NoError(t, m.Sync(ctx))
+	assert.Equal(t, 1, getUser(t, m, "dave").Status)
+}
+
+func TestMirror_StartStop(t *testing.T) {
+	m, _ := newTestMirror(t)
+
+	m.Start()
+	assert.Eventually(t, func() bool {
+		_, err := m.store.Users().Get(context.Background(), namespace, "alice", metav1.GetOptions{})
+
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	m.Stop()
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lockout counts the failed login attempts per user and per client ip, and locks them out
+// temporarily with an exponential backoff once there are too many of them.
+package lockout
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the lockout keys in redis.
+const KeyPrefix = "lockout-"
+
+const (
+	// EffectLockout is the analytics effect recorded when an user is locked out.
+	EffectLockout = "lockout"
+
+	// EffectIPLockout is the analytics effect recorded when a client ip is locked out.
+	EffectIPLockout = "lockout-ip"
+
+	// EffectUnlock is the analytics effect recorded when an user is unlocked by the administrator.
+	EffectUnlock = "unlock"
+)
+
+// Store defines the redis operations used to track the failed login attempts.
+// It is implemented by storage.RedisCluster.
+type Store interface {
+	SetRollingWindow(key string, per int64, val string, pipeline bool) (int, []interface{})
+	IncrememntWithExpire(key string, expire int64) int64
+	GetRawKey(key string) (string, error)
+	SetRawKey(key, value string, timeout time.Duration) error
+	DeleteRawKey(key string) bool
+}
+
+// Lockout locks the users and the client ips out after too many failed login attempts.
+// A nil Lockout never locks anyone out.
+type Lockout struct {
+	opts  *LockoutOptions
+	store Store
+	now   func() time.Time
+}
+
+var lockout *Lockout
+
+// NewLockout returns a new lockout instance.
+func NewLockout(opts *LockoutOptions, store Store) *Lockout {
+	lockout = &Lockout{
+		opts:  opts,
+		store: store,
+		now:   time.Now,
+	}
+
+	return lockout
+}
+
+// GetLockout returns the existed lockout instance.
+// It is nil when the lockout is not initialized.
+func GetLockout() *Lockout {
+	return lockout
+}
+
+// storeFor returns the store running the commands in ctx, so that they are traced as a part of it.
+func (l *Lockout) storeFor(ctx context.Context) Store {
+	if s, ok := l.store.(*storage.RedisCluster); ok {
+		return s.WithContext(ctx)
+	}
+
+	return l.store
+}
+
+// Check returns the remaining lockout duration of the user and the client ip, 0 means
+// neither of them is locked out.
+func (l *Lockout) Check(ctx context.Context, namespace, username, ip string) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	remaining := l.remaining(ctx, userKey("locked", namespace, username))
+	if ip != "" {
+		if d := l.remaining(ctx, ipKey("locked", ip)); d > remaining {
+			remaining = d
+		}
+	}
+
+	return remaining
+}
+
+// Fail records a failed login attempt, the user or the client ip is locked out when
+// its attempts within the window reach the limit.
+func (l *Lockout) Fail(ctx context.Context, namespace, username, ip string) {
+	if l == nil {
+		return
+	}
+
+	if l.opts.MaxAttempts > 0 && l.count(ctx, userKey("failures", namespace, username)) >= l.opts.MaxAttempts {
+		d := l.lock(ctx, userKey("failures", namespace, username), userKey("locked", namespace, username),
+			userKey("level", namespace, username))
+		log.Warnf("user `%s/%s` is locked out for %s", namespace, username, d)
+		l.record(namespace+"/"+username, EffectLockout)
+	}
+
+	if ip != "" && l.opts.IPMaxAttempts > 0 && l.count(ctx, ipKey("failures", ip)) >= l.opts.IPMaxAttempts {
+		d := l.lock(ctx, ipKey("failures", ip), ipKey("locked", ip), ipKey("level", ip))
+		log.Warnf("client ip `%s` is locked out for %s", ip, d)
+		l.record(namespace+"/"+username, EffectIPLockout)
+	}
+}
+
+// Succeed clears the failed login attempts and the backoff of the user. The attempts of the
+// client ip are kept, otherwise one valid account would be enough to guess the others.
+func (l *Lockout) Succeed(ctx context.Context, namespace, username string) {
+	if l == nil {
+		return
+	}
+
+	l.storeFor(ctx).DeleteRawKey(userKey("failures", namespace, username))
+	l.storeFor(ctx).DeleteRawKey(userKey("level", namespace, username))
+}
+
+// Unlock lifts the lockout of the user and clears its failed login attempts and backoff.
+func (l *Lockout) Unlock(ctx context.Context, namespace, username string) {
+	if l == nil {
+		return
+	}
+
+	l.storeFor(ctx).DeleteRawKey(userKey("locked", namespace, username))
+	l.Succeed(ctx, namespace, username)
+	l.record(namespace+"/"+username, EffectUnlock)
+}
+
+// count adds an attempt into the rolling window and returns the attempts within it.
+func (l *Lockout) count(ctx context.Context, key string) int {
+	// the returned count does not include the attempt just added
+	n, _ := l.storeFor(ctx).SetRollingWindow(key, int64(l.opts.Window/time.Second), "-1", false)
+
+	return n + 1
+}
+
+// lock locks the key out, the duration is doubled by every lockout since the backoff was reset.
+func (l *Lockout) lock(ctx context.Context, failuresKey, lockedKey, levelKey string) time.Duration {
+	level := l.storeFor(ctx).IncrememntWithExpire(levelKey, int64(l.opts.BackoffReset/time.Second))
+
+	d := l.opts.BaseDuration
+	for i := int64(1); i < level && d < l.opts.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > l.opts.MaxDuration {
+		d = l.opts.MaxDuration
+	}
+
+	until := l.now().Add(d)
+	if err := l.storeFor(ctx).SetRawKey(lockedKey, strconv.FormatInt(until.Unix(), 10), d); err != nil {
+		log.Errorf("set lockout key `%s` failed: %s", lockedKey, err.Error())
+	}
+	// start over counting after the lockout
+	l.storeFor(ctx).DeleteRawKey(failuresKey)
+
+	return d
+}
+
+func (l *Lockout) remaining(ctx context.Context, key string) time.Duration {
+	value, err := l.storeFor(ctx).GetRawKey(key)
+	if err != nil {
+		return 0
+	}
+
+	until, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	if d := time.Unix(until, 0).Sub(l.now()); d > 0 {
+		return d
+	}
+
+	return 0
+}
+
+func (l *Lockout) record(username, effect string) {
+	record := analytics.AnalyticsRecord{
+		TimeStamp: l.now().Unix(),
+		Username:  username,
+		Effect:    effect,
+	}
+	record.SetExpiry(0)
+	_ = analytics.GetAnalytics().RecordHit(&record)
+}
+
+func userKey(kind, namespace, username string) string {
+	return KeyPrefix + kind + "-user-" + namespace + "/" + username
+}
+
+func ipKey(kind, ip string) string {
+	return KeyPrefix + kind + "-ip-" + ip
+}
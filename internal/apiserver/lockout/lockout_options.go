@@ -0,0 +1,112 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockout
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// LockoutOptions contains configuration items related to the account lockout.
+type LockoutOptions struct {
+	Enable        bool          `json:"enable"          mapstructure:"enable"`
+	MaxAttempts   int           `json:"max-attempts"    mapstructure:"max-attempts"`
+	IPMaxAttempts int           `json:"ip-max-attempts" mapstructure:"ip-max-attempts"`
+	Window        time.Duration `json:"window"          mapstructure:"window"`
+	BaseDuration  time.Duration `json:"base-duration"   mapstructure:"base-duration"`
+	MaxDuration   time.Duration `json:"max-duration"    mapstructure:"max-duration"`
+	BackoffReset  time.Duration `json:"backoff-reset"   mapstructure:"backoff-reset"`
+}
+
+// NewLockoutOptions creates a LockoutOptions object with default parameters.
+func NewLockoutOptions() *LockoutOptions {
+	return &LockoutOptions{
+		Enable:        true,
+		MaxAttempts:   5,
+		IPMaxAttempts: 20,
+		Window:        15 * time.Minute,
+		BaseDuration:  time.Minute,
+		MaxDuration:   time.Hour,
+		BackoffReset:  24 * time.Hour,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *LockoutOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errors []error
+
+	if o.MaxAttempts < 0 {
+		errors = append(errors, fmt.Errorf("--lockout.max-attempts %v must not be negative", o.MaxAttempts))
+	}
+
+	if o.IPMaxAttempts < 0 {
+		errors = append(errors, fmt.Errorf("--lockout.ip-max-attempts %v must not be negative", o.IPMaxAttempts))
+	}
+
+	if o.Window < time.Second {
+		errors = append(errors, fmt.Errorf("--lockout.window %v must be at least 1s", o.Window))
+	}
+
+	if o.BaseDuration < time.Second {
+		errors = append(errors, fmt.Errorf("--lockout.base-duration %v must be at least 1s", o.BaseDuration))
+	}
+
+	if o.MaxDuration < o.BaseDuration {
+		errors = append(errors, fmt.Errorf("--lockout.max-duration %v must not be less than --lockout.base-duration %v",
+			o.MaxDuration, o.BaseDuration))
+	}
+
+	if o.BackoffReset < o.MaxDuration {
+		errors = append(errors, fmt.Errorf("--lockout.backoff-reset %v must not be less than --lockout.max-duration %v",
+			o.BackoffReset, o.MaxDuration))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the account lockout for a specific api server to the
+// specified FlagSet.
+func (o *LockoutOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "lockout.enable", o.Enable,
+		"Lock the accounts out temporarily after too many failed login attempts.")
+
+	fs.IntVar(&o.MaxAttempts, "lockout.max-attempts", o.MaxAttempts, ""+
+		"The number of failed login attempts of an user within the window which locks the user out, 0 disables it.")
+
+	fs.IntVar(&o.IPMaxAttempts, "lockout.ip-max-attempts", o.IPMaxAttempts, ""+
+		"The number of failed login attempts from a client ip within the window which locks the ip out, 0 disables it.")
+
+	fs.DurationVar(&o.Window, "lockout.window", o.Window,
+		"The rolling window in which the failed login attempts are counted.")
+
+	fs.DurationVar(&o.BaseDuration, "lockout.base-duration", o.BaseDuration, ""+
+		"The duration of the first lockout, it is doubled by every following lockout.")
+
+	fs.DurationVar(&o.MaxDuration, "lockout.max-duration", o.MaxDuration,
+		"The maximum duration of a lockout.")
+
+	fs.DurationVar(&o.BackoffReset, "lockout.backoff-reset", o.BackoffReset, ""+
+		"The lockout duration falls back to the base duration once this period has passed since the first lockout.")
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockout
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// fakeStore keeps the keys in memory, the expirations are ignored.
+type fakeStore struct {
+	windows map[string]int
+	values  map[string]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{windows: map[string]int{}, values: map[string]string{}}
+}
+
+func (s *fakeStore) SetRollingWindow(key string, per int64, val string, pipeline bool) (int, []interface{}) {
+	n := s.windows[key]
+	s.windows[key]++
+
+	return n, nil
+}
+
+func (s *fakeStore) IncrememntWithExpire(key string, expire int64) int64 {
+	n, _ := strconv.ParseInt(s.values[key], 10, 64)
+	n++
+	s.values[key] = strconv.FormatInt(n, 10)
+
+	return n
+}
+
+func (s *fakeStore) GetRawKey(key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *fakeStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *fakeStore) DeleteRawKey(key string) bool {
+	_, ok := s.windows[key]
+	delete(s.windows, key)
+	if _, found := s.values[key]; found {
+		ok = true
+	}
+	delete(s.values, key)
+
+	return ok
+}
+
+func newTestLockout(opts *LockoutOptions) (*Lockout, *time.Time) {
+	now := time.Unix(1600000000, 0)
+	l := NewLockout(opts, newFakeStore())
+	l.now = func() time.Time { return now }
+
+	return l, &now
+}
+
+func TestLockout_Backoff(t *testing.T) {
+	ctx := context.Background()
+	opts := NewLockoutOptions()
+	opts.MaxAttempts = 3
+	opts.IPMaxAttempts = 0
+	l, now := newTestLockout(opts)
+
+	for i := 0; i < 2; i++ {
+		l.Fail(ctx, "default", "admin", "10.0.0.1")
+		assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.1"))
+	}
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	assert.Equal(t, time.Minute, l.Check(ctx, "default", "admin", "10.0.0.1"))
+	assert.Zero(t, l.Check(ctx, "default", "root", "10.0.0.1"))
+
+	// the lockout expires, the attempts are counted from the start
+	*now = now.Add(time.Minute)
+	assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.1"))
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.1"))
+
+	// the second lockout lasts twice as long
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	assert.Equal(t, 2*time.Minute, l.Check(ctx, "default", "admin", "10.0.0.1"))
+
+	// the lockout never lasts longer than max duration
+	for i := 0; i < 30; i++ {
+		*now = now.Add(time.Hour)
+		for j := 0; j < 3; j++ {
+			l.Fail(ctx, "default", "admin", "10.0.0.1")
+		}
+	}
+	assert.Equal(t, time.Hour, l.Check(ctx, "default", "admin", "10.0.0.1"))
+
+	l.Unlock(ctx, "default", "admin")
+	assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.1"))
+	for i := 0; i < 3; i++ {
+		l.Fail(ctx, "default", "admin", "10.0.0.1")
+	}
+	assert.Equal(t, time.Minute, l.Check(ctx, "default", "admin", "10.0.0.1"))
+}
+
+func TestLockout_Succeed(t *testing.T) {
+	ctx := context.Background()
+	opts := NewLockoutOptions()
+	opts.MaxAttempts = 3
+	opts.IPMaxAttempts = 0
+	l, _ := newTestLockout(opts)
+
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	l.Succeed(ctx, "default", "admin")
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.1"))
+}
+
+func TestLockout_IP(t *testing.T) {
+	ctx := context.Background()
+	opts := NewLockoutOptions()
+	opts.MaxAttempts = 0
+	opts.IPMaxAttempts = 3
+	l, _ := newTestLockout(opts)
+
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	l.Fail(ctx, "default", "root", "10.0.0.1")
+	l.Succeed(ctx, "default", "guest")
+	l.Fail(ctx, "default", "guest", "10.0.0.1")
+	assert.Equal(t, time.Minute, l.Check(ctx, "default", "guest", "10.0.0.1"))
+	assert.Equal(t, time.Minute, l.Check(ctx, "default", "nobody", "10.0.0.1"))
+	assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.2"))
+}
+
+func TestLockout_Nil(t *testing.T) {
+	ctx := context.Background()
+	var l *Lockout
+
+	l.Fail(ctx, "default", "admin", "10.0.0.1")
+	l.Succeed(ctx, "default", "admin")
+	l.Unlock(ctx, "default", "admin")
+	assert.Zero(t, l.Check(ctx, "default", "admin", "10.0.0.1"))
+}
+
+func TestLockoutOptions_Validate(t *testing.T) {
+	opts := NewLockoutOptions()
+	assert.Empty(t, opts.Validate())
+
+	opts.MaxAttempts = -1
+	opts.MaxDuration = 30 * time.Second
+	assert.Len(t, opts.Validate(), 2)
+}
@@ -0,0 +1,449 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mfa implements the TOTP two-factor authentication. The users enroll a TOTP authenticator
+// with a confirmation code, and log in with the TOTP code or a recovery code after the password.
+package mfa
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/storage"
+	"github.com/dairongpeng/leona/pkg/totp"
+)
+
+// KeyPrefix defines the prefix of the mfa keys in redis.
+const KeyPrefix = "mfa-"
+
+const (
+	// EffectRecovery is the analytics effect recorded when an user logs in with a recovery code.
+	EffectRecovery = "mfa-recovery"
+
+	// EffectReset is the analytics effect recorded when the authenticator of an user is reset.
+	EffectReset = "mfa-reset"
+)
+
+const (
+	// recoveryCodeSize is the number of the random bytes of a recovery code, it is encoded into
+	// 16 base32 characters.
+	recoveryCodeSize = 10
+
+	// recoveryCodeSaltSize is the number of the random bytes of the salt hashing a recovery code.
+	recoveryCodeSaltSize = 16
+)
+
+// Enrollment is the TOTP authenticator to be enrolled, it is enrolled once a code it generates
+// is confirmed.
+type Enrollment struct {
+	// Secret is the base32 encoded secret which can be typed into the authenticator apps.
+	Secret string `json:"secret"`
+
+	// URI is the `otpauth://` key URI of the secret, which is the payload of the QR code.
+	URI string `json:"uri"`
+
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RecoveryCodes are responded once when the authenticator is enrolled, only their hashes are stored.
+type RecoveryCodes struct {
+	Codes []string `json:"recoveryCodes"`
+}
+
+// challenge is the login waiting for the second factor.
+type challenge struct {
+	Namespace string `json:"namespace"`
+	Username  string `json:"username"`
+}
+
+// Store defines the redis operations used to keep the enrollments and the challenges.
+// It is implemented by storage.RedisCluster.
+type Store interface {
+	GetRawKey(key string) (string, error)
+	SetRawKey(key, value string, timeout time.Duration) error
+	DeleteRawKey(key string) bool
+}
+
+// Manager enrolls the TOTP authenticators and verifies the second factors of the logins.
+// A nil Manager can not enroll an authenticator, and rejects the logins requiring the second factor.
+type Manager struct {
+	opts  *MFAOptions
+	store Store
+	aead  cipher.AEAD
+	now   func() time.Time
+}
+
+var manager *Manager
+
+// NewManager returns a new mfa manager, the TOTP secrets are encrypted by the key of the options.
+func NewManager(opts *MFAOptions, store Store) (*Manager, error) {
+	if opts.Key == "" {
+		return nil, errors.New("the mfa key encrypting the TOTP secrets is not set")
+	}
+
+	key := sha256.Sum256([]byte(opts.Key))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	manager = &Manager{
+		opts:  opts,
+		store: store,
+		aead:  aead,
+		now:   time.Now,
+	}
+
+	return manager, nil
+}
+
+// GetManager returns the existed mfa manager.
+// It is nil when the manager is not initialized.
+func GetManager() *Manager {
+	return manager
+}
+
+// storeFor returns the store running the commands in ctx, so that they are traced as a part of it.
+func (m *Manager) storeFor(ctx context.Context) Store {
+	if s, ok := m.store.(*storage.RedisCluster); ok {
+		return s.WithContext(ctx)
+	}
+
+	return m.store
+}
+
+// Enroll starts to enroll a new TOTP authenticator of the user, the user must not have one enrolled.
+func (m *Manager) Enroll(ctx context.Context, user *v1.User) (*Enrollment, error) {
+	if m == nil {
+		return nil, errors.WithCode(code.ErrUnknown, "the two-factor authentication is not initialized")
+	}
+	if user.MFAEnabled() {
+		return nil, errors.WithCode(code.ErrMFAAlreadyEnabled, "user `%s` has enrolled an authenticator", user.Name)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.WithCode(code.ErrUnknown, err.Error())
+	}
+	encrypted, err := m.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.storeFor(ctx).SetRawKey(enrollKey(user.Namespace, user.Name), encrypted, m.opts.EnrollTTL); err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return &Enrollment{
+		Secret:    secret,
+		URI:       totp.URI(m.opts.Issuer, accountName(user), secret),
+		ExpiresAt: m.now().Add(m.opts.EnrollTTL),
+	}, nil
+}
+
+// Confirm enrolls the authenticator once the code it generates is confirmed, the recovery codes
+// are generated and returned in plain text. The caller saves the user.
+func (m *Manager) Confirm(ctx context.Context, user *v1.User, passcode string) (*RecoveryCodes, error) {
+	if m == nil {
+		return nil, errors.WithCode(code.ErrUnknown, "the two-factor authentication is not initialized")
+	}
+	if user.MFAEnabled() {
+		return nil, errors.WithCode(code.ErrMFAAlreadyEnabled, "user `%s` has enrolled an authenticator", user.Name)
+	}
+
+	key := enrollKey(user.Namespace, user.Name)
+	encrypted, err := m.storeFor(ctx).GetRawKey(key)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrMFANotEnrolling, "user `%s` is not enrolling an authenticator", user.Name)
+	}
+	secret, err := m.decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	counter, ok := totp.Validate(secret, passcode, m.now(), m.opts.Skew)
+	if !ok {
+		return nil, errors.WithCode(code.ErrMFACodeInvalid, "the code of the authenticator is incorrect")
+	}
+
+	codes := make([]string, 0, m.opts.RecoveryCodes)
+	hashes := make([]string, 0, m.opts.RecoveryCodes)
+	for i := 0; i < m.opts.RecoveryCodes; i++ {
+		recoveryCode, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.WithCode(code.ErrUnknown, err.Error())
+		}
+		hash, err := hashRecoveryCode(recoveryCode)
+		if err != nil {
+			return nil, errors.WithCode(code.ErrUnknown, err.Error())
+		}
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, hash)
+	}
+
+	m.storeFor(ctx).DeleteRawKey(key)
+	m.markUsed(ctx, user, counter)
+
+	user.TOTPSecret = encrypted
+	user.RecoveryCodes = hashes
+	user.MFAEnabledAt = m.now()
+
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+// Reset removes the enrolled authenticator and the recovery codes of the user, the user logs in
+// with the password only until another authenticator is enrolled. The caller saves the user.
+func (m *Manager) Reset(ctx context.Context, user *v1.User) {
+	user.TOTPSecret = ""
+	user.RecoveryCodes = nil
+	user.MFAEnabledAt = time.Time{}
+
+	if m != nil {
+		m.storeFor(ctx).DeleteRawKey(enrollKey(user.Namespace, user.Name))
+		m.record(user.Name, EffectReset)
+	}
+}
+
+// Challenge returns the MFA token with which the user logs in with the second factor, the password
+// of the user has been verified.
+func (m *Manager) Challenge(ctx context.Context, user *v1.User) (string, time.Time, error) {
+	if m == nil {
+		return "", time.Time{}, errors.WithCode(code.ErrUnknown, "the two-factor authentication is not initialized")
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return "", time.Time{}, errors.WithCode(code.ErrUnknown, err.Error())
+	}
+	value, _ := json.Marshal(challenge{Namespace: user.Namespace, Username: user.Name})
+	if err := m.storeFor(ctx).SetRawKey(challengeKey(token), string(value), m.opts.ChallengeTTL); err != nil {
+		return "", time.Time{}, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return token, m.now().Add(m.opts.ChallengeTTL), nil
+}
+
+// Lookup returns the user the MFA token is responded to.
+func (m *Manager) Lookup(ctx context.Context, token string) (namespace string, username string, err error) {
+	invalid := errors.WithCode(code.ErrMFATokenInvalid, "the MFA token is invalid or expired")
+	if m == nil || token == "" {
+		return "", "", invalid
+	}
+
+	value, err := m.storeFor(ctx).GetRawKey(challengeKey(token))
+	if err != nil {
+		return "", "", invalid
+	}
+	var c challenge
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return "", "", invalid
+	}
+
+	return c.Namespace, c.Username, nil
+}
+
+// Complete consumes the MFA token once the second factor is verified, a token can only be used once.
+func (m *Manager) Complete(ctx context.Context, token string) error {
+	if m == nil || !m.storeFor(ctx).DeleteRawKey(challengeKey(token)) {
+		return errors.WithCode(code.ErrMFATokenInvalid, "the MFA token has been used")
+	}
+
+	return nil
+}
+
+// Verify verifies the TOTP code or the recovery code of the user, a code can only be used once.
+// It returns true if a recovery code is used, the caller saves the user without the used one.
+func (m *Manager) Verify(ctx context.Context, user *v1.User, passcode string) (bool, error) {
+	invalid := errors.WithCode(code.ErrMFACodeInvalid, "the second factor of user `%s` is incorrect", user.Name)
+	if m == nil || !user.MFAEnabled() {
+		return false, invalid
+	}
+
+	passcode = strings.TrimSpace(passcode)
+	if len(passcode) == totp.Digits {
+		secret, err := m.decrypt(user.TOTPSecret)
+		if err != nil {
+			return false, err
+		}
+
+		counter, ok := totp.Validate(secret, passcode, m.now(), m.opts.Skew)
+		if !ok || counter <= m.lastUsed(ctx, user) {
+			return false, invalid
+		}
+		m.markUsed(ctx, user, counter)
+
+		return false, nil
+	}
+
+	for i, hash := range user.RecoveryCodes {
+		if matchRecoveryCode(hash, passcode) {
+			user.RecoveryCodes = append(user.RecoveryCodes[:i:i], user.RecoveryCodes[i+1:]...)
+			m.record(user.Name, EffectRecovery)
+
+			return true, nil
+		}
+	}
+
+	return false, invalid
+}
+
+// lastUsed returns the time step of the TOTP code the user used lastly.
+func (m *Manager) lastUsed(ctx context.Context, user *v1.User) int64 {
+	value, err := m.storeFor(ctx).GetRawKey(usedKey(user.Namespace, user.Name))
+	if err != nil {
+		return 0
+	}
+	counter, _ := strconv.ParseInt(value, 10, 64)
+
+	return counter
+}
+
+// markUsed remembers the time step of the used TOTP code until it can not be accepted anymore.
+func (m *Manager) markUsed(ctx context.Context, user *v1.User, counter int64) {
+	ttl := time.Duration(2*m.opts.Skew+1) * totp.Period
+	_ = m.storeFor(ctx).SetRawKey(usedKey(user.Namespace, user.Name), strconv.FormatInt(counter, 10), ttl)
+}
+
+func (m *Manager) record(username, effect string) {
+	record := analytics.AnalyticsRecord{
+		TimeStamp: m.now().Unix(),
+		Username:  username,
+		Effect:    effect,
+	}
+	record.SetExpiry(0)
+	_ = analytics.GetAnalytics().RecordHit(&record)
+}
+
+func (m *Manager) encrypt(secret string) (string, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.WithCode(code.ErrEncrypt, err.Error())
+	}
+
+	return base64.RawStdEncoding.EncodeToString(m.aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func (m *Manager) decrypt(encrypted string) (string, error) {
+	data, err := base64.RawStdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < m.aead.NonceSize() {
+		return "", errors.WithCode(code.ErrEncrypt, "the TOTP secret is malformed")
+	}
+
+	nonce, ciphertext := data[:m.aead.NonceSize()], data[m.aead.NonceSize():]
+	secret, err := m.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.WithCode(code.ErrEncrypt, "the TOTP secret can not be decrypted by the mfa key")
+	}
+
+	return string(secret), nil
+}
+
+// accountName is the account shown by the authenticator apps.
+func accountName(user *v1.User) string {
+	if user.Namespace == "" || user.Namespace == metav1.NamespaceDefault {
+		return user.Name
+	}
+
+	return user.Namespace + "/" + user.Name
+}
+
+// generateRecoveryCode returns a random recovery code in the form of `xxxx-xxxx-xxxx-xxxx`.
+func generateRecoveryCode() (string, error) {
+	data := make([]byte, recoveryCodeSize)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data))
+
+	return s[:4] + "-" + s[4:8] + "-" + s[8:12] + "-" + s[12:16], nil
+}
+
+// hashRecoveryCode hashes the recovery code with a random salt, and returns them in the form of
+// `salt:hash`. The case and the separators are ignored.
+func hashRecoveryCode(recoveryCode string) (string, error) {
+	salt := make([]byte, recoveryCodeSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(salt) + ":" + hex.EncodeToString(saltedSum(salt, recoveryCode)), nil
+}
+
+// matchRecoveryCode returns true if the recovery code matches the salted hash.
+func matchRecoveryCode(hash, recoveryCode string) bool {
+	parts := strings.SplitN(hash, ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	sum, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(sum, saltedSum(salt, recoveryCode)) == 1
+}
+
+func saltedSum(salt []byte, recoveryCode string) []byte {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(recoveryCode))
+	sum := sha256.Sum256(append(append([]byte{}, salt...), normalized...))
+
+	return sum[:]
+}
+
+func randomToken() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func enrollKey(namespace, username string) string {
+	return KeyPrefix + "enroll-" + namespace + "/" + username
+}
+
+func challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return KeyPrefix + "challenge-" + hex.EncodeToString(sum[:])
+}
+
+func usedKey(namespace, username string) string {
+	return KeyPrefix + "used-" + namespace + "/" + username
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// MFAOptions contains configuration items related to the TOTP two-factor authentication.
+type MFAOptions struct {
+	Issuer        string        `json:"issuer"         mapstructure:"issuer"`
+	Key           string        `json:"-"              mapstructure:"key"`
+	EnrollTTL     time.Duration `json:"enroll-ttl"     mapstructure:"enroll-ttl"`
+	ChallengeTTL  time.Duration `json:"challenge-ttl"  mapstructure:"challenge-ttl"`
+	Skew          int           `json:"skew"           mapstructure:"skew"`
+	RecoveryCodes int           `json:"recovery-codes" mapstructure:"recovery-codes"`
+}
+
+// NewMFAOptions creates a MFAOptions object with default parameters.
+func NewMFAOptions() *MFAOptions {
+	return &MFAOptions{
+		Issuer:        "Leona",
+		EnrollTTL:     10 * time.Minute,
+		ChallengeTTL:  5 * time.Minute,
+		Skew:          1,
+		RecoveryCodes: 10,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *MFAOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errors []error
+
+	if o.Key != "" && len(o.Key) < 16 {
+		errors = append(errors, fmt.Errorf("--mfa.key must be at least 16 characters"))
+	}
+
+	if o.EnrollTTL < time.Minute {
+		errors = append(errors, fmt.Errorf("--mfa.enroll-ttl %v must be at least 1m", o.EnrollTTL))
+	}
+
+	if o.ChallengeTTL < 30*time.Second {
+		errors = append(errors, fmt.Errorf("--mfa.challenge-ttl %v must be at least 30s", o.ChallengeTTL))
+	}
+
+	if o.Skew < 0 || o.Skew > 10 {
+		errors = append(errors, fmt.Errorf("--mfa.skew %v must be between 0 and 10", o.Skew))
+	}
+
+	if o.RecoveryCodes < 1 || o.RecoveryCodes > 50 {
+		errors = append(errors, fmt.Errorf("--mfa.recovery-codes %v must be between 1 and 50", o.RecoveryCodes))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the TOTP two-factor authentication for a specific api server to
+// the specified FlagSet.
+func (o *MFAOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringVar(&o.Issuer, "mfa.issuer", o.Issuer,
+		"The issuer shown by the authenticator apps.")
+
+	fs.StringVar(&o.Key, "mfa.key", o.Key, ""+
+		"The key encrypting the TOTP secrets, at least 16 characters and different from the jwt key. "+
+		"The two-factor authentication is disabled if it is empty, "+
+		"and the enrolled authenticators can not be used anymore once it is changed.")
+
+	fs.DurationVar(&o.EnrollTTL, "mfa.enroll-ttl", o.EnrollTTL,
+		"How long the enrollment of an authenticator can be confirmed.")
+
+	fs.DurationVar(&o.ChallengeTTL, "mfa.challenge-ttl", o.ChallengeTTL, ""+
+		"How long the MFA token responded by the password login can be used to log in with the TOTP code.")
+
+	fs.IntVar(&o.Skew, "mfa.skew", o.Skew, ""+
+		"The number of 30 seconds time steps before and after the current one the TOTP codes are accepted in, "+
+		"it tolerates the clock drift of the authenticators.")
+
+	fs.IntVar(&o.RecoveryCodes, "mfa.recovery-codes", o.RecoveryCodes,
+		"The number of the recovery codes generated when an authenticator is enrolled.")
+}
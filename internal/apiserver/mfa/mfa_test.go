@@ -0,0 +1,211 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/storage"
+	"github.com/dairongpeng/leona/pkg/totp"
+)
+
+// fakeStore keeps the keys in memory, the expirations are ignored.
+type fakeStore struct {
+	values map[string]string
+}
+
+func (s *fakeStore) GetRawKey(key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *fakeStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *fakeStore) DeleteRawKey(key string) bool {
+	_, ok := s.values[key]
+	delete(s.values, key)
+
+	return ok
+}
+
+func newTestManager(t *testing.T) (*Manager, *time.Time) {
+	opts := NewMFAOptions()
+	opts.Key = "secret"
+	m, err := NewManager(opts, &fakeStore{values: map[string]string{}})
+	assert.NoError(t, err)
+
+	now := time.Unix(1600000000, 0)
+	m.now = func() time.Time { return now }
+
+	return m, &now
+}
+
+// enroll enrolls an authenticator of the user, and returns the secret and the recovery codes.
+func enroll(t *testing.T, m *Manager, user *v1.User) (string, []string) {
+	enrollment, err := m.Enroll(context.TODO(), user)
+	assert.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/Leona:tenant-a%2Fcolin?")
+
+	passcode, _ := totp.Code(enrollment.Secret, totp.Counter(m.now()))
+	recoveryCodes, err := m.Confirm(context.TODO(), user, passcode)
+	assert.NoError(t, err)
+	assert.Len(t, recoveryCodes.Codes, 10)
+	assert.True(t, user.MFAEnabled())
+
+	return enrollment.Secret, recoveryCodes.Codes
+}
+
+func newTestUser() *v1.User {
+	return &v1.User{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Name: "colin"}}
+}
+
+func TestManager_Enroll(t *testing.T) {
+	m, now := newTestManager(t)
+	user := newTestUser()
+
+	_, err := m.Confirm(context.TODO(), user, "123456")
+	assert.True(t, errors.IsCode(err, code.ErrMFANotEnrolling))
+
+	enrollment, err := m.Enroll(context.TODO(), user)
+	assert.NoError(t, err)
+
+	// the enrollment is kept until it is confirmed
+	_, err = m.Confirm(context.TODO(), user, "000000")
+	assert.True(t, errors.IsCode(err, code.ErrMFACodeInvalid))
+	assert.False(t, user.MFAEnabled())
+
+	passcode, _ := totp.Code(enrollment.Secret, totp.Counter(*now))
+	_, err = m.Confirm(context.TODO(), user, passcode)
+	assert.NoError(t, err)
+	assert.Equal(t, *now, user.MFAEnabledAt)
+
+	// the secret is stored encrypted
+	assert.NotContains(t, user.TOTPSecret, enrollment.Secret)
+
+	_, err = m.Enroll(context.TODO(), user)
+	assert.True(t, errors.IsCode(err, code.ErrMFAAlreadyEnabled))
+
+	m.Reset(context.TODO(), user)
+	assert.False(t, user.MFAEnabled())
+	assert.Empty(t, user.RecoveryCodes)
+}
+
+func TestManager_Verify(t *testing.T) {
+	m, now := newTestManager(t)
+	user := newTestUser()
+	secret, _ := enroll(t, m, user)
+
+	// the code confirming the enrollment can not be replayed
+	passcode, _ := totp.Code(secret, totp.Counter(*now))
+	_, err := m.Verify(context.TODO(), user, passcode)
+	assert.True(t, errors.IsCode(err, code.ErrMFACodeInvalid))
+
+	*now = now.Add(totp.Period)
+	passcode, _ = totp.Code(secret, totp.Counter(*now))
+	recovered, err := m.Verify(context.TODO(), user, passcode)
+	assert.NoError(t, err)
+	assert.False(t, recovered)
+
+	_, err = m.Verify(context.TODO(), user, passcode)
+	assert.True(t, errors.IsCode(err, code.ErrMFACodeInvalid))
+
+	// the secret can not be decrypted by another key
+	opts := NewMFAOptions()
+	opts.Key = "another"
+	other, _ := NewManager(opts, m.store)
+	other.now = m.now
+	*now = now.Add(totp.Period)
+	passcode, _ = totp.Code(secret, totp.Counter(*now))
+	_, err = other.Verify(context.TODO(), user, passcode)
+	assert.True(t, errors.IsCode(err, code.ErrEncrypt))
+}
+
+func TestManager_VerifyRecoveryCode(t *testing.T) {
+	m, _ := newTestManager(t)
+	user := newTestUser()
+	_, recoveryCodes := enroll(t, m, user)
+
+	recovered, err := m.Verify(context.TODO(), user, " "+recoveryCodes[3]+" ")
+	assert.NoError(t, err)
+	assert.True(t, recovered)
+	assert.Len(t, user.RecoveryCodes, 9)
+
+	_, err = m.Verify(context.TODO(), user, recoveryCodes[3])
+	assert.True(t, errors.IsCode(err, code.ErrMFACodeInvalid))
+
+	// the case and the separator are ignored
+	_, err = m.Verify(context.TODO(), user, strings.ToUpper(strings.ReplaceAll(recoveryCodes[4], "-", "")))
+	assert.NoError(t, err)
+}
+
+func TestRecoveryCode(t *testing.T) {
+	recoveryCode, err := generateRecoveryCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, recoveryCode)
+
+	// the same code is hashed with different salts
+	hash, err := hashRecoveryCode(recoveryCode)
+	assert.NoError(t, err)
+	another, err := hashRecoveryCode(recoveryCode)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, another)
+
+	assert.True(t, matchRecoveryCode(hash, recoveryCode))
+	assert.True(t, matchRecoveryCode(another, recoveryCode))
+	assert.False(t, matchRecoveryCode(hash, "aaaa-aaaa-aaaa-aaaa"))
+	assert.False(t, matchRecoveryCode("malformed", recoveryCode))
+}
+
+func TestManager_Challenge(t *testing.T) {
+	m, _ := newTestManager(t)
+	user := newTestUser()
+
+	token, expiresAt, err := m.Challenge(context.TODO(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, m.now().Add(5*time.Minute), expiresAt)
+
+	namespace, username, err := m.Lookup(context.TODO(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant-a", namespace)
+	assert.Equal(t, "colin", username)
+
+	assert.NoError(t, m.Complete(context.TODO(), token))
+	assert.True(t, errors.IsCode(m.Complete(context.TODO(), token), code.ErrMFATokenInvalid))
+
+	_, _, err = m.Lookup(context.TODO(), token)
+	assert.True(t, errors.IsCode(err, code.ErrMFATokenInvalid))
+
+	var nilManager *Manager
+	_, _, err = nilManager.Lookup(context.TODO(), token)
+	assert.True(t, errors.IsCode(err, code.ErrMFATokenInvalid))
+	_, err = nilManager.Verify(context.TODO(), user, "123456")
+	assert.True(t, errors.IsCode(err, code.ErrMFACodeInvalid))
+}
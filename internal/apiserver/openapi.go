@@ -0,0 +1,512 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	v2 "github.com/dairongpeng/leona/api/apiserver/v2"
+	"github.com/dairongpeng/leona/internal/apiserver/controller/v1/user"
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	"github.com/dairongpeng/leona/internal/apiserver/session"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/jwks"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/openapi"
+	"github.com/dairongpeng/leona/pkg/runtime"
+	"github.com/dairongpeng/leona/pkg/version"
+)
+
+const (
+	openapiPath   = "/openapi/v3"
+	openapiUIPath = "/openapi/ui"
+	// openapiUIAssetsPath serves the Swagger UI assets embedded in the binary.
+	openapiUIAssetsPath = openapiUIPath + "/assets"
+
+	securityBearer = "bearer"
+	securityBasic  = "basic"
+)
+
+// the error codes shared by the routes.
+var (
+	authErrors = []int{
+		code.ErrInvalidAuthHeader, code.ErrSignatureInvalid, code.ErrExpired, code.ErrTokenRevoked,
+		code.ErrPermissionDenied, code.ErrAccountLocked,
+	}
+	bodyErrors     = []int{code.ErrBind, code.ErrValidation, code.ErrUnsupportedMediaType}
+	responseErrors = []int{code.ErrNotAcceptable, code.ErrTooManyRequests, code.ErrDatabase}
+
+	// preconditionErrors are the errors of the update and delete routes checking If-Match
+	// and If-Unmodified-Since.
+	preconditionErrors = []int{code.ErrPreconditionFailed}
+)
+
+// loginToken is the response of the login and refresh routes.
+type loginToken struct {
+	// Token is the signed JWT token.
+	Token string `json:"token"`
+
+	// Expire is the expiration time of the token in RFC3339 form.
+	Expire string `json:"expire"`
+
+	// PasswordExpired is true if the password of the user expired, the token is only authenticated
+	// to change the password.
+	PasswordExpired bool `json:"passwordExpired,omitempty"`
+}
+
+// userListOptions is the query of the v1 user list routes, it is only used in the document.
+type userListOptions struct {
+	metav1.ListOptions   `json:"-"`
+	metav1.ExportOptions `json:"-"`
+}
+
+// installOpenAPI serves the OpenAPI document of the routes registered on g and the UI browsing it.
+// The document is built on the first request, when all the routes are registered.
+func installOpenAPI(g *gin.Engine) {
+	builder := newOpenAPIBuilder()
+
+	var once sync.Once
+	var doc *openapi.Document
+	g.GET(openapiPath, func(c *gin.Context) {
+		once.Do(func() {
+			doc = builder.Build(g.Routes())
+		})
+
+		core.WriteResponse(c, nil, doc)
+	})
+	g.GET(openapiUIPath, openapi.UIHandler(builder.Info.Title, openapiPath, openapiUIAssetsPath))
+	g.GET(openapiUIAssetsPath+"/*filepath", openapi.UIAssetsHandler())
+}
+
+func newOpenAPIBuilder() *openapi.Builder {
+	builder := openapi.NewBuilder(openapi.Info{
+		Title:       "Leona API Server",
+		Description: "The RESTful API of the leona apiserver.",
+		Version:     version.Get().GitVersion,
+	}, apiRoutes()...)
+	builder.Tags = []openapi.Tag{
+		{Name: "auth", Description: "Issue and refresh the JWT tokens."},
+		{Name: "users", Description: "Manage the users."},
+		{Name: "namespaces", Description: "Manage the namespaces isolating the users."},
+		{Name: "rbac", Description: "Manage the roles and grant them to the users."},
+	}
+	builder.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		securityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		securityBasic:  {Type: "http", Scheme: "basic"},
+	}
+	builder.MediaTypes = runtime.DefaultRegistry.MediaTypes()
+	builder.ErrorResponse = core.ErrResponse{}
+	builder.Exclude = []string{"/debug/", "/metrics", "/openapi/"}
+
+	return builder
+}
+
+// apiRoutes documents the routes installed by installController.
+func apiRoutes() []openapi.Route {
+	authenticated := []string{securityBearer, securityBasic}
+
+	routes := []openapi.Route{
+		{
+			Method:   http.MethodPost,
+			Path:     "/login",
+			Summary:  "Log in with the basic authorization header or the credentials in the body.",
+			Tags:     []string{"auth"},
+			Request:  loginInfo{},
+			Response: loginToken{},
+			Errors: []int{
+				code.ErrAccountLocked, code.ErrMFARequired, code.ErrTooManyRequests,
+			},
+			Security: []string{securityBasic},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/login/mfa",
+			Summary:  "Log in with the MFA token responded by the password login and the TOTP code or a recovery code.",
+			Tags:     []string{"auth"},
+			Request:  mfaLoginInfo{},
+			Response: loginToken{},
+			Errors: []int{
+				code.ErrMFATokenInvalid, code.ErrMFACodeInvalid, code.ErrAccountLocked, code.ErrTooManyRequests,
+			},
+		},
+		{Method: http.MethodPost, Path: "/logout", Summary: "Log out.", Tags: []string{"auth"}},
+		{
+			Method:   http.MethodPost,
+			Path:     "/refresh",
+			Summary:  "Refresh the JWT token.",
+			Tags:     []string{"auth"},
+			Response: loginToken{},
+			Errors:   []int{code.ErrTokenRevoked},
+			Security: []string{securityBearer},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/.well-known/jwks.json",
+			Summary:  "List the public keys verifying the JWT tokens signed by the asymmetric algorithms.",
+			Tags:     []string{"auth"},
+			Response: jwks.KeySet{},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v1/password-reset",
+			Summary: "Mail a password reset token to a user, it succeeds even if the user does not exist.",
+			Tags:    []string{"users"},
+			Request: user.PasswordResetRequest{},
+			Errors:  errs(bodyErrors, responseErrors),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v1/password-reset/confirm",
+			Summary: "Reset the password of a user by the token mailed to it.",
+			Tags:    []string{"users"},
+			Request: user.ConfirmPasswordResetRequest{},
+			Errors:  errs(bodyErrors, responseErrors, []int{code.ErrAccountTokenInvalid}),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/namespaces",
+			Summary:  "Create a namespace.",
+			Tags:     []string{"namespaces"},
+			Request:  v1.Namespace{},
+			Response: v1.Namespace{},
+			Errors:   errs(authErrors, bodyErrors, responseErrors, []int{code.ErrNamespaceAlreadyExist}),
+			Security: authenticated,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/v1/namespaces/:ns",
+			Summary: "Delete a namespace and the users in it.",
+			Tags:    []string{"namespaces"},
+			Errors: errs(authErrors, preconditionErrors, responseErrors,
+				[]int{code.ErrNamespaceNotFound, code.ErrNamespaceProtected}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/v1/namespaces/:ns",
+			Summary:  "Update a namespace.",
+			Tags:     []string{"namespaces"},
+			Request:  v1.Namespace{},
+			Response: v1.Namespace{},
+			Errors:   errs(authErrors, preconditionErrors, bodyErrors, responseErrors, []int{code.ErrNamespaceNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/namespaces",
+			Summary:  "List the namespaces.",
+			Tags:     []string{"namespaces"},
+			Query:    metav1.ListOptions{},
+			Response: v1.NamespaceList{},
+			Errors:   errs(authErrors, responseErrors, []int{code.ErrBind}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/namespaces/:ns",
+			Summary:  "Get a namespace.",
+			Tags:     []string{"namespaces"},
+			Response: v1.Namespace{},
+			Errors:   errs(authErrors, responseErrors, []int{code.ErrNamespaceNotFound}),
+			Security: authenticated,
+		},
+	}
+
+	for _, prefix := range []string{"/v1/users", "/v1/namespaces/:ns/users"} {
+		routes = append(routes, userRoutes(prefix, v1.User{}, v1.UserList{}, userListOptions{})...)
+	}
+	for _, prefix := range []string{"/v2/users", "/v2/namespaces/:ns/users"} {
+		routes = append(routes, userRoutes(prefix, v2.User{}, v2.UserList{}, metav1.ListOptions{})...)
+	}
+	for _, prefix := range []string{"/v1", "/v1/namespaces/:ns"} {
+		routes = append(routes, rbacRoutes(prefix)...)
+	}
+
+	// the users are created in the default namespace without authentication.
+	for i := range routes {
+		if routes[i].Method == http.MethodPost && (routes[i].Path == "/v1/users" || routes[i].Path == "/v2/users") {
+			routes[i].Security = nil
+			routes[i].Errors = errs(bodyErrors, responseErrors, []int{code.ErrUserAlreadyExist})
+		}
+	}
+
+	return routes
+}
+
+// userRoutes documents the user routes under prefix, served in the given schema.
+func userRoutes(prefix string, object, list, listOptions interface{}) []openapi.Route {
+	authenticated := []string{securityBearer, securityBasic}
+	notFound := []int{code.ErrUserNotFound, code.ErrNamespaceNotFound}
+
+	return []openapi.Route{
+		{
+			Method:   http.MethodPost,
+			Path:     prefix,
+			Summary:  "Create a user.",
+			Tags:     []string{"users"},
+			Request:  object,
+			Response: object,
+			Errors:   errs(authErrors, bodyErrors, responseErrors, []int{code.ErrUserAlreadyExist, code.ErrNamespaceNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/:name",
+			Summary:  "Delete a user.",
+			Tags:     []string{"users"},
+			Errors:   errs(authErrors, preconditionErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     prefix + "/:name/change-password",
+			Summary:  "Change the password of a user.",
+			Tags:     []string{"users"},
+			Request:  user.ChangePasswordRequest{},
+			Errors:   errs(authErrors, bodyErrors, responseErrors, notFound, []int{code.ErrPasswordIncorrect}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/:name/lockout",
+			Summary:  "Unlock a user locked out by too many failed login attempts.",
+			Tags:     []string{"users"},
+			Errors:   errs(authErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/:name/permissions",
+			Summary:  "List the effective permissions of a user, merged from the roles granted to the user.",
+			Tags:     []string{"users"},
+			Response: v1.PermissionList{},
+			Errors:   errs(authErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/:name/sessions",
+			Summary:  "List the sessions of a user started by logging in.",
+			Tags:     []string{"users"},
+			Response: session.SessionList{},
+			Errors:   errs(authErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/:name/sessions",
+			Summary:  "Revoke all the sessions of a user.",
+			Tags:     []string{"users"},
+			Errors:   errs(authErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/:name/sessions/:id",
+			Summary:  "Revoke a session of a user, its token is rejected from now on.",
+			Tags:     []string{"users"},
+			Errors:   errs(authErrors, responseErrors, notFound, []int{code.ErrSessionNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     prefix + "/:name/verify-email",
+			Summary:  "Verify the email address of a user by the mailed token, the token is mailed again if it is empty.",
+			Tags:     []string{"users"},
+			Request:  user.VerifyEmailRequest{},
+			Errors:   errs(authErrors, bodyErrors, responseErrors, notFound, []int{code.ErrAccountTokenInvalid}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     prefix + "/:name/mfa",
+			Summary:  "Start to enroll a TOTP authenticator of a user, it is enrolled once a code it generates is confirmed.",
+			Tags:     []string{"users"},
+			Response: mfa.Enrollment{},
+			Errors:   errs(authErrors, responseErrors, notFound, []int{code.ErrMFAAlreadyEnabled}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     prefix + "/:name/mfa",
+			Summary:  "Confirm the enrolling authenticator of a user by its code, the recovery codes are responded once.",
+			Tags:     []string{"users"},
+			Request:  user.ConfirmMFARequest{},
+			Response: mfa.RecoveryCodes{},
+			Errors: errs(authErrors, bodyErrors, responseErrors, notFound,
+				[]int{code.ErrMFANotEnrolling, code.ErrMFACodeInvalid, code.ErrMFAAlreadyEnabled}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/:name/mfa",
+			Summary:  "Reset the authenticator and the recovery codes of a user, the user logs in with the password only.",
+			Tags:     []string{"users"},
+			Errors:   errs(authErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     prefix + "/:name",
+			Summary:  "Update a user.",
+			Tags:     []string{"users"},
+			Request:  object,
+			Response: object,
+			Errors:   errs(authErrors, preconditionErrors, bodyErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix,
+			Summary:  "List the users.",
+			Tags:     []string{"users"},
+			Query:    listOptions,
+			Response: list,
+			Errors:   errs(authErrors, responseErrors, []int{code.ErrBind, code.ErrNamespaceNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/:name",
+			Summary:  "Get a user.",
+			Tags:     []string{"users"},
+			Response: object,
+			Errors:   errs(authErrors, responseErrors, notFound),
+			Security: authenticated,
+		},
+	}
+}
+
+// rbacRoutes documents the role and role binding routes under prefix.
+func rbacRoutes(prefix string) []openapi.Route {
+	authenticated := []string{securityBearer, securityBasic}
+	roleNotFound := []int{code.ErrRoleNotFound, code.ErrNamespaceNotFound}
+	bindingNotFound := []int{code.ErrRoleBindingNotFound, code.ErrNamespaceNotFound}
+
+	return []openapi.Route{
+		{
+			Method:   http.MethodPost,
+			Path:     prefix + "/roles",
+			Summary:  "Create a role.",
+			Tags:     []string{"rbac"},
+			Request:  v1.Role{},
+			Response: v1.Role{},
+			Errors: errs(authErrors, bodyErrors, responseErrors,
+				[]int{code.ErrRoleAlreadyExist, code.ErrRoleProtected, code.ErrNamespaceNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/roles/:name",
+			Summary:  "Delete a role.",
+			Tags:     []string{"rbac"},
+			Errors:   errs(authErrors, preconditionErrors, responseErrors, []int{code.ErrRoleProtected}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     prefix + "/roles/:name",
+			Summary:  "Update the rules of a role.",
+			Tags:     []string{"rbac"},
+			Request:  v1.Role{},
+			Response: v1.Role{},
+			Errors: errs(authErrors, preconditionErrors, bodyErrors, responseErrors, roleNotFound,
+				[]int{code.ErrRoleProtected}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/roles",
+			Summary:  "List the roles.",
+			Tags:     []string{"rbac"},
+			Query:    metav1.ListOptions{},
+			Response: v1.RoleList{},
+			Errors:   errs(authErrors, responseErrors, []int{code.ErrBind}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/roles/:name",
+			Summary:  "Get a role.",
+			Tags:     []string{"rbac"},
+			Response: v1.Role{},
+			Errors:   errs(authErrors, responseErrors, roleNotFound),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     prefix + "/rolebindings",
+			Summary:  "Grant a role to the users.",
+			Tags:     []string{"rbac"},
+			Request:  v1.RoleBinding{},
+			Response: v1.RoleBinding{},
+			Errors: errs(authErrors, bodyErrors, responseErrors,
+				[]int{code.ErrRoleBindingAlreadyExist, code.ErrRoleNotFound, code.ErrNamespaceNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     prefix + "/rolebindings/:name",
+			Summary:  "Delete a role binding.",
+			Tags:     []string{"rbac"},
+			Errors:   errs(authErrors, preconditionErrors, responseErrors),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     prefix + "/rolebindings/:name",
+			Summary:  "Update the role and the users of a role binding.",
+			Tags:     []string{"rbac"},
+			Request:  v1.RoleBinding{},
+			Response: v1.RoleBinding{},
+			Errors: errs(authErrors, preconditionErrors, bodyErrors, responseErrors, bindingNotFound,
+				[]int{code.ErrRoleNotFound}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/rolebindings",
+			Summary:  "List the role bindings.",
+			Tags:     []string{"rbac"},
+			Query:    metav1.ListOptions{},
+			Response: v1.RoleBindingList{},
+			Errors:   errs(authErrors, responseErrors, []int{code.ErrBind}),
+			Security: authenticated,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     prefix + "/rolebindings/:name",
+			Summary:  "Get a role binding.",
+			Tags:     []string{"rbac"},
+			Response: v1.RoleBinding{},
+			Errors:   errs(authErrors, responseErrors, bindingNotFound),
+			Security: authenticated,
+		},
+	}
+}
+
+func errs(groups ...[]int) []int {
+	var codes []int
+	for _, group := range groups {
+		codes = append(codes, group...)
+	}
+
+	return codes
+}
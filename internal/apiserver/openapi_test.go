@@ -0,0 +1,84 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/openapi"
+)
+
+func newTestEngine() *gin.Engine {
+	viper.Set("jwt.key", "openapi-test-key")
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	installController(g)
+	installOpenAPI(g)
+
+	return g
+}
+
+// TestOpenAPI_Drift fails when the routes are changed without updating the document.
+func TestOpenAPI_Drift(t *testing.T) {
+	g := newTestEngine()
+
+	undocumented, unregistered := newOpenAPIBuilder().Diff(g.Routes())
+	assert.Empty(t, undocumented, "the routes are not documented in apiRoutes")
+	assert.Empty(t, unregistered, "the documented routes are not registered by installController")
+}
+
+func TestOpenAPI_Serve(t *testing.T) {
+	g := newTestEngine()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, openapiPath, nil)
+	req.Header.Set("Accept", "application/json")
+	g.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var doc openapi.Document
+	if !assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc)) {
+		return
+	}
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.NotNil(t, doc.Paths["/v1/namespaces/{ns}/users/{name}"].Get)
+	assert.NotContains(t, doc.Paths, openapiPath)
+
+	user := doc.Components.Schemas["apiserver.v1.User"]
+	if assert.NotNil(t, user) {
+		assert.Contains(t, user.Required, "email")
+		assert.Equal(t, "email", user.Properties["email"].Format)
+	}
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openapiUIPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "SwaggerUIBundle")
+	assert.Contains(t, w.Body.String(), openapiUIAssetsPath+"/swagger-ui-bundle.js")
+
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openapiUIAssetsPath+"/swagger-ui-bundle.js", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "SwaggerUIBundle")
+}
@@ -16,12 +16,18 @@
 package options
 
 import (
+	"github.com/dairongpeng/leona/internal/apiserver/account"
 	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	"github.com/dairongpeng/leona/internal/apiserver/ldapuser"
+	"github.com/dairongpeng/leona/internal/apiserver/lockout"
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	"github.com/dairongpeng/leona/internal/apiserver/password"
 	genericoptions "github.com/dairongpeng/leona/internal/pkg/options"
 	"github.com/dairongpeng/leona/internal/pkg/server"
 	cliflag "github.com/dairongpeng/leona/pkg/cli/flag"
 	"github.com/dairongpeng/leona/pkg/json"
 	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/tracing"
 )
 
 // Options runs a leona api server.
@@ -30,12 +36,21 @@ type Options struct {
 	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"     mapstructure:"grpc"`
 	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure" mapstructure:"insecure"`
 	// SecureServing           *genericoptions.SecureServingOptions   `json:"secure"   mapstructure:"secure"`
-	MySQLOptions *genericoptions.MySQLOptions `json:"mysql"    mapstructure:"mysql"`
-	RedisOptions *genericoptions.RedisOptions `json:"redis"    mapstructure:"redis"`
-	// JwtOptions              *genericoptions.JwtOptions             `json:"jwt"      mapstructure:"jwt"`
-	Log              *log.Options                   `json:"log"      mapstructure:"log"`
-	FeatureOptions   *genericoptions.FeatureOptions `json:"feature"  mapstructure:"feature"`
-	AnalyticsOptions *analytics.AnalyticsOptions    `json:"analytics"      mapstructure:"analytics"`
+	MySQLOptions       *genericoptions.MySQLOptions       `json:"mysql"    mapstructure:"mysql"`
+	RedisOptions       *genericoptions.RedisOptions       `json:"redis"    mapstructure:"redis"`
+	JwtOptions         *genericoptions.JwtOptions         `json:"jwt"      mapstructure:"jwt"`
+	RateLimitOptions   *genericoptions.RateLimitOptions   `json:"ratelimit" mapstructure:"ratelimit"`
+	IdempotencyOptions *genericoptions.IdempotencyOptions `json:"idempotency" mapstructure:"idempotency"`
+	OIDCOptions        *genericoptions.OIDCOptions        `json:"oidc"     mapstructure:"oidc"`
+	Log                *log.Options                       `json:"log"      mapstructure:"log"`
+	TracingOptions     *tracing.Options                   `json:"tracing"        mapstructure:"tracing"`
+	FeatureOptions     *genericoptions.FeatureOptions     `json:"feature"  mapstructure:"feature"`
+	AnalyticsOptions   *analytics.AnalyticsOptions        `json:"analytics"      mapstructure:"analytics"`
+	PasswordOptions    *password.PasswordOptions          `json:"password"       mapstructure:"password"`
+	LockoutOptions     *lockout.LockoutOptions            `json:"lockout"        mapstructure:"lockout"`
+	LDAPOptions        *ldapuser.LDAPOptions              `json:"ldap"           mapstructure:"ldap"`
+	MailOptions        *account.MailOptions               `json:"mail"           mapstructure:"mail"`
+	MFAOptions         *mfa.MFAOptions                    `json:"mfa"            mapstructure:"mfa"`
 }
 
 // NewOptions creates a new Options object with default parameters.
@@ -45,12 +60,21 @@ func NewOptions() *Options {
 		GRPCOptions:             genericoptions.NewGRPCOptions(),
 		InsecureServing:         genericoptions.NewInsecureServingOptions(),
 		// SecureServing:           genericoptions.NewSecureServingOptions(),
-		MySQLOptions: genericoptions.NewMySQLOptions(),
-		RedisOptions: genericoptions.NewRedisOptions(),
-		// JwtOptions:              genericoptions.NewJwtOptions(),
-		Log:              log.NewOptions(),
-		FeatureOptions:   genericoptions.NewFeatureOptions(),
-		AnalyticsOptions: analytics.NewAnalyticsOptions(),
+		MySQLOptions:       genericoptions.NewMySQLOptions(),
+		RedisOptions:       genericoptions.NewRedisOptions(),
+		JwtOptions:         genericoptions.NewJwtOptions(),
+		RateLimitOptions:   genericoptions.NewRateLimitOptions(),
+		IdempotencyOptions: genericoptions.NewIdempotencyOptions(),
+		OIDCOptions:        genericoptions.NewOIDCOptions(),
+		Log:                log.NewOptions(),
+		TracingOptions:     tracing.NewOptions(),
+		FeatureOptions:     genericoptions.NewFeatureOptions(),
+		AnalyticsOptions:   analytics.NewAnalyticsOptions(),
+		PasswordOptions:    password.NewPasswordOptions(),
+		LockoutOptions:     lockout.NewLockoutOptions(),
+		LDAPOptions:        ldapuser.NewLDAPOptions(),
+		MailOptions:        account.NewMailOptions(),
+		MFAOptions:         mfa.NewMFAOptions(),
 	}
 
 	return &o
@@ -64,14 +88,23 @@ func (o *Options) ApplyTo(c *server.Config) error {
 // Flags returns flags for a specific APIServer by section name.
 func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.GenericServerRunOptions.AddFlags(fss.FlagSet("generic"))
-	// o.JwtOptions.AddFlags(fss.FlagSet("jwt"))
+	o.JwtOptions.AddFlags(fss.FlagSet("jwt"))
+	o.RateLimitOptions.AddFlags(fss.FlagSet("ratelimit"))
+	o.IdempotencyOptions.AddFlags(fss.FlagSet("idempotency"))
+	o.OIDCOptions.AddFlags(fss.FlagSet("oidc"))
 	o.GRPCOptions.AddFlags(fss.FlagSet("grpc"))
 	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
 	// o.RedisOptions.AddFlags(fss.FlagSet("redis"))
 	o.FeatureOptions.AddFlags(fss.FlagSet("features"))
 	o.InsecureServing.AddFlags(fss.FlagSet("insecure serving"))
+	o.PasswordOptions.AddFlags(fss.FlagSet("password"))
+	o.LockoutOptions.AddFlags(fss.FlagSet("lockout"))
+	o.LDAPOptions.AddFlags(fss.FlagSet("ldap"))
+	o.MailOptions.AddFlags(fss.FlagSet("mail"))
+	o.MFAOptions.AddFlags(fss.FlagSet("mfa"))
 	// o.SecureServing.AddFlags(fss.FlagSet("secure serving"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
+	o.TracingOptions.AddFlags(fss.FlagSet("tracing"))
 
 	return fss
 }
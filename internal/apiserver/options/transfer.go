@@ -0,0 +1,99 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	genericoptions "github.com/dairongpeng/leona/internal/pkg/options"
+	cliflag "github.com/dairongpeng/leona/pkg/cli/flag"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// Supported storage backends of the transfer commands.
+const (
+	StoreMySQL = "mysql"
+	StoreEtcd  = "etcd"
+)
+
+// TransferOptions contains the options of the export and import commands.
+type TransferOptions struct {
+	Store        string                       `json:"store"  mapstructure:"store"`
+	Format       string                       `json:"format" mapstructure:"format"`
+	File         string                       `json:"file"   mapstructure:"file"`
+	Exact        bool                         `json:"exact"  mapstructure:"exact"`
+	MySQLOptions *genericoptions.MySQLOptions `json:"mysql"  mapstructure:"mysql"`
+	EtcdOptions  *genericoptions.EtcdOptions  `json:"etcd"   mapstructure:"etcd"`
+	Log          *log.Options                 `json:"log"    mapstructure:"log"`
+}
+
+// NewTransferOptions creates a new TransferOptions object with default parameters.
+func NewTransferOptions() *TransferOptions {
+	return &TransferOptions{
+		Store:        StoreMySQL,
+		Format:       "yaml",
+		File:         "-",
+		MySQLOptions: genericoptions.NewMySQLOptions(),
+		EtcdOptions:  genericoptions.NewEtcdOptions(),
+		Log:          log.NewOptions(),
+	}
+}
+
+// Flags returns flags for the transfer commands by section name.
+func (o *TransferOptions) Flags() (fss cliflag.NamedFlagSets) {
+	o.AddFlags(fss.FlagSet("transfer"))
+	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
+	o.EtcdOptions.AddFlags(fss.FlagSet("etcd"))
+	o.Log.AddFlags(fss.FlagSet("logs"))
+
+	return fss
+}
+
+// AddFlags adds flags related to resource transfer to the specified FlagSet.
+func (o *TransferOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Store, "store", o.Store, "Storage backend to transfer resources from or to, one of: mysql, etcd.")
+	fs.StringVar(&o.Format, "format", o.Format, "Format of the resource stream, one of: yaml, json.")
+	fs.StringVar(&o.File, "file", o.File, "File to write or read the resource stream, - means stdout or stdin.")
+	fs.BoolVar(&o.Exact, "exact", o.Exact, ""+
+		"Keep the password hashes and the second factors of the users when exporting.")
+}
+
+// Validate checks TransferOptions and return a slice of found errs.
+func (o *TransferOptions) Validate() []error {
+	var errs []error
+
+	switch o.Store {
+	case StoreMySQL:
+		errs = append(errs, o.MySQLOptions.Validate()...)
+	case StoreEtcd:
+		errs = append(errs, o.EtcdOptions.Validate()...)
+	default:
+		errs = append(errs, fmt.Errorf("--store must be one of: %s, %s", StoreMySQL, StoreEtcd))
+	}
+
+	if o.Format != "yaml" && o.Format != "json" {
+		errs = append(errs, fmt.Errorf("--format must be one of: yaml, json"))
+	}
+
+	if o.File == "" {
+		errs = append(errs, fmt.Errorf("--file can not be empty"))
+	}
+
+	errs = append(errs, o.Log.Validate()...)
+
+	return errs
+}
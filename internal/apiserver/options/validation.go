@@ -14,6 +14,8 @@
 
 package options
 
+import "fmt"
+
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() []error {
 	var errs []error
@@ -24,9 +26,23 @@ func (o *Options) Validate() []error {
 	// errs = append(errs, o.SecureServing.Validate()...)
 	errs = append(errs, o.MySQLOptions.Validate()...)
 	// errs = append(errs, o.RedisOptions.Validate()...)
-	// errs = append(errs, o.JwtOptions.Validate()...)
+	errs = append(errs, o.JwtOptions.Validate()...)
+	errs = append(errs, o.RateLimitOptions.Validate()...)
+	errs = append(errs, o.IdempotencyOptions.Validate()...)
+	errs = append(errs, o.OIDCOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
+	errs = append(errs, o.TracingOptions.Validate()...)
 	errs = append(errs, o.FeatureOptions.Validate()...)
+	errs = append(errs, o.PasswordOptions.Validate()...)
+	errs = append(errs, o.LockoutOptions.Validate()...)
+	errs = append(errs, o.LDAPOptions.Validate()...)
+	errs = append(errs, o.MailOptions.Validate()...)
+	errs = append(errs, o.MFAOptions.Validate()...)
+
+	// the TOTP secrets are not encrypted by the key signing the tokens
+	if o.MFAOptions.Key != "" && o.MFAOptions.Key == o.JwtOptions.Key {
+		errs = append(errs, fmt.Errorf("--mfa.key must be different from --jwt.key"))
+	}
 
 	return errs
 }
@@ -0,0 +1,191 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package password enforces the password policy, prevents the reuse of the previous passwords
+// and expires the passwords which are too old.
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/validation"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// Policy is the password policy of the users.
+type Policy struct {
+	*validation.PasswordPolicy
+
+	// HistorySize is the number of the previous passwords which can not be reused.
+	HistorySize int
+
+	// MaxAge is the age after which the passwords must be changed, 0 means never.
+	MaxAge time.Duration
+}
+
+var (
+	policyLock sync.RWMutex
+	policy     = defaultPolicy()
+)
+
+// NewPolicy creates a Policy with the given options.
+func NewPolicy(opts *PasswordOptions) (*Policy, error) {
+	p := validation.DefaultPasswordPolicy()
+	p.MinLength = opts.MinLength
+	p.MaxLength = opts.MaxLength
+	p.RequireUpper = opts.RequireUppercase
+	p.RequireLower = opts.RequireLowercase
+	p.RequireNumber = opts.RequireNumber
+	p.RequireSpecial = opts.RequireSpecial
+	p.Block(opts.Blocklist...)
+
+	if opts.BlocklistFile != "" {
+		blocklist, err := readBlocklist(opts.BlocklistFile)
+		if err != nil {
+			return nil, err
+		}
+		p.Block(blocklist...)
+	}
+
+	return &Policy{
+		PasswordPolicy: p,
+		HistorySize:    opts.HistorySize,
+		MaxAge:         opts.MaxAge,
+	}, nil
+}
+
+func defaultPolicy() *Policy {
+	p, _ := NewPolicy(NewPasswordOptions())
+
+	return p
+}
+
+// SetPolicy sets the policy enforced by the apiserver, the strength requirements are also
+// enforced by the Validate methods of the users.
+func SetPolicy(p *Policy) {
+	policyLock.Lock()
+	defer policyLock.Unlock()
+
+	policy = p
+	validation.SetPasswordPolicy(p.PasswordPolicy)
+}
+
+// GetPolicy returns the policy enforced by the apiserver.
+func GetPolicy() *Policy {
+	policyLock.RLock()
+	defer policyLock.RUnlock()
+
+	return policy
+}
+
+// Validate checks the new password of a user, it must satisfy the policy and must be neither
+// the current password nor one of the previous passwords in the history.
+func (p *Policy) Validate(user *v1.User, password string, fldPath *field.Path) field.ErrorList {
+	if allErrs := p.PasswordPolicy.Validate(password, fldPath); len(allErrs) > 0 {
+		return allErrs
+	}
+
+	if p.reused(user, password) {
+		detail := "must not be the current password"
+		if p.HistorySize > 0 {
+			detail = fmt.Sprintf("must not be the current password or one of the last %d passwords", p.HistorySize)
+		}
+
+		return field.ErrorList{field.Invalid(fldPath, nil, detail)}
+	}
+
+	return nil
+}
+
+func (p *Policy) reused(user *v1.User, password string) bool {
+	if user.Password != "" && auth.Compare(user.Password, password) == nil {
+		return true
+	}
+
+	for i, hash := range user.PasswordHistory {
+		if i >= p.HistorySize {
+			break
+		}
+
+		if auth.Compare(hash, password) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Change replaces the password hash of the user, the previous hash is kept in the history.
+func (p *Policy) Change(user *v1.User, hash string, now time.Time) {
+	history := user.PasswordHistory
+	if user.Password != "" {
+		history = append([]string{user.Password}, history...)
+	}
+
+	if len(history) > p.HistorySize {
+		history = history[:p.HistorySize]
+	}
+
+	user.Password = hash
+	user.PasswordHistory = history
+	user.PasswordChangedAt = now
+}
+
+// Expired reports whether the password of the user is older than the max age. The passwords of
+// the users created before the password change time was recorded are as old as the users.
+func (p *Policy) Expired(user *v1.User, now time.Time) bool {
+	if p.MaxAge <= 0 {
+		return false
+	}
+
+	changedAt := user.PasswordChangedAt
+	if changedAt.IsZero() {
+		changedAt = user.CreatedAt
+	}
+
+	return !changedAt.IsZero() && now.Sub(changedAt) > p.MaxAge
+}
+
+// readBlocklist reads the passwords in the file, one password per line, the lines starting with `#` are ignored.
+func readBlocklist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open password blocklist failed")
+	}
+	defer f.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read password blocklist failed")
+	}
+
+	return passwords, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package password
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// PasswordOptions contains configuration items related to the password policy.
+type PasswordOptions struct {
+	MinLength        int           `json:"min-length"        mapstructure:"min-length"`
+	MaxLength        int           `json:"max-length"        mapstructure:"max-length"`
+	RequireUppercase bool          `json:"require-uppercase" mapstructure:"require-uppercase"`
+	RequireLowercase bool          `json:"require-lowercase" mapstructure:"require-lowercase"`
+	RequireNumber    bool          `json:"require-number"    mapstructure:"require-number"`
+	RequireSpecial   bool          `json:"require-special"   mapstructure:"require-special"`
+	Blocklist        []string      `json:"blocklist"         mapstructure:"blocklist"`
+	BlocklistFile    string        `json:"blocklist-file"    mapstructure:"blocklist-file"`
+	HistorySize      int           `json:"history-size"      mapstructure:"history-size"`
+	MaxAge           time.Duration `json:"max-age"           mapstructure:"max-age"`
+}
+
+// NewPasswordOptions creates a PasswordOptions object with default parameters.
+func NewPasswordOptions() *PasswordOptions {
+	return &PasswordOptions{
+		MinLength:        8,
+		MaxLength:        16,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumber:    true,
+		RequireSpecial:   true,
+		HistorySize:      5,
+		MaxAge:           0,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *PasswordOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errors []error
+
+	if o.MinLength < 1 {
+		errors = append(errors, fmt.Errorf("--password.min-length %v must be greater than 0", o.MinLength))
+	}
+
+	if o.MaxLength < o.MinLength {
+		errors = append(errors, fmt.Errorf("--password.max-length %v must not be less than --password.min-length %v",
+			o.MaxLength, o.MinLength))
+	}
+
+	if o.HistorySize < 0 {
+		errors = append(errors, fmt.Errorf("--password.history-size %v must not be negative", o.HistorySize))
+	}
+
+	if o.MaxAge < 0 {
+		errors = append(errors, fmt.Errorf("--password.max-age %v must not be negative", o.MaxAge))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the password policy for a specific api server to the
+// specified FlagSet.
+func (o *PasswordOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.IntVar(&o.MinLength, "password.min-length", o.MinLength,
+		"The minimum length of the passwords.")
+
+	fs.IntVar(&o.MaxLength, "password.max-length", o.MaxLength,
+		"The maximum length of the passwords.")
+
+	fs.BoolVar(&o.RequireUppercase, "password.require-uppercase", o.RequireUppercase,
+		"Require the passwords to contain an uppercase letter.")
+
+	fs.BoolVar(&o.RequireLowercase, "password.require-lowercase", o.RequireLowercase,
+		"Require the passwords to contain a lowercase letter.")
+
+	fs.BoolVar(&o.RequireNumber, "password.require-number", o.RequireNumber,
+		"Require the passwords to contain a number.")
+
+	fs.BoolVar(&o.RequireSpecial, "password.require-special", o.RequireSpecial,
+		"Require the passwords to contain a special character.")
+
+	fs.StringSliceVar(&o.Blocklist, "password.blocklist", o.Blocklist, ""+
+		"The passwords which are rejected besides the built-in common passwords, compared case-insensitively.")
+
+	fs.StringVar(&o.BlocklistFile, "password.blocklist-file", o.BlocklistFile, ""+
+		"A file holding the rejected passwords, one password per line.")
+
+	fs.IntVar(&o.HistorySize, "password.history-size", o.HistorySize, ""+
+		"The number of previous passwords which can not be reused, 0 disables the password history.")
+
+	fs.DurationVar(&o.MaxAge, "password.max-age", o.MaxAge, ""+
+		"The passwords older than max age must be changed before logging in, 0 means the passwords never expire.")
+}
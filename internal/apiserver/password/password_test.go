@@ -0,0 +1,119 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package password
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/auth"
+	"github.com/dairongpeng/leona/pkg/validation"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+func TestNewPolicy(t *testing.T) {
+	f, err := ioutil.TempFile("", "blocklist")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, _ = f.WriteString("# company passwords\nLeona@2021\n\n")
+	f.Close()
+
+	opts := NewPasswordOptions()
+	opts.MinLength = 10
+	opts.MaxLength = 20
+	opts.RequireSpecial = false
+	opts.Blocklist = []string{"Dairong12345"}
+	opts.BlocklistFile = f.Name()
+
+	p, err := NewPolicy(opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	path := field.NewPath("password")
+	assert.Empty(t, p.PasswordPolicy.Validate("Colin123456", path))
+	assert.NotEmpty(t, p.PasswordPolicy.Validate("Colin@123", path))
+	assert.NotEmpty(t, p.PasswordPolicy.Validate("dairong12345", path))
+	assert.NotEmpty(t, p.PasswordPolicy.Validate("LEONA@2021", path))
+
+	opts.BlocklistFile = f.Name() + ".non-existing"
+	_, err = NewPolicy(opts)
+	assert.Error(t, err)
+}
+
+func TestSetPolicy(t *testing.T) {
+	defer SetPolicy(defaultPolicy())
+
+	opts := NewPasswordOptions()
+	opts.MinLength = 12
+	opts.MaxLength = 32
+	p, _ := NewPolicy(opts)
+	SetPolicy(p)
+
+	assert.Equal(t, p, GetPolicy())
+	assert.NotEmpty(t, validation.ValidatePassword("Colin@2021", nil))
+	assert.Empty(t, validation.ValidatePassword("Colin@2021-long", nil))
+}
+
+func TestPolicy_ChangeAndValidate(t *testing.T) {
+	p, _ := NewPolicy(NewPasswordOptions())
+	p.HistorySize = 2
+
+	user := &v1.User{}
+	passwords := []string{"Colin@2018", "Colin@2019", "Colin@2020", "Colin@2021"}
+	for _, password := range passwords {
+		assert.Empty(t, p.Validate(user, password, field.NewPath("password")))
+
+		hash, _ := auth.Encrypt(password)
+		p.Change(user, hash, time.Now())
+	}
+
+	assert.Len(t, user.PasswordHistory, 2)
+	assert.False(t, user.PasswordChangedAt.IsZero())
+
+	// the current and the last 2 passwords can not be reused.
+	for _, password := range passwords[1:] {
+		errs := p.Validate(user, password, field.NewPath("password"))
+		if assert.Len(t, errs, 1, password) {
+			assert.Equal(t, field.ErrorTypeInvalid, errs[0].Type)
+		}
+	}
+	assert.Empty(t, p.Validate(user, passwords[0], field.NewPath("password")))
+}
+
+func TestPolicy_Expired(t *testing.T) {
+	now := time.Now()
+	p := &Policy{MaxAge: 24 * time.Hour}
+
+	user := &v1.User{}
+	assert.False(t, p.Expired(user, now))
+
+	user.CreatedAt = now.Add(-48 * time.Hour)
+	assert.True(t, p.Expired(user, now))
+
+	user.PasswordChangedAt = now.Add(-time.Hour)
+	assert.False(t, p.Expired(user, now))
+
+	p.MaxAge = 0
+	user.PasswordChangedAt = now.Add(-10000 * time.Hour)
+	assert.False(t, p.Expired(user, now))
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbac authorizes the requests by the roles granted to the users. The roles are granted by
+// the role bindings, and the administrators are granted the bootstrap admin role. The role
+// bindings in the default namespace grant their roles in all namespaces.
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// AdminRole is the bootstrap role granting everything, the administrators are granted it implicitly.
+const AdminRole = "admin"
+
+// NamespaceAll is the namespace of the permissions which apply in all namespaces.
+const NamespaceAll = "*"
+
+// BootstrapRole returns the bootstrap role of the name. The bootstrap roles exist in every namespace,
+// they can be bound but can not be changed.
+func BootstrapRole(namespace, name string) (*v1.Role, bool) {
+	if name != AdminRole {
+		return nil, false
+	}
+
+	return &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: AdminRole, Namespace: namespace},
+		Rules: []v1.PolicyRule{
+			{Verbs: []string{v1.VerbAll}, Resources: []string{v1.ResourceAll}},
+		},
+	}, true
+}
+
+// selfRules are the rules every user has in its namespace.
+func selfRules(username string) []v1.PolicyRule {
+	self := []string{username}
+
+	return []v1.PolicyRule{
+		{Verbs: []string{v1.VerbGet, v1.VerbUpdate}, Resources: []string{"users"}, ResourceNames: self},
+		{Verbs: []string{v1.VerbUpdate}, Resources: []string{"users/change-password"}, ResourceNames: self},
+		{Verbs: []string{v1.VerbGet, v1.VerbDelete}, Resources: []string{"users/sessions"}, ResourceNames: self},
+		{Verbs: []string{v1.VerbGet}, Resources: []string{"users/permissions"}, ResourceNames: self},
+		{Verbs: []string{v1.VerbCreate}, Resources: []string{"users/verify-email"}, ResourceNames: self},
+		{Verbs: []string{v1.VerbCreate, v1.VerbUpdate}, Resources: []string{"users/mfa"}, ResourceNames: self},
+	}
+}
+
+// Authorizer authorizes the requests by the permissions of the users.
+type Authorizer struct {
+	store store.Factory
+}
+
+var _ middleware.Authorizer = (*Authorizer)(nil)
+
+// NewAuthorizer returns a new authorizer looking up the roles in the store.
+func NewAuthorizer(store store.Factory) *Authorizer {
+	return &Authorizer{store: store}
+}
+
+// Authorize allows the request if any permission of the user allows it.
+func (a *Authorizer) Authorize(ctx context.Context, attrs middleware.Attributes) (bool, string, error) {
+	permissions, err := a.Permissions(ctx, attrs.Namespace, attrs.Username)
+	if errors.IsCode(err, code.ErrUserNotFound) {
+		return false, fmt.Sprintf("user %s/%s does not exist", attrs.Namespace, attrs.Username), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	for _, p := range permissions.Items {
+		if p.Namespace != NamespaceAll && (attrs.RequestNamespace == "" || p.Namespace != attrs.RequestNamespace) {
+			continue
+		}
+
+		if p.Allows(attrs.Verb, attrs.Resource, attrs.Name) {
+			return true, "", nil
+		}
+	}
+
+	resource := attrs.Resource
+	if attrs.Name != "" {
+		resource += " " + attrs.Name
+	}
+	if attrs.RequestNamespace != "" {
+		resource += " in namespace " + attrs.RequestNamespace
+	}
+
+	return false, fmt.Sprintf("user %s/%s can not %s %s", attrs.Namespace, attrs.Username, attrs.Verb, resource), nil
+}
+
+// Permissions returns the permissions of the user: the rules every user has on itself, the
+// bootstrap admin role of the administrators, and the roles granted by the role bindings.
+func (a *Authorizer) Permissions(ctx context.Context, namespace, username string) (*v1.PermissionList, error) {
+	user, err := a.store.Users().Get(ctx, namespace, username, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// the roles granted in the default namespace apply in all namespaces
+	scope := namespace
+	if namespace == metav1.NamespaceDefault {
+		scope = NamespaceAll
+	}
+
+	list := &v1.PermissionList{Items: []*v1.Permission{}}
+	for _, rule := range selfRules(username) {
+		list.Items = append(list.Items, &v1.Permission{PolicyRule: rule, Namespace: namespace})
+	}
+
+	if user.IsAdmin == 1 {
+		role, _ := BootstrapRole(namespace, AdminRole)
+		for _, rule := range role.Rules {
+			list.Items = append(list.Items, &v1.Permission{PolicyRule: rule, Namespace: scope, Role: role.Name})
+		}
+	}
+
+	bindings, err := a.store.RoleBindings().List(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, binding := range bindings.Items {
+		if !binding.Grants(username) {
+			continue
+		}
+
+		role, ok := BootstrapRole(namespace, binding.RoleRef)
+		if !ok {
+			role, err = a.store.Roles().Get(ctx, namespace, binding.RoleRef, metav1.GetOptions{})
+			if err != nil {
+				log.L(ctx).Warnf("get role %s/%s of role binding %s failed: %s",
+					namespace, binding.RoleRef, binding.Name, err.Error())
+
+				continue
+			}
+		}
+
+		for _, rule := range role.Rules {
+			list.Items = append(list.Items, &v1.Permission{
+				PolicyRule:  rule,
+				Namespace:   scope,
+				Role:        role.Name,
+				RoleBinding: binding.Name,
+			})
+		}
+	}
+	list.TotalCount = int64(len(list.Items))
+
+	return list, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+)
+
+func attrs(namespace, username, verb, resource, name, requestNamespace string) middleware.Attributes {
+	return middleware.Attributes{
+		Namespace:        namespace,
+		Username:         username,
+		Verb:             verb,
+		Resource:         resource,
+		Name:             name,
+		RequestNamespace: requestNamespace,
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	ctx := context.TODO()
+
+	for _, user := range []*v1.User{
+		{ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "rbac-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: "rbac-a"}, IsAdmin: 1},
+	} {
+		assert.Nil(t, storeIns.Users().Create(ctx, user, metav1.CreateOptions{}))
+	}
+	assert.Nil(t, storeIns.Roles().Create(ctx, &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: "viewer", Namespace: "rbac-a"},
+		Rules:      []v1.PolicyRule{{Verbs: []string{v1.VerbGet, v1.VerbList}, Resources: []string{"users"}}},
+	}, metav1.CreateOptions{}))
+	assert.Nil(t, storeIns.RoleBindings().Create(ctx, &v1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "rbac-a"},
+		RoleRef:    "viewer",
+		Users:      []string{"alice"},
+	}, metav1.CreateOptions{}))
+	assert.Nil(t, storeIns.RoleBindings().Create(ctx, &v1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "admins", Namespace: metav1.NamespaceDefault},
+		RoleRef:    AdminRole,
+		Users:      []string{"user3"},
+	}, metav1.CreateOptions{}))
+
+	tests := []struct {
+		name    string
+		attrs   middleware.Attributes
+		allowed bool
+	}{
+		{
+			name:    "get itself",
+			attrs:   attrs("default", "user2", v1.VerbGet, "users", "user2", "default"),
+			allowed: true,
+		},
+		{
+			name:    "change its password",
+			attrs:   attrs("default", "user2", v1.VerbUpdate, "users/change-password", "user2", "default"),
+			allowed: true,
+		},
+		{
+			name:    "revoke its sessions",
+			attrs:   attrs("default", "user2", v1.VerbDelete, "users/sessions", "user2", "default"),
+			allowed: true,
+		},
+		{
+			name:  "get another user",
+			attrs: attrs("default", "user2", v1.VerbGet, "users", "user4", "default"),
+		},
+		{
+			name:  "delete itself",
+			attrs: attrs("default", "user2", v1.VerbDelete, "users", "user2", "default"),
+		},
+		{
+			name:  "create users",
+			attrs: attrs("default", "user2", v1.VerbCreate, "users", "", "default"),
+		},
+		{
+			name:  "unlock itself",
+			attrs: attrs("default", "user2", v1.VerbDelete, "users/lockout", "user2", "default"),
+		},
+		{
+			name:  "list users",
+			attrs: attrs("default", "user2", v1.VerbList, "users", "", "default"),
+		},
+		{
+			name:  "get itself in another namespace",
+			attrs: attrs("default", "user2", v1.VerbGet, "users", "user2", "rbac-a"),
+		},
+		{
+			name:    "admin role bound in the default namespace",
+			attrs:   attrs("default", "user3", v1.VerbDelete, "users", "alice", "rbac-a"),
+			allowed: true,
+		},
+		{
+			name:    "admin role bound in the default namespace on namespaces",
+			attrs:   attrs("default", "user3", v1.VerbCreate, "namespaces", "", ""),
+			allowed: true,
+		},
+		{
+			name:    "custom role",
+			attrs:   attrs("rbac-a", "alice", v1.VerbList, "users", "", "rbac-a"),
+			allowed: true,
+		},
+		{
+			name:  "custom role without the verb",
+			attrs: attrs("rbac-a", "alice", v1.VerbDelete, "users", "bob", "rbac-a"),
+		},
+		{
+			name:  "custom role in another namespace",
+			attrs: attrs("rbac-a", "alice", v1.VerbList, "users", "", "default"),
+		},
+		{
+			name:    "administrator in its namespace",
+			attrs:   attrs("rbac-a", "bob", v1.VerbDelete, "users", "alice", "rbac-a"),
+			allowed: true,
+		},
+		{
+			name:  "administrator outside its namespace",
+			attrs: attrs("rbac-a", "bob", v1.VerbGet, "users", "user2", "default"),
+		},
+		{
+			name:  "administrator on namespaces",
+			attrs: attrs("rbac-a", "bob", v1.VerbList, "namespaces", "", ""),
+		},
+		{
+			name:  "unknown user",
+			attrs: attrs("rbac-a", "carol", v1.VerbGet, "users", "carol", "rbac-a"),
+		},
+	}
+
+	a := NewAuthorizer(storeIns)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason, err := a.Authorize(ctx, tt.attrs)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.allowed, allowed, reason)
+			if !allowed {
+				assert.NotEmpty(t, reason)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Permissions(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	ctx := context.TODO()
+
+	assert.Nil(t, storeIns.RoleBindings().Create(ctx, &v1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "dangling", Namespace: metav1.NamespaceDefault},
+		RoleRef:    "missing",
+		Users:      []string{"user5"},
+	}, metav1.CreateOptions{}))
+
+	permissions, err := NewAuthorizer(storeIns).Permissions(ctx, metav1.NamespaceDefault, "user5")
+	assert.Nil(t, err)
+	assert.Len(t, permissions.Items, len(selfRules("user5")))
+	for _, p := range permissions.Items {
+		assert.Equal(t, metav1.NamespaceDefault, p.Namespace)
+		assert.Empty(t, p.Role)
+	}
+}
+
+func TestBootstrapRole(t *testing.T) {
+	role, ok := BootstrapRole("rbac-a", AdminRole)
+	assert.True(t, ok)
+	assert.Equal(t, "rbac-a", role.Namespace)
+	assert.True(t, role.Rules[0].Allows(v1.VerbDelete, "namespaces", "rbac-a"))
+
+	_, ok = BootstrapRole("rbac-a", "viewer")
+	assert.False(t, ok)
+}
@@ -17,8 +17,21 @@ package apiserver
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/dairongpeng/leona/api/apiserver/scheme"
+	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	"github.com/dairongpeng/leona/internal/apiserver/controller/v1/namespace"
+	"github.com/dairongpeng/leona/internal/apiserver/controller/v1/permission"
+	"github.com/dairongpeng/leona/internal/apiserver/controller/v1/role"
+	"github.com/dairongpeng/leona/internal/apiserver/controller/v1/rolebinding"
 	"github.com/dairongpeng/leona/internal/apiserver/controller/v1/user"
+	userv2 "github.com/dairongpeng/leona/internal/apiserver/controller/v2/user"
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
 	"github.com/dairongpeng/leona/internal/apiserver/store/mysql"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/auth"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/idempotency"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/ratelimit"
+	"github.com/dairongpeng/leona/pkg/core"
 	// custom gin validators.
 	_ "github.com/dairongpeng/leona/pkg/validator"
 )
@@ -29,27 +42,186 @@ func initRouter(g *gin.Engine) {
 	installMiddleware(g)
 	// 初始化api接口
 	installController(g)
+	// 初始化api文档
+	installOpenAPI(g)
 }
 
 // installMiddleware 初始化路由中间件
 func installMiddleware(g *gin.Engine) {
+	// the analytics records are stored once the requests are responded
+	g.Use(analytics.Recorder())
 }
 
 func installController(g *gin.Engine) *gin.Engine {
+	// populate the kind and apiVersion of the responses
+	core.SetObjectTyper(scheme.Scheme)
+
+	// Middlewares.
+	jwtStrategy, _ := newJWTAuth().(auth.JWTStrategy)
+	g.POST("/login", jwtStrategy.LoginHandler)
+	// the second step of the login when the user has enrolled an authenticator
+	g.POST("/login/mfa", newMFAAuth(jwtStrategy).LoginHandler)
+	g.POST("/logout", jwtStrategy.LogoutHandler)
+	// Refresh time can be longer than token timeout
+	g.POST("/refresh", jwtStrategy.RefreshHandler)
+	// the public keys verifying the tokens
+	g.GET("/.well-known/jwks.json", serveJWKS)
+
+	auto := newAutoAuth()
+
 	// v1 handlers, requiring authentication
 	storeIns, _ := mysql.GetMySQLFactoryOr(nil)
+	userController := user.NewUserController(storeIns)
+	permissionController := permission.NewPermissionController(storeIns)
+	roleController := role.NewRoleController(storeIns)
+	roleBindingController := rolebinding.NewRoleBindingController(storeIns)
+	// every authenticated request is authorized by the roles granted to the user
+	authorization := middleware.Authorization(rbac.NewAuthorizer(storeIns))
 	v1 := g.Group("/v1")
 	{
-		// user RESTful resource
+		// the forgotten passwords are reset by the tokens mailed to the users
+		v1.POST("/password-reset", userController.RequestPasswordReset)
+		v1.POST("/password-reset/confirm", userController.ConfirmPasswordReset)
+
+		// user RESTful resource in the default namespace
 		userv1 := v1.Group("/users")
 		{
-			userController := user.NewUserController(storeIns)
-			userv1.POST("", userController.Create)
-			userv1.DELETE(":name", userController.Delete) // admin api
+			userv1.POST("", idempotency.Handler(), userController.Create)
+			userv1.Use(auto.AuthFunc(), ratelimit.User(), authorization, idempotency.Handler())
+			userv1.DELETE(":name", userController.Delete)
 			userv1.PUT(":name/change-password", userController.ChangePassword)
+			userv1.DELETE(":name/lockout", userController.Unlock)
+			userv1.GET(":name/permissions", permissionController.List)
+			userv1.GET(":name/sessions", userController.ListSessions)
+			userv1.POST(":name/verify-email", userController.VerifyEmail)
+			userv1.POST(":name/mfa", userController.EnrollMFA)
+			userv1.PUT(":name/mfa", userController.ConfirmMFA)
+			userv1.DELETE(":name/mfa", userController.ResetMFA)
+			userv1.DELETE(":name/sessions", userController.RevokeSessions)
+			userv1.DELETE(":name/sessions/:id", userController.RevokeSession)
 			userv1.PUT(":name", userController.Update)
 			userv1.GET("", userController.List)
-			userv1.GET(":name", userController.Get) // admin api
+			userv1.GET(":name", userController.Get)
+		}
+
+		// role RESTful resource in the default namespace
+		rolev1 := v1.Group("/roles", auto.AuthFunc(), ratelimit.User(), authorization, idempotency.Handler())
+		{
+			rolev1.POST("", roleController.Create)
+			rolev1.DELETE(":name", roleController.Delete)
+			rolev1.PUT(":name", roleController.Update)
+			rolev1.GET("", roleController.List)
+			rolev1.GET(":name", roleController.Get)
+		}
+
+		// rolebinding RESTful resource in the default namespace
+		rolebindingv1 := v1.Group("/rolebindings", auto.AuthFunc(), ratelimit.User(), authorization, idempotency.Handler())
+		{
+			rolebindingv1.POST("", roleBindingController.Create)
+			rolebindingv1.DELETE(":name", roleBindingController.Delete)
+			rolebindingv1.PUT(":name", roleBindingController.Update)
+			rolebindingv1.GET("", roleBindingController.List)
+			rolebindingv1.GET(":name", roleBindingController.Get)
+		}
+
+		// namespace RESTful resource
+		namespacev1 := v1.Group("/namespaces", auto.AuthFunc(), ratelimit.User(), authorization, idempotency.Handler())
+		{
+			namespaceController := namespace.NewNamespaceController(storeIns)
+			namespacev1.POST("", namespaceController.Create)
+			namespacev1.DELETE(":ns", namespaceController.Delete)
+			namespacev1.PUT(":ns", namespaceController.Update)
+			namespacev1.GET("", namespaceController.List)
+			namespacev1.GET(":ns", namespaceController.Get)
+
+			// user RESTful resource scoped to a namespace
+			nsuserv1 := namespacev1.Group(":ns/users")
+			{
+				nsuserv1.POST("", userController.Create)
+				nsuserv1.DELETE(":name", userController.Delete)
+				nsuserv1.PUT(":name/change-password", userController.ChangePassword)
+				nsuserv1.DELETE(":name/lockout", userController.Unlock)
+				nsuserv1.GET(":name/permissions", permissionController.List)
+				nsuserv1.GET(":name/sessions", userController.ListSessions)
+				nsuserv1.POST(":name/verify-email", userController.VerifyEmail)
+				nsuserv1.POST(":name/mfa", userController.EnrollMFA)
+				nsuserv1.PUT(":name/mfa", userController.ConfirmMFA)
+				nsuserv1.DELETE(":name/mfa", userController.ResetMFA)
+				nsuserv1.DELETE(":name/sessions", userController.RevokeSessions)
+				nsuserv1.DELETE(":name/sessions/:id", userController.RevokeSession)
+				nsuserv1.PUT(":name", userController.Update)
+				nsuserv1.GET("", userController.List)
+				nsuserv1.GET(":name", userController.Get)
+			}
+
+			// role RESTful resource scoped to a namespace
+			nsrolev1 := namespacev1.Group(":ns/roles")
+			{
+				nsrolev1.POST("", roleController.Create)
+				nsrolev1.DELETE(":name", roleController.Delete)
+				nsrolev1.PUT(":name", roleController.Update)
+				nsrolev1.GET("", roleController.List)
+				nsrolev1.GET(":name", roleController.Get)
+			}
+
+			// rolebinding RESTful resource scoped to a namespace
+			nsrolebindingv1 := namespacev1.Group(":ns/rolebindings")
+			{
+				nsrolebindingv1.POST("", roleBindingController.Create)
+				nsrolebindingv1.DELETE(":name", roleBindingController.Delete)
+				nsrolebindingv1.PUT(":name", roleBindingController.Update)
+				nsrolebindingv1.GET("", roleBindingController.List)
+				nsrolebindingv1.GET(":name", roleBindingController.Get)
+			}
+		}
+	}
+
+	// v2 handlers, the users are served in the restructured v2 schema over the same storage.
+	// Deleting and changing password are not versioned, they are served by the v1 handlers.
+	v2 := g.Group("/v2")
+	{
+		userv2Controller := userv2.NewUserController(storeIns)
+
+		// user RESTful resource in the default namespace
+		userGroup := v2.Group("/users")
+		{
+			userGroup.POST("", idempotency.Handler(), userv2Controller.Create)
+			userGroup.Use(auto.AuthFunc(), ratelimit.User(), authorization, idempotency.Handler())
+			userGroup.DELETE(":name", userController.Delete)
+			userGroup.PUT(":name/change-password", userController.ChangePassword)
+			userGroup.DELETE(":name/lockout", userController.Unlock)
+			userGroup.GET(":name/permissions", permissionController.List)
+			userGroup.GET(":name/sessions", userController.ListSessions)
+			userGroup.POST(":name/verify-email", userController.VerifyEmail)
+			userGroup.POST(":name/mfa", userController.EnrollMFA)
+			userGroup.PUT(":name/mfa", userController.ConfirmMFA)
+			userGroup.DELETE(":name/mfa", userController.ResetMFA)
+			userGroup.DELETE(":name/sessions", userController.RevokeSessions)
+			userGroup.DELETE(":name/sessions/:id", userController.RevokeSession)
+			userGroup.PUT(":name", userv2Controller.Update)
+			userGroup.GET("", userv2Controller.List)
+			userGroup.GET(":name", userv2Controller.Get)
+		}
+
+		// user RESTful resource scoped to a namespace
+		nsuserGroup := v2.Group("/namespaces/:ns/users",
+			auto.AuthFunc(), ratelimit.User(), authorization, idempotency.Handler())
+		{
+			nsuserGroup.POST("", userv2Controller.Create)
+			nsuserGroup.DELETE(":name", userController.Delete)
+			nsuserGroup.PUT(":name/change-password", userController.ChangePassword)
+			nsuserGroup.DELETE(":name/lockout", userController.Unlock)
+			nsuserGroup.GET(":name/permissions", permissionController.List)
+			nsuserGroup.GET(":name/sessions", userController.ListSessions)
+			nsuserGroup.POST(":name/verify-email", userController.VerifyEmail)
+			nsuserGroup.POST(":name/mfa", userController.EnrollMFA)
+			nsuserGroup.PUT(":name/mfa", userController.ConfirmMFA)
+			nsuserGroup.DELETE(":name/mfa", userController.ResetMFA)
+			nsuserGroup.DELETE(":name/sessions", userController.RevokeSessions)
+			nsuserGroup.DELETE(":name/sessions/:id", userController.RevokeSession)
+			nsuserGroup.PUT(":name", userv2Controller.Update)
+			nsuserGroup.GET("", userv2Controller.List)
+			nsuserGroup.GET(":name", userv2Controller.Get)
 		}
 	}
 
@@ -17,26 +17,38 @@ package apiserver
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/dairongpeng/leona/internal/apiserver/analytics"
+	schema "github.com/dairongpeng/leona/pkg/analytics"
 	"github.com/dairongpeng/leona/pkg/storage"
 
 	pb "github.com/dairongpeng/leona/api/proto/apiserver/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/dairongpeng/leona/internal/apiserver/account"
 	"github.com/dairongpeng/leona/internal/apiserver/config"
 	cachev1 "github.com/dairongpeng/leona/internal/apiserver/controller/v1/cache"
+	"github.com/dairongpeng/leona/internal/apiserver/keyring"
+	"github.com/dairongpeng/leona/internal/apiserver/ldapuser"
+	"github.com/dairongpeng/leona/internal/apiserver/lockout"
+	"github.com/dairongpeng/leona/internal/apiserver/mfa"
+	"github.com/dairongpeng/leona/internal/apiserver/password"
+	"github.com/dairongpeng/leona/internal/apiserver/session"
 	"github.com/dairongpeng/leona/internal/apiserver/store"
 	"github.com/dairongpeng/leona/internal/apiserver/store/mysql"
 	genericoptions "github.com/dairongpeng/leona/internal/pkg/options"
 	genericapiserver "github.com/dairongpeng/leona/internal/pkg/server"
+	"github.com/dairongpeng/leona/pkg/directory"
 	"github.com/dairongpeng/leona/pkg/log"
 	"github.com/dairongpeng/leona/pkg/shutdown"
 	"github.com/dairongpeng/leona/pkg/shutdown/shutdownmanagers/posixsignal"
+	"github.com/dairongpeng/leona/pkg/tracing"
 )
 
 // RedisKeyPrefix defines the prefix key in redis for analytics data.
-const RedisKeyPrefix = "analytics-"
+const RedisKeyPrefix = schema.KeyPrefix
 
 type apiServer struct {
 	gs               *shutdown.GracefulShutdown
@@ -44,6 +56,8 @@ type apiServer struct {
 	gRPCAPIServer    *grpcAPIServer
 	genericAPIServer *genericapiserver.GenericAPIServer
 	analyticsOptions *analytics.AnalyticsOptions
+	lockoutOptions   *lockout.LockoutOptions
+	ldapOptions      *ldapuser.LDAPOptions
 	redisCancelFunc  context.CancelFunc
 }
 
@@ -66,6 +80,48 @@ func createAPIServer(cfg *config.Config) (*apiServer, error) {
 	// 对优雅关停的实例添加监听信号
 	gs.AddShutdownManager(posixsignal.NewPosixSignalManager())
 
+	// 初始化链路追踪，未启用时只传递上游的 trace context
+	if err := tracing.Init(cfg.TracingOptions, "leona-apiserver"); err != nil {
+		return nil, err
+	}
+
+	// 设置密码策略
+	passwordPolicy, err := password.NewPolicy(cfg.PasswordOptions)
+	if err != nil {
+		return nil, err
+	}
+	password.SetPolicy(passwordPolicy)
+
+	// 初始化会话管理，令牌在签发后最长可以使用 timeout + max-refresh
+	session.NewManager(&storage.RedisCluster{}, cfg.JwtOptions.Timeout+cfg.JwtOptions.MaxRefresh)
+
+	// 非对称签名算法使用定期轮换的密钥签发令牌，公钥通过 /.well-known/jwks.json 发布
+	if cfg.JwtOptions.SigningAlgorithm != "HS256" {
+		if _, err := keyring.NewRing(&storage.RedisCluster{}, cfg.JwtOptions.SigningAlgorithm,
+			cfg.JwtOptions.KeyEncryptionKey, cfg.JwtOptions.RotationPeriod, cfg.JwtOptions.RotationOverlap); err != nil {
+			return nil, err
+		}
+	}
+
+	// 发送邮箱验证及密码重置邮件，token 的签名密钥默认由 jwt 密钥派生，与 jwt 令牌的签名互不通用
+	if cfg.MailOptions.Enable {
+		if cfg.MailOptions.Secret == "" {
+			cfg.MailOptions.Secret = cfg.JwtOptions.Key
+		}
+		if _, err := account.NewManager(cfg.MailOptions, &storage.RedisCluster{}, cfg.MailOptions.Mailer()); err != nil {
+			return nil, err
+		}
+	}
+
+	// 两步验证，TOTP 密钥使用单独的 mfa 密钥加密，未配置 mfa 密钥时不启用两步验证
+	if cfg.MFAOptions.Key != "" {
+		if _, err := mfa.NewManager(cfg.MFAOptions, &storage.RedisCluster{}); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Warn("mfa.key is not set, the two-factor authentication is disabled")
+	}
+
 	// 构建通用的配置
 	genericConfig, err := buildGenericConfig(cfg)
 	if err != nil {
@@ -96,6 +152,8 @@ func createAPIServer(cfg *config.Config) (*apiServer, error) {
 		genericAPIServer: genericServer,
 		gRPCAPIServer:    extraServer,
 		analyticsOptions: cfg.AnalyticsOptions,
+		lockoutOptions:   cfg.LockoutOptions,
+		ldapOptions:      cfg.LDAPOptions,
 	}
 
 	return server, nil
@@ -109,14 +167,42 @@ func (s *apiServer) PrepareRun() preparedAPIServer {
 		analyticsIns.Start()
 	}
 
+	// 开启登录失败锁定
+	if s.lockoutOptions.Enable {
+		lockout.NewLockout(s.lockoutOptions, &storage.RedisCluster{})
+	}
+
+	// 开启 LDAP 认证，并定期将目录用户同步到用户存储
+	if s.ldapOptions.Enable {
+		ldapuser.NewMirror(directory.New(s.ldapOptions.DirectoryConfig()), store.Client(),
+			s.ldapOptions.Namespace, s.ldapOptions.SyncInterval).Start()
+	}
+
 	// 初始化路由配置
 	initRouter(s.genericAPIServer.Engine)
 
 	// 初始化redis数据库
 	s.initRedisStore()
 
+	// 注册就绪检查，依赖的存储及 GRPC 服务不可用时 /readyz 返回失败
+	s.installHealthChecks()
+
 	// 监听到信号后，执行回调，做一些收尾清理工作，优雅关停
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
+		// 关停开始时就绪检查即失败，负载均衡不再转发新的请求
+		s.genericAPIServer.HealthChecks.SetShuttingDown()
+
+		if s.ldapOptions.Enable {
+			ldapuser.GetMirror().Stop()
+		}
+
+		// 导出尚未发送的 span
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracing.Shutdown(ctx); err != nil {
+			log.Warnf("shutdown tracing failed: %s", err.Error())
+		}
+
 		mysqlStore, _ := mysql.GetMySQLFactoryOr(nil)
 		if mysqlStore != nil {
 			return mysqlStore.Close()
@@ -170,7 +256,11 @@ func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 	//if err != nil {
 	//	log.Fatalf("Failed to generate credentials %s", err.Error())
 	//}
-	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(c.MaxMsgSize)}
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(c.MaxMsgSize),
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor()),
+	}
 	grpcServer := grpc.NewServer(opts...)
 
 	storeIns, _ := mysql.GetMySQLFactoryOr(c.mysqlOptions)
@@ -185,7 +275,7 @@ func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 
 	reflection.Register(grpcServer)
 
-	return &grpcAPIServer{grpcServer, c.Addr}, nil
+	return &grpcAPIServer{Server: grpcServer, address: c.Addr}, nil
 }
 
 func buildGenericConfig(cfg *config.Config) (genericConfig *genericapiserver.Config, lastErr error) {
@@ -198,6 +288,18 @@ func buildGenericConfig(cfg *config.Config) (genericConfig *genericapiserver.Con
 		return
 	}
 
+	if lastErr = cfg.JwtOptions.ApplyTo(genericConfig); lastErr != nil {
+		return
+	}
+
+	if lastErr = cfg.RateLimitOptions.ApplyTo(genericConfig); lastErr != nil {
+		return
+	}
+
+	if lastErr = cfg.IdempotencyOptions.ApplyTo(genericConfig); lastErr != nil {
+		return
+	}
+
 	//if lastErr = cfg.SecureServing.ApplyTo(genericConfig); lastErr != nil {
 	//	return
 	//}
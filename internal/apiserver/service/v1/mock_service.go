@@ -13,6 +13,7 @@
 // limitations under the License.
 
 // Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/dairongpeng/leona/internal/apiserver/service/v1 (interfaces: Service,UserSrv,NamespaceSrv,RoleSrv,RoleBindingSrv)
 
 // Package v1 is a generated GoMock package.
 package v1
@@ -22,7 +23,7 @@ import (
 	reflect "reflect"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
-	v10 "github.com/dairongpeng/leona/pkg/meta/v1"
+	v11 "github.com/dairongpeng/leona/pkg/meta/v1"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -49,6 +50,48 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Namespaces mocks base method.
+func (m *MockService) Namespaces() NamespaceSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Namespaces")
+	ret0, _ := ret[0].(NamespaceSrv)
+	return ret0
+}
+
+// Namespaces indicates an expected call of Namespaces.
+func (mr *MockServiceMockRecorder) Namespaces() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Namespaces", reflect.TypeOf((*MockService)(nil).Namespaces))
+}
+
+// RoleBindings mocks base method.
+func (m *MockService) RoleBindings() RoleBindingSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleBindings")
+	ret0, _ := ret[0].(RoleBindingSrv)
+	return ret0
+}
+
+// RoleBindings indicates an expected call of RoleBindings.
+func (mr *MockServiceMockRecorder) RoleBindings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleBindings", reflect.TypeOf((*MockService)(nil).RoleBindings))
+}
+
+// Roles mocks base method.
+func (m *MockService) Roles() RoleSrv {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Roles")
+	ret0, _ := ret[0].(RoleSrv)
+	return ret0
+}
+
+// Roles indicates an expected call of Roles.
+func (mr *MockServiceMockRecorder) Roles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Roles", reflect.TypeOf((*MockService)(nil).Roles))
+}
+
 // Users mocks base method.
 func (m *MockService) Users() UserSrv {
 	m.ctrl.T.Helper()
@@ -101,7 +144,7 @@ func (mr *MockUserSrvMockRecorder) ChangePassword(arg0, arg1 interface{}) *gomoc
 }
 
 // Create mocks base method.
-func (m *MockUserSrv) Create(arg0 context.Context, arg1 *v1.User, arg2 v10.CreateOptions) error {
+func (m *MockUserSrv) Create(arg0 context.Context, arg1 *v1.User, arg2 v11.CreateOptions) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
@@ -115,66 +158,81 @@ func (mr *MockUserSrvMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.
 }
 
 // Delete mocks base method.
-func (m *MockUserSrv) Delete(arg0 context.Context, arg1 string, arg2 v10.DeleteOptions) error {
+func (m *MockUserSrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v11.DeleteOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockUserSrvMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUserSrvMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserSrv)(nil).Delete), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserSrv)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// Export mocks base method.
+func (m *MockUserSrv) Export(arg0 context.Context, arg1 string, arg2 v11.ListOptions, arg3 v11.ExportOptions) (*v1.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUserSrvMockRecorder) Export(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUserSrv)(nil).Export), arg0, arg1, arg2, arg3)
 }
 
 // Get mocks base method.
-func (m *MockUserSrv) Get(arg0 context.Context, arg1 string, arg2 v10.GetOptions) (*v1.User, error) {
+func (m *MockUserSrv) Get(arg0 context.Context, arg1, arg2 string, arg3 v11.GetOptions) (*v1.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*v1.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockUserSrvMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUserSrvMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserSrv)(nil).Get), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserSrv)(nil).Get), arg0, arg1, arg2, arg3)
 }
 
 // List mocks base method.
-func (m *MockUserSrv) List(arg0 context.Context, arg1 v10.ListOptions) (*v1.UserList, error) {
+func (m *MockUserSrv) List(arg0 context.Context, arg1 string, arg2 v11.ListOptions) (*v1.UserList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*v1.UserList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockUserSrvMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserSrv)(nil).List), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserSrv)(nil).List), arg0, arg1, arg2)
 }
 
 // ListWithBadPerformance mocks base method.
-func (m *MockUserSrv) ListWithBadPerformance(arg0 context.Context, arg1 v10.ListOptions) (*v1.UserList, error) {
+func (m *MockUserSrv) ListWithBadPerformance(arg0 context.Context, arg1 string, arg2 v11.ListOptions) (*v1.UserList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListWithBadPerformance", arg0, arg1)
+	ret := m.ctrl.Call(m, "ListWithBadPerformance", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*v1.UserList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListWithBadPerformance indicates an expected call of ListWithBadPerformance.
-func (mr *MockUserSrvMockRecorder) ListWithBadPerformance(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserSrvMockRecorder) ListWithBadPerformance(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithBadPerformance", reflect.TypeOf((*MockUserSrv)(nil).ListWithBadPerformance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithBadPerformance", reflect.TypeOf((*MockUserSrv)(nil).ListWithBadPerformance), arg0, arg1, arg2)
 }
 
 // Update mocks base method.
-func (m *MockUserSrv) Update(arg0 context.Context, arg1 *v1.User, arg2 v10.UpdateOptions) error {
+func (m *MockUserSrv) Update(arg0 context.Context, arg1 *v1.User, arg2 v11.UpdateOptions) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
@@ -187,31 +245,140 @@ func (mr *MockUserSrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserSrv)(nil).Update), arg0, arg1, arg2)
 }
 
-// MockSecretSrv is a mock of SecretSrv interface.
-type MockSecretSrv struct {
+// MockNamespaceSrv is a mock of NamespaceSrv interface.
+type MockNamespaceSrv struct {
 	ctrl     *gomock.Controller
-	recorder *MockSecretSrvMockRecorder
+	recorder *MockNamespaceSrvMockRecorder
 }
 
-// MockSecretSrvMockRecorder is the mock recorder for MockSecretSrv.
-type MockSecretSrvMockRecorder struct {
-	mock *MockSecretSrv
+// MockNamespaceSrvMockRecorder is the mock recorder for MockNamespaceSrv.
+type MockNamespaceSrvMockRecorder struct {
+	mock *MockNamespaceSrv
 }
 
-// NewMockSecretSrv creates a new mock instance.
-func NewMockSecretSrv(ctrl *gomock.Controller) *MockSecretSrv {
-	mock := &MockSecretSrv{ctrl: ctrl}
-	mock.recorder = &MockSecretSrvMockRecorder{mock}
+// NewMockNamespaceSrv creates a new mock instance.
+func NewMockNamespaceSrv(ctrl *gomock.Controller) *MockNamespaceSrv {
+	mock := &MockNamespaceSrv{ctrl: ctrl}
+	mock.recorder = &MockNamespaceSrvMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockSecretSrv) EXPECT() *MockSecretSrvMockRecorder {
+func (m *MockNamespaceSrv) EXPECT() *MockNamespaceSrvMockRecorder {
 	return m.recorder
 }
 
+// Create mocks base method.
+func (m *MockNamespaceSrv) Create(arg0 context.Context, arg1 *v1.Namespace, arg2 v11.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockNamespaceSrvMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNamespaceSrv)(nil).Create), arg0, arg1, arg2)
+}
+
 // Delete mocks base method.
-func (m *MockSecretSrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v10.DeleteOptions) error {
+func (m *MockNamespaceSrv) Delete(arg0 context.Context, arg1 string, arg2 v11.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockNamespaceSrvMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNamespaceSrv)(nil).Delete), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockNamespaceSrv) Get(arg0 context.Context, arg1 string, arg2 v11.GetOptions) (*v1.Namespace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Namespace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockNamespaceSrvMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockNamespaceSrv)(nil).Get), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockNamespaceSrv) List(arg0 context.Context, arg1 v11.ListOptions) (*v1.NamespaceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*v1.NamespaceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockNamespaceSrvMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockNamespaceSrv)(nil).List), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockNamespaceSrv) Update(arg0 context.Context, arg1 *v1.Namespace, arg2 v11.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockNamespaceSrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockNamespaceSrv)(nil).Update), arg0, arg1, arg2)
+}
+
+// MockRoleSrv is a mock of RoleSrv interface.
+type MockRoleSrv struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleSrvMockRecorder
+}
+
+// MockRoleSrvMockRecorder is the mock recorder for MockRoleSrv.
+type MockRoleSrvMockRecorder struct {
+	mock *MockRoleSrv
+}
+
+// NewMockRoleSrv creates a new mock instance.
+func NewMockRoleSrv(ctrl *gomock.Controller) *MockRoleSrv {
+	mock := &MockRoleSrv{ctrl: ctrl}
+	mock.recorder = &MockRoleSrvMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleSrv) EXPECT() *MockRoleSrvMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRoleSrv) Create(arg0 context.Context, arg1 *v1.Role, arg2 v11.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRoleSrvMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRoleSrv)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockRoleSrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v11.DeleteOptions) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
@@ -219,50 +386,94 @@ func (m *MockSecretSrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v10
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockSecretSrvMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockRoleSrvMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRoleSrv)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// Get mocks base method.
+func (m *MockRoleSrv) Get(arg0 context.Context, arg1, arg2 string, arg3 v11.GetOptions) (*v1.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRoleSrvMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRoleSrv)(nil).Get), arg0, arg1, arg2, arg3)
+}
+
+// List mocks base method.
+func (m *MockRoleSrv) List(arg0 context.Context, arg1 string, arg2 v11.ListOptions) (*v1.RoleList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.RoleList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRoleSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSecretSrv)(nil).Delete), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRoleSrv)(nil).List), arg0, arg1, arg2)
 }
 
-// DeleteCollection mocks base method.
-func (m *MockSecretSrv) DeleteCollection(arg0 context.Context, arg1 string, arg2 []string, arg3 v10.DeleteOptions) error {
+// Update mocks base method.
+func (m *MockRoleSrv) Update(arg0 context.Context, arg1 *v1.Role, arg2 v11.UpdateOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCollection", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteCollection indicates an expected call of DeleteCollection.
-func (mr *MockSecretSrvMockRecorder) DeleteCollection(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// Update indicates an expected call of Update.
+func (mr *MockRoleSrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockSecretSrv)(nil).DeleteCollection), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRoleSrv)(nil).Update), arg0, arg1, arg2)
 }
 
-// MockPolicySrv is a mock of PolicySrv interface.
-type MockPolicySrv struct {
+// MockRoleBindingSrv is a mock of RoleBindingSrv interface.
+type MockRoleBindingSrv struct {
 	ctrl     *gomock.Controller
-	recorder *MockPolicySrvMockRecorder
+	recorder *MockRoleBindingSrvMockRecorder
 }
 
-// MockPolicySrvMockRecorder is the mock recorder for MockPolicySrv.
-type MockPolicySrvMockRecorder struct {
-	mock *MockPolicySrv
+// MockRoleBindingSrvMockRecorder is the mock recorder for MockRoleBindingSrv.
+type MockRoleBindingSrvMockRecorder struct {
+	mock *MockRoleBindingSrv
 }
 
-// NewMockPolicySrv creates a new mock instance.
-func NewMockPolicySrv(ctrl *gomock.Controller) *MockPolicySrv {
-	mock := &MockPolicySrv{ctrl: ctrl}
-	mock.recorder = &MockPolicySrvMockRecorder{mock}
+// NewMockRoleBindingSrv creates a new mock instance.
+func NewMockRoleBindingSrv(ctrl *gomock.Controller) *MockRoleBindingSrv {
+	mock := &MockRoleBindingSrv{ctrl: ctrl}
+	mock.recorder = &MockRoleBindingSrvMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPolicySrv) EXPECT() *MockPolicySrvMockRecorder {
+func (m *MockRoleBindingSrv) EXPECT() *MockRoleBindingSrvMockRecorder {
 	return m.recorder
 }
 
+// Create mocks base method.
+func (m *MockRoleBindingSrv) Create(arg0 context.Context, arg1 *v1.RoleBinding, arg2 v11.CreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRoleBindingSrvMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRoleBindingSrv)(nil).Create), arg0, arg1, arg2)
+}
+
 // Delete mocks base method.
-func (m *MockPolicySrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v10.DeleteOptions) error {
+func (m *MockRoleBindingSrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v11.DeleteOptions) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
@@ -270,21 +481,51 @@ func (m *MockPolicySrv) Delete(arg0 context.Context, arg1, arg2 string, arg3 v10
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockPolicySrvMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockRoleBindingSrvMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRoleBindingSrv)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// Get mocks base method.
+func (m *MockRoleBindingSrv) Get(arg0 context.Context, arg1, arg2 string, arg3 v11.GetOptions) (*v1.RoleBinding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.RoleBinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRoleBindingSrvMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPolicySrv)(nil).Delete), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRoleBindingSrv)(nil).Get), arg0, arg1, arg2, arg3)
 }
 
-// DeleteCollection mocks base method.
-func (m *MockPolicySrv) DeleteCollection(arg0 context.Context, arg1 string, arg2 []string, arg3 v10.DeleteOptions) error {
+// List mocks base method.
+func (m *MockRoleBindingSrv) List(arg0 context.Context, arg1 string, arg2 v11.ListOptions) (*v1.RoleBindingList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCollection", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.RoleBindingList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRoleBindingSrvMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRoleBindingSrv)(nil).List), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *MockRoleBindingSrv) Update(arg0 context.Context, arg1 *v1.RoleBinding, arg2 v11.UpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteCollection indicates an expected call of DeleteCollection.
-func (mr *MockPolicySrvMockRecorder) DeleteCollection(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// Update indicates an expected call of Update.
+func (mr *MockRoleBindingSrvMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockPolicySrv)(nil).DeleteCollection), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRoleBindingSrv)(nil).Update), arg0, arg1, arg2)
 }
@@ -0,0 +1,141 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// NamespaceSrv defines functions used to handle namespace request.
+type NamespaceSrv interface {
+	Create(ctx context.Context, namespace *v1.Namespace, opts metav1.CreateOptions) error
+	Update(ctx context.Context, namespace *v1.Namespace, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error)
+}
+
+type namespaceService struct {
+	store store.Factory
+}
+
+var _ NamespaceSrv = (*namespaceService)(nil)
+
+func newNamespaces(srv *service) *namespaceService {
+	return &namespaceService{store: srv.store}
+}
+
+func (n *namespaceService) Create(ctx context.Context, namespace *v1.Namespace, opts metav1.CreateOptions) error {
+	if _, err := n.store.Namespaces().Get(ctx, namespace.Name, metav1.GetOptions{}); err == nil {
+		return errors.WithCode(code.ErrNamespaceAlreadyExist, "namespace %s already exist", namespace.Name)
+	}
+
+	namespace.Phase = v1.NamespaceActive
+	if err := n.store.Namespaces().Create(ctx, namespace, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (n *namespaceService) Update(ctx context.Context, namespace *v1.Namespace, opts metav1.UpdateOptions) error {
+	if err := n.store.Namespaces().Update(ctx, namespace, opts); err != nil {
+		return updateError(err)
+	}
+
+	return nil
+}
+
+// Delete terminates the namespace, the namespace is marked as terminating first so no more
+// resources can be created in it, then it is removed together with all its resources.
+func (n *namespaceService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if name == metav1.NamespaceDefault {
+		return errors.WithCode(code.ErrNamespaceProtected, "namespace %s can not be deleted", name)
+	}
+
+	namespace, err := n.store.Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if opts.Version != 0 && opts.Version != namespace.Version {
+		return errors.WithCode(code.ErrResourceConflict, "namespace %s has been modified", name)
+	}
+
+	if namespace.Phase != v1.NamespaceTerminating {
+		namespace.Phase = v1.NamespaceTerminating
+		if err := n.store.Namespaces().Update(ctx, namespace, metav1.UpdateOptions{}); err != nil {
+			return updateError(err)
+		}
+
+		// the namespace is deleted in the version marked as terminating
+		if opts.Version != 0 {
+			opts.Version = namespace.Version
+		}
+	}
+
+	if err := n.store.Namespaces().Delete(ctx, name, opts); err != nil {
+		log.L(ctx).Errorf("delete namespace %s from storage failed: %s", name, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+func (n *namespaceService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error) {
+	namespace, err := n.store.Namespaces().Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return namespace, nil
+}
+
+func (n *namespaceService) List(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error) {
+	namespaces, err := n.store.Namespaces().List(ctx, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return namespaces, nil
+}
+
+// ensureNamespaceActive make sure resources are only created in an existing and active namespace.
+// The default namespace always exists.
+func ensureNamespaceActive(ctx context.Context, store store.Factory, name string) error {
+	if name == metav1.NamespaceDefault {
+		return nil
+	}
+
+	namespace, err := store.Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if namespace.Phase == v1.NamespaceTerminating {
+		return errors.WithCode(code.ErrNamespaceTerminating, "namespace %s is being terminated", name)
+	}
+
+	return nil
+}
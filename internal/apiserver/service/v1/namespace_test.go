@@ -0,0 +1,121 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+func Test_namespaceService_Delete_Cascade(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	srv := NewService(storeIns)
+	ctx := context.TODO()
+
+	err := srv.Namespaces().Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}}, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	user := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "user1", Namespace: "tenant-a"},
+		Nickname:   "user1",
+		Email:      "user1@foxmail.com",
+	}
+	assert.Nil(t, srv.Users().Create(ctx, user, metav1.CreateOptions{}))
+
+	// the same name can be reused in another namespace.
+	got, err := srv.Users().Get(ctx, "tenant-a", "user1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-a", got.Namespace)
+	_, err = srv.Users().Get(ctx, metav1.NamespaceDefault, "user1", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, srv.Namespaces().Delete(ctx, "tenant-a", metav1.DeleteOptions{}))
+
+	_, err = srv.Users().Get(ctx, "tenant-a", "user1", metav1.GetOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrUserNotFound))
+	_, err = srv.Users().Get(ctx, metav1.NamespaceDefault, "user1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	_, err = srv.Namespaces().Get(ctx, "tenant-a", metav1.GetOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrNamespaceNotFound))
+}
+
+func Test_namespaceService_Delete_Default(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	n := &namespaceService{store: mockFactory}
+
+	err := n.Delete(context.TODO(), metav1.NamespaceDefault, metav1.DeleteOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrNamespaceProtected))
+}
+
+func Test_userService_Create_NamespaceTerminating(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	mockNamespaceStore := store.NewMockNamespaceStore(ctrl)
+	mockFactory.EXPECT().Namespaces().Return(mockNamespaceStore)
+	mockNamespaceStore.EXPECT().Get(gomock.Any(), gomock.Eq("tenant-b"), gomock.Any()).Return(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-b"},
+		Phase:      v1.NamespaceTerminating,
+	}, nil)
+
+	u := &userService{store: mockFactory}
+	err := u.Create(context.TODO(), &v1.User{ObjectMeta: metav1.ObjectMeta{
+		Name:      "user1",
+		Namespace: "tenant-b",
+	}}, metav1.CreateOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrNamespaceTerminating))
+}
+
+func Test_userService_Export(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	srv := NewService(storeIns)
+	opts := metav1.ListOptions{Offset: pointer.ToInt64(0), Limit: pointer.ToInt64(10)}
+
+	users, err := srv.Users().Export(context.TODO(), metav1.NamespaceDefault, opts, metav1.ExportOptions{Export: true})
+	assert.Nil(t, err)
+	assert.Len(t, users.Items, 10)
+	for _, user := range users.Items {
+		assert.Zero(t, user.ID)
+		assert.Empty(t, user.InstanceID)
+		assert.Empty(t, user.Password)
+		assert.Equal(t, metav1.NamespaceDefault, user.Namespace)
+		assert.True(t, user.CreatedAt.IsZero())
+	}
+
+	// the stored users are left untouched.
+	got, err := srv.Users().Get(context.TODO(), metav1.NamespaceDefault, users.Items[0].Name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.NotZero(t, got.ID)
+
+	users, err = srv.Users().Export(context.TODO(), metav1.NamespaceDefault, opts, metav1.ExportOptions{Export: true, Exact: true})
+	assert.Nil(t, err)
+	assert.Equal(t, metav1.NamespaceDefault, users.Items[0].Namespace)
+	assert.NotEmpty(t, users.Items[0].Password)
+}
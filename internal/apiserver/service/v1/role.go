@@ -0,0 +1,113 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+// RoleSrv defines functions used to handle role request.
+type RoleSrv interface {
+	Create(ctx context.Context, role *v1.Role, opts metav1.CreateOptions) error
+	Update(ctx context.Context, role *v1.Role, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Role, error)
+	List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleList, error)
+}
+
+type roleService struct {
+	store store.Factory
+}
+
+var _ RoleSrv = (*roleService)(nil)
+
+func newRoles(srv *service) *roleService {
+	return &roleService{store: srv.store}
+}
+
+func (r *roleService) Create(ctx context.Context, role *v1.Role, opts metav1.CreateOptions) error {
+	if _, ok := rbac.BootstrapRole(role.Namespace, role.Name); ok {
+		return errors.WithCode(code.ErrRoleProtected, "role %s is a bootstrap role", role.Name)
+	}
+
+	if err := ensureNamespaceActive(ctx, r.store, role.Namespace); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Roles().Get(ctx, role.Namespace, role.Name, metav1.GetOptions{}); err == nil {
+		return errors.WithCode(code.ErrRoleAlreadyExist, "role %s already exist", role.Name)
+	}
+
+	if err := r.store.Roles().Create(ctx, role, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (r *roleService) Update(ctx context.Context, role *v1.Role, opts metav1.UpdateOptions) error {
+	if _, ok := rbac.BootstrapRole(role.Namespace, role.Name); ok {
+		return errors.WithCode(code.ErrRoleProtected, "role %s is a bootstrap role", role.Name)
+	}
+
+	if err := r.store.Roles().Update(ctx, role, opts); err != nil {
+		return updateError(err)
+	}
+
+	return nil
+}
+
+func (r *roleService) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	if _, ok := rbac.BootstrapRole(namespace, name); ok {
+		return errors.WithCode(code.ErrRoleProtected, "role %s is a bootstrap role", name)
+	}
+
+	if err := r.store.Roles().Delete(ctx, namespace, name, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the role, the bootstrap roles exist in every namespace.
+func (r *roleService) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Role, error) {
+	if role, ok := rbac.BootstrapRole(namespace, name); ok {
+		return role, nil
+	}
+
+	role, err := r.store.Roles().Get(ctx, namespace, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *roleService) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleList, error) {
+	roles, err := r.store.Roles().List(ctx, namespace, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return roles, nil
+}
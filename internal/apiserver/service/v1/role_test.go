@@ -0,0 +1,68 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+func Test_roleService_Bootstrap(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	srv := NewService(storeIns)
+	ctx := context.TODO()
+
+	role := &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: rbac.AdminRole, Namespace: metav1.NamespaceDefault},
+		Rules:      []v1.PolicyRule{{Verbs: []string{v1.VerbGet}, Resources: []string{"users"}}},
+	}
+	assert.True(t, errors.IsCode(srv.Roles().Create(ctx, role, metav1.CreateOptions{}), code.ErrRoleProtected))
+	assert.True(t, errors.IsCode(srv.Roles().Update(ctx, role, metav1.UpdateOptions{}), code.ErrRoleProtected))
+	err := srv.Roles().Delete(ctx, metav1.NamespaceDefault, rbac.AdminRole, metav1.DeleteOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrRoleProtected))
+
+	got, err := srv.Roles().Get(ctx, "tenant-r", rbac.AdminRole, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-r", got.Namespace)
+}
+
+func Test_roleService_Create(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	srv := NewService(storeIns)
+	ctx := context.TODO()
+
+	newRole := func(namespace string) *v1.Role {
+		return &v1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewer", Namespace: namespace},
+			Rules:      []v1.PolicyRule{{Verbs: []string{v1.VerbGet}, Resources: []string{"users"}}},
+		}
+	}
+
+	assert.Nil(t, srv.Roles().Create(ctx, newRole(metav1.NamespaceDefault), metav1.CreateOptions{}))
+	err := srv.Roles().Create(ctx, newRole(metav1.NamespaceDefault), metav1.CreateOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrRoleAlreadyExist))
+
+	err = srv.Roles().Create(ctx, newRole("tenant-missing"), metav1.CreateOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrNamespaceNotFound))
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+// RoleBindingSrv defines functions used to handle role binding request.
+type RoleBindingSrv interface {
+	Create(ctx context.Context, binding *v1.RoleBinding, opts metav1.CreateOptions) error
+	Update(ctx context.Context, binding *v1.RoleBinding, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.RoleBinding, error)
+	List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleBindingList, error)
+}
+
+type roleBindingService struct {
+	store store.Factory
+}
+
+var _ RoleBindingSrv = (*roleBindingService)(nil)
+
+func newRoleBindings(srv *service) *roleBindingService {
+	return &roleBindingService{store: srv.store}
+}
+
+func (r *roleBindingService) Create(ctx context.Context, binding *v1.RoleBinding, opts metav1.CreateOptions) error {
+	if err := ensureNamespaceActive(ctx, r.store, binding.Namespace); err != nil {
+		return err
+	}
+
+	if _, err := r.store.RoleBindings().Get(ctx, binding.Namespace, binding.Name, metav1.GetOptions{}); err == nil {
+		return errors.WithCode(code.ErrRoleBindingAlreadyExist, "role binding %s already exist", binding.Name)
+	}
+
+	if err := r.ensureRoleExist(ctx, binding); err != nil {
+		return err
+	}
+
+	if err := r.store.RoleBindings().Create(ctx, binding, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+func (r *roleBindingService) Update(ctx context.Context, binding *v1.RoleBinding, opts metav1.UpdateOptions) error {
+	if err := r.ensureRoleExist(ctx, binding); err != nil {
+		return err
+	}
+
+	if err := r.store.RoleBindings().Update(ctx, binding, opts); err != nil {
+		return updateError(err)
+	}
+
+	return nil
+}
+
+func (r *roleBindingService) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	if err := r.store.RoleBindings().Delete(ctx, namespace, name, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *roleBindingService) Get(
+	ctx context.Context,
+	namespace, name string,
+	opts metav1.GetOptions,
+) (*v1.RoleBinding, error) {
+	binding, err := r.store.RoleBindings().Get(ctx, namespace, name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return binding, nil
+}
+
+func (r *roleBindingService) List(
+	ctx context.Context,
+	namespace string,
+	opts metav1.ListOptions,
+) (*v1.RoleBindingList, error) {
+	bindings, err := r.store.RoleBindings().List(ctx, namespace, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return bindings, nil
+}
+
+// ensureRoleExist make sure the role binding refers to a bootstrap role or a role in its namespace.
+func (r *roleBindingService) ensureRoleExist(ctx context.Context, binding *v1.RoleBinding) error {
+	if _, ok := rbac.BootstrapRole(binding.Namespace, binding.RoleRef); ok {
+		return nil
+	}
+
+	if _, err := r.store.Roles().Get(ctx, binding.Namespace, binding.RoleRef, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/rbac"
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+func Test_roleBindingService_Create(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	srv := NewService(storeIns)
+	ctx := context.TODO()
+
+	binding := &v1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "editors", Namespace: metav1.NamespaceDefault},
+		RoleRef:    "editor",
+		Users:      []string{"user1"},
+	}
+	err := srv.RoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrRoleNotFound))
+
+	// the bootstrap roles can be bound without being created.
+	binding.RoleRef = rbac.AdminRole
+	assert.Nil(t, srv.RoleBindings().Create(ctx, binding, metav1.CreateOptions{}))
+
+	err = srv.RoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrRoleBindingAlreadyExist))
+
+	binding.RoleRef = "editor"
+	err = srv.RoleBindings().Update(ctx, binding, metav1.UpdateOptions{})
+	assert.True(t, errors.IsCode(err, code.ErrRoleNotFound))
+}
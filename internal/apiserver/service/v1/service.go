@@ -14,13 +14,20 @@
 
 package v1
 
-//go:generate mockgen -self_package=github.com/dairongpeng/leona/internal/parseserver/service/v1 -destination mock_service.go -package v1 github.com/dairongpeng/leona/internal/parseserver/service/v1 Service,UserSrv,SecretSrv,PolicySrv
+//go:generate mockgen -self_package=github.com/dairongpeng/leona/internal/apiserver/service/v1 -destination mock_service.go -package v1 github.com/dairongpeng/leona/internal/apiserver/service/v1 Service,UserSrv,NamespaceSrv,RoleSrv,RoleBindingSrv
 
-import "github.com/dairongpeng/leona/internal/apiserver/store"
+import (
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+)
 
 // Service defines functions used to return resource interface.
 type Service interface {
 	Users() UserSrv
+	Namespaces() NamespaceSrv
+	Roles() RoleSrv
+	RoleBindings() RoleBindingSrv
 }
 
 type service struct {
@@ -37,3 +44,25 @@ func NewService(store store.Factory) Service {
 func (s *service) Users() UserSrv {
 	return newUsers(s)
 }
+
+func (s *service) Namespaces() NamespaceSrv {
+	return newNamespaces(s)
+}
+
+func (s *service) Roles() RoleSrv {
+	return newRoles(s)
+}
+
+func (s *service) RoleBindings() RoleBindingSrv {
+	return newRoleBindings(s)
+}
+
+// updateError returns the error of a failed update, the conflicts with the concurrent writes are
+// responded as they are, the others are database errors.
+func updateError(err error) error {
+	if errors.IsCode(err, code.ErrResourceConflict) {
+		return err
+	}
+
+	return errors.WithCode(code.ErrDatabase, err.Error())
+}
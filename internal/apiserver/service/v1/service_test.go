@@ -82,3 +82,30 @@ func Test_service_Users(t *testing.T) {
 		})
 	}
 }
+
+func Test_service_Namespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := store.NewMockFactory(ctrl)
+	s := &service{
+		store: mockFactory,
+	}
+
+	tests := []struct {
+		name string
+		want NamespaceSrv
+	}{
+		{
+			name: "default",
+			want: newNamespaces(s),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Namespaces(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("service.Namespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,7 +16,6 @@ package v1
 
 import (
 	"context"
-	"regexp"
 	"sync"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
@@ -32,10 +31,11 @@ import (
 type UserSrv interface {
 	Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error
 	Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error
-	Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error
-	Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error)
-	List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
-	ListWithBadPerformance(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
+	Delete(ctx context.Context, namespace, username string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error)
+	List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error)
+	ListWithBadPerformance(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error)
+	Export(ctx context.Context, namespace string, opts metav1.ListOptions, exportOpts metav1.ExportOptions) (*v1.UserList, error)
 	ChangePassword(ctx context.Context, user *v1.User) error
 }
 
@@ -50,8 +50,8 @@ func newUsers(srv *service) *userService {
 }
 
 // List returns user list in the storage. This function has a good performance.
-func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
-	users, err := u.store.Users().List(ctx, opts)
+func (u *userService) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error) {
+	users, err := u.store.Users().List(ctx, namespace, opts)
 	if err != nil {
 		log.L(ctx).Errorf("list users from storage failed: %s", err.Error())
 
@@ -85,6 +85,7 @@ func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.Us
 					ID:         user.ID,
 					InstanceID: user.InstanceID,
 					Name:       user.Name,
+					Namespace:  user.Namespace,
 					Extend:     user.Extend,
 					CreatedAt:  user.CreatedAt,
 					UpdatedAt:  user.UpdatedAt,
@@ -122,8 +123,12 @@ func (u *userService) List(ctx context.Context, opts metav1.ListOptions) (*v1.Us
 }
 
 // ListWithBadPerformance returns user list in the storage. This function has a bad performance.
-func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
-	users, err := u.store.Users().List(ctx, opts)
+func (u *userService) ListWithBadPerformance(
+	ctx context.Context,
+	namespace string,
+	opts metav1.ListOptions,
+) (*v1.UserList, error) {
+	users, err := u.store.Users().List(ctx, namespace, opts)
 	if err != nil {
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
 	}
@@ -139,6 +144,7 @@ func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.Li
 			ObjectMeta: metav1.ObjectMeta{
 				ID:        user.ID,
 				Name:      user.Name,
+				Namespace: user.Namespace,
 				CreatedAt: user.CreatedAt,
 				UpdatedAt: user.UpdatedAt,
 			},
@@ -152,10 +158,41 @@ func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.Li
 	return &v1.UserList{ListMeta: users.ListMeta, Items: infos}, nil
 }
 
+// Export returns user list in the storage with the fields populated by the system stripped.
+func (u *userService) Export(
+	ctx context.Context,
+	namespace string,
+	opts metav1.ListOptions,
+	exportOpts metav1.ExportOptions,
+) (*v1.UserList, error) {
+	users, err := u.store.Users().List(ctx, namespace, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	infos := make([]*v1.User, 0, len(users.Items))
+	for _, user := range users.Items {
+		// copy the user, the storage may return the object it holds.
+		info := *user
+		info.PrepareForExport(exportOpts)
+		infos = append(infos, &info)
+	}
+
+	return &v1.UserList{ListMeta: users.ListMeta, Items: infos}, nil
+}
+
 func (u *userService) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
+	if user.Namespace == "" {
+		user.Namespace = metav1.NamespaceDefault
+	}
+
+	if err := ensureNamespaceActive(ctx, u.store, user.Namespace); err != nil {
+		return err
+	}
+
 	if err := u.store.Users().Create(ctx, user, opts); err != nil {
-		if match, _ := regexp.MatchString("Duplicate entry '.*' for key 'idx_name'", err.Error()); match {
-			return errors.WithCode(code.ErrUserAlreadyExist, err.Error())
+		if errors.IsCode(err, code.ErrUserAlreadyExist) {
+			return err
 		}
 
 		return errors.WithCode(code.ErrDatabase, err.Error())
@@ -164,16 +201,16 @@ func (u *userService) Create(ctx context.Context, user *v1.User, opts metav1.Cre
 	return nil
 }
 
-func (u *userService) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
-	if err := u.store.Users().Delete(ctx, username, opts); err != nil {
+func (u *userService) Delete(ctx context.Context, namespace, username string, opts metav1.DeleteOptions) error {
+	if err := u.store.Users().Delete(ctx, namespace, username, opts); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (u *userService) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
-	user, err := u.store.Users().Get(ctx, username, opts)
+func (u *userService) Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error) {
+	user, err := u.store.Users().Get(ctx, namespace, username, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -183,7 +220,7 @@ func (u *userService) Get(ctx context.Context, username string, opts metav1.GetO
 
 func (u *userService) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
 	if err := u.store.Users().Update(ctx, user, opts); err != nil {
-		return errors.WithCode(code.ErrDatabase, err.Error())
+		return updateError(err)
 	}
 
 	return nil
@@ -192,7 +229,7 @@ func (u *userService) Update(ctx context.Context, user *v1.User, opts metav1.Upd
 func (u *userService) ChangePassword(ctx context.Context, user *v1.User) error {
 	// Save changed fields.
 	if err := u.store.Users().Update(ctx, user, metav1.UpdateOptions{}); err != nil {
-		return errors.WithCode(code.ErrDatabase, err.Error())
+		return updateError(err)
 	}
 
 	return nil
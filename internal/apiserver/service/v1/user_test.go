@@ -44,8 +44,8 @@ func BenchmarkListUser(b *testing.B) {
 	}
 
 	for i := 0; i < b.N; i++ {
-		//_, _ = u.ListWithBadPerformance(context.TODO(), opts)
-		_, _ = u.List(context.TODO(), opts)
+		//_, _ = u.ListWithBadPerformance(context.TODO(), metav1.NamespaceDefault, opts)
+		_, _ = u.List(context.TODO(), metav1.NamespaceDefault, opts)
 	}
 }
 
@@ -0,0 +1,297 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session tracks the sessions started by logging in, and revokes the JWT tokens issued to them.
+package session
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the session keys in redis.
+const KeyPrefix = "session-"
+
+// Session is started by logging in, and lasts as long as its token is refreshed.
+// The id of the session is the `jti` claim of its token.
+type Session struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	Username  string    `json:"username"`
+	ClientIP  string    `json:"clientIP,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	LoginedAt time.Time `json:"loginedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SessionList is the whole list of the sessions of an user.
+type SessionList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Session `json:"items"`
+}
+
+// Store defines the redis operations used to keep the sessions and the revocation list.
+// It is implemented by storage.RedisCluster.
+type Store interface {
+	GetRawKey(key string) (string, error)
+	SetRawKey(key, value string, timeout time.Duration) error
+	DeleteRawKey(key string) bool
+	AddToSet(key, value string)
+	GetSet(key string) (map[string]string, error)
+	RemoveFromSet(key, value string)
+}
+
+// Manager keeps the sessions of the users and the revocation list of their tokens.
+// A nil Manager does not keep the sessions, and never revokes a token.
+type Manager struct {
+	store Store
+
+	// lifetime is how long a token can be used after it was issued, including refreshing it.
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+var manager *Manager
+
+// NewManager returns a new session manager, the lifetime is how long a token can be used after
+// it was issued, including refreshing it.
+func NewManager(store Store, lifetime time.Duration) *Manager {
+	manager = &Manager{
+		store:    store,
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+
+	return manager
+}
+
+// GetManager returns the existed session manager.
+// It is nil when the manager is not initialized.
+func GetManager() *Manager {
+	return manager
+}
+
+// storeFor returns the store running the commands in ctx, so that they are traced as a part of it.
+func (m *Manager) storeFor(ctx context.Context) Store {
+	if s, ok := m.store.(*storage.RedisCluster); ok {
+		return s.WithContext(ctx)
+	}
+
+	return m.store
+}
+
+// Create starts a session of the user which lasts until expiresAt unless it is refreshed.
+func (m *Manager) Create(
+	ctx context.Context,
+	namespace, username, clientIP, userAgent string,
+	expiresAt time.Time,
+) *Session {
+	s := &Session{
+		ID:        uuid.Must(uuid.NewV4()).String(),
+		Namespace: namespace,
+		Username:  username,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		LoginedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+	if m == nil {
+		return s
+	}
+	s.LoginedAt = m.now()
+
+	if err := m.save(ctx, s); err != nil {
+		log.Errorf("save session `%s` of user `%s/%s` failed: %s", s.ID, namespace, username, err.Error())
+
+		return s
+	}
+	m.storeFor(ctx).AddToSet(userKey(namespace, username), s.ID)
+
+	return s
+}
+
+// Refresh extends the session to expiresAt when its token is refreshed.
+func (m *Manager) Refresh(ctx context.Context, id string, expiresAt time.Time) {
+	if m == nil {
+		return
+	}
+
+	s, err := m.get(ctx, id)
+	if err != nil {
+		return
+	}
+
+	s.ExpiresAt = expiresAt
+	if err := m.save(ctx, s); err != nil {
+		log.Errorf("save session `%s` failed: %s", id, err.Error())
+	}
+}
+
+// List returns the sessions of the user, the latest first.
+func (m *Manager) List(ctx context.Context, namespace, username string) (*SessionList, error) {
+	list := &SessionList{Items: []*Session{}}
+	if m == nil {
+		return list, nil
+	}
+
+	ids, err := m.storeFor(ctx).GetSet(userKey(namespace, username))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, id := range ids {
+		s, err := m.get(ctx, id)
+		if err != nil {
+			// the session expired
+			m.storeFor(ctx).RemoveFromSet(userKey(namespace, username), id)
+
+			continue
+		}
+		list.Items = append(list.Items, s)
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].LoginedAt.After(list.Items[j].LoginedAt)
+	})
+	list.TotalCount = int64(len(list.Items))
+
+	return list, nil
+}
+
+// Revoke ends the session of the user, its token is rejected from now on.
+func (m *Manager) Revoke(ctx context.Context, namespace, username, id string) error {
+	if m == nil {
+		return errors.WithCode(code.ErrSessionNotFound, "session `%s` not found", id)
+	}
+
+	s, err := m.get(ctx, id)
+	if err != nil || s.Namespace != namespace || s.Username != username {
+		return errors.WithCode(code.ErrSessionNotFound, "session `%s` not found", id)
+	}
+
+	m.revoke(ctx, namespace, username, id)
+
+	return nil
+}
+
+// RevokeAll ends all the sessions of the user, the tokens issued to the user before are rejected from now on.
+func (m *Manager) RevokeAll(ctx context.Context, namespace, username string) error {
+	if m == nil {
+		return nil
+	}
+
+	// the tokens issued before are rejected even if their sessions are not kept. The time is kept in
+	// seconds as the `iat` claim, so that the tokens issued later in the same second are accepted, the
+	// sessions started earlier in that second are revoked one by one below.
+	before := strconv.FormatInt(m.now().Unix(), 10)
+	if err := m.storeFor(ctx).SetRawKey(revokedBeforeKey(namespace, username), before, m.lifetime); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	ids, err := m.storeFor(ctx).GetSet(userKey(namespace, username))
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, id := range ids {
+		m.revoke(ctx, namespace, username, id)
+	}
+
+	return nil
+}
+
+// Revoked reports whether the token of the session, issued to the user at loginedAt, is revoked.
+func (m *Manager) Revoked(ctx context.Context, namespace, username, id string, loginedAt time.Time) bool {
+	if m == nil {
+		return false
+	}
+
+	if id != "" {
+		if _, err := m.storeFor(ctx).GetRawKey(revokedKey(id)); err == nil {
+			return true
+		}
+	}
+
+	value, err := m.storeFor(ctx).GetRawKey(revokedBeforeKey(namespace, username))
+	if err != nil {
+		return false
+	}
+	before, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return loginedAt.Unix() < before
+}
+
+func (m *Manager) revoke(ctx context.Context, namespace, username, id string) {
+	// the token can not be used longer than the lifetime, so is the revocation kept
+	if err := m.storeFor(ctx).SetRawKey(revokedKey(id), namespace+"/"+username, m.lifetime); err != nil {
+		log.Errorf("revoke session `%s` of user `%s/%s` failed: %s", id, namespace, username, err.Error())
+	}
+	m.storeFor(ctx).DeleteRawKey(sessionKey(id))
+	m.storeFor(ctx).RemoveFromSet(userKey(namespace, username), id)
+}
+
+func (m *Manager) get(ctx context.Context, id string) (*Session, error) {
+	value, err := m.storeFor(ctx).GetRawKey(sessionKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (m *Manager) save(ctx context.Context, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return m.storeFor(ctx).SetRawKey(sessionKey(s.ID), string(data), m.lifetime)
+}
+
+func sessionKey(id string) string {
+	return KeyPrefix + id
+}
+
+func revokedKey(id string) string {
+	return KeyPrefix + "revoked-" + id
+}
+
+func revokedBeforeKey(namespace, username string) string {
+	return KeyPrefix + "revoked-before-" + namespace + "/" + username
+}
+
+func userKey(namespace, username string) string {
+	return KeyPrefix + "user-" + namespace + "/" + username
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// fakeStore keeps the keys in memory, the expirations are ignored.
+type fakeStore struct {
+	values map[string]string
+	sets   map[string]map[string]bool
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{values: map[string]string{}, sets: map[string]map[string]bool{}}
+}
+
+func (s *fakeStore) GetRawKey(key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *fakeStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *fakeStore) DeleteRawKey(key string) bool {
+	_, ok := s.values[key]
+	delete(s.values, key)
+
+	return ok
+}
+
+func (s *fakeStore) AddToSet(key, value string) {
+	if s.sets[key] == nil {
+		s.sets[key] = map[string]bool{}
+	}
+	s.sets[key][value] = true
+}
+
+func (s *fakeStore) GetSet(key string) (map[string]string, error) {
+	result := map[string]string{}
+	i := 0
+	for value := range s.sets[key] {
+		result[strconv.Itoa(i)] = value
+		i++
+	}
+
+	return result, nil
+}
+
+func (s *fakeStore) RemoveFromSet(key, value string) {
+	delete(s.sets[key], value)
+}
+
+func newTestManager() (*Manager, *fakeStore, *time.Time) {
+	now := time.Unix(1600000000, 0)
+	store := newFakeStore()
+	m := NewManager(store, 2*time.Hour)
+	m.now = func() time.Time { return now }
+
+	return m, store, &now
+}
+
+func TestManager_Revoke(t *testing.T) {
+	ctx := context.Background()
+	m, _, now := newTestManager()
+
+	first := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", now.Add(time.Hour))
+	*now = now.Add(time.Minute)
+	second := m.Create(ctx, "default", "admin", "10.0.0.2", "curl", now.Add(time.Hour))
+	m.Create(ctx, "default", "root", "10.0.0.1", "curl", now.Add(time.Hour))
+
+	list, err := m.List(ctx, "default", "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), list.TotalCount)
+	assert.Equal(t, second.ID, list.Items[0].ID)
+	assert.Equal(t, first.ID, list.Items[1].ID)
+
+	// the session of another user can not be revoked
+	err = m.Revoke(ctx, "default", "root", first.ID)
+	assert.True(t, errors.IsCode(err, code.ErrSessionNotFound))
+	assert.False(t, m.Revoked(ctx, "default", "admin", first.ID, first.LoginedAt))
+
+	assert.NoError(t, m.Revoke(ctx, "default", "admin", first.ID))
+	assert.True(t, m.Revoked(ctx, "default", "admin", first.ID, first.LoginedAt))
+	assert.False(t, m.Revoked(ctx, "default", "admin", second.ID, second.LoginedAt))
+
+	list, _ = m.List(ctx, "default", "admin")
+	assert.Equal(t, int64(1), list.TotalCount)
+
+	err = m.Revoke(ctx, "default", "admin", first.ID)
+	assert.True(t, errors.IsCode(err, code.ErrSessionNotFound))
+}
+
+func TestManager_RevokeAll(t *testing.T) {
+	ctx := context.Background()
+	m, _, now := newTestManager()
+
+	s := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", now.Add(time.Hour))
+	*now = now.Add(time.Minute)
+	assert.NoError(t, m.RevokeAll(ctx, "default", "admin"))
+
+	assert.True(t, m.Revoked(ctx, "default", "admin", s.ID, s.LoginedAt))
+	// the tokens without a session issued before are revoked too
+	assert.True(t, m.Revoked(ctx, "default", "admin", "", s.LoginedAt))
+	assert.False(t, m.Revoked(ctx, "default", "root", "", s.LoginedAt))
+
+	// the sessions started afterwards are not affected
+	after := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", now.Add(time.Hour))
+	assert.False(t, m.Revoked(ctx, "default", "admin", after.ID, after.LoginedAt))
+
+	list, _ := m.List(ctx, "default", "admin")
+	assert.Equal(t, int64(1), list.TotalCount)
+
+	// the `iat` claim is in seconds, the sessions started earlier in the same second are revoked, the
+	// ones started later in that second are not
+	*now = now.Add(time.Minute + 200*time.Millisecond)
+	earlier := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", now.Add(time.Hour))
+	*now = now.Add(300 * time.Millisecond)
+	assert.NoError(t, m.RevokeAll(ctx, "default", "admin"))
+	*now = now.Add(300 * time.Millisecond)
+	later := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", now.Add(time.Hour))
+
+	assert.True(t, m.Revoked(ctx, "default", "admin", earlier.ID, earlier.LoginedAt.Truncate(time.Second)))
+	assert.False(t, m.Revoked(ctx, "default", "admin", later.ID, later.LoginedAt.Truncate(time.Second)))
+}
+
+func TestManager_Refresh(t *testing.T) {
+	ctx := context.Background()
+	m, store, now := newTestManager()
+
+	s := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", now.Add(time.Hour))
+	m.Refresh(ctx, s.ID, now.Add(2*time.Hour))
+
+	list, _ := m.List(ctx, "default", "admin")
+	assert.Equal(t, now.Add(2*time.Hour).Unix(), list.Items[0].ExpiresAt.Unix())
+
+	// the expired sessions are dropped from the list
+	store.DeleteRawKey(sessionKey(s.ID))
+	list, _ = m.List(ctx, "default", "admin")
+	assert.Empty(t, list.Items)
+	assert.Empty(t, store.sets[userKey("default", "admin")])
+}
+
+func TestManager_Nil(t *testing.T) {
+	ctx := context.Background()
+	var m *Manager
+
+	s := m.Create(ctx, "default", "admin", "10.0.0.1", "curl", time.Now().Add(time.Hour))
+	assert.NotEmpty(t, s.ID)
+	assert.False(t, m.Revoked(ctx, "default", "admin", s.ID, s.LoginedAt))
+	assert.NoError(t, m.RevokeAll(ctx, "default", "admin"))
+
+	list, err := m.List(ctx, "default", "admin")
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
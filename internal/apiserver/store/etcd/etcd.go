@@ -28,7 +28,9 @@ import (
 
 	"github.com/dairongpeng/leona/internal/apiserver/store"
 	genericoptions "github.com/dairongpeng/leona/internal/pkg/options"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
 	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 )
 
 // EtcdCreateEventFunc defines etcd create event functon handler.
@@ -63,6 +65,18 @@ func (ds *datastore) Users() store.UserStore {
 	return newUsers(ds)
 }
 
+func (ds *datastore) Namespaces() store.NamespaceStore {
+	return newNamespaces(ds)
+}
+
+func (ds *datastore) Roles() store.RoleStore {
+	return newRoles(ds)
+}
+
+func (ds *datastore) RoleBindings() store.RoleBindingStore {
+	return newRoleBindings(ds)
+}
+
 // Close clsoe the etcdStore clinet.
 func (ds *datastore) Close() error {
 	if ds.cli != nil {
@@ -189,6 +203,21 @@ func (ds *datastore) SessionLiving() bool {
 	return ds.leaseLiving
 }
 
+// Name returns the name of the health check of the datastore.
+func (ds *datastore) Name() string {
+	return "etcd"
+}
+
+// Check fails when the session of the datastore is not living, it makes the datastore a
+// health checker of the server.
+func (ds *datastore) Check(ctx context.Context) error {
+	if !ds.SessionLiving() {
+		return fmt.Errorf("etcd session is not living")
+	}
+
+	return nil
+}
+
 func (ds *datastore) RestartSession() error {
 	if ds.leaseLiving {
 		return fmt.Errorf("session is living, can't restart")
@@ -282,6 +311,57 @@ func (ds *datastore) Get(ctx context.Context, key string) ([]byte, error) {
 	return resp.Kvs[0].Value, nil
 }
 
+// PutIf puts the key-value pair if the value stored under the key satisfies the condition, nil is
+// passed to the condition when the key does not exist. The check and the put are atomic, false is
+// returned when the condition does not hold.
+func (ds *datastore) PutIf(ctx context.Context, key string, val string, cond func(stored []byte) bool) (bool, error) {
+	return ds.commitIf(ctx, key, cond, clientv3.OpPut(ds.getKey(key), val))
+}
+
+// DeleteIf deletes the key if the value stored under it satisfies the condition, as PutIf does.
+func (ds *datastore) DeleteIf(ctx context.Context, key string, cond func(stored []byte) bool) (bool, error) {
+	return ds.commitIf(ctx, key, cond, clientv3.OpDelete(ds.getKey(key)))
+}
+
+// commitIf runs the operation if the value of the key satisfies the condition and the key has not
+// been modified since the value was read.
+func (ds *datastore) commitIf(
+	ctx context.Context,
+	key string,
+	cond func(stored []byte) bool,
+	op clientv3.Op,
+) (bool, error) {
+	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	defer cancel()
+
+	key = ds.getKey(key)
+
+	resp, err := ds.cli.Get(nctx, key)
+	if err != nil {
+		return false, errors.Wrap(err, "get key from etcd failed")
+	}
+
+	// the mod revision of a missing key is 0
+	var stored []byte
+	var modRevision int64
+	if len(resp.Kvs) > 0 {
+		stored, modRevision = resp.Kvs[0].Value, resp.Kvs[0].ModRevision
+	}
+	if !cond(stored) {
+		return false, nil
+	}
+
+	tresp, err := ds.cli.Txn(nctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
+		Then(op).
+		Commit()
+	if err != nil {
+		return false, errors.Wrap(err, "commit etcd transaction failed")
+	}
+
+	return tresp.Succeeded, nil
+}
+
 // EtcdKeyValue defines etcd returned key-value pairs.
 type EtcdKeyValue struct {
 	Key   string
@@ -310,6 +390,23 @@ func (ds *datastore) List(ctx context.Context, prefix string) ([]EtcdKeyValue, e
 	return ret, nil
 }
 
+// paginate returns the page of the key-values selected by the offset and limit of the list options.
+func paginate(kvs []EtcdKeyValue, opts metav1.ListOptions) []EtcdKeyValue {
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	if ol.Offset < 0 {
+		ol.Offset = 0
+	}
+	if ol.Offset >= len(kvs) {
+		return nil
+	}
+	kvs = kvs[ol.Offset:]
+	if ol.Limit >= 0 && ol.Limit < len(kvs) {
+		kvs = kvs[:ol.Limit]
+	}
+
+	return kvs
+}
+
 // Cancel cancel etcd client.
 func (w *EtcdWatcher) Cancel() {
 	w.watcher.Close()
@@ -391,3 +488,17 @@ func (ds *datastore) Delete(ctx context.Context, key string) ([]byte, error) {
 
 	return nil, nil
 }
+
+// DeletePrefix deletes all the keys with the given prefix.
+func (ds *datastore) DeletePrefix(ctx context.Context, prefix string) error {
+	nctx, cancel := context.WithTimeout(ctx, ds.requestTimeout)
+	defer cancel()
+
+	prefix = ds.getKey(prefix)
+
+	if _, err := ds.cli.Delete(nctx, prefix, clientv3.WithPrefix()); err != nil {
+		return errors.Wrap(err, "delete keys from etcd failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/util/jsonutil"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+type namespaces struct {
+	ds *datastore
+}
+
+func newNamespaces(ds *datastore) *namespaces {
+	return &namespaces{ds: ds}
+}
+
+var keyNamespace = "/namespaces/%v"
+
+func (n *namespaces) getKey(name string) string {
+	return fmt.Sprintf(keyNamespace, name)
+}
+
+// Create creates a new namespace.
+func (n *namespaces) Create(ctx context.Context, namespace *v1.Namespace, opts metav1.CreateOptions) error {
+	namespace.Version = 1
+
+	return n.ds.Put(ctx, n.getKey(namespace.Name), jsonutil.ToString(namespace))
+}
+
+// Update updates a namespace.
+func (n *namespaces) Update(ctx context.Context, namespace *v1.Namespace, opts metav1.UpdateOptions) error {
+	return update(ctx, n.ds, n.getKey(namespace.Name), namespace, func() ([]byte, error) {
+		return json.Marshal(namespace)
+	})
+}
+
+// Delete deletes the namespace and all the resources scoped to it.
+func (n *namespaces) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	// the namespace is deleted first, so that its resources are kept when its version has changed
+	if err := remove(ctx, n.ds, n.getKey(name), name, opts); err != nil {
+		return err
+	}
+
+	if err := n.ds.DeletePrefix(ctx, newUsers(n.ds).getPrefix(name)); err != nil {
+		return err
+	}
+
+	if err := n.ds.DeletePrefix(ctx, newRoles(n.ds).getKey(name, "")); err != nil {
+		return err
+	}
+
+	if err := n.ds.DeletePrefix(ctx, newRoleBindings(n.ds).getKey(name, "")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get return a namespace by the namespace identifier.
+func (n *namespaces) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error) {
+	resp, err := n.ds.Get(ctx, n.getKey(name))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrNamespaceNotFound, err.Error())
+	}
+
+	var namespace v1.Namespace
+	if err := json.Unmarshal(resp, &namespace); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to Namespace struct failed")
+	}
+
+	return &namespace, nil
+}
+
+// List return all namespaces.
+func (n *namespaces) List(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error) {
+	kvs, err := n.ds.List(ctx, n.getKey(""))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &v1.NamespaceList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: int64(len(kvs)),
+		},
+	}
+
+	for _, v := range paginate(kvs, opts) {
+		var namespace v1.Namespace
+		if err := json.Unmarshal(v.Value, &namespace); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to Namespace struct failed")
+		}
+
+		ret.Items = append(ret.Items, &namespace)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/util/jsonutil"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+type roles struct {
+	ds *datastore
+}
+
+func newRoles(ds *datastore) *roles {
+	return &roles{ds: ds}
+}
+
+var keyRole = "/roles/%v/%v"
+
+func (r *roles) getKey(namespace, name string) string {
+	return fmt.Sprintf(keyRole, namespace, name)
+}
+
+// Create creates a new role.
+func (r *roles) Create(ctx context.Context, role *v1.Role, opts metav1.CreateOptions) error {
+	role.Version = 1
+
+	return r.ds.Put(ctx, r.getKey(role.Namespace, role.Name), jsonutil.ToString(role))
+}
+
+// Update updates a role.
+func (r *roles) Update(ctx context.Context, role *v1.Role, opts metav1.UpdateOptions) error {
+	return update(ctx, r.ds, r.getKey(role.Namespace, role.Name), role, func() ([]byte, error) {
+		return json.Marshal(role)
+	})
+}
+
+// Delete deletes the role by the role identifier.
+func (r *roles) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	return remove(ctx, r.ds, r.getKey(namespace, name), name, opts)
+}
+
+// Get return a role by the role identifier.
+func (r *roles) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Role, error) {
+	resp, err := r.ds.Get(ctx, r.getKey(namespace, name))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrRoleNotFound, err.Error())
+	}
+
+	var role v1.Role
+	if err := json.Unmarshal(resp, &role); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to Role struct failed")
+	}
+
+	return &role, nil
+}
+
+// List return all roles in the namespace.
+func (r *roles) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleList, error) {
+	kvs, err := r.ds.List(ctx, r.getKey(namespace, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &v1.RoleList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: int64(len(kvs)),
+		},
+	}
+
+	for _, v := range paginate(kvs, opts) {
+		var role v1.Role
+		if err := json.Unmarshal(v.Value, &role); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to Role struct failed")
+		}
+
+		ret.Items = append(ret.Items, &role)
+	}
+
+	return ret, nil
+}
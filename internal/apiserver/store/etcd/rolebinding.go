@@ -0,0 +1,101 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/util/jsonutil"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+type roleBindings struct {
+	ds *datastore
+}
+
+func newRoleBindings(ds *datastore) *roleBindings {
+	return &roleBindings{ds: ds}
+}
+
+var keyRoleBinding = "/rolebindings/%v/%v"
+
+func (b *roleBindings) getKey(namespace, name string) string {
+	return fmt.Sprintf(keyRoleBinding, namespace, name)
+}
+
+// Create creates a new role binding.
+func (b *roleBindings) Create(ctx context.Context, binding *v1.RoleBinding, opts metav1.CreateOptions) error {
+	binding.Version = 1
+
+	return b.ds.Put(ctx, b.getKey(binding.Namespace, binding.Name), jsonutil.ToString(binding))
+}
+
+// Update updates a role binding.
+func (b *roleBindings) Update(ctx context.Context, binding *v1.RoleBinding, opts metav1.UpdateOptions) error {
+	return update(ctx, b.ds, b.getKey(binding.Namespace, binding.Name), binding, func() ([]byte, error) {
+		return json.Marshal(binding)
+	})
+}
+
+// Delete deletes the role binding by the role binding identifier.
+func (b *roleBindings) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	return remove(ctx, b.ds, b.getKey(namespace, name), name, opts)
+}
+
+// Get return a role binding by the role binding identifier.
+func (b *roleBindings) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.RoleBinding, error) {
+	resp, err := b.ds.Get(ctx, b.getKey(namespace, name))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrRoleBindingNotFound, err.Error())
+	}
+
+	var binding v1.RoleBinding
+	if err := json.Unmarshal(resp, &binding); err != nil {
+		return nil, errors.Wrap(err, "unmarshal to RoleBinding struct failed")
+	}
+
+	return &binding, nil
+}
+
+// List return all role bindings in the namespace.
+func (b *roleBindings) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleBindingList, error) {
+	kvs, err := b.ds.List(ctx, b.getKey(namespace, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &v1.RoleBindingList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: int64(len(kvs)),
+		},
+	}
+
+	for _, v := range paginate(kvs, opts) {
+		var binding v1.RoleBinding
+		if err := json.Unmarshal(v.Value, &binding); err != nil {
+			return nil, errors.Wrap(err, "unmarshal to RoleBinding struct failed")
+		}
+
+		ret.Items = append(ret.Items, &binding)
+	}
+
+	return ret, nil
+}
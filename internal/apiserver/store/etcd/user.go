@@ -17,12 +17,13 @@ package etcd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	"github.com/dairongpeng/leona/pkg/errors"
-	"github.com/dairongpeng/leona/pkg/json"
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
-	"github.com/dairongpeng/leona/pkg/util/jsonutil"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
 )
 
 type users struct {
@@ -33,50 +34,106 @@ func newUsers(ds *datastore) *users {
 	return &users{ds: ds}
 }
 
-var keyUser = "/users/%v"
+var keyUser = "/users/%v/%v"
+
+func (u *users) getKey(namespace, name string) string {
+	return fmt.Sprintf(keyUser, namespace, name)
+}
+
+// getPrefix returns the key prefix of the users in namespace,
+// metav1.NamespaceAll returns the prefix of all users.
+func (u *users) getPrefix(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return "/users/"
+	}
 
-func (u *users) getKey(name string) string {
-	return fmt.Sprintf(keyUser, name)
+	return u.getKey(namespace, "")
 }
 
-// Create creates a new user account.
+// Create creates a new user account, the existing user of the same name is never overwritten. The
+// user is saved in its stored form, which keeps the fields never responded by the API.
 func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
-	return u.ds.Put(ctx, u.getKey(user.Name), jsonutil.ToString(user))
+	user.Version = 1
+
+	data, err := user.MarshalStored()
+	if err != nil {
+		return errors.Wrap(err, "marshal User struct failed")
+	}
+
+	ok, err := u.ds.PutIf(ctx, u.getKey(user.Namespace, user.Name), string(data), absent)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.WithCode(code.ErrUserAlreadyExist, "user `%s/%s` already exists", user.Namespace, user.Name)
+	}
+
+	return nil
 }
 
 // Update updates an user account information.
 func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
-	return u.ds.Put(ctx, u.getKey(user.Name), jsonutil.ToString(user))
+	return update(ctx, u.ds, u.getKey(user.Namespace, user.Name), user, user.MarshalStored)
+}
+
+// UpdateLoginedAt records the login time of the user, the version and the update time are kept.
+func (u *users) UpdateLoginedAt(ctx context.Context, namespace, username string, loginedAt time.Time) error {
+	user, err := u.Get(ctx, namespace, username, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	user.LoginedAt = loginedAt
+
+	data, err := user.MarshalStored()
+	if err != nil {
+		return errors.Wrap(err, "marshal User struct failed")
+	}
+
+	// the login is dropped when the user is updated meanwhile
+	_, err = u.ds.PutIf(ctx, u.getKey(namespace, username), string(data), versionIs(user.Version))
+
+	return err
 }
 
 // Delete deletes the user by the user identifier.
-func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
+func (u *users) Delete(ctx context.Context, namespace, username string, opts metav1.DeleteOptions) error {
+	return remove(ctx, u.ds, u.getKey(namespace, username), username, opts)
+}
 
-	if _, err := u.ds.Delete(ctx, u.getKey(username)); err != nil {
-		return err
+// DeleteCollection batch deletes the users.
+func (u *users) DeleteCollection(
+	ctx context.Context,
+	namespace string,
+	usernames []string,
+	opts metav1.DeleteOptions,
+) error {
+	for _, username := range usernames {
+		if _, err := u.ds.Delete(ctx, u.getKey(namespace, username)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 // Get return an user by the user identifier.
-func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
-	resp, err := u.ds.Get(ctx, u.getKey(username))
+func (u *users) Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error) {
+	resp, err := u.ds.Get(ctx, u.getKey(namespace, username))
 	if err != nil {
-		return nil, err
+		return nil, errors.WithCode(code.ErrUserNotFound, err.Error())
 	}
 
 	var user v1.User
-	if err := json.Unmarshal(resp, &user); err != nil {
+	if err := user.UnmarshalStored(resp); err != nil {
 		return nil, errors.Wrap(err, "unmarshal to User struct failed")
 	}
 
 	return &user, nil
 }
 
-// List return all users.
-func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
-	kvs, err := u.ds.List(ctx, u.getKey(""))
+// List return all users in the namespace, or users across all namespaces if namespace is metav1.NamespaceAll.
+func (u *users) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error) {
+	kvs, err := u.ds.List(ctx, u.getPrefix(namespace))
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +144,9 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 		},
 	}
 
-	for _, v := range kvs {
+	for _, v := range paginate(kvs, opts) {
 		var user v1.User
-		if err := json.Unmarshal(v.Value, &user); err != nil {
+		if err := user.UnmarshalStored(v.Value); err != nil {
 			return nil, errors.Wrap(err, "unmarshal to User struct failed")
 		}
 
@@ -0,0 +1,105 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+// update puts the object with the incremented version under the key. The object is written only if
+// the version of the stored one has not changed since the object was read, code.ErrResourceConflict
+// is returned otherwise.
+func update(
+	ctx context.Context,
+	ds *datastore,
+	key string,
+	obj metav1.ObjectMetaAccessor,
+	marshal func() ([]byte, error),
+) error {
+	meta := obj.GetObjectMeta()
+	version, updatedAt := meta.GetVersion(), meta.GetUpdatedAt()
+	meta.SetVersion(version + 1)
+	meta.SetUpdatedAt(time.Now())
+
+	data, err := marshal()
+	if err != nil {
+		meta.SetVersion(version)
+		meta.SetUpdatedAt(updatedAt)
+
+		return errors.Wrap(err, "marshal object failed")
+	}
+
+	ok, err := ds.PutIf(ctx, key, string(data), versionIs(version))
+	if err == nil && ok {
+		return nil
+	}
+
+	meta.SetVersion(version)
+	meta.SetUpdatedAt(updatedAt)
+	if err != nil {
+		return err
+	}
+
+	return errors.WithCode(code.ErrResourceConflict, "`%s` has been modified or deleted", meta.GetName())
+}
+
+// remove deletes the key, only if the stored object has the version given by the options when it is set.
+func remove(ctx context.Context, ds *datastore, key, name string, opts metav1.DeleteOptions) error {
+	if opts.Version == 0 {
+		_, err := ds.Delete(ctx, key)
+
+		return err
+	}
+
+	ok, err := ds.DeleteIf(ctx, key, versionIs(opts.Version))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.WithCode(code.ErrResourceConflict, "`%s` has been modified or deleted", name)
+	}
+
+	return nil
+}
+
+// absent is the condition which holds when the key does not exist.
+func absent(stored []byte) bool {
+	return stored == nil
+}
+
+// versionIs returns the condition which holds when the stored object has the given version.
+func versionIs(version uint64) func(stored []byte) bool {
+	return func(stored []byte) bool {
+		if stored == nil {
+			return false
+		}
+
+		var obj struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(stored, &obj); err != nil {
+			return false
+		}
+
+		return obj.Metadata.Version == version
+	}
+}
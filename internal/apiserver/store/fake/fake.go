@@ -28,13 +28,28 @@ const ResourceCount = 1000
 
 type datastore struct {
 	sync.RWMutex
-	users []*v1.User
+	users        []*v1.User
+	namespaces   []*v1.Namespace
+	roles        []*v1.Role
+	roleBindings []*v1.RoleBinding
 }
 
 func (ds *datastore) Users() store.UserStore {
 	return newUsers(ds)
 }
 
+func (ds *datastore) Namespaces() store.NamespaceStore {
+	return newNamespaces(ds)
+}
+
+func (ds *datastore) Roles() store.RoleStore {
+	return newRoles(ds)
+}
+
+func (ds *datastore) RoleBindings() store.RoleBindingStore {
+	return newRoleBindings(ds)
+}
+
 func (ds *datastore) Close() error {
 	return nil
 }
@@ -48,7 +63,8 @@ var (
 func GetFakeFactoryOr() (store.Factory, error) {
 	once.Do(func() {
 		fakeFactory = &datastore{
-			users: FakeUsers(ResourceCount),
+			users:      FakeUsers(ResourceCount),
+			namespaces: FakeNamespaces(),
 		}
 	})
 
@@ -66,8 +82,10 @@ func FakeUsers(count int) []*v1.User {
 	for i := 1; i <= count; i++ {
 		users = append(users, &v1.User{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: fmt.Sprintf("user%d", i),
-				ID:   uint64(i),
+				Name:      fmt.Sprintf("user%d", i),
+				Namespace: metav1.NamespaceDefault,
+				ID:        uint64(i),
+				Version:   1,
 			},
 			Nickname: fmt.Sprintf("user%d", i),
 			Password: fmt.Sprintf("User%d@2020", i),
@@ -77,3 +95,18 @@ func FakeUsers(count int) []*v1.User {
 
 	return users
 }
+
+// FakeNamespaces returns fake namespace data.
+func FakeNamespaces() []*v1.Namespace {
+	return []*v1.Namespace{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:    metav1.NamespaceDefault,
+				ID:      1,
+				Version: 1,
+			},
+			Description: "default namespace",
+			Phase:       v1.NamespaceActive,
+		},
+	}
+}
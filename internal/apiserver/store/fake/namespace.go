@@ -0,0 +1,176 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/fields"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
+)
+
+type namespaces struct {
+	ds *datastore
+}
+
+func newNamespaces(ds *datastore) *namespaces {
+	return &namespaces{ds}
+}
+
+// Create creates a new namespace.
+func (n *namespaces) Create(ctx context.Context, namespace *v1.Namespace, opts metav1.CreateOptions) error {
+	n.ds.Lock()
+	defer n.ds.Unlock()
+
+	for _, ns := range n.ds.namespaces {
+		if ns.Name == namespace.Name {
+			return errors.WithCode(code.ErrNamespaceAlreadyExist, "record already exist")
+		}
+	}
+
+	if len(n.ds.namespaces) > 0 {
+		namespace.ID = n.ds.namespaces[len(n.ds.namespaces)-1].ID + 1
+	}
+	namespace.Version = 1
+	n.ds.namespaces = append(n.ds.namespaces, namespace)
+
+	return nil
+}
+
+// Update updates a namespace.
+func (n *namespaces) Update(ctx context.Context, namespace *v1.Namespace, opts metav1.UpdateOptions) error {
+	n.ds.Lock()
+	defer n.ds.Unlock()
+
+	for i, ns := range n.ds.namespaces {
+		if ns.Name == namespace.Name {
+			if err := update(ns, namespace); err != nil {
+				return err
+			}
+			n.ds.namespaces[i] = namespace
+
+			return nil
+		}
+	}
+
+	return conflict(namespace.Name)
+}
+
+// Delete deletes the namespace and all the resources scoped to it.
+func (n *namespaces) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	n.ds.Lock()
+	defer n.ds.Unlock()
+
+	for _, ns := range n.ds.namespaces {
+		if ns.Name == name && !deletable(ns, opts) {
+			return conflict(name)
+		}
+	}
+
+	users := n.ds.users
+	n.ds.users = make([]*v1.User, 0)
+	for _, user := range users {
+		if user.Namespace == name {
+			continue
+		}
+
+		n.ds.users = append(n.ds.users, user)
+	}
+
+	roles := n.ds.roles
+	n.ds.roles = make([]*v1.Role, 0)
+	for _, role := range roles {
+		if role.Namespace == name {
+			continue
+		}
+
+		n.ds.roles = append(n.ds.roles, role)
+	}
+
+	roleBindings := n.ds.roleBindings
+	n.ds.roleBindings = make([]*v1.RoleBinding, 0)
+	for _, binding := range roleBindings {
+		if binding.Namespace == name {
+			continue
+		}
+
+		n.ds.roleBindings = append(n.ds.roleBindings, binding)
+	}
+
+	namespaces := n.ds.namespaces
+	n.ds.namespaces = make([]*v1.Namespace, 0)
+	for _, ns := range namespaces {
+		if ns.Name == name {
+			continue
+		}
+
+		n.ds.namespaces = append(n.ds.namespaces, ns)
+	}
+
+	return nil
+}
+
+// Get return a namespace by the namespace identifier.
+func (n *namespaces) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error) {
+	n.ds.RLock()
+	defer n.ds.RUnlock()
+
+	for _, ns := range n.ds.namespaces {
+		if ns.Name == name {
+			return ns, nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrNamespaceNotFound, "record not found")
+}
+
+// List return all namespaces.
+func (n *namespaces) List(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error) {
+	n.ds.RLock()
+	defer n.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+
+	namespaces := make([]*v1.Namespace, 0)
+	i := 0
+	for _, ns := range n.ds.namespaces {
+		if len(namespaces) == ol.Limit {
+			break
+		}
+		if !strings.Contains(ns.Name, name) {
+			continue
+		}
+		i++
+		if i <= ol.Offset {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+
+	return &v1.NamespaceList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: int64(len(n.ds.namespaces)),
+		},
+		Items: namespaces,
+	}, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/fields"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
+)
+
+type roles struct {
+	ds *datastore
+}
+
+func newRoles(ds *datastore) *roles {
+	return &roles{ds}
+}
+
+// Create creates a new role.
+func (r *roles) Create(ctx context.Context, role *v1.Role, opts metav1.CreateOptions) error {
+	r.ds.Lock()
+	defer r.ds.Unlock()
+
+	for _, item := range r.ds.roles {
+		if item.Namespace == role.Namespace && item.Name == role.Name {
+			return errors.WithCode(code.ErrRoleAlreadyExist, "record already exist")
+		}
+	}
+
+	if len(r.ds.roles) > 0 {
+		role.ID = r.ds.roles[len(r.ds.roles)-1].ID + 1
+	}
+	role.Version = 1
+	r.ds.roles = append(r.ds.roles, role)
+
+	return nil
+}
+
+// Update updates a role.
+func (r *roles) Update(ctx context.Context, role *v1.Role, opts metav1.UpdateOptions) error {
+	r.ds.Lock()
+	defer r.ds.Unlock()
+
+	for i, item := range r.ds.roles {
+		if item.Namespace == role.Namespace && item.Name == role.Name {
+			if err := update(item, role); err != nil {
+				return err
+			}
+			r.ds.roles[i] = role
+
+			return nil
+		}
+	}
+
+	return conflict(role.Name)
+}
+
+// Delete deletes the role by the role identifier.
+func (r *roles) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	r.ds.Lock()
+	defer r.ds.Unlock()
+
+	roles := r.ds.roles
+	r.ds.roles = make([]*v1.Role, 0)
+	for _, item := range roles {
+		if item.Namespace == namespace && item.Name == name {
+			if !deletable(item, opts) {
+				r.ds.roles = roles
+
+				return conflict(name)
+			}
+
+			continue
+		}
+
+		r.ds.roles = append(r.ds.roles, item)
+	}
+
+	return nil
+}
+
+// Get return a role by the role identifier.
+func (r *roles) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Role, error) {
+	r.ds.RLock()
+	defer r.ds.RUnlock()
+
+	for _, item := range r.ds.roles {
+		if item.Namespace == namespace && item.Name == name {
+			return item, nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrRoleNotFound, "record not found")
+}
+
+// List return all roles in the namespace.
+func (r *roles) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleList, error) {
+	r.ds.RLock()
+	defer r.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+
+	roles := make([]*v1.Role, 0)
+	total, i := 0, 0
+	for _, item := range r.ds.roles {
+		if item.Namespace != namespace || !strings.Contains(item.Name, name) {
+			continue
+		}
+		total++
+		i++
+		if i <= ol.Offset || len(roles) == ol.Limit {
+			continue
+		}
+		roles = append(roles, item)
+	}
+
+	return &v1.RoleList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: int64(total),
+		},
+		Items: roles,
+	}, nil
+}
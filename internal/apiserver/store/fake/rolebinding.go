@@ -0,0 +1,144 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/fields"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
+)
+
+type roleBindings struct {
+	ds *datastore
+}
+
+func newRoleBindings(ds *datastore) *roleBindings {
+	return &roleBindings{ds}
+}
+
+// Create creates a new role binding.
+func (b *roleBindings) Create(ctx context.Context, binding *v1.RoleBinding, opts metav1.CreateOptions) error {
+	b.ds.Lock()
+	defer b.ds.Unlock()
+
+	for _, item := range b.ds.roleBindings {
+		if item.Namespace == binding.Namespace && item.Name == binding.Name {
+			return errors.WithCode(code.ErrRoleBindingAlreadyExist, "record already exist")
+		}
+	}
+
+	if len(b.ds.roleBindings) > 0 {
+		binding.ID = b.ds.roleBindings[len(b.ds.roleBindings)-1].ID + 1
+	}
+	binding.Version = 1
+	b.ds.roleBindings = append(b.ds.roleBindings, binding)
+
+	return nil
+}
+
+// Update updates a role binding.
+func (b *roleBindings) Update(ctx context.Context, binding *v1.RoleBinding, opts metav1.UpdateOptions) error {
+	b.ds.Lock()
+	defer b.ds.Unlock()
+
+	for i, item := range b.ds.roleBindings {
+		if item.Namespace == binding.Namespace && item.Name == binding.Name {
+			if err := update(item, binding); err != nil {
+				return err
+			}
+			b.ds.roleBindings[i] = binding
+
+			return nil
+		}
+	}
+
+	return conflict(binding.Name)
+}
+
+// Delete deletes the role binding by the role binding identifier.
+func (b *roleBindings) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	b.ds.Lock()
+	defer b.ds.Unlock()
+
+	roleBindings := b.ds.roleBindings
+	b.ds.roleBindings = make([]*v1.RoleBinding, 0)
+	for _, item := range roleBindings {
+		if item.Namespace == namespace && item.Name == name {
+			if !deletable(item, opts) {
+				b.ds.roleBindings = roleBindings
+
+				return conflict(name)
+			}
+
+			continue
+		}
+
+		b.ds.roleBindings = append(b.ds.roleBindings, item)
+	}
+
+	return nil
+}
+
+// Get return a role binding by the role binding identifier.
+func (b *roleBindings) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.RoleBinding, error) {
+	b.ds.RLock()
+	defer b.ds.RUnlock()
+
+	for _, item := range b.ds.roleBindings {
+		if item.Namespace == namespace && item.Name == name {
+			return item, nil
+		}
+	}
+
+	return nil, errors.WithCode(code.ErrRoleBindingNotFound, "record not found")
+}
+
+// List return all role bindings in the namespace.
+func (b *roleBindings) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleBindingList, error) {
+	b.ds.RLock()
+	defer b.ds.RUnlock()
+
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+
+	roleBindings := make([]*v1.RoleBinding, 0)
+	total, i := 0, 0
+	for _, item := range b.ds.roleBindings {
+		if item.Namespace != namespace || !strings.Contains(item.Name, name) {
+			continue
+		}
+		total++
+		i++
+		if i <= ol.Offset || len(roleBindings) == ol.Limit {
+			continue
+		}
+		roleBindings = append(roleBindings, item)
+	}
+
+	return &v1.RoleBindingList{
+		ListMeta: metav1.ListMeta{
+			TotalCount: int64(total),
+		},
+		Items: roleBindings,
+	}, nil
+}
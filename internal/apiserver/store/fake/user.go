@@ -17,6 +17,7 @@ package fake
 import (
 	"context"
 	"strings"
+	"time"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	"github.com/dairongpeng/leona/pkg/errors"
@@ -26,7 +27,6 @@ import (
 
 	"github.com/dairongpeng/leona/internal/pkg/code"
 	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
-	reflectutil "github.com/dairongpeng/leona/internal/pkg/util/reflect"
 )
 
 type users struct {
@@ -43,7 +43,7 @@ func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOpt
 	defer u.ds.Unlock()
 
 	for _, u := range u.ds.users {
-		if u.Name == user.Name {
+		if u.Namespace == user.Namespace && u.Name == user.Name {
 			return errors.WithCode(code.ErrUserAlreadyExist, "record already exist")
 		}
 	}
@@ -51,6 +51,7 @@ func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOpt
 	if len(u.ds.users) > 0 {
 		user.ID = u.ds.users[len(u.ds.users)-1].ID + 1
 	}
+	user.Version = 1
 	u.ds.users = append(u.ds.users, user)
 
 	return nil
@@ -61,26 +62,35 @@ func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOpt
 	u.ds.Lock()
 	defer u.ds.Unlock()
 
-	for _, u := range u.ds.users {
-		if u.Name == user.Name {
-			if _, err := reflectutil.CopyObj(user, u, nil); err != nil {
-				return errors.Wrap(err, "copy user failed")
+	for i, item := range u.ds.users {
+		if item.Namespace == user.Namespace && item.Name == user.Name {
+			if err := update(item, user); err != nil {
+				return err
 			}
+			u.ds.users[i] = user
+
+			return nil
 		}
 	}
 
-	return nil
+	return conflict(user.Name)
 }
 
 // Delete deletes the user by the user identifier.
-func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
+func (u *users) Delete(ctx context.Context, namespace, username string, opts metav1.DeleteOptions) error {
 	u.ds.Lock()
 	defer u.ds.Unlock()
 
 	users := u.ds.users
 	u.ds.users = make([]*v1.User, 0)
 	for _, user := range users {
-		if user.Name == username {
+		if user.Namespace == namespace && user.Name == username {
+			if !deletable(user, opts) {
+				u.ds.users = users
+
+				return conflict(username)
+			}
+
 			continue
 		}
 
@@ -91,14 +101,35 @@ func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteO
 }
 
 // DeleteCollection batch deletes the users.
-func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
+// UpdateLoginedAt records the login time of the user, the version and the update time are kept.
+func (u *users) UpdateLoginedAt(ctx context.Context, namespace, username string, loginedAt time.Time) error {
+	u.ds.Lock()
+	defer u.ds.Unlock()
+
+	for _, user := range u.ds.users {
+		if user.Namespace == namespace && user.Name == username {
+			user.LoginedAt = loginedAt
+
+			return nil
+		}
+	}
+
+	return errors.WithCode(code.ErrUserNotFound, "record not found")
+}
+
+func (u *users) DeleteCollection(
+	ctx context.Context,
+	namespace string,
+	usernames []string,
+	opts metav1.DeleteOptions,
+) error {
 	u.ds.Lock()
 	defer u.ds.Unlock()
 
 	users := u.ds.users
 	u.ds.users = make([]*v1.User, 0)
 	for _, user := range users {
-		if stringutil.StringIn(user.Name, usernames) {
+		if user.Namespace == namespace && stringutil.StringIn(user.Name, usernames) {
 			continue
 		}
 
@@ -109,12 +140,12 @@ func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts m
 }
 
 // Get return an user by the user identifier.
-func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
+func (u *users) Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error) {
 	u.ds.RLock()
 	defer u.ds.RUnlock()
 
 	for _, u := range u.ds.users {
-		if u.Name == username {
+		if u.Namespace == namespace && u.Name == username {
 			return u, nil
 		}
 	}
@@ -122,8 +153,8 @@ func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions
 	return nil, errors.WithCode(code.ErrUserNotFound, "record not found")
 }
 
-// List return all users.
-func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+// List return all users in the namespace, or users across all namespaces if namespace is metav1.NamespaceAll.
+func (u *users) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error) {
 	u.ds.RLock()
 	defer u.ds.RUnlock()
 
@@ -134,14 +165,20 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 	users := make([]*v1.User, 0)
 	i := 0
 	for _, user := range u.ds.users {
-		if i == ol.Limit {
+		if len(users) == ol.Limit {
 			break
 		}
+		if namespace != metav1.NamespaceAll && user.Namespace != namespace {
+			continue
+		}
 		if !strings.Contains(user.Name, username) {
 			continue
 		}
-		users = append(users, user)
 		i++
+		if i <= ol.Offset {
+			continue
+		}
+		users = append(users, user)
 	}
 
 	return &v1.UserList{
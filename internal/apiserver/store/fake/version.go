@@ -0,0 +1,47 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"time"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+// update increments the version of the object replacing the stored one, code.ErrResourceConflict is
+// returned when the version of the stored object has changed since the object was read.
+func update(stored, obj metav1.ObjectMetaAccessor) error {
+	meta := obj.GetObjectMeta()
+	if stored.GetObjectMeta().GetVersion() != meta.GetVersion() {
+		return conflict(meta.GetName())
+	}
+
+	meta.SetVersion(meta.GetVersion() + 1)
+	meta.SetUpdatedAt(time.Now())
+
+	return nil
+}
+
+// deletable reports whether the stored object can be deleted with the options.
+func deletable(stored metav1.ObjectMetaAccessor, opts metav1.DeleteOptions) bool {
+	return opts.Version == 0 || stored.GetObjectMeta().GetVersion() == opts.Version
+}
+
+func conflict(name string) error {
+	return errors.WithCode(code.ErrResourceConflict, "`%s` has been modified or deleted", name)
+}
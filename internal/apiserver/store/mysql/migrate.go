@@ -0,0 +1,202 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// migration is a versioned change of the database schema. The applied migrations are recorded in
+// the schema_migration table, so each of them runs only once. The migrations only add the missing
+// tables, columns and indexes, so they are also safe to run against a database created by hand.
+type migration struct {
+	version int
+	name    string
+	migrate func(db *gorm.DB) error
+}
+
+var migrations = []migration{
+	{version: 1, name: "add namespaces", migrate: addNamespaces},
+	{version: 2, name: "add user security columns", migrate: addUserSecurityColumns},
+	{version: 3, name: "add user source columns", migrate: addUserSourceColumns},
+	{version: 4, name: "add version columns", migrate: addVersionColumns},
+	{version: 5, name: "rebuild user name index", migrate: rebuildUserNameIndex},
+}
+
+// schemaMigration records an applied migration.
+type schemaMigration struct {
+	Version   int       `gorm:"primary_key;column:version"`
+	Name      string    `gorm:"column:name;type:varchar(128);not null"`
+	AppliedAt time.Time `gorm:"column:appliedAt"`
+}
+
+// TableName maps to mysql table name.
+func (m *schemaMigration) TableName() string {
+	return "schema_migration"
+}
+
+// migrate applies the migrations which have not been applied to the database.
+func migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return errors.Wrap(err, "migrate schema_migration table failed")
+	}
+
+	var applied []schemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return errors.Wrap(err, "list applied migrations failed")
+	}
+	done := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		done[m.Version] = true
+	}
+
+	for _, m := range migrations {
+		if done[m.version] {
+			continue
+		}
+
+		log.Infof("Applying database migration %d: %s", m.version, m.name)
+		if err := m.migrate(db); err != nil {
+			return errors.Wrapf(err, "apply migration %d failed", m.version)
+		}
+		record := &schemaMigration{Version: m.version, Name: m.name, AppliedAt: time.Now()}
+		if err := db.Create(record).Error; err != nil {
+			return errors.Wrapf(err, "record migration %d failed", m.version)
+		}
+	}
+
+	return nil
+}
+
+// addColumns adds the columns of the given fields which are missing from the table of the model.
+func addColumns(db *gorm.DB, model interface{}, fields ...string) error {
+	migrator := db.Migrator()
+	for _, field := range fields {
+		if migrator.HasColumn(model, field) {
+			continue
+		}
+		if err := migrator.AddColumn(model, field); err != nil {
+			return errors.Wrapf(err, "add column %s failed", field)
+		}
+	}
+
+	return nil
+}
+
+// addNamespaces adds the namespace and rbac tables, and moves the existing users into the
+// default namespace.
+func addNamespaces(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if err := migrator.AutoMigrate(&v1.Namespace{}, &v1.Role{}, &v1.RoleBinding{}); err != nil {
+		return errors.Wrap(err, "migrate namespace and rbac tables failed")
+	}
+
+	user := &v1.User{}
+	if !migrator.HasTable(user) {
+		return errors.Wrap(migrator.AutoMigrate(user), "migrate user table failed")
+	}
+
+	if err := addColumns(db, user, "Namespace"); err != nil {
+		return err
+	}
+	// the users created before namespaces were introduced belong to the default namespace
+	if err := db.Exec("UPDATE `user` SET namespace = ? WHERE namespace = ''", metav1.NamespaceDefault).Error; err != nil {
+		return errors.Wrap(err, "backfill user namespace failed")
+	}
+
+	// the name index is rebuilt on (namespace, name) by rebuildUserNameIndex
+	return nil
+}
+
+// addUserSecurityColumns adds the columns of the password policy, email verification and two-factor
+// authentication to the user table.
+func addUserSecurityColumns(db *gorm.DB) error {
+	return addColumns(db, &v1.User{},
+		"PasswordChangedAt",
+		"PasswordHistoryShadow",
+		"EmailVerifiedAt",
+		"MFAEnabledAt",
+		"TOTPSecret",
+		"RecoveryCodesShadow",
+	)
+}
+
+// addUserSourceColumns adds the columns of the identity source to the user table, and moves the
+// markers of the ldap users out of the extend field, where the requests can change them.
+func addUserSourceColumns(db *gorm.DB) error {
+	if err := addColumns(db, &v1.User{}, "Source", "SourceID", "SourceRemoved"); err != nil {
+		return err
+	}
+
+	var users []*v1.User
+	if err := db.Where("extendShadow LIKE ?", `%"source":"ldap"%`).Find(&users).Error; err != nil {
+		return errors.Wrap(err, "list ldap users failed")
+	}
+	for _, user := range users {
+		if source, _ := user.Extend["source"].(string); source != "ldap" {
+			continue
+		}
+
+		user.Source = "ldap"
+		user.SourceID, _ = user.Extend["ldapDN"].(string)
+		user.SourceRemoved, _ = user.Extend["ldapRemoved"].(bool)
+		delete(user.Extend, "source")
+		delete(user.Extend, "ldapDN")
+		delete(user.Extend, "ldapRemoved")
+		if err := db.Save(user).Error; err != nil {
+			return errors.Wrapf(err, "backfill the source of user %s/%s failed", user.Namespace, user.Name)
+		}
+	}
+
+	return nil
+}
+
+// addVersionColumns adds the version column to the tables of the versioned objects, the existing
+// rows start at the first version.
+func addVersionColumns(db *gorm.DB) error {
+	for _, model := range []metav1.ObjectMetaAccessor{&v1.User{}, &v1.Namespace{}, &v1.Role{}, &v1.RoleBinding{}} {
+		if err := addColumns(db, model, "Version"); err != nil {
+			return err
+		}
+		if err := db.Model(model).Where("version = 0").UpdateColumn("version", 1).Error; err != nil {
+			return errors.Wrap(err, "backfill version failed")
+		}
+	}
+
+	return nil
+}
+
+// rebuildUserNameIndex recreates the unique name index of the user table on (namespace, name). The
+// tables created before namespaces were introduced have the index on the name only, which keeps the
+// names unique across the namespaces. The columns of an index can not be told apart by the migrator,
+// so the index is always dropped and created again.
+func rebuildUserNameIndex(db *gorm.DB) error {
+	migrator := db.Migrator()
+	user := &v1.User{}
+	if migrator.HasIndex(user, "idx_name") {
+		if err := migrator.DropIndex(user, "idx_name"); err != nil {
+			return errors.Wrap(err, "drop user name index failed")
+		}
+	}
+
+	return errors.Wrap(migrator.CreateIndex(user, "idx_name"), "create user name index failed")
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRebuildUserNameIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		indexed int
+	}{
+		// the tables created before namespaces were introduced have idx_name on the name only.
+		{name: "baseline", indexed: 1},
+		{name: "no index", indexed: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer sqlDB.Close()
+
+			db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT DATABASE()")).
+				WillReturnRows(sqlmock.NewRows([]string{"DATABASE()"}).AddRow("leona"))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM information_schema.statistics")).
+				WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(tt.indexed))
+			if tt.indexed > 0 {
+				mock.ExpectExec(regexp.QuoteMeta("DROP INDEX `idx_name` ON `user`")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectExec(regexp.QuoteMeta("CREATE UNIQUE INDEX `idx_name` ON `user`(`namespace`,`name`)")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			assert.NoError(t, rebuildUserNameIndex(db))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrations_RebuildUserNameIndex(t *testing.T) {
+	var found bool
+	for i, m := range migrations {
+		if m.name == "rebuild user name index" {
+			found = true
+			// the index must be rebuilt after the namespace column is added.
+			assert.Equal(t, len(migrations)-1, i)
+		}
+	}
+	assert.True(t, found)
+}
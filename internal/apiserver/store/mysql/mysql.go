@@ -15,6 +15,7 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -40,6 +41,33 @@ func (ds *datastore) Users() store.UserStore {
 	return newUsers(ds)
 }
 
+func (ds *datastore) Namespaces() store.NamespaceStore {
+	return newNamespaces(ds)
+}
+
+func (ds *datastore) Roles() store.RoleStore {
+	return newRoles(ds)
+}
+
+func (ds *datastore) RoleBindings() store.RoleBindingStore {
+	return newRoleBindings(ds)
+}
+
+// Name returns the name of the health check of the datastore.
+func (ds *datastore) Name() string {
+	return "mysql"
+}
+
+// Check pings the database, it makes the datastore a health checker of the server.
+func (ds *datastore) Check(ctx context.Context) error {
+	db, err := ds.db.DB()
+	if err != nil {
+		return errors.Wrap(err, "get gorm db instance failed")
+	}
+
+	return db.PingContext(ctx)
+}
+
 func (ds *datastore) Close() error {
 	db, err := ds.db.DB()
 	if err != nil {
@@ -75,10 +103,14 @@ func GetMySQLFactoryOr(opts *genericoptions.MySQLOptions) (store.Factory, error)
 			Logger:                logger.New(opts.LogLevel),
 		}
 		dbIns, err = db.New(options)
+		if err != nil {
+			return
+		}
 
-		// uncomment the following line if you need auto migration the given models
-		// not suggested in production environment.
-		// migrateDatabase(dbIns)
+		// apply the versioned migrations, they only add the missing tables, columns and indexes
+		if err = migrate(dbIns); err != nil {
+			return
+		}
 
 		mysqlFactory = &datastore{dbIns}
 	})
@@ -96,6 +128,15 @@ func cleanDatabase(db *gorm.DB) error {
 	if err := db.Migrator().DropTable(&v1.User{}); err != nil {
 		return errors.Wrap(err, "drop user table failed")
 	}
+	if err := db.Migrator().DropTable(&v1.Namespace{}); err != nil {
+		return errors.Wrap(err, "drop namespace table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.Role{}); err != nil {
+		return errors.Wrap(err, "drop role table failed")
+	}
+	if err := db.Migrator().DropTable(&v1.RoleBinding{}); err != nil {
+		return errors.Wrap(err, "drop rolebinding table failed")
+	}
 
 	return nil
 }
@@ -107,6 +148,15 @@ func migrateDatabase(db *gorm.DB) error {
 	if err := db.AutoMigrate(&v1.User{}); err != nil {
 		return errors.Wrap(err, "migrate user model failed")
 	}
+	if err := db.AutoMigrate(&v1.Namespace{}); err != nil {
+		return errors.Wrap(err, "migrate namespace model failed")
+	}
+	if err := db.AutoMigrate(&v1.Role{}); err != nil {
+		return errors.Wrap(err, "migrate role model failed")
+	}
+	if err := db.AutoMigrate(&v1.RoleBinding{}); err != nil {
+		return errors.Wrap(err, "migrate rolebinding model failed")
+	}
 
 	return nil
 }
@@ -0,0 +1,109 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/fields"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	gorm "gorm.io/gorm"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
+)
+
+type namespaces struct {
+	db *gorm.DB
+}
+
+func newNamespaces(ds *datastore) *namespaces {
+	return &namespaces{db: ds.db}
+}
+
+// Create creates a new namespace.
+func (n *namespaces) Create(ctx context.Context, namespace *v1.Namespace, opts metav1.CreateOptions) error {
+	namespace.Version = 1
+
+	return n.db.WithContext(ctx).Create(&namespace).Error
+}
+
+// Update updates a namespace.
+func (n *namespaces) Update(ctx context.Context, namespace *v1.Namespace, opts metav1.UpdateOptions) error {
+	return update(n.db.WithContext(ctx), namespace)
+}
+
+// Delete deletes the namespace and all the resources scoped to it in one transaction.
+func (n *namespaces) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	db := n.db.WithContext(ctx)
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// the namespace is deleted first, so that its resources are kept when its version has changed
+		result := versioned(tx, opts).Where("name = ?", name).Delete(&v1.Namespace{})
+		if err := deleted(result, name, opts); err != nil {
+			return err
+		}
+		if err := tx.Where("namespace = ?", name).Delete(&v1.User{}).Error; err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+		if err := tx.Where("namespace = ?", name).Delete(&v1.Role{}).Error; err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+		if err := tx.Where("namespace = ?", name).Delete(&v1.RoleBinding{}).Error; err != nil {
+			return errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// Get return a namespace by the namespace identifier.
+func (n *namespaces) Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Namespace, error) {
+	namespace := &v1.Namespace{}
+	err := n.db.WithContext(ctx).Where("name = ?", name).First(&namespace).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrNamespaceNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return namespace, nil
+}
+
+// List return all namespaces.
+func (n *namespaces) List(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error) {
+	ret := &v1.NamespaceList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+	d := n.db.WithContext(ctx).Where("name like ?", "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/fields"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	gorm "gorm.io/gorm"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
+)
+
+type roles struct {
+	db *gorm.DB
+}
+
+func newRoles(ds *datastore) *roles {
+	return &roles{db: ds.db}
+}
+
+// Create creates a new role.
+func (r *roles) Create(ctx context.Context, role *v1.Role, opts metav1.CreateOptions) error {
+	role.Version = 1
+
+	return r.db.WithContext(ctx).Create(&role).Error
+}
+
+// Update updates a role.
+func (r *roles) Update(ctx context.Context, role *v1.Role, opts metav1.UpdateOptions) error {
+	return update(r.db.WithContext(ctx), role)
+}
+
+// Delete deletes the role by the role identifier.
+func (r *roles) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	db := r.db.WithContext(ctx)
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+
+	result := versioned(db, opts).Where("namespace = ? and name = ?", namespace, name).Delete(&v1.Role{})
+
+	return deleted(result, name, opts)
+}
+
+// Get return a role by the role identifier.
+func (r *roles) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.Role, error) {
+	role := &v1.Role{}
+	err := r.db.WithContext(ctx).Where("namespace = ? and name = ?", namespace, name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrRoleNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return role, nil
+}
+
+// List return all roles in the namespace.
+func (r *roles) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleList, error) {
+	ret := &v1.RoleList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+	d := r.db.WithContext(ctx).Where("namespace = ? and name like ?", namespace, "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
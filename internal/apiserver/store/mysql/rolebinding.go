@@ -0,0 +1,94 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/fields"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	gorm "gorm.io/gorm"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
+)
+
+type roleBindings struct {
+	db *gorm.DB
+}
+
+func newRoleBindings(ds *datastore) *roleBindings {
+	return &roleBindings{db: ds.db}
+}
+
+// Create creates a new role binding.
+func (b *roleBindings) Create(ctx context.Context, binding *v1.RoleBinding, opts metav1.CreateOptions) error {
+	binding.Version = 1
+
+	return b.db.WithContext(ctx).Create(&binding).Error
+}
+
+// Update updates a role binding.
+func (b *roleBindings) Update(ctx context.Context, binding *v1.RoleBinding, opts metav1.UpdateOptions) error {
+	return update(b.db.WithContext(ctx), binding)
+}
+
+// Delete deletes the role binding by the role binding identifier.
+func (b *roleBindings) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	db := b.db.WithContext(ctx)
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+
+	result := versioned(db, opts).Where("namespace = ? and name = ?", namespace, name).Delete(&v1.RoleBinding{})
+
+	return deleted(result, name, opts)
+}
+
+// Get return a role binding by the role binding identifier.
+func (b *roleBindings) Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.RoleBinding, error) {
+	binding := &v1.RoleBinding{}
+	err := b.db.WithContext(ctx).Where("namespace = ? and name = ?", namespace, name).First(&binding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrRoleBindingNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return binding, nil
+}
+
+// List return all role bindings in the namespace.
+func (b *roleBindings) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleBindingList, error) {
+	ret := &v1.RoleBindingList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+	d := b.db.WithContext(ctx).Where("namespace = ? and name like ?", namespace, "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
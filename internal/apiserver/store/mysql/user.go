@@ -16,17 +16,22 @@ package mysql
 
 import (
 	"context"
+	"time"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	"github.com/dairongpeng/leona/pkg/errors"
 	"github.com/dairongpeng/leona/pkg/fields"
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/go-sql-driver/mysql"
 	gorm "gorm.io/gorm"
 
 	"github.com/dairongpeng/leona/internal/pkg/code"
 	"github.com/dairongpeng/leona/internal/pkg/util/gormutil"
 )
 
+// errDuplicateEntry is the MySQL error number of the duplicate values of a unique key.
+const errDuplicateEntry = 1062
+
 type users struct {
 	db *gorm.DB
 }
@@ -37,41 +42,69 @@ func newUsers(ds *datastore) *users {
 
 // Create creates a new user account.
 func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
-	return u.db.Create(&user).Error
+	user.Version = 1
+
+	err := u.db.WithContext(ctx).Create(&user).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		return errors.WithCode(code.ErrUserAlreadyExist, err.Error())
+	}
+
+	return err
 }
 
 // Update updates an user account information.
 func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
-	return u.db.Save(user).Error
+	return update(u.db.WithContext(ctx), user)
+}
+
+// UpdateLoginedAt records the login time of the user, the version and the update time are kept.
+func (u *users) UpdateLoginedAt(ctx context.Context, namespace, username string, loginedAt time.Time) error {
+	err := u.db.WithContext(ctx).Model(&v1.User{}).
+		Where("namespace = ? and name = ?", namespace, username).
+		UpdateColumn("loginedAt", loginedAt).Error
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
 }
 
 // Delete deletes the user by the user identifier.
-func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error {
+func (u *users) Delete(ctx context.Context, namespace, username string, opts metav1.DeleteOptions) error {
+	db := u.db.WithContext(ctx)
 	if opts.Unscoped {
-		u.db = u.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	err := u.db.Where("name = ?", username).Delete(&v1.User{}).Error
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return errors.WithCode(code.ErrDatabase, err.Error())
-	}
+	result := versioned(db, opts).Where("namespace = ? and name = ?", namespace, username).Delete(&v1.User{})
 
-	return nil
+	return deleted(result, username, opts)
 }
 
 // DeleteCollection batch deletes the users.
-func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts metav1.DeleteOptions) error {
+func (u *users) DeleteCollection(
+	ctx context.Context,
+	namespace string,
+	usernames []string,
+	opts metav1.DeleteOptions,
+) error {
+	db := u.db.WithContext(ctx)
 	if opts.Unscoped {
-		u.db = u.db.Unscoped()
+		db = db.Unscoped()
 	}
 
-	return u.db.Where("name in (?)", usernames).Delete(&v1.User{}).Error
+	return db.Where("namespace = ? and name in (?)", namespace, usernames).Delete(&v1.User{}).Error
 }
 
 // Get return an user by the user identifier.
-func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
+func (u *users) Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error) {
 	user := &v1.User{}
-	err := u.db.Where("name = ? and status = 1", username).First(&user).Error
+	db := u.db.WithContext(ctx).Where("namespace = ? and name = ?", namespace, username)
+	if !opts.IncludeDisabled {
+		db = db.Where("status = 1")
+	}
+	err := db.First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(code.ErrUserNotFound, err.Error())
@@ -83,14 +116,15 @@ func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions
 	return user, nil
 }
 
-// List return all users.
-func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+// List return all users in the namespace, or users across all namespaces if namespace is metav1.NamespaceAll.
+func (u *users) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error) {
 	ret := &v1.UserList{}
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	username, _ := selector.RequiresExactMatch("name")
-	d := u.db.Where("name like ? and status = 1", "%"+username+"%").
+	d := u.scoped(ctx, namespace).
+		Where("name like ? and status = 1", "%"+username+"%").
 		Offset(ol.Offset).
 		Limit(ol.Limit).
 		Order("id desc").
@@ -103,11 +137,15 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 }
 
 // ListOptional show a more graceful query method.
-func (u *users) ListOptional(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
+func (u *users) ListOptional(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error) {
 	ret := &v1.UserList{}
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
 
-	where := v1.User{}
+	where := v1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+		},
+	}
 	whereNot := v1.User{
 		IsAdmin: 0,
 	}
@@ -117,7 +155,7 @@ func (u *users) ListOptional(ctx context.Context, opts metav1.ListOptions) (*v1.
 		where.Name = username
 	}
 
-	d := u.db.Where(where).
+	d := u.db.WithContext(ctx).Where(where).
 		Not(whereNot).
 		Offset(ol.Offset).
 		Limit(ol.Limit).
@@ -129,3 +167,12 @@ func (u *users) ListOptional(ctx context.Context, opts metav1.ListOptions) (*v1.
 
 	return ret, d.Error
 }
+
+// scoped limits the query to the given namespace, metav1.NamespaceAll means no limitation.
+func (u *users) scoped(ctx context.Context, namespace string) *gorm.DB {
+	if namespace == metav1.NamespaceAll {
+		return u.db.WithContext(ctx)
+	}
+
+	return u.db.WithContext(ctx).Where("namespace = ?", namespace)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+)
+
+// update saves all the fields of the object and increments its version. The row is written only
+// if its version has not changed since the object was read, code.ErrResourceConflict is returned
+// otherwise, so the writes made between the read and the update are never overwritten.
+func update(db *gorm.DB, obj metav1.ObjectMetaAccessor) error {
+	meta := obj.GetObjectMeta()
+	version := meta.GetVersion()
+	meta.SetVersion(version + 1)
+
+	result := db.Model(obj).Select("*").Where("version = ?", version).Updates(obj)
+	if result.Error == nil && result.RowsAffected == 1 {
+		return nil
+	}
+
+	meta.SetVersion(version)
+	if result.Error != nil {
+		return errors.WithCode(code.ErrDatabase, result.Error.Error())
+	}
+
+	return errors.WithCode(code.ErrResourceConflict, "`%s` has been modified or deleted", meta.GetName())
+}
+
+// versioned limits the delete to the version given by the options, the delete which removes no row
+// is then checked by deleted.
+func versioned(db *gorm.DB, opts metav1.DeleteOptions) *gorm.DB {
+	if opts.Version == 0 {
+		return db
+	}
+
+	return db.Where("version = ?", opts.Version)
+}
+
+// deleted checks the result of the delete limited by versioned, code.ErrResourceConflict is returned
+// when the object to delete has been modified or deleted.
+func deleted(result *gorm.DB, name string, opts metav1.DeleteOptions) error {
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, result.Error.Error())
+	}
+
+	if opts.Version != 0 && result.RowsAffected == 0 {
+		return errors.WithCode(code.ErrResourceConflict, "`%s` has been modified or deleted", name)
+	}
+
+	return nil
+}
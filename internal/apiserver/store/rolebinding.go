@@ -0,0 +1,31 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// RoleBindingStore defines the role binding storage interface, the role bindings are scoped to a namespace.
+type RoleBindingStore interface {
+	Create(ctx context.Context, binding *v1.RoleBinding, opts metav1.CreateOptions) error
+	Update(ctx context.Context, binding *v1.RoleBinding, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, namespace, name string, opts metav1.GetOptions) (*v1.RoleBinding, error)
+	List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.RoleBindingList, error)
+}
@@ -14,13 +14,16 @@
 
 package store
 
-//go:generate mockgen -self_package=github.com/dairongpeng/leona/internal/parseserver/store -destination mock_store.go -package store github.com/dairongpeng/leona/internal/parseserver/store Factory,UserStore,SecretStore,PolicyStore
+//go:generate mockgen -self_package=github.com/dairongpeng/leona/internal/apiserver/store -destination mock_store.go -package store github.com/dairongpeng/leona/internal/apiserver/store Factory,UserStore,NamespaceStore,RoleStore,RoleBindingStore
 
 var client Factory
 
 // Factory defines the leona platform storage interface.
 type Factory interface {
 	Users() UserStore
+	Namespaces() NamespaceStore
+	Roles() RoleStore
+	RoleBindings() RoleBindingStore
 	Close() error
 }
 
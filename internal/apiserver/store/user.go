@@ -16,16 +16,22 @@ package store
 
 import (
 	"context"
+	"time"
 
 	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
 	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
 )
 
 // UserStore defines the user storage interface.
+// Users are scoped to a namespace, List with metav1.NamespaceAll returns users across all namespaces.
+// UpdateLoginedAt records a login without changing the version of the user, so that the logins do not
+// fail the preconditions of the concurrent updates.
 type UserStore interface {
 	Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error
 	Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error
-	Delete(ctx context.Context, username string, opts metav1.DeleteOptions) error
-	Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error)
-	List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error)
+	UpdateLoginedAt(ctx context.Context, namespace, username string, loginedAt time.Time) error
+	Delete(ctx context.Context, namespace, username string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, namespace string, usernames []string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, namespace, username string, opts metav1.GetOptions) (*v1.User, error)
+	List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error)
 }
@@ -0,0 +1,114 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/dairongpeng/leona/internal/apiserver/options"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/apiserver/store/etcd"
+	"github.com/dairongpeng/leona/internal/apiserver/store/mysql"
+	"github.com/dairongpeng/leona/internal/apiserver/transfer"
+	"github.com/dairongpeng/leona/pkg/app"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// newExportCommand creates the command which dumps all the resources in the storage.
+func newExportCommand() *app.Command {
+	opts := options.NewTransferOptions()
+
+	return app.NewCommand("export",
+		"Export all the resources from the storage as a YAML or JSON stream",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			return runTransfer(opts, func(ctx context.Context, factory store.Factory) error {
+				w := io.Writer(os.Stdout)
+				if opts.File != "-" {
+					f, err := os.Create(opts.File)
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					w = f
+				}
+
+				exportOpts := metav1.ExportOptions{Export: true, Exact: opts.Exact}
+				if err := transfer.Export(ctx, factory, w, opts.Format, exportOpts); err != nil {
+					return err
+				}
+				log.Infof("Export resources from %s finished", opts.Store)
+
+				return nil
+			})
+		}),
+	)
+}
+
+// newImportCommand creates the command which restores the resources into the storage.
+func newImportCommand() *app.Command {
+	opts := options.NewTransferOptions()
+
+	return app.NewCommand("import",
+		"Import the resources from a YAML or JSON stream into the storage",
+		app.WithCommandOptions(opts),
+		app.WithCommandRunFunc(func(args []string) error {
+			return runTransfer(opts, func(ctx context.Context, factory store.Factory) error {
+				r := io.Reader(os.Stdin)
+				if opts.File != "-" {
+					f, err := os.Open(opts.File)
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					r = f
+				}
+
+				count, err := transfer.Import(ctx, factory, r, opts.Format)
+				if err != nil {
+					return err
+				}
+				log.Infof("Import %d resources into %s finished", count, opts.Store)
+
+				return nil
+			})
+		}),
+	)
+}
+
+// runTransfer connects to the storage backend chosen by the options and runs fn against it.
+func runTransfer(opts *options.TransferOptions, fn func(ctx context.Context, factory store.Factory) error) error {
+	log.Init(opts.Log)
+	defer log.Flush()
+
+	var (
+		factory store.Factory
+		err     error
+	)
+	if opts.Store == options.StoreEtcd {
+		factory, err = etcd.GetEtcdFactoryOr(opts.EtcdOptions, nil)
+	} else {
+		factory, err = mysql.GetMySQLFactoryOr(opts.MySQLOptions)
+	}
+	if err != nil {
+		return err
+	}
+	defer factory.Close()
+
+	return fn(context.Background(), factory)
+}
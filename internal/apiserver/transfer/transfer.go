@@ -0,0 +1,312 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/AlekSi/pointer"
+	"github.com/ghodss/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/dairongpeng/leona/api/apiserver/scheme"
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	pkgscheme "github.com/dairongpeng/leona/pkg/scheme"
+)
+
+// Supported formats of the transfer stream.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// pageSize is the number of records fetched from the storage at a time.
+const pageSize = 500
+
+// Export writes all the resources in the storage to w as a stream of documents.
+// Namespaces are written before the users scoped to them, so that the stream can be imported in order.
+func Export(ctx context.Context, factory store.Factory, w io.Writer, format string, opts metav1.ExportOptions) error {
+	enc, err := newEncoder(w, format)
+	if err != nil {
+		return err
+	}
+
+	for offset := int64(0); ; offset += pageSize {
+		namespaces, err := factory.Namespaces().List(ctx, listOptions(offset))
+		if err != nil {
+			return errors.Wrap(err, "list namespaces failed")
+		}
+
+		for _, namespace := range namespaces.Items {
+			item := *namespace
+			item.PrepareForExport(opts)
+			if err := enc.encode(&item); err != nil {
+				return err
+			}
+		}
+
+		if isLastPage(offset, len(namespaces.Items), namespaces.TotalCount) {
+			break
+		}
+	}
+
+	for offset := int64(0); ; offset += pageSize {
+		users, err := factory.Users().List(ctx, metav1.NamespaceAll, listOptions(offset))
+		if err != nil {
+			return errors.Wrap(err, "list users failed")
+		}
+
+		for _, user := range users.Items {
+			item := *user
+			item.PrepareForExport(opts)
+			if err := enc.encode(&item); err != nil {
+				return err
+			}
+		}
+
+		if isLastPage(offset, len(users.Items), users.TotalCount) {
+			break
+		}
+	}
+
+	return enc.flush()
+}
+
+// Import reads a stream of documents from r and saves the resources into the storage,
+// existing resources are updated. It returns the number of imported resources.
+func Import(ctx context.Context, factory store.Factory, r io.Reader, format string) (int, error) {
+	dec, err := newDecoder(r, format)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		data, err := dec.decode()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, errors.WithCode(code.ErrDecodingFailed, err.Error())
+		}
+
+		obj, err := decodeObject(data)
+		if err != nil {
+			return count, errors.Wrapf(err, "decode document %d failed", count+1)
+		}
+
+		switch obj := obj.(type) {
+		case *v1.Namespace:
+			err = importNamespace(ctx, factory, obj)
+		case *v1.User:
+			err = importUser(ctx, factory, obj)
+		default:
+			err = fmt.Errorf("unsupported kind `%s` in document %d", obj.GetObjectKind().GroupVersionKind().Kind, count+1)
+		}
+		if err != nil {
+			return count, err
+		}
+
+		count++
+	}
+}
+
+// decodeObject decodes the document into the v1 object of its kind and apiVersion.
+func decodeObject(data []byte) (pkgscheme.Object, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(data, &typeMeta); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	obj, err := scheme.Scheme.New(typeMeta.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+
+	if user, ok := obj.(*v1.User); ok {
+		err = user.UnmarshalStored(data)
+	} else {
+		err = json.Unmarshal(data, obj)
+	}
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	if typeMeta.GroupVersionKind().GroupVersion() == v1.SchemeGroupVersion {
+		return obj, nil
+	}
+
+	return scheme.Scheme.ConvertToVersion(obj, v1.SchemeGroupVersion)
+}
+
+func importNamespace(ctx context.Context, factory store.Factory, namespace *v1.Namespace) error {
+	if namespace.Phase == "" {
+		namespace.Phase = v1.NamespaceActive
+	}
+
+	old, err := factory.Namespaces().Get(ctx, namespace.Name, metav1.GetOptions{})
+	if err != nil {
+		log.Infof("create namespace `%s`", namespace.Name)
+
+		return factory.Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+	}
+
+	namespace.ID = old.ID
+	namespace.InstanceID = old.InstanceID
+	namespace.CreatedAt = old.CreatedAt
+	namespace.Version = old.Version
+	log.Infof("update namespace `%s`", namespace.Name)
+
+	return factory.Namespaces().Update(ctx, namespace, metav1.UpdateOptions{})
+}
+
+func importUser(ctx context.Context, factory store.Factory, user *v1.User) error {
+	if user.Namespace == "" {
+		user.Namespace = metav1.NamespaceDefault
+	}
+	if user.Password == "" {
+		log.Warnf("user `%s/%s` has no password, it can not login until the password is reset",
+			user.Namespace, user.Name)
+	}
+
+	old, err := factory.Users().Get(ctx, user.Namespace, user.Name, metav1.GetOptions{})
+	if err != nil {
+		log.Infof("create user `%s/%s`", user.Namespace, user.Name)
+
+		return factory.Users().Create(ctx, user, metav1.CreateOptions{})
+	}
+
+	user.ID = old.ID
+	user.InstanceID = old.InstanceID
+	user.CreatedAt = old.CreatedAt
+	user.Version = old.Version
+	log.Infof("update user `%s/%s`", user.Namespace, user.Name)
+
+	return factory.Users().Update(ctx, user, metav1.UpdateOptions{})
+}
+
+// isLastPage returns true if the page is the last one. The total count is also checked, so that the
+// export ends even if the storage ignores the offset and limit.
+func isLastPage(offset int64, items int, total int64) bool {
+	return items < pageSize || offset+int64(items) >= total
+}
+
+func listOptions(offset int64) metav1.ListOptions {
+	return metav1.ListOptions{
+		Offset: pointer.ToInt64(offset),
+		Limit:  pointer.ToInt64(pageSize),
+	}
+}
+
+// encoder writes documents to the transfer stream.
+type encoder struct {
+	w      *bufio.Writer
+	format string
+}
+
+func newEncoder(w io.Writer, format string) (*encoder, error) {
+	if format != FormatYAML && format != FormatJSON {
+		return nil, fmt.Errorf("unsupported format `%s`, must be one of: %s, %s", format, FormatYAML, FormatJSON)
+	}
+
+	return &encoder{w: bufio.NewWriter(w), format: format}, nil
+}
+
+// encode writes the object with its kind and api version as one document.
+func (e *encoder) encode(obj pkgscheme.Object) error {
+	scheme.Scheme.SetTypeMeta(obj)
+
+	var data []byte
+	var err error
+	// the fields of the users which are never responded are kept by the exact export
+	if user, ok := obj.(*v1.User); ok {
+		data, err = user.MarshalStored()
+	} else {
+		data, err = json.Marshal(obj)
+	}
+	if err != nil {
+		return errors.WithCode(code.ErrEncodingJSON, err.Error())
+	}
+
+	if e.format == FormatYAML {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return errors.WithCode(code.ErrEncodingYaml, err.Error())
+		}
+		data = append([]byte("---\n"), data...)
+	} else {
+		data = append(data, '\n')
+	}
+
+	_, err = e.w.Write(data)
+
+	return err
+}
+
+func (e *encoder) flush() error {
+	return e.w.Flush()
+}
+
+// decoder reads documents from the transfer stream, each document is returned in JSON.
+type decoder struct {
+	// json decodes the next JSON document, the decoder type depends on the json build tags.
+	json func(v interface{}) error
+	yaml *yamlv3.Decoder
+}
+
+func newDecoder(r io.Reader, format string) (*decoder, error) {
+	switch format {
+	case FormatJSON:
+		return &decoder{json: json.NewDecoder(r).Decode}, nil
+	case FormatYAML:
+		return &decoder{yaml: yamlv3.NewDecoder(r)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format `%s`, must be one of: %s, %s", format, FormatYAML, FormatJSON)
+	}
+}
+
+// decode returns the next document, io.EOF is returned at the end of the stream.
+func (d *decoder) decode() ([]byte, error) {
+	if d.json != nil {
+		var data json.RawMessage
+		if err := d.json(&data); err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	}
+
+	for {
+		var doc map[string]interface{}
+		if err := d.yaml.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		// skip empty documents
+		if len(doc) == 0 {
+			continue
+		}
+
+		return json.Marshal(doc)
+	}
+}
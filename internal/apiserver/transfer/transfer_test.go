@@ -0,0 +1,169 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/apiserver/store"
+	"github.com/dairongpeng/leona/internal/apiserver/store/fake"
+)
+
+func TestExportImport(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	ctx := context.TODO()
+
+	err := storeIns.Users().Create(ctx, &v1.User{
+		ObjectMeta:      metav1.ObjectMeta{Name: "exported-mfa", Namespace: metav1.NamespaceDefault},
+		Nickname:        "exported",
+		Password:        "Exported@2020",
+		Email:           "exported-mfa@foxmail.com",
+		PasswordHistory: []string{"history"},
+		MFAEnabledAt:    time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC),
+		TOTPSecret:      "secret",
+		RecoveryCodes:   []string{"recovery"},
+	}, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Export(ctx, storeIns, &buf, format, metav1.ExportOptions{Export: true, Exact: true})
+			assert.Nil(t, err)
+			assert.Contains(t, buf.String(), "user1")
+			assert.NotContains(t, buf.String(), "instanceID")
+			assert.Contains(t, buf.String(), "apiVersion")
+			assert.Contains(t, buf.String(), "totpSecret")
+
+			count, err := Import(ctx, storeIns, &buf, format)
+			assert.Nil(t, err)
+			assert.Equal(t, fake.ResourceCount+2, count)
+
+			user, err := storeIns.Users().Get(ctx, metav1.NamespaceDefault, "user1", metav1.GetOptions{})
+			assert.Nil(t, err)
+			assert.NotEmpty(t, user.Password)
+
+			// the second factor is kept by the exact export
+			user, err = storeIns.Users().Get(ctx, metav1.NamespaceDefault, "exported-mfa", metav1.GetOptions{})
+			assert.Nil(t, err)
+			assert.True(t, user.MFAEnabled())
+			assert.Equal(t, []string{"history"}, user.PasswordHistory)
+			assert.Equal(t, []string{"recovery"}, user.RecoveryCodes)
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	ctx := context.TODO()
+
+	stream := `
+---
+kind: Namespace
+apiVersion: v1
+metadata:
+  name: tenant-import
+description: imported namespace
+---
+kind: User
+apiVersion: v1
+metadata:
+  name: imported
+  namespace: tenant-import
+nickname: imported
+email: imported@foxmail.com
+---
+kind: User
+apiVersion: v2
+metadata:
+  name: imported-v2
+  namespace: tenant-import
+spec:
+  profile:
+    displayName: imported
+    email: imported-v2@foxmail.com
+  admin: true
+`
+	count, err := Import(ctx, storeIns, strings.NewReader(stream), FormatYAML)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	namespace, err := storeIns.Namespaces().Get(ctx, "tenant-import", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, v1.NamespaceActive, namespace.Phase)
+
+	user, err := storeIns.Users().Get(ctx, "tenant-import", "imported", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "imported@foxmail.com", user.Email)
+
+	user, err = storeIns.Users().Get(ctx, "tenant-import", "imported-v2", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "imported-v2@foxmail.com", user.Email)
+	assert.Equal(t, 1, user.IsAdmin)
+
+	_, err = Import(ctx, storeIns, strings.NewReader(`{"kind":"Secret","apiVersion":"v1"}`), FormatJSON)
+	assert.NotNil(t, err)
+
+	_, err = Import(ctx, storeIns, strings.NewReader(""), "xml")
+	assert.NotNil(t, err)
+}
+
+// unpagedUsers is a user store which ignores the offset and limit of the list options.
+type unpagedUsers struct {
+	store.UserStore
+}
+
+func (u unpagedUsers) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*v1.UserList, error) {
+	return u.UserStore.List(ctx, namespace, metav1.ListOptions{Limit: pointer.ToInt64(-1)})
+}
+
+type unpagedFactory struct {
+	store.Factory
+}
+
+func (f unpagedFactory) Users() store.UserStore {
+	return unpagedUsers{f.Factory.Users()}
+}
+
+func TestExportKeepsNamespace(t *testing.T) {
+	storeIns, _ := fake.GetFakeFactoryOr()
+	ctx := context.TODO()
+
+	err := storeIns.Users().Create(ctx, &v1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "exported", Namespace: "tenant-export"},
+		Nickname:   "exported",
+		Password:   "Exported@2020",
+		Email:      "exported@foxmail.com",
+	}, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	err = Export(ctx, unpagedFactory{storeIns}, &buf, FormatJSON, metav1.ExportOptions{Export: true})
+	assert.Nil(t, err)
+
+	users, _ := storeIns.Users().List(ctx, metav1.NamespaceAll, metav1.ListOptions{Limit: pointer.ToInt64(-1)})
+	assert.Equal(t, int(users.TotalCount), strings.Count(buf.String(), `"kind":"User"`))
+	assert.Contains(t, buf.String(), `"namespace":"tenant-export"`)
+	assert.NotContains(t, buf.String(), "Exported@2020")
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gstash
+
+import (
+	"time"
+
+	"github.com/dairongpeng/leona/internal/gstash/gstashs"
+	"github.com/dairongpeng/leona/internal/gstash/options"
+	"github.com/dairongpeng/leona/internal/gstash/storage"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// maxRetryBackoff is the longest delay between the retries of a writing.
+const maxRetryBackoff = time.Minute
+
+// lockExpiry is how long the gstash lock is held unless it is extended, it is extended every third
+// of it while the records are delivered.
+const lockExpiry = 10 * time.Minute
+
+// isolationTimeout bounds how long the records of a batch are written one by one.
+const isolationTimeout = lockExpiry / 2
+
+// sleep and now are replaced by the tests.
+var (
+	sleep = time.Sleep
+	now   = time.Now
+)
+
+// deliver delivers a batch of records at least once. The batch is moved to the processing list
+// before it is written, and the list is deleted only after the batch is written to all the required
+// gstashs. The batch left in the processing list, by a failure or a crash of the previous round, is
+// delivered again before a new one is taken. The records which can not be decoded, and the ones of a
+// batch delivered max-deliveries times which still can not be written, are moved to the dead-letter list.
+// It must be called with the gstash lock held.
+func (s *gstashServer) deliver() {
+	values, err := s.analyticsStore.GetSet(storage.ProcessingKeyName)
+	if err != nil {
+		log.Errorf("Couldn't get the processing analytics data: %s", err.Error())
+
+		return
+	}
+
+	if len(values) > 0 {
+		log.Warnf("Delivering %d in-flight analytics records again", len(values))
+		recordsRecovered.Add(float64(len(values)))
+	} else {
+		values, err = s.analyticsStore.MoveToProcessing(
+			storage.AnalyticsKeyName, storage.ProcessingKeyName, int64(s.batchSize))
+		if err != nil {
+			log.Errorf("Couldn't take the analytics data: %s", err.Error())
+
+			return
+		}
+		if len(values) == 0 {
+			return
+		}
+		recordsPulled.Add(float64(len(values)))
+	}
+
+	deliveries, err := s.analyticsStore.IncrKey(storage.DeliveriesKeyName)
+	if err != nil {
+		log.Errorf("Couldn't count the deliveries of the analytics data: %s", err.Error())
+
+		return
+	}
+
+	if deliveries > int64(s.maxDeliveries) {
+		s.isolate(values)
+
+		return
+	}
+
+	keys, poison := s.decode(values)
+	if len(keys) > 0 && !writeToGStashs(keys, s.secInterval, true) {
+		log.Warnf("Writing to the required gstashs failed, the analytics data will be delivered again")
+
+		return
+	}
+
+	s.acknowledge(poison)
+}
+
+// isolate writes the records of a batch delivered max-deliveries times one by one, each of them once
+// without the retries, which are spent by the deliveries. The records which still can not be written
+// while the others are written are moved to the dead-letter list, so are the records not written before
+// the isolation times out. The batch is kept if none of its records is written, the required gstashs
+// are down rather than the records bad.
+func (s *gstashServer) isolate(values []interface{}) {
+	var poison, failed []interface{}
+	written := 0
+	deadline := now().Add(isolationTimeout)
+	for i, v := range values {
+		if now().After(deadline) {
+			log.Warnf("Writing the analytics records one by one timed out, %d of them are not written",
+				len(values)-i)
+			failed = append(failed, values[i:]...)
+
+			break
+		}
+
+		keys, undecodable := s.decode([]interface{}{v})
+		switch {
+		case len(undecodable) > 0:
+			poison = append(poison, v)
+		case writeToGStashs(keys, s.secInterval, false):
+			written++
+		default:
+			failed = append(failed, v)
+		}
+	}
+
+	if written == 0 && len(failed) > 0 {
+		log.Warnf("None of the %d analytics records can be written to the required gstashs, "+
+			"they will be delivered again", len(failed))
+
+		return
+	}
+	if len(failed) > 0 {
+		log.Errorf("%d analytics records failed to be delivered %d times, moving them to %s",
+			len(failed), s.maxDeliveries, storage.DeadLetterKeyName)
+	}
+
+	s.acknowledge(append(poison, failed...))
+}
+
+// acknowledge moves the records which can not be delivered to the dead-letter list, and deletes the
+// processing list whose other records are written.
+func (s *gstashServer) acknowledge(poison []interface{}) {
+	if err := s.analyticsStore.AppendToSet(storage.DeadLetterKeyName, poison); err != nil {
+		log.Errorf("Couldn't move the analytics data to the dead-letter list: %s", err.Error())
+
+		return
+	}
+	recordsDeadLettered.Add(float64(len(poison)))
+
+	// 全部必需的插件写入成功后确认，删除处理中的记录
+	if err := s.analyticsStore.DeleteKeys(storage.ProcessingKeyName, storage.DeliveriesKeyName); err != nil {
+		log.Errorf("Couldn't acknowledge the analytics data: %s", err.Error())
+	}
+}
+
+// writeWithRetry writes the records to the gstash, the failed writing is retried with an
+// exponential backoff.
+func writeWithRetry(
+	pmp gstashs.Gstash,
+	health *gstashHealth,
+	config options.GStashConfig,
+	keys []interface{},
+	purgeDelay int,
+) error {
+	backoff := time.Duration(config.RetryBackoff) * time.Second
+
+	for attempt := 0; ; attempt++ {
+		err := execGStashWriting(pmp, health, keys, purgeDelay)
+		if err == nil || attempt >= config.Retries {
+			return err
+		}
+
+		log.Warnf("Retrying writing to %s in %s", pmp.GetName(), backoff)
+		writeRetries.WithLabelValues(health.name).Inc()
+		sleep(backoff)
+
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
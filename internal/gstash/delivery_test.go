@@ -0,0 +1,348 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gstash
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dairongpeng/leona/internal/gstash/gstashs"
+	"github.com/dairongpeng/leona/internal/gstash/options"
+	"github.com/dairongpeng/leona/internal/gstash/storage"
+	schema "github.com/dairongpeng/leona/pkg/analytics"
+)
+
+type fakeStore struct {
+	lists    map[string][]interface{}
+	counters map[string]int64
+}
+
+func newFakeStore(values ...interface{}) *fakeStore {
+	return &fakeStore{
+		lists:    map[string][]interface{}{storage.AnalyticsKeyName: values},
+		counters: map[string]int64{},
+	}
+}
+
+func (f *fakeStore) Init(config interface{}) error { return nil }
+func (f *fakeStore) GetName() string               { return "fake" }
+func (f *fakeStore) Connect() bool                 { return true }
+func (f *fakeStore) Ping() error                   { return nil }
+
+func (f *fakeStore) GetAndDeleteSet(keyName string) []interface{} {
+	values := f.lists[keyName]
+	delete(f.lists, keyName)
+
+	return values
+}
+
+func (f *fakeStore) MoveToProcessing(keyName, processingKeyName string, count int64) ([]interface{}, error) {
+	values := f.lists[keyName]
+	if int64(len(values)) > count {
+		values = values[:count]
+	}
+	f.lists[keyName] = f.lists[keyName][len(values):]
+	f.lists[processingKeyName] = append(f.lists[processingKeyName], values...)
+
+	return values, nil
+}
+
+func (f *fakeStore) GetSet(keyName string) ([]interface{}, error) {
+	return f.lists[keyName], nil
+}
+
+func (f *fakeStore) AppendToSet(keyName string, values []interface{}) error {
+	f.lists[keyName] = append(f.lists[keyName], values...)
+
+	return nil
+}
+
+func (f *fakeStore) IncrKey(keyName string) (int64, error) {
+	f.counters[keyName]++
+
+	return f.counters[keyName], nil
+}
+
+func (f *fakeStore) DeleteKeys(keyNames ...string) error {
+	for _, name := range keyNames {
+		delete(f.lists, name)
+		delete(f.counters, name)
+	}
+
+	return nil
+}
+
+type fakeGstash struct {
+	gstashs.CommonGstashConfig
+	fails int
+	// reject is the username of the records failing to be written
+	reject  string
+	written [][]interface{}
+}
+
+func (f *fakeGstash) GetName() string             { return "fake" }
+func (f *fakeGstash) New() gstashs.Gstash         { return &fakeGstash{} }
+func (f *fakeGstash) Init(meta interface{}) error { return nil }
+
+func (f *fakeGstash) WriteData(ctx context.Context, keys []interface{}) error {
+	if f.fails != 0 {
+		f.fails--
+
+		return errors.New("write failed")
+	}
+	for _, key := range keys {
+		if record, _ := key.(schema.Record); f.reject != "" && record.Username == f.reject {
+			return errors.New("record rejected")
+		}
+	}
+	f.written = append(f.written, keys)
+
+	return nil
+}
+
+func setGstashs(t *testing.T, configs []options.GStashConfig, pmpIns ...gstashs.Gstash) {
+	t.Helper()
+
+	pmps = pmpIns
+	pmpsConfig = configs
+	pmpsHealth = make([]*gstashHealth, len(pmpIns))
+	for i := range pmpIns {
+		pmpsHealth[i] = newGstashHealth("fake")
+	}
+	sleep = func(time.Duration) {}
+
+	t.Cleanup(func() {
+		pmps, pmpsConfig, pmpsHealth = nil, nil, nil
+		sleep, now = time.Sleep, time.Now
+	})
+}
+
+func encodedRecords(t *testing.T, n int) []interface{} {
+	t.Helper()
+
+	usernames := make([]string, n)
+	for i := range usernames {
+		usernames[i] = "admin"
+	}
+
+	return encodedRecordsOf(t, usernames...)
+}
+
+func encodedRecordsOf(t *testing.T, usernames ...string) []interface{} {
+	t.Helper()
+
+	values := make([]interface{}, 0, len(usernames))
+	for _, username := range usernames {
+		data, err := schema.Encode(schema.CodecMsgpack, &schema.Record{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, string(data))
+	}
+
+	return values
+}
+
+func newTestServer(store storage.AnalyticsStorage) *gstashServer {
+	return &gstashServer{
+		secInterval:    10,
+		analyticsStore: store,
+		deliveryMode:   options.DeliveryAtLeastOnce,
+		batchSize:      2,
+		maxDeliveries:  2,
+	}
+}
+
+func TestDeliverAcknowledgesWrittenBatch(t *testing.T) {
+	pmp := &fakeGstash{}
+	setGstashs(t, []options.GStashConfig{{}}, pmp)
+	store := newFakeStore(encodedRecords(t, 3)...)
+
+	newTestServer(store).deliver()
+
+	if len(pmp.written) != 1 || len(pmp.written[0]) != 2 {
+		t.Fatalf("expected a batch of 2 records written, got %v", pmp.written)
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 0 {
+		t.Errorf("expected the processing list to be acknowledged")
+	}
+	if len(store.lists[storage.AnalyticsKeyName]) != 1 {
+		t.Errorf("expected 1 record left in the queue, got %d", len(store.lists[storage.AnalyticsKeyName]))
+	}
+}
+
+func TestDeliverRetriesFailedWriting(t *testing.T) {
+	pmp := &fakeGstash{fails: 2}
+	setGstashs(t, []options.GStashConfig{{Retries: 2, RetryBackoff: 1}}, pmp)
+	store := newFakeStore(encodedRecords(t, 1)...)
+
+	newTestServer(store).deliver()
+
+	if len(pmp.written) != 1 {
+		t.Fatalf("expected the batch written after the retries, got %v", pmp.written)
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 0 {
+		t.Errorf("expected the processing list to be acknowledged")
+	}
+}
+
+func TestDeliverRecoversUnacknowledgedBatch(t *testing.T) {
+	pmp := &fakeGstash{fails: 1}
+	setGstashs(t, []options.GStashConfig{{}}, pmp)
+	store := newFakeStore(encodedRecords(t, 3)...)
+	s := newTestServer(store)
+
+	s.deliver()
+	if len(store.lists[storage.ProcessingKeyName]) != 2 {
+		t.Fatalf("expected the failed batch kept in the processing list")
+	}
+
+	s.deliver()
+	if len(pmp.written) != 1 || len(pmp.written[0]) != 2 {
+		t.Fatalf("expected the in-flight batch delivered again, got %v", pmp.written)
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 0 || len(store.lists[storage.AnalyticsKeyName]) != 1 {
+		t.Errorf("expected the in-flight batch acknowledged before a new one is taken")
+	}
+}
+
+func TestDeliverDeadLettersPoisonRecords(t *testing.T) {
+	pmp := &fakeGstash{}
+	setGstashs(t, []options.GStashConfig{{}}, pmp)
+	store := newFakeStore(append(encodedRecords(t, 1), "not a record")...)
+
+	newTestServer(store).deliver()
+
+	if len(pmp.written) != 1 || len(pmp.written[0]) != 1 {
+		t.Fatalf("expected the decodable record written, got %v", pmp.written)
+	}
+	if dead := store.lists[storage.DeadLetterKeyName]; len(dead) != 1 || dead[0] != "not a record" {
+		t.Errorf("expected the undecodable record dead-lettered, got %v", dead)
+	}
+}
+
+func TestDeliverDeadLettersAfterMaxDeliveries(t *testing.T) {
+	pmp := &fakeGstash{reject: "bad"}
+	setGstashs(t, []options.GStashConfig{{}}, pmp)
+	values := encodedRecordsOf(t, "admin", "bad")
+	store := newFakeStore(values...)
+	s := newTestServer(store)
+
+	for i := 0; i <= s.maxDeliveries; i++ {
+		s.deliver()
+	}
+
+	if len(pmp.written) != 1 || len(pmp.written[0]) != 1 {
+		t.Fatalf("expected the good record written on its own, got %v", pmp.written)
+	}
+	if dead := store.lists[storage.DeadLetterKeyName]; len(dead) != 1 || dead[0] != values[1] {
+		t.Errorf("expected only the failing record dead-lettered, got %v", dead)
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 0 || store.counters[storage.DeliveriesKeyName] != 0 {
+		t.Errorf("expected the dead-lettered batch acknowledged")
+	}
+}
+
+func TestDeliverIsolatesWithoutRetries(t *testing.T) {
+	pmp := &fakeGstash{reject: "bad"}
+	setGstashs(t, []options.GStashConfig{{Retries: 3, RetryBackoff: 1}}, pmp)
+	sleeps := 0
+	sleep = func(time.Duration) { sleeps++ }
+	store := newFakeStore(encodedRecordsOf(t, "admin", "bad")...)
+	s := newTestServer(store)
+
+	for i := 0; i < s.maxDeliveries; i++ {
+		s.deliver()
+	}
+	retried := sleeps
+
+	s.deliver()
+	if sleeps != retried {
+		t.Errorf("expected the records written one by one without the retries, got %d retries", sleeps-retried)
+	}
+	if len(store.lists[storage.DeadLetterKeyName]) != 1 || len(store.lists[storage.ProcessingKeyName]) != 0 {
+		t.Errorf("expected the failing record dead-lettered and the batch acknowledged")
+	}
+}
+
+func TestDeliverIsolationTimesOut(t *testing.T) {
+	pmp := &fakeGstash{}
+	setGstashs(t, []options.GStashConfig{{}}, pmp)
+	// each reading of the clock passes the whole isolation timeout
+	clock := time.Now()
+	now = func() time.Time {
+		clock = clock.Add(isolationTimeout)
+
+		return clock
+	}
+	values := encodedRecords(t, 2)
+	store := newFakeStore(values...)
+	s := newTestServer(store)
+	store.counters[storage.DeliveriesKeyName] = int64(s.maxDeliveries)
+
+	s.deliver()
+
+	if len(pmp.written) != 1 {
+		t.Fatalf("expected a record written before the isolation timed out, got %v", pmp.written)
+	}
+	if dead := store.lists[storage.DeadLetterKeyName]; len(dead) != 1 || dead[0] != values[1] {
+		t.Errorf("expected the record not written in time dead-lettered, got %v", dead)
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 0 {
+		t.Errorf("expected the batch acknowledged")
+	}
+}
+
+func TestDeliverKeepsBatchWhenGstashIsDown(t *testing.T) {
+	pmp := &fakeGstash{fails: -1}
+	setGstashs(t, []options.GStashConfig{{}}, pmp)
+	store := newFakeStore(encodedRecords(t, 2)...)
+	s := newTestServer(store)
+
+	for i := 0; i <= s.maxDeliveries; i++ {
+		s.deliver()
+	}
+
+	if len(store.lists[storage.DeadLetterKeyName]) != 0 {
+		t.Errorf("expected nothing dead-lettered while the gstash is down, got %v", store.lists[storage.DeadLetterKeyName])
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 2 {
+		t.Fatalf("expected the batch kept in the processing list")
+	}
+
+	// the batch is written once the gstash is back
+	pmp.fails = 0
+	s.deliver()
+	if len(pmp.written) != 2 || len(store.lists[storage.ProcessingKeyName]) != 0 {
+		t.Errorf("expected the batch written and acknowledged, got %v", pmp.written)
+	}
+}
+
+func TestDeliverIgnoresOptionalGstash(t *testing.T) {
+	required, optional := &fakeGstash{}, &fakeGstash{fails: -1}
+	setGstashs(t, []options.GStashConfig{{}, {Optional: true}}, required, optional)
+	store := newFakeStore(encodedRecords(t, 1)...)
+
+	newTestServer(store).deliver()
+
+	if len(required.written) != 1 {
+		t.Fatalf("expected the batch written to the required gstash")
+	}
+	if len(store.lists[storage.ProcessingKeyName]) != 0 {
+		t.Errorf("expected the failed optional gstash not to block the acknowledgement")
+	}
+}
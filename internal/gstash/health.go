@@ -0,0 +1,63 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gstash
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// gstashHealth records the result of the latest writing to a gstash, it is the readiness check
+// of the gstash, so the status of every gstash is listed by `/readyz?verbose`.
+type gstashHealth struct {
+	name string
+	lock sync.RWMutex
+	err  error
+}
+
+func newGstashHealth(name string) *gstashHealth {
+	return &gstashHealth{name: name}
+}
+
+// Name returns the name of the health check.
+func (h *gstashHealth) Name() string {
+	return "gstash-" + h.name
+}
+
+// Check returns the error of the latest writing to the gstash.
+func (h *gstashHealth) Check(ctx context.Context) error {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.err
+}
+
+func (h *gstashHealth) set(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.err = err
+}
+
+// setWritten records the result of a writing to the gstash.
+func (h *gstashHealth) setWritten(err error) {
+	if err != nil {
+		err = fmt.Errorf("writing at %s failed: %w", time.Now().Format(time.RFC3339), err)
+	}
+
+	h.set(err)
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gstash
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "leona"
+	metricsSubsystem = "gstash"
+
+	lockAcquired  = "acquired"
+	lockContended = "contended"
+)
+
+// The metrics are registered to the default registry, they are served by the `/metrics` endpoint
+// of the health check address. The gstashs are labeled by their configuration keys.
+var (
+	recordsPulled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_pulled_total",
+		Help:      "The number of the analytics records pulled from redis.",
+	})
+
+	decodeErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "decode_errors_total",
+		Help:      "The number of the analytics records failed to be decoded.",
+	})
+
+	recordsWritten = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_written_total",
+		Help:      "The number of the analytics records written to a gstash.",
+	}, []string{"gstash"})
+
+	recordsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_failed_total",
+		Help:      "The number of the analytics records failed to be written to a gstash, or timed out.",
+	}, []string{"gstash"})
+
+	recordsFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_filtered_total",
+		Help:      "The number of the analytics records dropped by the filters of a gstash.",
+	}, []string{"gstash"})
+
+	writeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "write_duration_seconds",
+		Help:      "The time taken to write a batch of analytics records to a gstash.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"gstash"})
+
+	writeRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "write_retries_total",
+		Help:      "The number of the retries of the failed writings to a gstash.",
+	}, []string{"gstash"})
+
+	recordsRecovered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_recovered_total",
+		Help:      "The number of the in-flight analytics records delivered again after a failure or a crash.",
+	})
+
+	recordsDeadLettered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "records_dead_lettered_total",
+		Help:      "The number of the analytics records moved to the dead-letter list.",
+	})
+
+	lockAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "lock_attempts_total",
+		Help:      "The number of the attempts to take the purge lock, contended when another instance holds it.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		recordsPulled,
+		decodeErrors,
+		recordsWritten,
+		recordsFailed,
+		recordsFiltered,
+		writeDuration,
+		writeRetries,
+		recordsRecovered,
+		recordsDeadLettered,
+		lockAttempts,
+	)
+}
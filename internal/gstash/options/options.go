@@ -22,6 +22,15 @@ import (
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
+// The delivery modes of the analytics records.
+const (
+	// DeliveryAtLeastOnce keeps the records in a processing list until they are written to all the
+	// required gstashs, the records are written again after a failure or a crash.
+	DeliveryAtLeastOnce = "at-least-once"
+	// DeliveryAtMostOnce deletes the records from redis before they are written.
+	DeliveryAtMostOnce = "at-most-once"
+)
+
 // GStashConfig defines options for gstash back-end.
 type GStashConfig struct {
 	Type                  string                     `json:"type"                    mapstructure:"type"`
@@ -29,6 +38,12 @@ type GStashConfig struct {
 	Timeout               int                        `json:"timeout"                 mapstructure:"timeout"`
 	OmitDetailedRecording bool                       `json:"omit-detailed-recording" mapstructure:"omit-detailed-recording"`
 	Meta                  map[string]interface{}     `json:"meta"                    mapstructure:"meta"`
+	// Optional gstashs do not hold back the acknowledgement of the records when they fail.
+	Optional bool `json:"optional"                mapstructure:"optional"`
+	// Retries is how many times a failed writing is retried, the delay in seconds between
+	// the retries starts from RetryBackoff and doubles every time.
+	Retries      int `json:"retries"                 mapstructure:"retries"`
+	RetryBackoff int `json:"retry-backoff"           mapstructure:"retry-backoff"`
 }
 
 // Options runs a gstashserver.
@@ -38,6 +53,9 @@ type Options struct {
 	HealthCheckPath       string                       `json:"health-check-path"       mapstructure:"health-check-path"`
 	HealthCheckAddress    string                       `json:"health-check-address"    mapstructure:"health-check-address"`
 	OmitDetailedRecording bool                         `json:"omit-detailed-recording" mapstructure:"omit-detailed-recording"`
+	DeliveryMode          string                       `json:"delivery-mode"           mapstructure:"delivery-mode"`
+	BatchSize             int                          `json:"batch-size"              mapstructure:"batch-size"`
+	MaxDeliveries         int                          `json:"max-deliveries"          mapstructure:"max-deliveries"`
 	RedisOptions          *genericoptions.RedisOptions `json:"redis"                   mapstructure:"redis"`
 	Log                   *log.Options                 `json:"log"                     mapstructure:"log"`
 }
@@ -52,10 +70,15 @@ func NewOptions() *Options {
 				Meta: map[string]interface{}{
 					"csv_dir": "./analytics-data",
 				},
+				Retries:      3,
+				RetryBackoff: 1,
 			},
 		},
 		HealthCheckPath:    "healthz",
 		HealthCheckAddress: "0.0.0.0:7070",
+		DeliveryMode:       DeliveryAtLeastOnce,
+		BatchSize:          1000,
+		MaxDeliveries:      5,
 		RedisOptions:       genericoptions.NewRedisOptions(),
 		Log:                log.NewOptions(),
 	}
@@ -76,9 +99,17 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	fs.StringVar(&o.HealthCheckPath, "health-check-path", o.HealthCheckPath, ""+
 		"Specifies liveness health check request path.")
 	fs.StringVar(&o.HealthCheckAddress, "health-check-address", o.HealthCheckAddress, ""+
-		"Specifies liveness health check bind address.")
+		"Specifies the bind address of the health checks and of the prometheus metrics at /metrics.")
 	fs.BoolVar(&o.OmitDetailedRecording, "omit-detailed-recording", o.OmitDetailedRecording, ""+
 		"Setting this to true will avoid writing policy fields for each authorization request in gstashs.")
+	fs.StringVar(&o.DeliveryMode, "delivery-mode", o.DeliveryMode, ""+
+		"How the records are delivered to the gstashs, at-least-once keeps them in redis until they are written "+
+		"to all the required gstashs, at-most-once deletes them before they are written.")
+	fs.IntVar(&o.BatchSize, "batch-size", o.BatchSize, ""+
+		"The number of the records delivered in a batch in the at-least-once mode.")
+	fs.IntVar(&o.MaxDeliveries, "max-deliveries", o.MaxDeliveries, ""+
+		"How many times a batch is delivered at most in the at-least-once mode, "+
+		"it is moved to the dead-letter list then.")
 
 	return fss
 }
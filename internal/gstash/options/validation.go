@@ -14,10 +14,33 @@
 
 package options
 
+import "fmt"
+
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() []error {
 	var errs []error
 
+	switch o.DeliveryMode {
+	case DeliveryAtLeastOnce, DeliveryAtMostOnce:
+	default:
+		errs = append(errs, fmt.Errorf("--delivery-mode %q must be one of %s or %s",
+			o.DeliveryMode, DeliveryAtLeastOnce, DeliveryAtMostOnce))
+	}
+
+	if o.BatchSize < 1 || o.BatchSize > 10000 {
+		errs = append(errs, fmt.Errorf("--batch-size %v must be between 1 and 10000", o.BatchSize))
+	}
+
+	if o.MaxDeliveries < 1 {
+		errs = append(errs, fmt.Errorf("--max-deliveries %v must be at least 1", o.MaxDeliveries))
+	}
+
+	for name, gstash := range o.Gstashs {
+		if gstash.Retries < 0 || gstash.RetryBackoff < 0 {
+			errs = append(errs, fmt.Errorf("the retries and retry-backoff of gstash %s must not be negative", name))
+		}
+	}
+
 	errs = append(errs, o.RedisOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
 
@@ -21,10 +21,11 @@ import (
 
 // Run runs the specified gstash server. This should never exit.
 func Run(cfg *config.Config, stopCh <-chan struct{}) error {
-	go genericapiserver.ServeHealthCheck(cfg.HealthCheckPath, cfg.HealthCheckAddress)
+	healthChecks := genericapiserver.NewHealthChecks()
+	go genericapiserver.ServeHealthCheck(cfg.HealthCheckPath, cfg.HealthCheckAddress, healthChecks)
 
 	// 启动gstash server
-	server, err := createGstashServer(cfg)
+	server, err := createGstashServer(cfg, healthChecks)
 	if err != nil {
 		return err
 	}
@@ -17,8 +17,6 @@ package gstash
 import (
 	"context"
 	"fmt"
-	"github.com/vmihailenco/msgpack/v5"
-
 	"sync"
 	"time"
 
@@ -32,10 +30,18 @@ import (
 	"github.com/dairongpeng/leona/internal/gstash/options"
 	"github.com/dairongpeng/leona/internal/gstash/storage"
 	"github.com/dairongpeng/leona/internal/gstash/storage/redis"
+	genericapiserver "github.com/dairongpeng/leona/internal/pkg/server"
+	schema "github.com/dairongpeng/leona/pkg/analytics"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
-var pmps []gstashs.Gstash
+var (
+	pmps []gstashs.Gstash
+	// pmpsHealth 记录每个插件最近一次写入的结果，与 pmps 一一对应
+	pmpsHealth []*gstashHealth
+	// pmpsConfig 记录每个插件的配置，与 pmps 一一对应
+	pmpsConfig []options.GStashConfig
+)
 
 type gstashServer struct {
 	secInterval int
@@ -44,6 +50,11 @@ type gstashServer struct {
 	mutex          *redsync.Mutex
 	analyticsStore storage.AnalyticsStorage
 	gstash         map[string]options.GStashConfig
+	healthChecks   *genericapiserver.HealthChecks
+	// 投递方式，以及 at-least-once 方式下每批的记录数和最多投递次数
+	deliveryMode  string
+	batchSize     int
+	maxDeliveries int
 }
 
 // preparedGenericAPIServer is a private wrapper that enforces a call of PrepareRun() before Run can be invoked.
@@ -51,7 +62,7 @@ type preparedGstashServer struct {
 	*gstashServer
 }
 
-func createGstashServer(cfg *config.Config) (*gstashServer, error) {
+func createGstashServer(cfg *config.Config, healthChecks *genericapiserver.HealthChecks) (*gstashServer, error) {
 	// use the same redis database with authorization log history
 	// 创建redis客户端
 	client := goredislib.NewClient(&goredislib.Options{
@@ -67,11 +78,15 @@ func createGstashServer(cfg *config.Config) (*gstashServer, error) {
 		secInterval: cfg.PurgeDelay,
 		omitDetails: cfg.OmitDetailedRecording,
 		// 分布式锁
-		mutex: rs.NewMutex("leona-gstash", redsync.WithExpiry(10*time.Minute)),
+		mutex: rs.NewMutex("leona-gstash", redsync.WithExpiry(lockExpiry)),
 		// input的数据源
 		analyticsStore: &redis.RedisClusterStorageManager{},
 		// 输出源配置列表
-		gstash: cfg.Gstashs,
+		gstash:        cfg.Gstashs,
+		healthChecks:  healthChecks,
+		deliveryMode:  cfg.DeliveryMode,
+		batchSize:     cfg.BatchSize,
+		maxDeliveries: cfg.MaxDeliveries,
 	}
 
 	// 初始化redis的配置
@@ -79,6 +94,11 @@ func createGstashServer(cfg *config.Config) (*gstashServer, error) {
 		return nil, err
 	}
 
+	// 数据源redis不可用时就绪检查失败
+	healthChecks.AddReadyzChecks(genericapiserver.NamedCheck("redis", func(context.Context) error {
+		return server.analyticsStore.Ping()
+	}))
+
 	return server, nil
 }
 
@@ -103,6 +123,7 @@ func (s preparedGstashServer) Run(stopCh <-chan struct{}) error {
 		// exit consumption cycle when receive SIGINT and SIGTERM signal
 		case <-stopCh:
 			log.Info("stop purge loop")
+			s.healthChecks.SetShuttingDown()
 
 			return nil
 		}
@@ -114,49 +135,101 @@ func (s *gstashServer) gStash() {
 	// 分布式锁控制，保证gstash可以横向扩展
 	if err := s.mutex.Lock(); err != nil {
 		log.Info("there is already an leona-gstash instance running.")
+		lockAttempts.WithLabelValues(lockContended).Inc()
 
 		return
 	}
+	lockAttempts.WithLabelValues(lockAcquired).Inc()
 	defer func() {
 		if _, err := s.mutex.Unlock(); err != nil {
 			log.Errorf("could not release iam-gStash lock. err: %v", err)
 		}
 	}()
+	// the writings may be retried with long backoffs, the lock is extended until they are done
+	defer s.keepLock()()
+
+	if s.deliveryMode == options.DeliveryAtLeastOnce {
+		s.deliver()
+
+		return
+	}
 
 	// 消费后删除，和kafka不同的是, kafka可以基于一批数据多次消费
 	analyticsValues := s.analyticsStore.GetAndDeleteSet(storage.AnalyticsKeyName)
 	if len(analyticsValues) == 0 {
 		return
 	}
+	recordsPulled.Add(float64(len(analyticsValues)))
+
+	// 拿到队列中的每一条记录，细粒度的处理。压缩，脱敏等，无法解码的记录被丢弃
+	keys, _ := s.decode(analyticsValues)
+
+	// Send to gstash
+	writeToGStashs(keys, s.secInterval, true)
+}
 
+// keepLock extends the gstash lock periodically until the returned func is called, so that the lock
+// is not expired and taken by another instance while the records are being delivered.
+func (s *gstashServer) keepLock() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lockExpiry / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if ok, err := s.mutex.Extend(); !ok {
+					log.Errorf("could not extend iam-gStash lock. err: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// decode decodes the records by the codec of their header bytes, the records which can not be
+// decoded are returned as poison.
+func (s *gstashServer) decode(values []interface{}) (keys, poison []interface{}) {
 	// Convert to something clean
-	keys := make([]interface{}, len(analyticsValues))
+	keys = make([]interface{}, 0, len(values))
 
-	// 拿到队列中的每一条记录，细粒度的处理。压缩，脱敏等
-	for i, v := range analyticsValues {
-		decoded := analytics.AnalyticsRecord{}
-		err := msgpack.Unmarshal([]byte(v.(string)), &decoded)
-		log.Debugf("Decoded Record: %v", decoded)
+	for _, v := range values {
+		raw, _ := v.(string)
+		decoded, err := schema.Decode([]byte(raw))
 		if err != nil {
 			log.Errorf("Couldn't unmarshal analytics data: %s", err.Error())
-		} else {
-			if s.omitDetails { // 简单的脱敏
-				decoded.Policies = ""
-				decoded.Deciders = ""
-			}
-			keys[i] = interface{}(decoded)
+			decodeErrors.Inc()
+			poison = append(poison, v)
+
+			continue
+		}
+		log.Debugf("Decoded Record: %v", decoded)
+
+		if s.omitDetails { // 简单的脱敏
+			decoded.Policies = ""
+			decoded.Deciders = ""
 		}
+		keys = append(keys, *decoded)
 	}
 
-	// Send to gstash
-	writeToGStashs(keys, s.secInterval)
+	return keys, poison
 }
 
 func (s *gstashServer) initialize() {
 	// 该server配置了多少个收集器插件，初始化这些收集器
 	pmps = make([]gstashs.Gstash, len(s.gstash))
+	pmpsHealth = make([]*gstashHealth, len(s.gstash))
+	pmpsConfig = make([]options.GStashConfig, len(s.gstash))
 	i := 0
 	for key, pmp := range s.gstash {
+		pmpsConfig[i] = pmp
+		pmpsHealth[i] = newGstashHealth(key)
+		s.healthChecks.AddReadyzChecks(pmpsHealth[i])
+
 		gstashTypeName := pmp.Type
 		if gstashTypeName == "" {
 			gstashTypeName = key
@@ -166,6 +239,7 @@ func (s *gstashServer) initialize() {
 		pmpType, err := gstashs.GetGstashByName(gstashTypeName)
 		if err != nil {
 			log.Errorf("Gstash load error (skipping): %s", err.Error())
+			pmpsHealth[i].set(err)
 		} else {
 			// New一个插件实例
 			pmpIns := pmpType.New()
@@ -173,6 +247,7 @@ func (s *gstashServer) initialize() {
 			initErr := pmpIns.Init(pmp.Meta)
 			if initErr != nil {
 				log.Errorf("Gstash init error (skipping): %s", initErr.Error())
+				pmpsHealth[i].set(initErr)
 			} else {
 				log.Infof("Init Gstash: %s", pmpIns.GetName())
 				// 设置过滤规则
@@ -186,20 +261,46 @@ func (s *gstashServer) initialize() {
 	}
 }
 
-func writeToGStashs(keys []interface{}, purgeDelay int) {
+// writeToGStashs writes the records to all the gstashs, it returns false if any required gstash failed.
+// The gstashs failed to be initialized are skipped, the failed writings are retried unless retry is false.
+func writeToGStashs(keys []interface{}, purgeDelay int, retry bool) bool {
 	// Send to gstash
-	if pmps != nil {
-		var wg sync.WaitGroup
-		wg.Add(len(pmps))
-		// 按照写入插件做并发写入
-		for _, pmp := range pmps {
-			go execGStashWriting(&wg, pmp, &keys, purgeDelay)
-		}
-		// 等待一轮写入结束
-		wg.Wait()
-	} else {
+	if pmps == nil {
 		log.Warn("No gstash defined!")
+
+		return true
 	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(pmps))
+	// 按照写入插件做并发写入
+	for i, pmp := range pmps {
+		if pmp == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, pmp gstashs.Gstash) {
+			defer wg.Done()
+
+			config := pmpsConfig[i]
+			if !retry {
+				config.Retries = 0
+			}
+			errs[i] = writeWithRetry(pmp, pmpsHealth[i], config, keys, purgeDelay)
+		}(i, pmp)
+	}
+	// 等待一轮写入结束
+	wg.Wait()
+
+	ok := true
+	for i, err := range errs {
+		if err != nil && !pmpsConfig[i].Optional {
+			ok = false
+		}
+	}
+
+	return ok
 }
 
 func filterData(gstash gstashs.Gstash, keys []interface{}) []interface{} {
@@ -207,10 +308,10 @@ func filterData(gstash gstashs.Gstash, keys []interface{}) []interface{} {
 	if !filters.HasFilter() && !gstash.GetOmitDetailedRecording() {
 		return keys
 	}
-	filteredKeys := keys[:] // nolint: gocritic
-	newLenght := 0
+	// the keys are shared by the gstashs written concurrently, they are filtered into a copy
+	filteredKeys := make([]interface{}, 0, len(keys))
 
-	for _, key := range filteredKeys {
+	for _, key := range keys {
 		decoded, _ := key.(analytics.AnalyticsRecord)
 		if gstash.GetOmitDetailedRecording() {
 			decoded.Policies = ""
@@ -220,16 +321,14 @@ func filterData(gstash gstashs.Gstash, keys []interface{}) []interface{} {
 		if filters.ShouldFilter(decoded) {
 			continue
 		}
-		filteredKeys[newLenght] = decoded
-		newLenght++
+		filteredKeys = append(filteredKeys, decoded)
 	}
-	filteredKeys = filteredKeys[:newLenght]
 
 	return filteredKeys
 }
 
 // execGStashWriting 每个插件的具体写入规则
-func execGStashWriting(wg *sync.WaitGroup, pmp gstashs.Gstash, keys *[]interface{}, purgeDelay int) {
+func execGStashWriting(pmp gstashs.Gstash, health *gstashHealth, keys []interface{}, purgeDelay int) error {
 	timer := time.AfterFunc(time.Duration(purgeDelay)*time.Second, func() {
 		if pmp.GetTimeout() == 0 {
 			log.Warnf(
@@ -241,7 +340,6 @@ func execGStashWriting(wg *sync.WaitGroup, pmp gstashs.Gstash, keys *[]interface
 		}
 	})
 	defer timer.Stop()
-	defer wg.Done()
 
 	log.Debugf("Writing to: %s", pmp.GetName())
 
@@ -257,19 +355,31 @@ func execGStashWriting(wg *sync.WaitGroup, pmp gstashs.Gstash, keys *[]interface
 
 	defer cancel()
 
-	go func(ch chan error, ctx context.Context, pmp gstashs.Gstash, keys *[]interface{}) {
-		filteredKeys := filterData(pmp, *keys)
+	filteredKeys := filterData(pmp, keys)
+	recordsFiltered.WithLabelValues(health.name).Add(float64(len(keys) - len(filteredKeys)))
 
-		ch <- pmp.WriteData(ctx, filteredKeys)
-	}(ch, ctx, pmp, keys)
+	start := time.Now()
+	go func(ch chan error, ctx context.Context, pmp gstashs.Gstash, keys []interface{}) {
+		ch <- pmp.WriteData(ctx, keys)
+	}(ch, ctx, pmp, filteredKeys)
 
 	select {
 	// err 不管是否为nil，都会留存在当前ch中
 	case err := <-ch:
+		writeDuration.WithLabelValues(health.name).Observe(time.Since(start).Seconds())
 		if err != nil {
 			log.Warnf("Error Writing to: %s - Error: %s", pmp.GetName(), err.Error())
+			recordsFailed.WithLabelValues(health.name).Add(float64(len(filteredKeys)))
+		} else {
+			recordsWritten.WithLabelValues(health.name).Add(float64(len(filteredKeys)))
 		}
+		health.setWritten(err)
+
+		return err
 	case <-ctx.Done():
+		writeDuration.WithLabelValues(health.name).Observe(time.Since(start).Seconds())
+		recordsFailed.WithLabelValues(health.name).Add(float64(len(filteredKeys)))
+		health.setWritten(ctx.Err())
 		//nolint: errorlint
 		switch ctx.Err() {
 		case context.Canceled:
@@ -277,5 +387,7 @@ func execGStashWriting(wg *sync.WaitGroup, pmp gstashs.Gstash, keys *[]interface
 		case context.DeadlineExceeded:
 			log.Warnf("Timeout Writing to: %s", pmp.GetName())
 		}
+
+		return ctx.Err()
 	}
 }
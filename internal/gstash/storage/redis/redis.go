@@ -25,6 +25,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 
 	genericoptions "github.com/dairongpeng/leona/internal/pkg/options"
+	schema "github.com/dairongpeng/leona/pkg/analytics"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -32,7 +33,7 @@ import (
 
 // RedisKeyPrefix defines prefix for iam analytics key.
 const (
-	RedisKeyPrefix      = "analytics-"
+	RedisKeyPrefix      = schema.KeyPrefix
 	defaultRedisAddress = "127.0.0.1:6379"
 )
 
@@ -261,6 +262,15 @@ func (r *RedisClusterStorageManager) Connect() bool {
 	return true
 }
 
+// Ping checks the connection to redis, the connection is established first if it is dropped.
+func (r *RedisClusterStorageManager) Ping() error {
+	if r.db == nil {
+		r.Connect()
+	}
+
+	return r.db.Ping().Err()
+}
+
 func (r *RedisClusterStorageManager) hashKey(in string) string {
 	return in
 }
@@ -315,6 +325,88 @@ func (r *RedisClusterStorageManager) GetAndDeleteSet(keyName string) []interface
 	return result
 }
 
+// moveToProcessingScript moves at most ARGV[1] records from the head of KEYS[1] to the tail of
+// KEYS[2] atomically, and returns them. The records are pushed in chunks, unpack is limited by
+// the lua stack.
+var moveToProcessingScript = redis.NewScript(`
+local vals = redis.call('LRANGE', KEYS[1], 0, tonumber(ARGV[1]) - 1)
+if #vals > 0 then
+	redis.call('LTRIM', KEYS[1], #vals, -1)
+	for i = 1, #vals, 1000 do
+		redis.call('RPUSH', KEYS[2], unpack(vals, i, math.min(i + 999, #vals)))
+	end
+end
+return vals
+`)
+
+// MoveToProcessing moves at most count records of the list to the processing list, and returns them.
+// Both keys must be in the same hash slot when redis runs as a cluster.
+func (r *RedisClusterStorageManager) MoveToProcessing(
+	keyName, processingKeyName string,
+	count int64,
+) ([]interface{}, error) {
+	r.ensureConnection()
+
+	vals, err := moveToProcessingScript.Run(r.db, []string{r.fixKey(keyName), r.fixKey(processingKeyName)}, count).
+		Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to move the records to the processing list")
+	}
+
+	result, _ := vals.([]interface{})
+
+	return result, nil
+}
+
+// GetSet returns all the values of the list.
+func (r *RedisClusterStorageManager) GetSet(keyName string) ([]interface{}, error) {
+	r.ensureConnection()
+
+	vals, err := r.db.LRange(r.fixKey(keyName), 0, -1).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the list")
+	}
+
+	result := make([]interface{}, len(vals))
+	for i, v := range vals {
+		result[i] = v
+	}
+
+	return result, nil
+}
+
+// AppendToSet appends the values to the list.
+func (r *RedisClusterStorageManager) AppendToSet(keyName string, values []interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	r.ensureConnection()
+
+	return errors.Wrap(r.db.RPush(r.fixKey(keyName), values...).Err(), "failed to append to the list")
+}
+
+// IncrKey increments the key by one, and returns the value.
+func (r *RedisClusterStorageManager) IncrKey(keyName string) (int64, error) {
+	r.ensureConnection()
+
+	val, err := r.db.Incr(r.fixKey(keyName)).Result()
+
+	return val, errors.Wrap(err, "failed to increment key")
+}
+
+// DeleteKeys deletes the keys.
+func (r *RedisClusterStorageManager) DeleteKeys(keyNames ...string) error {
+	r.ensureConnection()
+
+	for _, keyName := range keyNames {
+		if err := r.db.Del(r.fixKey(keyName)).Err(); err != nil {
+			return errors.Wrap(err, "failed to delete key")
+		}
+	}
+
+	return nil
+}
+
 // SetKey will create (or update) a key value in the store.
 func (r *RedisClusterStorageManager) SetKey(keyName, session string, timeout int64) error {
 	log.Debugf("[STORE] SET Raw key is: %s", keyName)
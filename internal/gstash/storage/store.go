@@ -15,15 +15,37 @@
 // Package storage defines storages which store the analytics data from iam-authz-server.
 package storage
 
+import (
+	schema "github.com/dairongpeng/leona/pkg/analytics"
+)
+
 // AnalyticsStorage defines the analytics storage interface.
 type AnalyticsStorage interface {
 	Init(config interface{}) error
 	GetName() string
 	Connect() bool
+	Ping() error
 	GetAndDeleteSet(string) []interface{}
+
+	// The at-least-once delivery moves the records to a processing list, which is deleted
+	// once they are written.
+	MoveToProcessing(keyName, processingKeyName string, count int64) ([]interface{}, error)
+	GetSet(keyName string) ([]interface{}, error)
+	AppendToSet(keyName string, values []interface{}) error
+	IncrKey(keyName string) (int64, error)
+	DeleteKeys(keyNames ...string) error
 }
 
 const (
-	// AnalyticsKeyName defines the key name in redis which used to analytics.
-	AnalyticsKeyName string = "iam-system-analytics"
+	// AnalyticsKeyName defines the key name in redis which used to analytics, it is the one
+	// leona-apiserver writes to. The keys below share its hash tag, they are in the same slot of
+	// Redis Cluster, which the scripts moving the records between them require.
+	AnalyticsKeyName string = schema.KeyName
+
+	// ProcessingKeyName defines the key name of the records being delivered.
+	ProcessingKeyName = AnalyticsKeyName + "-processing"
+	// DeliveriesKeyName defines the key name of the times the processing records are delivered.
+	DeliveriesKeyName = AnalyticsKeyName + "-deliveries"
+	// DeadLetterKeyName defines the key name of the records which can not be delivered.
+	DeadLetterKeyName = AnalyticsKeyName + "-dead-letter"
 )
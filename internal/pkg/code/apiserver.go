@@ -39,3 +39,66 @@ const (
 	// ErrPolicyNotFound - 404: Policy not found.
 	ErrPolicyNotFound int = iota + 110201
 )
+
+// leona-apiserver: namespace errors.
+const (
+	// ErrNamespaceNotFound - 404: Namespace not found.
+	ErrNamespaceNotFound int = iota + 110301
+
+	// ErrNamespaceAlreadyExist - 400: Namespace already exist.
+	ErrNamespaceAlreadyExist
+
+	// ErrNamespaceTerminating - 403: Namespace is being terminated.
+	ErrNamespaceTerminating
+
+	// ErrNamespaceProtected - 403: Namespace can not be deleted.
+	ErrNamespaceProtected
+)
+
+// leona-apiserver: session errors.
+const (
+	// ErrSessionNotFound - 404: Session not found.
+	ErrSessionNotFound int = iota + 110401
+)
+
+// leona-apiserver: rbac errors.
+const (
+	// ErrRoleNotFound - 404: Role not found.
+	ErrRoleNotFound int = iota + 110501
+
+	// ErrRoleAlreadyExist - 400: Role already exist.
+	ErrRoleAlreadyExist
+
+	// ErrRoleProtected - 403: Bootstrap role can not be changed.
+	ErrRoleProtected
+
+	// ErrRoleBindingNotFound - 404: Role binding not found.
+	ErrRoleBindingNotFound
+
+	// ErrRoleBindingAlreadyExist - 400: Role binding already exist.
+	ErrRoleBindingAlreadyExist
+)
+
+// leona-apiserver: account errors.
+const (
+	// ErrAccountTokenInvalid - 400: Token is invalid or expired.
+	ErrAccountTokenInvalid int = iota + 110601
+)
+
+// leona-apiserver: mfa errors.
+const (
+	// ErrMFARequired - 401: Second factor is required.
+	ErrMFARequired int = iota + 110701
+
+	// ErrMFATokenInvalid - 401: MFA token is invalid or expired.
+	ErrMFATokenInvalid
+
+	// ErrMFACodeInvalid - 401: Second factor code is invalid.
+	ErrMFACodeInvalid
+
+	// ErrMFAAlreadyEnabled - 400: Second factor is already enrolled.
+	ErrMFAAlreadyEnabled
+
+	// ErrMFANotEnrolling - 400: Second factor enrollment is not started or expired.
+	ErrMFANotEnrolling
+)
@@ -37,6 +37,21 @@ const (
 
 	// ErrPageNotFound - 404: Page not found.
 	ErrPageNotFound
+
+	// ErrTooManyRequests - 429: Too many requests, the rate limit is exceeded.
+	ErrTooManyRequests
+
+	// ErrIdempotencyKeyReused - 422: Idempotency key was used by a different request.
+	ErrIdempotencyKeyReused
+
+	// ErrIdempotencyKeyInProgress - 409: A request with the same idempotency key is in progress.
+	ErrIdempotencyKeyInProgress
+
+	// ErrPreconditionFailed - 412: The precondition of the request failed.
+	ErrPreconditionFailed
+
+	// ErrResourceConflict - 409: The resource has been modified by another request, read it and try again.
+	ErrResourceConflict
 )
 
 // common: database errors.
@@ -67,6 +82,15 @@ const (
 
 	// PermissionDenied - 403: Permission denied.
 	ErrPermissionDenied
+
+	// ErrPasswordExpired - 403: Password expired, it must be changed before logging in.
+	ErrPasswordExpired
+
+	// ErrAccountLocked - 429: Too many failed login attempts, the account is locked temporarily.
+	ErrAccountLocked
+
+	// ErrTokenRevoked - 401: Token has been revoked.
+	ErrTokenRevoked
 )
 
 // common: encode/decode errors.
@@ -94,4 +118,10 @@ const (
 
 	// ErrDecodingYaml - 500: Yaml data could not be decoded.
 	ErrDecodingYaml
+
+	// ErrNotAcceptable - 406: None of the media types in the `Accept` header is supported.
+	ErrNotAcceptable
+
+	// ErrUnsupportedMediaType - 415: The `Content-Type` of the request body is not supported.
+	ErrUnsupportedMediaType
 )
@@ -23,12 +23,33 @@ func init() {
 	register(ErrReachMaxCount, 400, "Secret reach the max count")
 	register(ErrSecretNotFound, 404, "Secret not found")
 	register(ErrPolicyNotFound, 404, "Policy not found")
+	register(ErrNamespaceNotFound, 404, "Namespace not found")
+	register(ErrNamespaceAlreadyExist, 400, "Namespace already exist")
+	register(ErrNamespaceTerminating, 403, "Namespace is being terminated")
+	register(ErrNamespaceProtected, 403, "Namespace can not be deleted")
+	register(ErrSessionNotFound, 404, "Session not found")
+	register(ErrRoleNotFound, 404, "Role not found")
+	register(ErrRoleAlreadyExist, 400, "Role already exist")
+	register(ErrRoleProtected, 403, "Bootstrap role can not be changed")
+	register(ErrRoleBindingNotFound, 404, "Role binding not found")
+	register(ErrRoleBindingAlreadyExist, 400, "Role binding already exist")
+	register(ErrAccountTokenInvalid, 400, "Token is invalid or expired")
+	register(ErrMFARequired, 401, "Second factor is required")
+	register(ErrMFATokenInvalid, 401, "MFA token is invalid or expired")
+	register(ErrMFACodeInvalid, 401, "Second factor code is invalid")
+	register(ErrMFAAlreadyEnabled, 400, "Second factor is already enrolled")
+	register(ErrMFANotEnrolling, 400, "Second factor enrollment is not started or expired")
 	register(ErrSuccess, 200, "OK")
 	register(ErrUnknown, 500, "Internal server error")
 	register(ErrBind, 400, "Error occurred while binding the request body to the struct")
 	register(ErrValidation, 400, "Validation failed")
 	register(ErrTokenInvalid, 401, "Token invalid")
 	register(ErrPageNotFound, 404, "Page not found")
+	register(ErrTooManyRequests, 429, "Too many requests, the rate limit is exceeded")
+	register(ErrIdempotencyKeyReused, 422, "Idempotency key was used by a different request")
+	register(ErrIdempotencyKeyInProgress, 409, "A request with the same idempotency key is in progress")
+	register(ErrPreconditionFailed, 412, "The precondition of the request failed")
+	register(ErrResourceConflict, 409, "The resource has been modified by another request, read it and try again")
 	register(ErrDatabase, 500, "Database error")
 	register(ErrEncrypt, 401, "Error occurred while encrypting the user password")
 	register(ErrSignatureInvalid, 401, "Signature is invalid")
@@ -37,6 +58,9 @@ func init() {
 	register(ErrMissingHeader, 401, "The `Authorization` header was empty")
 	register(ErrPasswordIncorrect, 401, "Password was incorrect")
 	register(ErrPermissionDenied, 403, "Permission denied")
+	register(ErrPasswordExpired, 403, "Password expired, it must be changed before logging in")
+	register(ErrAccountLocked, 429, "Too many failed login attempts, the account is locked temporarily")
+	register(ErrTokenRevoked, 401, "Token has been revoked")
 	register(ErrEncodingFailed, 500, "Encoding failed due to an error with the data")
 	register(ErrDecodingFailed, 500, "Decoding failed due to an error with the data")
 	register(ErrInvalidJSON, 500, "Data is not valid JSON")
@@ -45,4 +69,6 @@ func init() {
 	register(ErrInvalidYaml, 500, "Data is not valid Yaml")
 	register(ErrEncodingYaml, 500, "Yaml data could not be encoded")
 	register(ErrDecodingYaml, 500, "Yaml data could not be decoded")
+	register(ErrNotAcceptable, 406, "None of the media types in the `Accept` header is supported")
+	register(ErrUnsupportedMediaType, 415, "The `Content-Type` of the request body is not supported")
 }
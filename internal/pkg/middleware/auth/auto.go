@@ -28,10 +28,14 @@ import (
 const authHeaderCount = 2
 
 // AutoStrategy defines authentication strategy which can automatically choose between Basic and Bearer
-// according `Authorization` header.
+// according `Authorization` header. The bearer tokens issued by the OpenID Connect provider are
+// authenticated by the oidc strategy if it is set, and the basic credentials are authenticated by
+// the ldap strategy if it is set.
 type AutoStrategy struct {
 	basic BasicStrategy
 	jwt   JWTStrategy
+	oidc  *OIDCStrategy
+	ldap  *LDAPStrategy
 }
 
 var _ middleware.AuthStrategy = &AutoStrategy{}
@@ -44,6 +48,20 @@ func NewAutoStrategy(basic BasicStrategy, jwt JWTStrategy) AutoStrategy {
 	}
 }
 
+// WithOIDC returns the auto strategy which also accepts the ID tokens by the oidc strategy.
+func (a AutoStrategy) WithOIDC(oidc OIDCStrategy) AutoStrategy {
+	a.oidc = &oidc
+
+	return a
+}
+
+// WithLDAP returns the auto strategy which authenticates the basic credentials by the ldap strategy.
+func (a AutoStrategy) WithLDAP(ldap LDAPStrategy) AutoStrategy {
+	a.ldap = &ldap
+
+	return a
+}
+
 // AuthFunc defines auto strategy as the gin authentication middleware.
 func (a AutoStrategy) AuthFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -63,8 +81,20 @@ func (a AutoStrategy) AuthFunc() gin.HandlerFunc {
 
 		switch authHeader[0] {
 		case "Basic":
+			if a.ldap != nil {
+				operator.SetStrategy(a.ldap)
+
+				break
+			}
+
 			operator.SetStrategy(a.basic)
 		case "Bearer":
+			if a.oidc != nil && a.oidc.Accepts(authHeader[1]) {
+				operator.SetStrategy(a.oidc)
+
+				break
+			}
+
 			operator.SetStrategy(a.jwt)
 			// a.JWT.MiddlewareFunc()(c)
 		default:
@@ -27,14 +27,18 @@ import (
 )
 
 // BasicStrategy defines Basic authentication strategy.
+// The username can be qualified by a namespace in the form of `<namespace>/<username>`.
+// The compare function returns the error which is responded when the authentication failed.
 type BasicStrategy struct {
-	compare func(username string, password string) bool
+	compare func(c *gin.Context, namespace string, username string, password string) error
 }
 
 var _ middleware.AuthStrategy = &BasicStrategy{}
 
 // NewBasicStrategy create basic strategy with compare function.
-func NewBasicStrategy(compare func(username string, password string) bool) BasicStrategy {
+func NewBasicStrategy(
+	compare func(c *gin.Context, namespace string, username string, password string) error,
+) BasicStrategy {
 	return BasicStrategy{
 		compare: compare,
 	}
@@ -58,8 +62,7 @@ func (b BasicStrategy) AuthFunc() gin.HandlerFunc {
 
 		payload, _ := base64.StdEncoding.DecodeString(auth[1])
 		pair := strings.SplitN(string(payload), ":", 2)
-
-		if len(pair) != 2 || !b.compare(pair[0], pair[1]) {
+		if len(pair) != 2 {
 			core.WriteResponse(
 				c,
 				errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong."),
@@ -70,7 +73,16 @@ func (b BasicStrategy) AuthFunc() gin.HandlerFunc {
 			return
 		}
 
-		c.Set(middleware.UsernameKey, pair[0])
+		namespace, username := middleware.SplitNamespacedName(pair[0])
+		if err := b.compare(c, namespace, username, pair[1]); err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(middleware.UsernameKey, username)
+		c.Set(middleware.NamespaceKey, namespace)
 
 		c.Next()
 	}
@@ -15,28 +15,306 @@
 package auth
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	ginjwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
 )
 
 // AuthzAudience defines the value of jwt audience field.
 const AuthzAudience = "leona.authz.leona.com"
 
+// ClaimsValidator validates the claims of a token, the token is rejected with the returned error.
+type ClaimsValidator func(c *gin.Context, claims ginjwt.MapClaims) error
+
+// KeySet holds the keys signing and verifying the tokens.
+type KeySet interface {
+	// SigningKey returns the key signing the new tokens, the kid is put into the token header
+	// unless it is empty.
+	SigningKey() (kid string, method jwt.SigningMethod, key interface{}, err error)
+
+	// VerificationKey returns the key verifying the tokens with the kid header.
+	VerificationKey(kid string) (method jwt.SigningMethod, key interface{}, err error)
+}
+
+// hmacKeySet signs and verifies the tokens with a single shared secret.
+type hmacKeySet []byte
+
+// NewHMACKeySet returns the key set signing the tokens with the HS256 secret key.
+func NewHMACKeySet(key []byte) KeySet {
+	return hmacKeySet(key)
+}
+
+func (k hmacKeySet) SigningKey() (string, jwt.SigningMethod, interface{}, error) {
+	return "", jwt.SigningMethodHS256, []byte(k), nil
+}
+
+func (k hmacKeySet) VerificationKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	return jwt.SigningMethodHS256, []byte(k), nil
+}
+
 // JWTStrategy defines jwt bearer authentication strategy.
+// The GinJWTMiddleware configures the strategy, the tokens are signed and verified by the key set.
 type JWTStrategy struct {
 	ginjwt.GinJWTMiddleware
+	keys       KeySet
+	validators []ClaimsValidator
 }
 
 var _ middleware.AuthStrategy = &JWTStrategy{}
 
-// NewJWTStrategy create jwt bearer strategy with GinJWTMiddleware.
-func NewJWTStrategy(gjwt ginjwt.GinJWTMiddleware) JWTStrategy {
-	return JWTStrategy{gjwt}
+// NewJWTStrategy create jwt bearer strategy with GinJWTMiddleware, the tokens are also validated
+// by the given validators when authenticating and refreshing.
+// The tokens are signed with the HS256 key of GinJWTMiddleware if keys is nil.
+func NewJWTStrategy(gjwt ginjwt.GinJWTMiddleware, keys KeySet, validators ...ClaimsValidator) JWTStrategy {
+	if keys == nil {
+		keys = NewHMACKeySet(gjwt.Key)
+	}
+
+	return JWTStrategy{gjwt, keys, validators}
 }
 
 // AuthFunc defines jwt bearer strategy as the gin authentication middleware.
 func (j JWTStrategy) AuthFunc() gin.HandlerFunc {
-	return j.MiddlewareFunc()
+	return func(c *gin.Context) {
+		token, err := j.parse(c)
+		if err != nil {
+			j.unauthorized(c, http.StatusUnauthorized, err)
+
+			return
+		}
+
+		claims := ginjwt.ExtractClaimsFromToken(token)
+		if exp, ok := claims["exp"].(float64); !ok {
+			j.unauthorized(c, http.StatusBadRequest, ginjwt.ErrMissingExpField)
+
+			return
+		} else if int64(exp) < j.TimeFunc().Unix() {
+			j.unauthorized(c, http.StatusUnauthorized, ginjwt.ErrExpiredToken)
+
+			return
+		}
+
+		if !j.validate(c, claims) {
+			return
+		}
+
+		c.Set("JWT_PAYLOAD", claims)
+		identity := j.IdentityHandler(c)
+		if identity != nil {
+			c.Set(j.IdentityKey, identity)
+		}
+
+		if !j.Authorizator(identity, c) {
+			j.unauthorized(c, http.StatusForbidden, ginjwt.ErrForbidden)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// LoginHandler authenticates the user by the Authenticator, and responds with a new token.
+func (j JWTStrategy) LoginHandler(c *gin.Context) {
+	data, err := j.Authenticator(c)
+	if err != nil {
+		j.unauthorized(c, http.StatusUnauthorized, err)
+
+		return
+	}
+
+	claims := ginjwt.MapClaims{}
+	if j.PayloadFunc != nil {
+		claims = j.PayloadFunc(data)
+	}
+
+	token, expire, err := j.sign(c, claims)
+	if err != nil {
+		j.unauthorized(c, http.StatusUnauthorized, ginjwt.ErrFailedTokenCreation)
+
+		return
+	}
+
+	j.LoginResponse(c, http.StatusOK, token, expire)
+}
+
+// RefreshHandler refreshes the token unless it is rejected by the validators, the expired tokens
+// can be refreshed until MaxRefresh has passed.
+// The claims of the token are available to the refresh response by ginjwt.ExtractClaims.
+func (j JWTStrategy) RefreshHandler(c *gin.Context) {
+	token, err := j.parse(c)
+	// the expired token is still refreshed within MaxRefresh
+	if verr, ok := err.(*jwt.ValidationError); err != nil && (!ok || verr.Errors != jwt.ValidationErrorExpired) {
+		j.unauthorized(c, http.StatusUnauthorized, err)
+
+		return
+	}
+
+	claims := ginjwt.ExtractClaimsFromToken(token)
+	if origIat, _ := claims["orig_iat"].(float64); int64(origIat) < j.TimeFunc().Add(-j.MaxRefresh).Unix() {
+		j.unauthorized(c, http.StatusUnauthorized, ginjwt.ErrExpiredToken)
+
+		return
+	}
+
+	if !j.validate(c, claims) {
+		return
+	}
+	c.Set("JWT_PAYLOAD", claims)
+
+	refreshed := ginjwt.MapClaims{}
+	for key, value := range claims {
+		refreshed[key] = value
+	}
+
+	tokenString, expire, err := j.sign(c, refreshed)
+	if err != nil {
+		j.unauthorized(c, http.StatusUnauthorized, ginjwt.ErrFailedTokenCreation)
+
+		return
+	}
+
+	j.RefreshResponse(c, http.StatusOK, tokenString, expire)
+}
+
+// LogoutHandler logs out, the claims of the token are available to the logout response by ginjwt.ExtractClaims.
+func (j JWTStrategy) LogoutHandler(c *gin.Context) {
+	if token, err := j.parse(c); err == nil {
+		c.Set("JWT_PAYLOAD", ginjwt.ExtractClaimsFromToken(token))
+	}
+
+	// delete the cookie of the token
+	if j.SendCookie {
+		j.setCookie(c, "", -1)
+	}
+
+	j.LogoutResponse(c, http.StatusOK)
+}
+
+// ParseTokenString parses and verifies the token with the key selected by its kid header.
+func (j JWTStrategy) ParseTokenString(token string) (*jwt.Token, error) {
+	return jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		method, key, err := j.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Method.Alg() != method.Alg() {
+			return nil, ginjwt.ErrInvalidSigningAlgorithm
+		}
+
+		return key, nil
+	})
+}
+
+// parse parses the token looked up by TokenLookup.
+func (j JWTStrategy) parse(c *gin.Context) (*jwt.Token, error) {
+	token, err := j.lookup(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.ParseTokenString(token)
+}
+
+// lookup returns the token in the first place of TokenLookup containing it.
+func (j JWTStrategy) lookup(c *gin.Context) (string, error) {
+	for _, method := range strings.Split(j.TokenLookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(method), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		var token string
+		switch name := strings.TrimSpace(parts[1]); strings.TrimSpace(parts[0]) {
+		case "header":
+			if header := c.Request.Header.Get(name); header != "" {
+				fields := strings.SplitN(header, " ", 2)
+				if len(fields) != 2 || fields[0] != j.TokenHeadName {
+					return "", ginjwt.ErrInvalidAuthHeader
+				}
+				token = fields[1]
+			}
+		case "query":
+			token = c.Query(name)
+		case "cookie":
+			token, _ = c.Cookie(name)
+		case "param":
+			token = c.Param(name)
+		}
+
+		if token != "" {
+			return token, nil
+		}
+	}
+
+	return "", ginjwt.ErrEmptyAuthHeader
+}
+
+// sign signs the claims with the signing key of the key set, the token expires after Timeout.
+func (j JWTStrategy) sign(c *gin.Context, claims ginjwt.MapClaims) (string, time.Time, error) {
+	kid, method, key, err := j.keys.SigningKey()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := j.TimeFunc()
+	expire := now.Add(j.Timeout)
+	claims["exp"] = expire.Unix()
+	claims["orig_iat"] = now.Unix()
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims(claims))
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if j.SendCookie {
+		j.setCookie(c, tokenString, int(j.CookieMaxAge.Seconds()))
+	}
+
+	return tokenString, expire, nil
+}
+
+func (j JWTStrategy) setCookie(c *gin.Context, value string, maxAge int) {
+	if j.CookieSameSite != 0 {
+		c.SetSameSite(j.CookieSameSite)
+	}
+
+	c.SetCookie(j.CookieName, value, maxAge, "/", j.CookieDomain, j.SecureCookie, j.CookieHTTPOnly)
+}
+
+// unauthorized responds the error by the Unauthorized func of GinJWTMiddleware.
+func (j JWTStrategy) unauthorized(c *gin.Context, status int, err error) {
+	c.Header("WWW-Authenticate", "JWT realm="+j.Realm)
+	if !j.DisabledAbort {
+		c.Abort()
+	}
+
+	j.Unauthorized(c, status, j.HTTPStatusMessageFunc(err, c))
+}
+
+func (j JWTStrategy) validate(c *gin.Context, claims ginjwt.MapClaims) bool {
+	for _, validate := range j.validators {
+		if err := validate(c, claims); err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return false
+		}
+	}
+
+	return true
 }
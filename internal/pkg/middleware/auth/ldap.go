@@ -0,0 +1,156 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/directory"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// LoginGuard throttles the failed logins of the directory users.
+type LoginGuard interface {
+	// Check returns the error responded when the user is not allowed to log in now.
+	Check(c *gin.Context, namespace, username string) error
+	// Fail records a failed login of the user.
+	Fail(c *gin.Context, namespace, username string)
+	// Succeed clears the failed logins of the user.
+	Succeed(c *gin.Context, namespace, username string)
+}
+
+// LDAPStrategy defines Basic authentication strategy which binds to the LDAP directory as the user.
+// The directory users belong to a namespace, the credentials qualified by another namespace and
+// the users not in the directory are authenticated by the fallback strategy.
+// The guard throttles the failed logins, and the login function maps the authenticated directory
+// user to the local user, its error is responded when the user is not allowed to log in.
+type LDAPStrategy struct {
+	directory *directory.Directory
+	namespace string
+	guard     LoginGuard
+	login     func(c *gin.Context, entry *directory.Entry) (namespace string, username string, err error)
+	fallback  middleware.AuthStrategy
+}
+
+var _ middleware.AuthStrategy = &LDAPStrategy{}
+
+// NewLDAPStrategy create ldap strategy with the directory, the namespace of the directory users,
+// the login guard, the login function and the fallback strategy.
+func NewLDAPStrategy(
+	dir *directory.Directory,
+	namespace string,
+	guard LoginGuard,
+	login func(c *gin.Context, entry *directory.Entry) (namespace string, username string, err error),
+	fallback middleware.AuthStrategy,
+) LDAPStrategy {
+	return LDAPStrategy{
+		directory: dir,
+		namespace: namespace,
+		guard:     guard,
+		login:     login,
+		fallback:  fallback,
+	}
+}
+
+// AuthFunc defines ldap strategy as the gin authentication middleware.
+func (l LDAPStrategy) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
+
+		if len(auth) != 2 || auth[0] != "Basic" {
+			core.WriteResponse(
+				c,
+				errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong."),
+				nil,
+			)
+			c.Abort()
+
+			return
+		}
+
+		payload, _ := base64.StdEncoding.DecodeString(auth[1])
+		pair := strings.SplitN(string(payload), ":", 2)
+		if len(pair) != 2 {
+			core.WriteResponse(
+				c,
+				errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong."),
+				nil,
+			)
+			c.Abort()
+
+			return
+		}
+
+		// the unqualified username belongs to the namespace of the directory users
+		username := pair[0]
+		if strings.Contains(username, "/") {
+			var namespace string
+			namespace, username = middleware.SplitNamespacedName(username)
+			if namespace != l.namespace {
+				l.fallback.AuthFunc()(c)
+
+				return
+			}
+		}
+
+		if err := l.guard.Check(c, l.namespace, username); err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		entry, err := l.directory.Authenticate(username, pair[1])
+		switch {
+		case err == nil:
+			l.guard.Succeed(c, l.namespace, username)
+		case errors.Is(err, directory.ErrUserNotFound):
+			l.fallback.AuthFunc()(c)
+
+			return
+		case errors.Is(err, directory.ErrInvalidCredentials):
+			l.guard.Fail(c, l.namespace, username)
+			core.WriteResponse(c, errors.WithCode(code.ErrPasswordIncorrect, err.Error()), nil)
+			c.Abort()
+
+			return
+		default:
+			log.L(c).Errorf("authenticate user `%s` by the directory failed: %s", username, err.Error())
+			core.WriteResponse(c, errors.WithCode(code.ErrUnknown, "the directory is unavailable"), nil)
+			c.Abort()
+
+			return
+		}
+
+		namespace, username, err := l.login(c, entry)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(middleware.UsernameKey, username)
+		c.Set(middleware.NamespaceKey, namespace)
+		c.Next()
+	}
+}
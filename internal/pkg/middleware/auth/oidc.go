@@ -0,0 +1,104 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/oidc"
+)
+
+// OIDCStrategy defines bearer authentication strategy accepting the ID tokens issued by an
+// OpenID Connect provider. The login function maps the claims of a verified ID token to the local
+// user, its error is responded when the user is not allowed to log in.
+type OIDCStrategy struct {
+	verifier *oidc.Verifier
+	login    func(c *gin.Context, claims oidc.Claims) (namespace string, username string, err error)
+}
+
+var _ middleware.AuthStrategy = &OIDCStrategy{}
+
+// NewOIDCStrategy create oidc strategy with the verifier of the ID tokens and the login function.
+func NewOIDCStrategy(
+	verifier *oidc.Verifier,
+	login func(c *gin.Context, claims oidc.Claims) (namespace string, username string, err error),
+) OIDCStrategy {
+	return OIDCStrategy{
+		verifier: verifier,
+		login:    login,
+	}
+}
+
+// Accepts reports whether the token claims to be issued by the provider, the token is not verified.
+func (o OIDCStrategy) Accepts(token string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return false
+	}
+
+	return claims.VerifyIssuer(o.verifier.Issuer(), true)
+}
+
+// AuthFunc defines oidc strategy as the gin authentication middleware.
+func (o OIDCStrategy) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Request.Header.Get("Authorization")
+		if len(header) == 0 {
+			core.WriteResponse(c, errors.WithCode(code.ErrMissingHeader, "Authorization header cannot be empty."), nil)
+			c.Abort()
+
+			return
+		}
+
+		auth := strings.SplitN(header, " ", 2)
+		if len(auth) != 2 || auth[0] != "Bearer" {
+			core.WriteResponse(c, errors.WithCode(code.ErrInvalidAuthHeader, "Authorization header format is wrong."), nil)
+			c.Abort()
+
+			return
+		}
+
+		claims, err := o.verifier.Verify(c, auth[1])
+		if err != nil {
+			if verr, ok := err.(*jwt.ValidationError); ok && verr.Errors&jwt.ValidationErrorExpired != 0 {
+				core.WriteResponse(c, errors.WithCode(code.ErrExpired, err.Error()), nil)
+			} else {
+				core.WriteResponse(c, errors.WithCode(code.ErrSignatureInvalid, err.Error()), nil)
+			}
+			c.Abort()
+
+			return
+		}
+
+		namespace, username, err := o.login(c, claims)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(middleware.UsernameKey, username)
+		c.Set(middleware.NamespaceKey, namespace)
+		c.Next()
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/dairongpeng/leona/api/apiserver/v1"
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+)
+
+// namespacesResource is the only resource which is not scoped to a namespace.
+const namespacesResource = "namespaces"
+
+var versionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+// Attributes describes the request to authorize.
+type Attributes struct {
+	// Namespace and Username identify the authenticated user.
+	Namespace string
+	Username  string
+
+	// Verb is derived from the http method, the GET requests of the resource collections are `list`.
+	Verb string
+
+	// Resource is the requested resource, the subresources are in the form of `<resource>/<subresource>`.
+	Resource string
+
+	// Name is the name of the requested resource, it is empty for the resource collections.
+	Name string
+
+	// RequestNamespace is the namespace of the requested resource, it is empty for the resources
+	// not scoped to a namespace.
+	RequestNamespace string
+}
+
+// Authorizer decides whether the request is allowed, the reason explains the decision.
+type Authorizer interface {
+	Authorize(ctx context.Context, attrs Attributes) (allowed bool, reason string, err error)
+}
+
+// Authorization make sure users are allowed to perform the requests by the authorizer.
+func Authorization(authorizer Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, reason, err := authorizer.Authorize(c, RequestAttributes(c))
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		if !allowed {
+			core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, reason), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequestAttributes derives the attributes of the request from its route, e.g.
+// `PUT /v1/namespaces/:ns/users/:name/change-password` updates the `users/change-password`
+// of the user `:name` in the namespace `:ns`.
+func RequestAttributes(c *gin.Context) Attributes {
+	attrs := Attributes{
+		Namespace:        c.GetString(NamespaceKey),
+		Username:         c.GetString(UsernameKey),
+		RequestNamespace: RequestNamespace(c),
+	}
+
+	segments := strings.Split(strings.Trim(c.FullPath(), "/"), "/")
+	if versionRegexp.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+	if len(segments) > 2 && segments[0] == namespacesResource {
+		segments = segments[2:]
+	}
+
+	if len(segments) > 0 {
+		attrs.Resource = segments[0]
+	}
+	if len(segments) > 1 && strings.HasPrefix(segments[1], ":") {
+		attrs.Name = c.Param(segments[1][1:])
+	}
+	if len(segments) > 2 {
+		attrs.Resource += "/" + segments[2]
+	}
+	if attrs.Resource == namespacesResource {
+		attrs.RequestNamespace = ""
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		attrs.Verb = v1.VerbGet
+		if len(segments) == 1 {
+			attrs.Verb = v1.VerbList
+		}
+	case http.MethodPost:
+		attrs.Verb = v1.VerbCreate
+	case http.MethodPut, http.MethodPatch:
+		attrs.Verb = v1.VerbUpdate
+	case http.MethodDelete:
+		attrs.Verb = v1.VerbDelete
+	default:
+		attrs.Verb = strings.ToLower(c.Request.Method)
+	}
+
+	return attrs
+}
@@ -23,6 +23,10 @@ import (
 // UsernameKey defines the key in gin context which represents the owner of the secret.
 const UsernameKey = "username"
 
+// PasswordExpiredKey defines the key in gin context which is set when the password of the authenticated
+// user expired, the user is only authenticated to change the password.
+const PasswordExpiredKey = "passwordExpired"
+
 // Context is a middleware that injects common prefix fields to gin.Context.
 // Context 中间件，用来在 gin.Context 中设置 requestID和 username键
 // 在打印日志时，将 gin.Context 类型的变量传递给 log.L() 函数，log.L() 函数会在日志输出中输出 requestID和 username域
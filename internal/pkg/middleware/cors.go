@@ -29,10 +29,13 @@ const (
 // HTTP请求跨域的中间件
 func Cors() gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"PUT", "PATCH", "GET", "POST", "OPTIONS", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{"PUT", "PATCH", "GET", "POST", "OPTIONS", "DELETE"},
+		AllowHeaders: []string{
+			"Origin", "Authorization", "Content-Type", "Accept",
+			"Idempotency-Key", "If-Match", "If-None-Match", "If-Unmodified-Since",
+		},
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed", "ETag", "Last-Modified"},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			return origin == "https://github.com"
@@ -0,0 +1,280 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the idempotency keys in redis.
+const KeyPrefix = "idempotency-"
+
+// Defines the idempotency headers.
+const (
+	// HeaderKey is the request header identifying the retries of a request.
+	HeaderKey = "Idempotency-Key"
+
+	// HeaderReplayed is set on the replayed responses.
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+// maxKeyLength is the max length of the Idempotency-Key header.
+const maxKeyLength = 255
+
+// skippedHeaders are the response headers describing the request instead of the response, they are
+// not replayed.
+var skippedHeaders = []string{middleware.XRequestIDKey, "Set-Cookie", "Retry-After",
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// Config defines how long the responses are kept.
+type Config struct {
+	// TTL is how long the response is replayed to the retries.
+	TTL time.Duration
+
+	// LockTimeout is how long the request is in progress at most, the retries are rejected meanwhile.
+	// The request is executed again once it has passed, e.g. the server crashed while handling it.
+	LockTimeout time.Duration
+}
+
+// Store keeps the responses by the idempotency keys.
+// It is implemented by storage.RedisCluster.
+type Store interface {
+	GetRawKey(key string) (string, error)
+	SetRawKey(key, value string, timeout time.Duration) error
+	SetRawKeyIfNotExist(key, value string, timeout time.Duration) (bool, error)
+	DeleteRawKey(key string) bool
+}
+
+// Idempotency replays the responses of the requests with the same idempotency key.
+type Idempotency struct {
+	config *Config
+	store  Store
+}
+
+var idempotency *Idempotency
+
+// New creates the idempotency middleware keeping the responses in redis.
+func New(config *Config) *Idempotency {
+	return NewWithStore(config, &storage.RedisCluster{})
+}
+
+// NewWithStore creates the idempotency middleware keeping the responses in the given store.
+func NewWithStore(config *Config, store Store) *Idempotency {
+	idempotency = &Idempotency{
+		config: config,
+		store:  store,
+	}
+
+	return idempotency
+}
+
+// storeFor returns the store running the commands in the context of the request, so that
+// they are traced as a part of it.
+func (i *Idempotency) storeFor(c *gin.Context) Store {
+	if s, ok := i.store.(*storage.RedisCluster); ok {
+		return s.WithContext(c.Request.Context())
+	}
+
+	return i.store
+}
+
+// record is the response kept for the retries, the status is 0 while the request is in progress.
+type record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Handler replays the response of the mutating request retried with the same `Idempotency-Key` header,
+// the key reused with a different request is rejected. The keys are scoped by the authenticated user,
+// it must be installed after the authentication middleware. It does nothing until the middleware is created.
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		i := idempotency
+		key := c.GetHeader(HeaderKey)
+		if i == nil || key == "" || !mutating(c.Request.Method) {
+			c.Next()
+
+			return
+		}
+
+		i.handle(c, key)
+	}
+}
+
+func (i *Idempotency) handle(c *gin.Context, key string) {
+	if len(key) > maxKeyLength {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation,
+			"the %s header must be at most %d characters", HeaderKey, maxKeyLength), nil)
+		c.Abort()
+
+		return
+	}
+
+	// the body is a part of the fingerprint, the oversized bodies are rejected before they are buffered
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, core.MaxBodyBytes))
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+		c.Abort()
+
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	storeKey := KeyPrefix + hash(scope(c), key)
+	fingerprint := hash(c.Request.Method, c.Request.URL.RequestURI(), string(body))
+
+	pending, _ := json.Marshal(record{Fingerprint: fingerprint})
+	acquired, err := i.storeFor(c).SetRawKeyIfNotExist(storeKey, string(pending), i.config.LockTimeout)
+	if err != nil {
+		// the requests are not deduplicated while redis is unavailable
+		log.L(c).Warnf("keep the idempotency key failed: %s", err.Error())
+		c.Next()
+
+		return
+	}
+	if !acquired {
+		i.replay(c, storeKey, fingerprint)
+
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	i.save(c, storeKey, fingerprint, recorder)
+}
+
+// save keeps the response for the retries, the server errors are not kept so that the retries are
+// executed again.
+func (i *Idempotency) save(c *gin.Context, storeKey, fingerprint string, recorder *responseRecorder) {
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError {
+		i.storeFor(c).DeleteRawKey(storeKey)
+
+		return
+	}
+
+	header := recorder.Header().Clone()
+	for _, name := range skippedHeaders {
+		header.Del(name)
+	}
+
+	value, _ := json.Marshal(record{
+		Fingerprint: fingerprint,
+		Status:      status,
+		Header:      header,
+		Body:        recorder.body.Bytes(),
+	})
+	if err := i.storeFor(c).SetRawKey(storeKey, string(value), i.config.TTL); err != nil {
+		log.L(c).Warnf("keep the response of the idempotency key failed: %s", err.Error())
+		i.storeFor(c).DeleteRawKey(storeKey)
+	}
+}
+
+// replay responds the kept response to the retry.
+func (i *Idempotency) replay(c *gin.Context, storeKey, fingerprint string) {
+	defer c.Abort()
+
+	var r record
+	value, err := i.storeFor(c).GetRawKey(storeKey)
+	if err == nil {
+		err = json.Unmarshal([]byte(value), &r)
+	}
+
+	switch {
+	case err == nil && r.Fingerprint != fingerprint:
+		core.WriteResponse(c, errors.WithCode(code.ErrIdempotencyKeyReused,
+			"the %s header was used by a different request", HeaderKey), nil)
+	case err != nil || r.Status == 0:
+		c.Header("Retry-After", "1")
+		core.WriteResponse(c, errors.WithCode(code.ErrIdempotencyKeyInProgress,
+			"the request with the same %s header is in progress", HeaderKey), nil)
+	default:
+		for name, values := range r.Header {
+			for _, v := range values {
+				c.Writer.Header().Add(name, v)
+			}
+		}
+		c.Header(HeaderReplayed, "true")
+		c.Writer.WriteHeader(r.Status)
+		_, _ = c.Writer.Write(r.Body)
+	}
+}
+
+// responseRecorder records the body written to the response.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
+
+// scope is the user the idempotency key belongs to, the keys of the unauthenticated requests are scoped
+// by the client IP so that the clients can not replay the responses of each other.
+func scope(c *gin.Context) string {
+	username := c.GetString(middleware.UsernameKey)
+	if username == "" {
+		return "ip:" + c.ClientIP()
+	}
+
+	return c.GetString(middleware.NamespaceKey) + "/" + username
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func hash(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
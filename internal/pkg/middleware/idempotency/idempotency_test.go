@@ -0,0 +1,235 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// fakeStore keeps the keys in memory, the expirations are ignored.
+type fakeStore struct {
+	sync.Mutex
+	values map[string]string
+}
+
+func (s *fakeStore) GetRawKey(key string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+
+	return "", storage.ErrKeyNotFound
+}
+
+func (s *fakeStore) SetRawKey(key, value string, timeout time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *fakeStore) SetRawKeyIfNotExist(key, value string, timeout time.Duration) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.values[key]; ok {
+		return false, nil
+	}
+	s.values[key] = value
+
+	return true, nil
+}
+
+func (s *fakeStore) DeleteRawKey(key string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	_, ok := s.values[key]
+	delete(s.values, key)
+
+	return ok
+}
+
+type unavailableStore struct {
+	fakeStore
+}
+
+func (*unavailableStore) SetRawKeyIfNotExist(key, value string, timeout time.Duration) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+// newTestEngine counts the created users, the creation fails with 500 when the body is `fail`.
+func newTestEngine(created *int) *gin.Engine {
+	g := gin.New()
+	g.Use(func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-User"))
+	}, Handler())
+	g.POST("/v1/users", func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		if string(body) == "fail" {
+			c.String(http.StatusInternalServerError, "failed")
+
+			return
+		}
+
+		*created++
+		c.Header("Location", "/v1/users/colin")
+		c.Header(middleware.XRequestIDKey, c.GetHeader(middleware.XRequestIDKey))
+		c.String(http.StatusOK, "created %d %s", *created, body)
+	})
+	g.GET("/v1/users", func(c *gin.Context) {
+		*created++
+	})
+
+	return g
+}
+
+func serve(g *gin.Engine, method, key, user, body string) *httptest.ResponseRecorder {
+	return serveFrom(g, "192.0.2.1:1234", method, key, user, body)
+}
+
+func serveFrom(g *gin.Engine, remoteAddr, method, key, user, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/v1/users", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(HeaderKey, key)
+	req.Header.Set("X-User", user)
+	req.Header.Set(middleware.XRequestIDKey, "request-"+body)
+	g.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	NewWithStore(&Config{TTL: time.Hour, LockTimeout: time.Minute}, &fakeStore{values: map[string]string{}})
+	created := 0
+	g := newTestEngine(&created)
+
+	w := serve(g, http.MethodPost, "key-1", "admin", "colin")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "created 1 colin", w.Body.String())
+	assert.Empty(t, w.Header().Get(HeaderReplayed))
+
+	// the retry is replayed without the headers of the request
+	w = serve(g, http.MethodPost, "key-1", "admin", "colin")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "created 1 colin", w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(HeaderReplayed))
+	assert.Equal(t, "/v1/users/colin", w.Header().Get("Location"))
+	assert.Empty(t, w.Header().Get(middleware.XRequestIDKey))
+	assert.Equal(t, 1, created)
+
+	w = serve(g, http.MethodPost, "key-1", "admin", "john")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, created)
+
+	// the keys are scoped by the users
+	w = serve(g, http.MethodPost, "key-1", "root", "john")
+	assert.Equal(t, "created 2 john", w.Body.String())
+
+	// the server errors are not replayed
+	assert.Equal(t, http.StatusInternalServerError, serve(g, http.MethodPost, "key-2", "admin", "fail").Code)
+	assert.Empty(t, serve(g, http.MethodPost, "key-2", "admin", "fail").Header().Get(HeaderReplayed))
+
+	// the reads and the requests without the key are not deduplicated
+	serve(g, http.MethodGet, "key-3", "admin", "")
+	serve(g, http.MethodGet, "key-3", "admin", "")
+	serve(g, http.MethodPost, "", "admin", "colin")
+	assert.Equal(t, 5, created)
+
+	w = serve(g, http.MethodPost, strings.Repeat("k", 256), "admin", "colin")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// the oversized bodies are not buffered
+	w = serve(g, http.MethodPost, "key-4", "admin", strings.Repeat("a", core.MaxBodyBytes+1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 5, created)
+}
+
+func TestHandler_Anonymous(t *testing.T) {
+	NewWithStore(&Config{TTL: time.Hour, LockTimeout: time.Minute}, &fakeStore{values: map[string]string{}})
+	created := 0
+	g := newTestEngine(&created)
+
+	w := serveFrom(g, "192.0.2.1:1234", http.MethodPost, "key-1", "", "colin")
+	assert.Equal(t, "created 1 colin", w.Body.String())
+
+	w = serveFrom(g, "192.0.2.1:5678", http.MethodPost, "key-1", "", "colin")
+	assert.Equal(t, "true", w.Header().Get(HeaderReplayed))
+
+	// the anonymous keys are scoped by the client IPs
+	w = serveFrom(g, "192.0.2.2:1234", http.MethodPost, "key-1", "", "colin")
+	assert.Empty(t, w.Header().Get(HeaderReplayed))
+	assert.Equal(t, "created 2 colin", w.Body.String())
+}
+
+func TestHandler_InProgress(t *testing.T) {
+	store := &fakeStore{values: map[string]string{}}
+	NewWithStore(&Config{TTL: time.Hour, LockTimeout: time.Minute}, store)
+
+	g := gin.New()
+	var retry *httptest.ResponseRecorder
+	g.POST("/v1/users", Handler(), func(c *gin.Context) {
+		// the retry arrives while the request is in progress
+		retry = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v1/users", strings.NewReader("colin"))
+		req.Header.Set(HeaderKey, "key-1")
+		g.ServeHTTP(retry, req)
+
+		c.String(http.StatusOK, "created")
+	})
+
+	w := serve(g, http.MethodPost, "key-1", "", "colin")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusConflict, retry.Code)
+	assert.Equal(t, "1", retry.Header().Get("Retry-After"))
+}
+
+func TestHandler_StoreUnavailable(t *testing.T) {
+	NewWithStore(&Config{TTL: time.Hour, LockTimeout: time.Minute}, &unavailableStore{})
+	created := 0
+	g := newTestEngine(&created)
+
+	serve(g, http.MethodPost, "key-1", "admin", "colin")
+	serve(g, http.MethodPost, "key-1", "admin", "colin")
+	assert.Equal(t, 2, created)
+}
+
+func TestHandler_NoIdempotency(t *testing.T) {
+	idempotency = nil
+	created := 0
+	g := newTestEngine(&created)
+
+	serve(g, http.MethodPost, "key-1", "admin", "colin")
+	serve(g, http.MethodPost, "key-1", "admin", "colin")
+	assert.Equal(t, 2, created)
+}
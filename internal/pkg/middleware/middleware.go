@@ -16,7 +16,6 @@ package middleware
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	gindump "github.com/tpkeeper/gin-dump"
@@ -26,12 +25,11 @@ import (
 var Middlewares = defaultMiddlewares()
 
 // NoCache is a middleware function that appends headers
-// to prevent the client from caching the HTTP response.
-// 禁止客户端缓存HTTP请求的返回结果
+// to require the client to revalidate the cached HTTP response
+// with its ETag before reusing it.
+// 要求客户端使用ETag校验缓存的HTTP请求返回结果后再使用
 func NoCache(c *gin.Context) {
-	c.Header("Cache-Control", "no-cache, no-store, max-age=0, must-revalidate, value")
-	c.Header("Expires", "Thu, 01 Jan 1970 00:00:00 GMT")
-	c.Header("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache")
 	c.Next()
 }
 
@@ -44,7 +42,7 @@ func Options(c *gin.Context) {
 	} else {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "authorization, origin, content-type, accept")
+		c.Header("Access-Control-Allow-Headers", "authorization, origin, content-type, accept, idempotency-key, if-match, if-none-match, if-unmodified-since")
 		c.Header("Allow", "HEAD,GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		c.Header("Content-Type", "application/json")
 		c.AbortWithStatus(http.StatusOK)
@@ -0,0 +1,51 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+const (
+	// NamespaceKey defines the key in gin context which represents the namespace of the authenticated user.
+	NamespaceKey = "namespace"
+
+	// NamespaceParam defines the route parameter which carries the namespace of the requested resource.
+	NamespaceParam = "ns"
+)
+
+// RequestNamespace returns the namespace of the requested resource,
+// routes without a namespace parameter are served in the default namespace.
+func RequestNamespace(c *gin.Context) string {
+	if namespace := c.Param(NamespaceParam); namespace != "" {
+		return namespace
+	}
+
+	return metav1.NamespaceDefault
+}
+
+// SplitNamespacedName splits a login identity in the form of `<namespace>/<name>`,
+// identities without a namespace belong to the default namespace.
+func SplitNamespacedName(identity string) (namespace string, name string) {
+	if i := strings.Index(identity, "/"); i > 0 {
+		return identity[:i], identity[i+1:]
+	}
+
+	return metav1.NamespaceDefault, identity
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/runtime"
+)
+
+// Negotiate is a middleware which rejects the requests whose `Accept` header contains none of
+// the supported media types with 406, and the requests whose body is in an unsupported
+// `Content-Type` with 415. The supported media types are the ones in runtime.DefaultRegistry.
+func Negotiate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !core.Acceptable(c.GetHeader("Accept")) {
+			core.WriteResponse(c, errors.WithCode(code.ErrNotAcceptable,
+				"supported media types: %v", runtime.DefaultRegistry.MediaTypes()), nil)
+			c.Abort()
+
+			return
+		}
+
+		if hasBody(c.Request) {
+			if _, ok := runtime.DefaultRegistry.SerializerForContentType(c.ContentType()); !ok {
+				core.WriteResponse(c, errors.WithCode(code.ErrUnsupportedMediaType,
+					"supported media types: %v", runtime.DefaultRegistry.MediaTypes()), nil)
+				c.Abort()
+
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func hasBody(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return r.ContentLength != 0
+	default:
+		return false
+	}
+}
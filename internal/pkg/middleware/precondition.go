@@ -0,0 +1,63 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// HasPreconditions reports whether the request carries an If-Match or If-Unmodified-Since
+// header, the controllers which do not load the object by themselves use it to skip the lookup.
+func HasPreconditions(c *gin.Context) bool {
+	return c.GetHeader("If-Match") != "" || c.GetHeader("If-Unmodified-Since") != ""
+}
+
+// CheckPreconditions evaluates the If-Match and If-Unmodified-Since headers of an update or
+// delete request against the current state of the object, code.ErrPreconditionFailed is
+// returned when they do not hold. As defined by RFC 7232, If-Unmodified-Since is ignored
+// when If-Match is present, and so are the invalid dates.
+// The check is atomic with the write as long as the object is written in the version checked
+// here: the stores update the object, and delete it with metav1.DeleteOptions.Version, only if
+// its version has not changed since it was read.
+func CheckPreconditions(c *gin.Context, obj metav1.ObjectMetaAccessor) error {
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		if !core.ETagMatch(ifMatch, core.ETag(obj), false) {
+			return errors.WithCode(code.ErrPreconditionFailed, "the resource has been modified, If-Match does not hold")
+		}
+
+		return nil
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Unmodified-Since"))
+	if err != nil {
+		return nil
+	}
+
+	// HTTP dates have a resolution of one second.
+	if obj.GetObjectMeta().GetUpdatedAt().Truncate(time.Second).After(since) {
+		return errors.WithCode(code.ErrPreconditionFailed, "the resource has been modified since %s",
+			since.UTC().Format(http.TimeFormat))
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/internal/pkg/code"
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/pkg/core"
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// Defines the supported rate limiting algorithms.
+const (
+	// SlidingWindow counts the requests in the rolling window of the period.
+	SlidingWindow = "sliding-window"
+
+	// TokenBucket refills the bucket of limit tokens over the period, a request takes a token.
+	TokenBucket = "token-bucket"
+)
+
+// Defines the rate limit headers set on the responses.
+const (
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+)
+
+// Quota is the number of the requests allowed in the period, the quota with a limit of 0 is unlimited.
+type Quota struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Config defines the quotas of the limiter.
+type Config struct {
+	// Algorithm is either SlidingWindow or TokenBucket.
+	Algorithm string
+
+	// IP is the quota of every client ip.
+	IP Quota
+
+	// User is the quota of every authenticated user.
+	User Quota
+
+	// Routes are the quotas of every client ip on the given routes, keyed by the method and the
+	// path of the routes, e.g. `POST /login`.
+	Routes map[string]Quota
+}
+
+// Result is the state of a quota after taking a request from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is the duration until the quota is fully available again.
+	Reset time.Duration
+
+	// RetryAfter is the duration until the next request is allowed, it is 0 when the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Store takes a request from the quota identified by key, the commands run in ctx. Refund gives the
+// request taken back to the quota.
+type Store interface {
+	Take(ctx context.Context, key string, quota Quota) (Result, error)
+	Refund(ctx context.Context, key string, quota Quota) error
+}
+
+// Limiter throttles the requests by the quotas in its config.
+type Limiter struct {
+	config   *Config
+	routes   map[string]Quota
+	store    Store
+	fallback Store
+}
+
+var limiter *Limiter
+
+// New creates a limiter sharing the quotas through redis, the quotas are kept in memory instead
+// while redis is unavailable.
+func New(config *Config) *Limiter {
+	return NewWithStore(config, NewRedisStore(config.Algorithm))
+}
+
+// NewWithStore creates a limiter sharing the quotas through the given store.
+func NewWithStore(config *Config, store Store) *Limiter {
+	routes := make(map[string]Quota, len(config.Routes))
+	for route, quota := range config.Routes {
+		routes[routeKey(route)] = quota
+	}
+
+	limiter = &Limiter{
+		config:   config,
+		routes:   routes,
+		store:    store,
+		fallback: NewMemoryStore(config.Algorithm),
+	}
+
+	return limiter
+}
+
+// Handler limits the requests per client ip and per route, it is registered in middleware.Middlewares
+// as `ratelimit`.
+func (l *Limiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		var keys []quotaKey
+		if l.config.IP.Limit > 0 {
+			keys = append(keys, quotaKey{key: "ip-" + ip, quota: l.config.IP})
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+		if quota, ok := l.routes[route]; ok && quota.Limit > 0 {
+			keys = append(keys, quotaKey{key: "route-" + route + "-" + ip, quota: quota})
+		}
+
+		l.limit(c, keys...)
+	}
+}
+
+// User limits the requests per authenticated user, it must be installed after the authentication
+// middleware. It does nothing until a limiter is created.
+func User() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l := limiter
+		username := c.GetString(middleware.UsernameKey)
+		if l == nil || l.config.User.Limit <= 0 || username == "" {
+			c.Next()
+
+			return
+		}
+
+		namespace := c.GetString(middleware.NamespaceKey)
+		l.limit(c, quotaKey{key: "user-" + namespace + "/" + username, quota: l.config.User})
+	}
+}
+
+type quotaKey struct {
+	key   string
+	quota Quota
+}
+
+// takenQuota is the quota a request is taken from, and the store keeping it.
+type takenQuota struct {
+	quotaKey
+	store Store
+}
+
+// limit takes the request from the quotas in order, the request is rejected by the first exhausted quota
+// and is given back to the quotas taken before, so that the rejected requests are not charged.
+// The headers describe the rejecting quota, or the quota with the least remaining requests.
+func (l *Limiter) limit(c *gin.Context, keys ...quotaKey) {
+	var result *Result
+	var taken []takenQuota
+	for _, k := range keys {
+		r, store := l.take(c, k.key, k.quota)
+		if result == nil || !r.Allowed || r.Remaining < result.Remaining {
+			result = &r
+		}
+
+		if !r.Allowed {
+			l.refund(c, taken)
+
+			break
+		}
+		taken = append(taken, takenQuota{quotaKey: k, store: store})
+	}
+
+	if result == nil {
+		c.Next()
+
+		return
+	}
+
+	c.Header(HeaderLimit, strconv.Itoa(result.Limit))
+	c.Header(HeaderRemaining, strconv.Itoa(result.Remaining))
+	c.Header(HeaderReset, strconv.FormatInt(seconds(result.Reset), 10))
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.FormatInt(seconds(result.RetryAfter), 10))
+		core.WriteResponse(c, errors.WithCode(code.ErrTooManyRequests,
+			"rate limit of %d requests exceeded, retry after %d seconds", result.Limit, seconds(result.RetryAfter)), nil)
+		c.Abort()
+
+		return
+	}
+
+	c.Next()
+}
+
+// take takes the request from the quota, it returns the store the request is taken from.
+func (l *Limiter) take(ctx context.Context, key string, quota Quota) (Result, Store) {
+	r, err := l.store.Take(ctx, key, quota)
+	if err != nil {
+		log.Debugf("rate limit store is unavailable, falling back to memory: %s", err.Error())
+		r, _ = l.fallback.Take(ctx, key, quota)
+
+		return r, l.fallback
+	}
+
+	return r, l.store
+}
+
+// refund gives the request back to the quotas it is taken from.
+func (l *Limiter) refund(ctx context.Context, taken []takenQuota) {
+	for _, t := range taken {
+		if err := t.store.Refund(ctx, t.key, t.quota); err != nil {
+			log.Debugf("refund rate limit quota `%s` failed: %s", t.key, err.Error())
+		}
+	}
+}
+
+// routeKey normalizes the route to the upper case method and the path.
+func routeKey(route string) string {
+	fields := strings.Fields(route)
+	if len(fields) != 2 {
+		return route
+	}
+
+	return strings.ToUpper(fields[0]) + " " + fields[1]
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/internal/pkg/middleware"
+)
+
+type unavailableStore struct{}
+
+func (unavailableStore) Take(ctx context.Context, key string, quota Quota) (Result, error) {
+	return Result{}, errors.New("unavailable")
+}
+
+func (unavailableStore) Refund(ctx context.Context, key string, quota Quota) error {
+	return errors.New("unavailable")
+}
+
+func newTestEngine(l *Limiter) *gin.Engine {
+	g := gin.New()
+	g.Use(l.Handler())
+	g.POST("/login", func(c *gin.Context) {})
+	g.GET("/v1/users", func(c *gin.Context) {
+		c.Set(middleware.UsernameKey, c.GetHeader("X-User"))
+	}, User(), func(c *gin.Context) {})
+
+	return g
+}
+
+func serve(g *gin.Engine, method, path, user string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("X-User", user)
+	g.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestLimiter_Handler(t *testing.T) {
+	l := NewWithStore(&Config{
+		Algorithm: SlidingWindow,
+		IP:        Quota{Limit: 6, Period: time.Minute},
+		User:      Quota{Limit: 2, Period: time.Minute},
+		Routes:    map[string]Quota{"post /login": {Limit: 1, Period: time.Minute}},
+	}, unavailableStore{})
+	g := newTestEngine(l)
+
+	w := serve(g, http.MethodPost, "/login", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(HeaderLimit))
+	assert.Equal(t, "0", w.Header().Get(HeaderRemaining))
+
+	w = serve(g, http.MethodPost, "/login", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// the users are limited separately
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/v1/users", "admin").Code)
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/v1/users", "admin").Code)
+	w = serve(g, http.MethodGet, "/v1/users", "admin")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderLimit))
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/v1/users", "root").Code)
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/v1/users", "guest").Code)
+
+	// the client ip has used up its quota
+	w = serve(g, http.MethodGet, "/v1/users", "guest")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "6", w.Header().Get(HeaderLimit))
+}
+
+func TestLimiter_RefundRejected(t *testing.T) {
+	l := NewWithStore(&Config{
+		Algorithm: TokenBucket,
+		IP:        Quota{Limit: 2, Period: time.Minute},
+		Routes:    map[string]Quota{"post /login": {Limit: 1, Period: time.Minute}},
+	}, unavailableStore{})
+	g := newTestEngine(l)
+
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodPost, "/login", "").Code)
+
+	// the requests rejected by the route quota are not charged to the client ip
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusTooManyRequests, serve(g, http.MethodPost, "/login", "").Code)
+	}
+
+	w := serve(g, http.MethodGet, "/v1/users", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get(HeaderRemaining))
+}
+
+func TestUser_NoLimiter(t *testing.T) {
+	limiter = nil
+
+	g := gin.New()
+	g.GET("/v1/users", User(), func(c *gin.Context) {})
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/v1/users", "admin").Code)
+}
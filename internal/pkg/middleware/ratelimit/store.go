@@ -0,0 +1,258 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dairongpeng/leona/pkg/storage"
+)
+
+// KeyPrefix defines the prefix of the rate limit keys in redis.
+const KeyPrefix = "ratelimit-"
+
+// sweepInterval is how often the memory store drops the idle quotas.
+const sweepInterval = time.Minute
+
+type redisStore struct {
+	algorithm string
+	redis     *storage.RedisCluster
+}
+
+// NewRedisStore returns a store keeping the quotas in redis, it fails when redis is unavailable.
+func NewRedisStore(algorithm string) Store {
+	return &redisStore{
+		algorithm: algorithm,
+		redis:     &storage.RedisCluster{KeyPrefix: KeyPrefix},
+	}
+}
+
+func (s *redisStore) Take(ctx context.Context, key string, quota Quota) (Result, error) {
+	if !storage.Connected() {
+		return Result{}, storage.ErrRedisIsDown
+	}
+
+	if s.algorithm == TokenBucket {
+		return s.takeToken(ctx, key, quota)
+	}
+
+	return s.takeWindow(ctx, key, quota)
+}
+
+func (s *redisStore) takeToken(ctx context.Context, key string, quota Quota) (Result, error) {
+	allowed, tokens, wait, err := s.redis.WithContext(ctx).TakeToken(key, int64(quota.Limit), quota.Period)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    allowed,
+		Limit:      quota.Limit,
+		Remaining:  int(tokens),
+		Reset:      refill(float64(tokens), quota),
+		RetryAfter: wait,
+	}, nil
+}
+
+func (s *redisStore) takeWindow(ctx context.Context, key string, quota Quota) (Result, error) {
+	allowed, count, reset, err := s.redis.WithContext(ctx).TakeWindow(key, int64(quota.Limit), quota.Period)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     quota.Limit,
+		Remaining: max(quota.Limit-int(count), 0),
+		Reset:     reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+
+	return result, nil
+}
+
+func (s *redisStore) Refund(ctx context.Context, key string, quota Quota) error {
+	if s.algorithm == TokenBucket {
+		return s.redis.WithContext(ctx).RefundToken(key, int64(quota.Limit))
+	}
+
+	return s.redis.WithContext(ctx).RefundWindow(key)
+}
+
+type window struct {
+	hits   []time.Time
+	period time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+type memoryStore struct {
+	algorithm string
+	now       func() time.Time
+
+	lock      sync.Mutex
+	windows   map[string]*window
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns a store keeping the quotas in memory, the quotas are not shared by the api servers.
+func NewMemoryStore(algorithm string) Store {
+	return &memoryStore{
+		algorithm: algorithm,
+		now:       time.Now,
+		windows:   make(map[string]*window),
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+func (s *memoryStore) Take(ctx context.Context, key string, quota Quota) (Result, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	if s.algorithm == TokenBucket {
+		return s.takeToken(key, quota, now), nil
+	}
+
+	return s.takeWindow(key, quota, now), nil
+}
+
+func (s *memoryStore) takeToken(key string, quota Quota, now time.Time) Result {
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(quota.Limit), last: now}
+		s.buckets[key] = b
+	}
+	b.period = quota.Period
+
+	rate := float64(quota.Limit) / float64(quota.Period)
+	b.tokens += float64(now.Sub(b.last)) * rate
+	if b.tokens > float64(quota.Limit) {
+		b.tokens = float64(quota.Limit)
+	}
+	b.last = now
+
+	result := Result{Limit: quota.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = refill(b.tokens, quota)
+
+	return result
+}
+
+func (s *memoryStore) takeWindow(key string, quota Quota, now time.Time) Result {
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{}
+		s.windows[key] = w
+	}
+	w.period = quota.Period
+
+	// drop the hits out of the window
+	i := 0
+	for i < len(w.hits) && now.Sub(w.hits[i]) >= quota.Period {
+		i++
+	}
+	w.hits = w.hits[i:]
+
+	result := Result{Limit: quota.Limit, Reset: quota.Period}
+	if len(w.hits) < quota.Limit {
+		w.hits = append(w.hits, now)
+		result.Allowed = true
+	}
+	result.Remaining = quota.Limit - len(w.hits)
+	if len(w.hits) > 0 {
+		result.Reset = w.hits[0].Add(quota.Period).Sub(now)
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.Reset
+	}
+
+	return result
+}
+
+func (s *memoryStore) Refund(ctx context.Context, key string, quota Quota) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.algorithm == TokenBucket {
+		if b, ok := s.buckets[key]; ok {
+			b.tokens = math.Min(b.tokens+1, float64(quota.Limit))
+		}
+
+		return nil
+	}
+
+	// the latest hit is removed, it is the hit of the request unless another request is taken meanwhile
+	if w, ok := s.windows[key]; ok && len(w.hits) > 0 {
+		w.hits = w.hits[:len(w.hits)-1]
+	}
+
+	return nil
+}
+
+// sweep drops the quotas which are fully available again.
+func (s *memoryStore) sweep(now time.Time) {
+	for key, w := range s.windows {
+		if len(w.hits) == 0 || now.Sub(w.hits[len(w.hits)-1]) >= w.period {
+			delete(s.windows, key)
+		}
+	}
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
+
+// refill returns the duration until the bucket holding tokens is full again.
+func refill(tokens float64, quota Quota) time.Duration {
+	missing := float64(quota.Limit) - tokens
+	if missing <= 0 {
+		return 0
+	}
+
+	return time.Duration(missing * float64(quota.Period) / float64(quota.Limit))
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMemoryStore(algorithm string) (*memoryStore, *time.Time) {
+	now := time.Unix(1600000000, 0)
+	s, _ := NewMemoryStore(algorithm).(*memoryStore)
+	s.now = func() time.Time { return now }
+
+	return s, &now
+}
+
+func TestMemoryStore_SlidingWindow(t *testing.T) {
+	s, now := newTestMemoryStore(SlidingWindow)
+	quota := Quota{Limit: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		r, _ := s.Take(context.TODO(), "ip-10.0.0.1", quota)
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 2-i, r.Remaining)
+		*now = now.Add(10 * time.Second)
+	}
+
+	r, _ := s.Take(context.TODO(), "ip-10.0.0.1", quota)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.Equal(t, 30*time.Second, r.RetryAfter)
+
+	r, _ = s.Take(context.TODO(), "ip-10.0.0.2", quota)
+	assert.True(t, r.Allowed)
+
+	// the first hit slides out of the window
+	*now = now.Add(30 * time.Second)
+	r, _ = s.Take(context.TODO(), "ip-10.0.0.1", quota)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.Equal(t, 10*time.Second, r.Reset)
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	s, now := newTestMemoryStore(TokenBucket)
+	quota := Quota{Limit: 2, Period: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		r, _ := s.Take(context.TODO(), "ip-10.0.0.1", quota)
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 1-i, r.Remaining)
+	}
+
+	r, _ := s.Take(context.TODO(), "ip-10.0.0.1", quota)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 30*time.Second, r.RetryAfter)
+	assert.Equal(t, time.Minute, r.Reset)
+
+	// a token is refilled every 30 seconds
+	*now = now.Add(30 * time.Second)
+	r, _ = s.Take(context.TODO(), "ip-10.0.0.1", quota)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+}
+
+func TestMemoryStore_Refund(t *testing.T) {
+	for _, algorithm := range []string{SlidingWindow, TokenBucket} {
+		t.Run(algorithm, func(t *testing.T) {
+			s, _ := newTestMemoryStore(algorithm)
+			quota := Quota{Limit: 1, Period: time.Minute}
+
+			r, _ := s.Take(context.TODO(), "ip-10.0.0.1", quota)
+			assert.True(t, r.Allowed)
+			assert.NoError(t, s.Refund(context.TODO(), "ip-10.0.0.1", quota))
+			r, _ = s.Take(context.TODO(), "ip-10.0.0.1", quota)
+			assert.True(t, r.Allowed)
+
+			// the quota never holds more than its limit
+			assert.NoError(t, s.Refund(context.TODO(), "ip-10.0.0.1", quota))
+			assert.NoError(t, s.Refund(context.TODO(), "ip-10.0.0.1", quota))
+			r, _ = s.Take(context.TODO(), "ip-10.0.0.1", quota)
+			assert.True(t, r.Allowed)
+			r, _ = s.Take(context.TODO(), "ip-10.0.0.1", quota)
+			assert.False(t, r.Allowed)
+		})
+	}
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	s, now := newTestMemoryStore(SlidingWindow)
+	quota := Quota{Limit: 3, Period: time.Minute}
+
+	_, _ = s.Take(context.TODO(), "ip-10.0.0.1", quota)
+	*now = now.Add(2 * time.Minute)
+	_, _ = s.Take(context.TODO(), "ip-10.0.0.2", quota)
+
+	assert.NotContains(t, s.windows, "ip-10.0.0.1")
+	assert.Contains(t, s.windows, "ip-10.0.0.2")
+}
@@ -1,72 +0,0 @@
-// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at
-//
-//     http://www.apache.org/licenses/LICENSE-2.0
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
-
-package middleware
-
-import (
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-
-	"github.com/dairongpeng/leona/internal/apiserver/store"
-	"github.com/dairongpeng/leona/internal/pkg/code"
-	"github.com/dairongpeng/leona/pkg/core"
-	"github.com/dairongpeng/leona/pkg/errors"
-	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
-)
-
-// Validation make sure users have the right resource permission and operation.
-func Validation() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if err := isAdmin(c); err != nil {
-			switch c.FullPath() {
-			case "/v1/users":
-				if c.Request.Method != http.MethodPost {
-					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
-					c.Abort()
-
-					return
-				}
-			case "/v1/users/:name", "/v1/users/:name/change_password":
-				username := c.GetString("username")
-				if c.Request.Method == http.MethodDelete ||
-					(c.Request.Method != http.MethodDelete && username != c.Param("name")) {
-					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
-					c.Abort()
-
-					return
-				}
-			default:
-			}
-		}
-
-		c.Next()
-	}
-}
-
-// isAdmin make sure the user is administrator.
-// It returns a `github.com/dairongpeng/leona/pkg/errors.withCode` error.
-func isAdmin(c *gin.Context) error {
-	username := c.GetString(UsernameKey)
-	user, err := store.Client().Users().Get(c, username, metav1.GetOptions{})
-	if err != nil {
-		return errors.WithCode(code.ErrDatabase, err.Error())
-	}
-
-	if user.IsAdmin != 1 {
-		return errors.WithCode(code.ErrPermissionDenied, "user %s is not a administrator", username)
-	}
-
-	return nil
-}
@@ -0,0 +1,91 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/dairongpeng/leona/internal/pkg/middleware/idempotency"
+	"github.com/dairongpeng/leona/internal/pkg/server"
+)
+
+// IdempotencyOptions contains configuration items related to the idempotency middleware.
+type IdempotencyOptions struct {
+	Enable      bool          `json:"enable"       mapstructure:"enable"`
+	TTL         time.Duration `json:"ttl"          mapstructure:"ttl"`
+	LockTimeout time.Duration `json:"lock-timeout" mapstructure:"lock-timeout"`
+}
+
+// NewIdempotencyOptions creates a IdempotencyOptions object with default parameters.
+func NewIdempotencyOptions() *IdempotencyOptions {
+	return &IdempotencyOptions{
+		Enable:      true,
+		TTL:         24 * time.Hour,
+		LockTimeout: time.Minute,
+	}
+}
+
+// ApplyTo applies the run options to the method receiver and returns self.
+func (o *IdempotencyOptions) ApplyTo(c *server.Config) error {
+	if !o.Enable {
+		return nil
+	}
+
+	c.Idempotency = &idempotency.Config{
+		TTL:         o.TTL,
+		LockTimeout: o.LockTimeout,
+	}
+
+	return nil
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *IdempotencyOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errors []error
+
+	if o.TTL < time.Minute {
+		errors = append(errors, fmt.Errorf("--idempotency.ttl %v must be at least 1m", o.TTL))
+	}
+
+	if o.LockTimeout < time.Second || o.LockTimeout > o.TTL {
+		errors = append(errors, fmt.Errorf("--idempotency.lock-timeout %v must be between 1s and the ttl", o.LockTimeout))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the idempotency middleware for a specific api server to the
+// specified FlagSet.
+func (o *IdempotencyOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "idempotency.enable", o.Enable, ""+
+		"Replay the responses of the mutating requests retried with the same Idempotency-Key header.")
+
+	fs.DurationVar(&o.TTL, "idempotency.ttl", o.TTL,
+		"How long the responses are replayed to the retries.")
+
+	fs.DurationVar(&o.LockTimeout, "idempotency.lock-timeout", o.LockTimeout, ""+
+		"How long a request is in progress at most, the retries are rejected meanwhile.")
+}
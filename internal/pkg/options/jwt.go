@@ -22,14 +22,19 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/dairongpeng/leona/internal/pkg/server"
+	"github.com/dairongpeng/leona/pkg/jwks"
 )
 
 // JwtOptions contains configuration items related to API server features.
 type JwtOptions struct {
-	Realm      string        `json:"realm"       mapstructure:"realm"`
-	Key        string        `json:"key"         mapstructure:"key"`
-	Timeout    time.Duration `json:"timeout"     mapstructure:"timeout"`
-	MaxRefresh time.Duration `json:"max-refresh" mapstructure:"max-refresh"`
+	Realm            string        `json:"realm"             mapstructure:"realm"`
+	Key              string        `json:"key"               mapstructure:"key"`
+	Timeout          time.Duration `json:"timeout"           mapstructure:"timeout"`
+	MaxRefresh       time.Duration `json:"max-refresh"       mapstructure:"max-refresh"`
+	SigningAlgorithm string        `json:"signing-algorithm" mapstructure:"signing-algorithm"`
+	RotationPeriod   time.Duration `json:"rotation-period"   mapstructure:"rotation-period"`
+	RotationOverlap  time.Duration `json:"rotation-overlap"  mapstructure:"rotation-overlap"`
+	KeyEncryptionKey string        `json:"key-encryption-key" mapstructure:"key-encryption-key"`
 }
 
 // NewJwtOptions creates a JwtOptions object with default parameters.
@@ -37,20 +42,28 @@ func NewJwtOptions() *JwtOptions {
 	defaults := server.NewConfig()
 
 	return &JwtOptions{
-		Realm:      defaults.Jwt.Realm,
-		Key:        defaults.Jwt.Key,
-		Timeout:    defaults.Jwt.Timeout,
-		MaxRefresh: defaults.Jwt.MaxRefresh,
+		Realm:            defaults.Jwt.Realm,
+		Key:              defaults.Jwt.Key,
+		Timeout:          defaults.Jwt.Timeout,
+		MaxRefresh:       defaults.Jwt.MaxRefresh,
+		SigningAlgorithm: defaults.Jwt.SigningAlgorithm,
+		RotationPeriod:   defaults.Jwt.RotationPeriod,
+		RotationOverlap:  defaults.Jwt.RotationOverlap,
+		KeyEncryptionKey: defaults.Jwt.KeyEncryptionKey,
 	}
 }
 
 // ApplyTo applies the run options to the method receiver and returns self.
 func (s *JwtOptions) ApplyTo(c *server.Config) error {
 	c.Jwt = &server.JwtInfo{
-		Realm:      s.Realm,
-		Key:        s.Key,
-		Timeout:    s.Timeout,
-		MaxRefresh: s.MaxRefresh,
+		Realm:            s.Realm,
+		Key:              s.Key,
+		Timeout:          s.Timeout,
+		MaxRefresh:       s.MaxRefresh,
+		SigningAlgorithm: s.SigningAlgorithm,
+		RotationPeriod:   s.RotationPeriod,
+		RotationOverlap:  s.RotationOverlap,
+		KeyEncryptionKey: s.KeyEncryptionKey,
 	}
 
 	return nil
@@ -61,8 +74,29 @@ func (s *JwtOptions) ApplyTo(c *server.Config) error {
 func (s *JwtOptions) Validate() []error {
 	var errs []error
 
-	if !govalidator.StringLength(s.Key, "6", "32") {
-		errs = append(errs, fmt.Errorf("--secret-key must larger than 5 and little than 33"))
+	switch s.SigningAlgorithm {
+	case "HS256":
+		if !govalidator.StringLength(s.Key, "6", "32") {
+			errs = append(errs, fmt.Errorf("--secret-key must larger than 5 and little than 33"))
+		}
+	case jwks.RS256, jwks.ES256, jwks.EdDSA:
+		if s.RotationPeriod <= 0 {
+			errs = append(errs, fmt.Errorf("--jwt.rotation-period must be greater than 0"))
+		}
+
+		// the rotated private keys are kept in redis, they must be encrypted by a key of their own
+		if len(s.KeyEncryptionKey) < 16 {
+			errs = append(errs, fmt.Errorf("--jwt.key-encryption-key must be at least 16 characters"))
+		}
+
+		// the tokens signed by the retired key must be verified until they can not be refreshed
+		if s.RotationOverlap < s.Timeout || s.RotationOverlap < s.MaxRefresh {
+			errs = append(errs, fmt.Errorf("--jwt.rotation-overlap %v must not be shorter than "+
+				"--jwt.timeout and --jwt.max-refresh", s.RotationOverlap))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("--jwt.signing-algorithm %q is not one of HS256, RS256, ES256 and EdDSA",
+			s.SigningAlgorithm))
 	}
 
 	return errs
@@ -81,4 +115,14 @@ func (s *JwtOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.DurationVar(&s.MaxRefresh, "jwt.max-refresh", s.MaxRefresh, ""+
 		"This field allows clients to refresh their token until MaxRefresh has passed.")
+
+	fs.StringVar(&s.SigningAlgorithm, "jwt.signing-algorithm", s.SigningAlgorithm, ""+
+		"The algorithm signing jwt token, one of HS256, RS256, ES256 and EdDSA. "+
+		"HS256 signs with jwt.key, the others sign with the rotated keys published by /.well-known/jwks.json.")
+	fs.DurationVar(&s.RotationPeriod, "jwt.rotation-period", s.RotationPeriod, ""+
+		"How long an asymmetric signing key is used before it is rotated.")
+	fs.DurationVar(&s.RotationOverlap, "jwt.rotation-overlap", s.RotationOverlap, ""+
+		"How long a rotated key still verifies the tokens it signed.")
+	fs.StringVar(&s.KeyEncryptionKey, "jwt.key-encryption-key", s.KeyEncryptionKey, ""+
+		"The key encrypting the rotated private keys kept in redis, it is required by the asymmetric algorithms.")
 }
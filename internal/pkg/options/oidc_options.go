@@ -0,0 +1,113 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/dairongpeng/leona/pkg/oidc"
+)
+
+// OIDCOptions contains configuration items related to the authentication by the ID tokens of an
+// OpenID Connect provider.
+type OIDCOptions struct {
+	Enable         bool          `json:"enable"          mapstructure:"enable"`
+	IssuerURL      string        `json:"issuer-url"      mapstructure:"issuer-url"`
+	ClientID       string        `json:"client-id"       mapstructure:"client-id"`
+	UsernameClaim  string        `json:"username-claim"  mapstructure:"username-claim"`
+	NamespaceClaim string        `json:"namespace-claim" mapstructure:"namespace-claim"`
+	EmailClaim     string        `json:"email-claim"     mapstructure:"email-claim"`
+	NicknameClaim  string        `json:"nickname-claim"  mapstructure:"nickname-claim"`
+	Provision      bool          `json:"provision"       mapstructure:"provision"`
+	JWKSCacheTTL   time.Duration `json:"jwks-cache-ttl"  mapstructure:"jwks-cache-ttl"`
+}
+
+// NewOIDCOptions creates a OIDCOptions object with default parameters.
+func NewOIDCOptions() *OIDCOptions {
+	return &OIDCOptions{
+		Enable:        false,
+		UsernameClaim: "preferred_username",
+		EmailClaim:    "email",
+		NicknameClaim: "name",
+		Provision:     false,
+		JWKSCacheTTL:  oidc.DefaultJWKSCacheTTL,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *OIDCOptions) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	var errors []error
+
+	if u, err := url.Parse(o.IssuerURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errors = append(errors, fmt.Errorf("--oidc.issuer-url %q must be an absolute url", o.IssuerURL))
+	}
+
+	if o.ClientID == "" {
+		errors = append(errors, fmt.Errorf("--oidc.client-id must not be empty"))
+	}
+
+	if o.UsernameClaim == "" {
+		errors = append(errors, fmt.Errorf("--oidc.username-claim must not be empty"))
+	}
+
+	if o.JWKSCacheTTL <= 0 {
+		errors = append(errors, fmt.Errorf("--oidc.jwks-cache-ttl %v must be greater than 0", o.JWKSCacheTTL))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the OpenID Connect authentication for a specific api server to the
+// specified FlagSet.
+func (o *OIDCOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "oidc.enable", o.Enable, ""+
+		"Accept the ID tokens issued by the OpenID Connect provider as the bearer tokens.")
+
+	fs.StringVar(&o.IssuerURL, "oidc.issuer-url", o.IssuerURL, ""+
+		"The issuer url of the OpenID Connect provider, its discovery document is served under it.")
+
+	fs.StringVar(&o.ClientID, "oidc.client-id", o.ClientID,
+		"The client id the ID tokens must be issued to.")
+
+	fs.StringVar(&o.UsernameClaim, "oidc.username-claim", o.UsernameClaim,
+		"The claim of the ID token used as the username.")
+
+	fs.StringVar(&o.NamespaceClaim, "oidc.namespace-claim", o.NamespaceClaim, ""+
+		"The claim of the ID token used as the namespace of the user, the default namespace is used if it is empty.")
+
+	fs.StringVar(&o.EmailClaim, "oidc.email-claim", o.EmailClaim,
+		"The claim of the ID token used as the email of the provisioned user.")
+
+	fs.StringVar(&o.NicknameClaim, "oidc.nickname-claim", o.NicknameClaim,
+		"The claim of the ID token used as the nickname of the provisioned user.")
+
+	fs.BoolVar(&o.Provision, "oidc.provision", o.Provision, ""+
+		"Create the user on the first login if it does not exist, otherwise the unknown users are rejected.")
+
+	fs.DurationVar(&o.JWKSCacheTTL, "oidc.jwks-cache-ttl", o.JWKSCacheTTL,
+		"How long the keys of the provider are cached before they are fetched again.")
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/dairongpeng/leona/internal/pkg/middleware/ratelimit"
+	"github.com/dairongpeng/leona/internal/pkg/server"
+)
+
+// RateLimitOptions contains configuration items related to the rate limiting middleware.
+type RateLimitOptions struct {
+	Enable    bool           `json:"enable"     mapstructure:"enable"`
+	Algorithm string         `json:"algorithm"  mapstructure:"algorithm"`
+	Period    time.Duration  `json:"period"     mapstructure:"period"`
+	IPLimit   int            `json:"ip-limit"   mapstructure:"ip-limit"`
+	UserLimit int            `json:"user-limit" mapstructure:"user-limit"`
+	Routes    map[string]int `json:"routes"     mapstructure:"routes"`
+}
+
+// NewRateLimitOptions creates a RateLimitOptions object with default parameters.
+func NewRateLimitOptions() *RateLimitOptions {
+	return &RateLimitOptions{
+		Enable:    false,
+		Algorithm: ratelimit.SlidingWindow,
+		Period:    time.Minute,
+		IPLimit:   600,
+		UserLimit: 300,
+		Routes: map[string]int{
+			"POST /login": 20,
+		},
+	}
+}
+
+// ApplyTo applies the run options to the method receiver and returns self.
+func (o *RateLimitOptions) ApplyTo(c *server.Config) error {
+	if !o.Enable {
+		return nil
+	}
+
+	routes := make(map[string]ratelimit.Quota, len(o.Routes))
+	for route, limit := range o.Routes {
+		routes[route] = ratelimit.Quota{Limit: limit, Period: o.Period}
+	}
+
+	c.RateLimit = &ratelimit.Config{
+		Algorithm: o.Algorithm,
+		IP:        ratelimit.Quota{Limit: o.IPLimit, Period: o.Period},
+		User:      ratelimit.Quota{Limit: o.UserLimit, Period: o.Period},
+		Routes:    routes,
+	}
+
+	return nil
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *RateLimitOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errors []error
+
+	if o.Algorithm != ratelimit.SlidingWindow && o.Algorithm != ratelimit.TokenBucket {
+		errors = append(errors, fmt.Errorf("--ratelimit.algorithm %s must be one of %s, %s",
+			o.Algorithm, ratelimit.SlidingWindow, ratelimit.TokenBucket))
+	}
+
+	if o.Period < time.Second {
+		errors = append(errors, fmt.Errorf("--ratelimit.period %v must be at least 1s", o.Period))
+	}
+
+	if o.IPLimit < 0 {
+		errors = append(errors, fmt.Errorf("--ratelimit.ip-limit %v must not be negative", o.IPLimit))
+	}
+
+	if o.UserLimit < 0 {
+		errors = append(errors, fmt.Errorf("--ratelimit.user-limit %v must not be negative", o.UserLimit))
+	}
+
+	for route, limit := range o.Routes {
+		if len(strings.Fields(route)) != 2 {
+			errors = append(errors, fmt.Errorf("--ratelimit.routes route `%s` must be in the form of `<method> <path>`", route))
+		}
+
+		if limit < 0 {
+			errors = append(errors, fmt.Errorf("--ratelimit.routes limit %v of route `%s` must not be negative", limit, route))
+		}
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the rate limiting middleware for a specific api server to the
+// specified FlagSet.
+func (o *RateLimitOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.BoolVar(&o.Enable, "ratelimit.enable", o.Enable, ""+
+		"Register the ratelimit middleware, it is installed when listed in --server.middlewares.")
+
+	fs.StringVar(&o.Algorithm, "ratelimit.algorithm", o.Algorithm, ""+
+		"The rate limiting algorithm. Supported algorithms: sliding-window, token-bucket.")
+
+	fs.DurationVar(&o.Period, "ratelimit.period", o.Period,
+		"The period of the rate limit quotas.")
+
+	fs.IntVar(&o.IPLimit, "ratelimit.ip-limit", o.IPLimit,
+		"The requests allowed per client ip in a period, 0 means unlimited.")
+
+	fs.IntVar(&o.UserLimit, "ratelimit.user-limit", o.UserLimit,
+		"The requests allowed per authenticated user in a period, 0 means unlimited.")
+
+	fs.StringToIntVar(&o.Routes, "ratelimit.routes", o.Routes, ""+
+		"The requests allowed per client ip on the routes in a period, e.g. `POST /login=20`.")
+}
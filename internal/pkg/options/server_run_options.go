@@ -62,7 +62,7 @@ func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet) {
 		"Start the server in a specified server mode. Supported server mode: debug, test, release.")
 
 	fs.BoolVar(&s.Healthz, "server.healthz", s.Healthz, ""+
-		"Add self readiness check and install /healthz router.")
+		"Add self liveness and readiness checks and install /healthz, /livez and /readyz routers.")
 
 	fs.StringSliceVar(&s.Middlewares, "server.middlewares", s.Middlewares, ""+
 		"List of allowed middlewares for server, comma separated. If this list is empty default middlewares will be used.")
@@ -25,6 +25,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 
+	"github.com/dairongpeng/leona/internal/pkg/middleware/idempotency"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/ratelimit"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -42,6 +44,8 @@ type Config struct {
 	SecureServing   *SecureServingInfo
 	InsecureServing *InsecureServingInfo
 	Jwt             *JwtInfo
+	RateLimit       *ratelimit.Config
+	Idempotency     *idempotency.Config
 	Mode            string
 	Middlewares     []string
 	Healthz         bool
@@ -84,6 +88,14 @@ type JwtInfo struct {
 	Timeout time.Duration
 	// defaults to zero
 	MaxRefresh time.Duration
+	// defaults to HS256
+	SigningAlgorithm string
+	// defaults to 30 days
+	RotationPeriod time.Duration
+	// defaults to 48 hours
+	RotationOverlap time.Duration
+	// defaults to empty
+	KeyEncryptionKey string
 }
 
 // NewConfig returns a Config struct with the default values.
@@ -95,9 +107,12 @@ func NewConfig() *Config {
 		EnableProfiling: true,
 		EnableMetrics:   true,
 		Jwt: &JwtInfo{
-			Realm:      "leona jwt",
-			Timeout:    1 * time.Hour,
-			MaxRefresh: 1 * time.Hour,
+			Realm:            "leona jwt",
+			Timeout:          1 * time.Hour,
+			MaxRefresh:       1 * time.Hour,
+			SigningAlgorithm: "HS256",
+			RotationPeriod:   30 * 24 * time.Hour,
+			RotationOverlap:  48 * time.Hour,
 		},
 	}
 }
@@ -123,6 +138,9 @@ func (c CompletedConfig) New() (*GenericAPIServer, error) {
 		enableMetrics:       c.EnableMetrics,
 		enableProfiling:     c.EnableProfiling,
 		middlewares:         c.Middlewares,
+		rateLimit:           c.RateLimit,
+		idempotency:         c.Idempotency,
+		HealthChecks:        NewHealthChecks(),
 		Engine:              gin.New(),
 	}
 
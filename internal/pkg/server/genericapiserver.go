@@ -30,6 +30,8 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/dairongpeng/leona/internal/pkg/middleware"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/idempotency"
+	"github.com/dairongpeng/leona/internal/pkg/middleware/ratelimit"
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
@@ -37,6 +39,8 @@ import (
 // type GenericAPIServer gin.Engine.
 type GenericAPIServer struct {
 	middlewares []string
+	rateLimit   *ratelimit.Config
+	idempotency *idempotency.Config
 	mode        string
 	// SecureServingInfo holds configuration of the TLS server.
 	SecureServingInfo *SecureServingInfo
@@ -48,6 +52,9 @@ type GenericAPIServer struct {
 	// gracefully shutdown returns.
 	ShutdownTimeout time.Duration
 
+	// HealthChecks holds the liveness and readiness checks served by `/livez` and `/readyz`.
+	HealthChecks *HealthChecks
+
 	*gin.Engine
 	healthz         bool
 	enableMetrics   bool
@@ -68,11 +75,11 @@ func initGenericAPIServer(s *GenericAPIServer) {
 
 // InstallAPIs install generic apis.
 func (s *GenericAPIServer) InstallAPIs() {
-	// install healthz handler
+	// install health check handlers, `/healthz` runs the liveness checks and keeps its JSON body
 	if s.healthz {
-		s.GET("/healthz", func(c *gin.Context) {
-			core.WriteResponse(c, nil, map[string]string{"status": "ok"})
-		})
+		s.GET("/healthz", gin.WrapH(s.HealthChecks.HealthzHandler()))
+		s.GET("/livez", gin.WrapH(s.HealthChecks.LivezHandler()))
+		s.GET("/readyz", gin.WrapH(s.HealthChecks.ReadyzHandler()))
 	}
 
 	// install metric handler
@@ -105,10 +112,24 @@ func (s *GenericAPIServer) InstallMiddlewares() {
 	// necessary middlewares
 	// 安装RequestID中间件
 	s.Use(middleware.RequestID())
+	// 安装Tracing中间件，未启用tracing时只传递上游的trace context
+	s.Use(middleware.Tracing())
 	// 安装Context中间件
 	s.Use(middleware.Context())
+	// 安装内容协商中间件
+	s.Use(middleware.Negotiate())
 	// s.Use(limits.RequestSizeLimiter(10))
 
+	// 注册限流中间件，需要在 middlewares 中启用
+	if s.rateLimit != nil {
+		middleware.Middlewares["ratelimit"] = ratelimit.New(s.rateLimit).Handler()
+	}
+
+	// 创建幂等中间件，由路由在认证之后安装
+	if s.idempotency != nil {
+		idempotency.New(s.idempotency)
+	}
+
 	// install custom middlewares
 	for _, m := range s.middlewares {
 		mw, ok := middleware.Middlewares[m]
@@ -211,6 +232,8 @@ func (s *GenericAPIServer) Run() error {
 
 // Close graceful shutdown the api server.
 func (s *GenericAPIServer) Close() {
+	s.HealthChecks.SetShuttingDown()
+
 	// The context is used to inform the server it has 10 seconds to finish
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -230,9 +253,9 @@ func (s *GenericAPIServer) Close() {
 func (s *GenericAPIServer) ping(ctx context.Context) error {
 	// 当 HTTP 服务监听在所有网卡时，请求 IP 为 127.0.0.1；
 	// 当 HTTP 服务监听在指定网卡时，我们需要请求该网卡的 IP 地址。
-	url := fmt.Sprintf("http://%s/healthz", s.InsecureServingInfo.Address)
+	url := fmt.Sprintf("http://%s/livez", s.InsecureServingInfo.Address)
 	if strings.Contains(s.InsecureServingInfo.Address, "0.0.0.0") {
-		url = fmt.Sprintf("http://127.0.0.1:%s/healthz", strings.Split(s.InsecureServingInfo.Address, ":")[1])
+		url = fmt.Sprintf("http://127.0.0.1:%s/livez", strings.Split(s.InsecureServingInfo.Address, ":")[1])
 	}
 
 	for {
@@ -241,7 +264,7 @@ func (s *GenericAPIServer) ping(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		// Ping the server by sending a GET request to `/healthz`.
+		// Ping the server by sending a GET request to `/livez`.
 
 		resp, err := http.DefaultClient.Do(req)
 		if err == nil && resp.StatusCode == http.StatusOK {
@@ -18,18 +18,25 @@ package server
 import (
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	"github.com/dairongpeng/leona/pkg/log"
 )
 
-// ServeHealthCheck runs a http server used to provide a api to check gstash health status.
-func ServeHealthCheck(healthPath string, healthAddress string) {
-	http.HandleFunc("/"+healthPath, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status": "ok"}`))
-	})
+// ServeHealthCheck runs a http server used to provide the liveness and readiness checks of gstash,
+// the health path runs the liveness checks and responds with the JSON body it always has. The metrics
+// of the default prometheus registry are served by `/metrics`.
+func ServeHealthCheck(healthPath string, healthAddress string, checks *HealthChecks) {
+	mux := http.NewServeMux()
+	mux.Handle("/livez", checks.LivezHandler())
+	mux.Handle("/readyz", checks.ReadyzHandler())
+	mux.Handle("/metrics", promhttp.Handler())
+
+	if healthPath != "livez" && healthPath != "readyz" && healthPath != "metrics" {
+		mux.Handle("/"+healthPath, checks.HealthzHandler())
+	}
 
-	if err := http.ListenAndServe(healthAddress, nil); err != nil {
+	if err := http.ListenAndServe(healthAddress, mux); err != nil {
 		log.Fatalf("Error serving health check endpoint: %s", err.Error())
 	}
 }
@@ -0,0 +1,212 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+// HealthChecker is a named check of the health of the server or one of its dependencies.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c healthCheck) Name() string { return c.name }
+
+func (c healthCheck) Check(ctx context.Context) error { return c.check(ctx) }
+
+// NamedCheck returns a health checker with the given name which runs the check function.
+func NamedCheck(name string, check func(ctx context.Context) error) HealthChecker {
+	return healthCheck{name: name, check: check}
+}
+
+// PingHealthz returns ok automatically when checked, it tells the server is able to serve requests.
+var PingHealthz = NamedCheck("ping", func(context.Context) error { return nil })
+
+// HealthChecks is the registry of the liveness and readiness checks of a server. The liveness
+// checks tell whether the process should be restarted, the readiness checks tell whether the
+// server should receive traffic, so the latter include the dependencies of the server and fail
+// once the server starts to shut down.
+type HealthChecks struct {
+	lock         sync.RWMutex
+	livez        []HealthChecker
+	readyz       []HealthChecker
+	shuttingDown int32
+}
+
+// NewHealthChecks returns a registry with the ping check, and the shutdown readiness check.
+func NewHealthChecks() *HealthChecks {
+	h := &HealthChecks{livez: []HealthChecker{PingHealthz}}
+	h.readyz = []HealthChecker{PingHealthz, NamedCheck("shutdown", h.checkShutdown)}
+
+	return h
+}
+
+// AddLivezChecks adds the checks to the liveness checks.
+func (h *HealthChecks) AddLivezChecks(checks ...HealthChecker) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.livez = append(h.livez, checks...)
+}
+
+// AddReadyzChecks adds the checks to the readiness checks.
+func (h *HealthChecks) AddReadyzChecks(checks ...HealthChecker) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.readyz = append(h.readyz, checks...)
+}
+
+// SetShuttingDown fails the readiness checks from now on, so that the load balancers stop
+// sending new requests to the server which is shutting down.
+func (h *HealthChecks) SetShuttingDown() {
+	atomic.StoreInt32(&h.shuttingDown, 1)
+}
+
+func (h *HealthChecks) checkShutdown(context.Context) error {
+	if atomic.LoadInt32(&h.shuttingDown) == 1 {
+		return errors.New("the server is shutting down")
+	}
+
+	return nil
+}
+
+// LivezHandler returns the handler of the liveness checks.
+func (h *HealthChecks) LivezHandler() http.Handler {
+	return h.handler("livez", h.livezChecks)
+}
+
+// HealthzHandler returns the handler of the legacy `/healthz` endpoint. It runs the liveness checks
+// and keeps responding with the JSON body `{"status":"ok"}`, which the existing probes expect.
+func (h *HealthChecks) HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, code := "ok", http.StatusOK
+		for _, check := range h.livezChecks() {
+			if err := check.Check(r.Context()); err != nil {
+				log.Warnf("healthz check `%s` failed: %s", check.Name(), err.Error())
+				status, code = "failed", http.StatusInternalServerError
+
+				break
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		_, _ = fmt.Fprintf(w, `{"status":%q}`, status)
+	})
+}
+
+func (h *HealthChecks) livezChecks() []HealthChecker {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.livez
+}
+
+// ReadyzHandler returns the handler of the readiness checks.
+func (h *HealthChecks) ReadyzHandler() http.Handler {
+	return h.handler("readyz", func() []HealthChecker {
+		h.lock.RLock()
+		defer h.lock.RUnlock()
+
+		return h.readyz
+	})
+}
+
+// handler runs the checks and responds with `ok`, or with `500 Internal Server Error` listing the
+// checks when one of them fails. The `verbose` query lists the checks and the failure reasons
+// anyway, and the checks named by the `exclude` queries are skipped.
+func (h *HealthChecks) handler(name string, checks func() []HealthChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		excluded := map[string]bool{}
+		for _, exclude := range r.URL.Query()["exclude"] {
+			excluded[strings.TrimSpace(exclude)] = true
+		}
+		_, verbose := r.URL.Query()["verbose"]
+
+		var out bytes.Buffer
+		failed := false
+		for _, check := range checks() {
+			if _, ok := excluded[check.Name()]; ok {
+				delete(excluded, check.Name())
+				fmt.Fprintf(&out, "[+]%s excluded: ok\n", check.Name())
+
+				continue
+			}
+
+			if err := check.Check(r.Context()); err != nil {
+				log.Warnf("%s check `%s` failed: %s", name, check.Name(), err.Error())
+				failed = true
+
+				reason := "reason withheld"
+				if verbose {
+					reason = err.Error()
+				}
+				fmt.Fprintf(&out, "[-]%s failed: %s\n", check.Name(), reason)
+
+				continue
+			}
+
+			fmt.Fprintf(&out, "[+]%s ok\n", check.Name())
+		}
+
+		if len(excluded) > 0 {
+			names := make([]string, 0, len(excluded))
+			for name := range excluded {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Fprintf(&out, "warn: some health checks cannot be excluded: no matches for %s\n", strings.Join(names, ","))
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		if failed {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(&out, "%s check failed\n", name)
+			_, _ = out.WriteTo(w)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if !verbose {
+			_, _ = w.Write([]byte("ok"))
+
+			return
+		}
+
+		fmt.Fprintf(&out, "%s check passed\n", name)
+		_, _ = out.WriteTo(w)
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthChecks(t *testing.T) {
+	checks := NewHealthChecks()
+	checks.AddLivezChecks(NamedCheck("deadlock", func(context.Context) error { return nil }))
+	checks.AddReadyzChecks(NamedCheck("mysql", func(context.Context) error { return errors.New("connection refused") }))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		url     string
+		status  int
+		body    string
+	}{
+		{
+			name:    "livez",
+			handler: checks.LivezHandler(),
+			url:     "/livez",
+			status:  http.StatusOK,
+			body:    "ok",
+		},
+		{
+			name:    "livez verbose",
+			handler: checks.LivezHandler(),
+			url:     "/livez?verbose",
+			status:  http.StatusOK,
+			body:    "[+]ping ok\n[+]deadlock ok\nlivez check passed\n",
+		},
+		{
+			name:    "readyz",
+			handler: checks.ReadyzHandler(),
+			url:     "/readyz",
+			status:  http.StatusInternalServerError,
+			body:    "[+]ping ok\n[+]shutdown ok\n[-]mysql failed: reason withheld\nreadyz check failed\n",
+		},
+		{
+			name:    "readyz verbose",
+			handler: checks.ReadyzHandler(),
+			url:     "/readyz?verbose",
+			status:  http.StatusInternalServerError,
+			body:    "[+]ping ok\n[+]shutdown ok\n[-]mysql failed: connection refused\nreadyz check failed\n",
+		},
+		{
+			name:    "readyz exclude",
+			handler: checks.ReadyzHandler(),
+			url:     "/readyz?verbose&exclude=mysql&exclude=redis",
+			status:  http.StatusOK,
+			body: "[+]ping ok\n[+]shutdown ok\n[+]mysql excluded: ok\n" +
+				"warn: some health checks cannot be excluded: no matches for redis\nreadyz check passed\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+			assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestHealthChecks_Healthz(t *testing.T) {
+	checks := NewHealthChecks()
+
+	w := httptest.NewRecorder()
+	checks.HealthzHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"status":"ok"}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	checks.AddLivezChecks(NamedCheck("deadlock", func(context.Context) error { return errors.New("deadlock") }))
+
+	w = httptest.NewRecorder()
+	checks.HealthzHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, `{"status":"failed"}`, w.Body.String())
+}
+
+func TestHealthChecks_SetShuttingDown(t *testing.T) {
+	checks := NewHealthChecks()
+
+	w := httptest.NewRecorder()
+	checks.ReadyzHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	checks.SetShuttingDown()
+
+	w = httptest.NewRecorder()
+	checks.ReadyzHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz?verbose", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "[-]shutdown failed: the server is shutting down\n")
+
+	// the server is still alive while shutting down.
+	w = httptest.NewRecorder()
+	checks.LivezHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
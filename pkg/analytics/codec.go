@@ -0,0 +1,165 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	msgpackv5 "github.com/vmihailenco/msgpack/v5"
+	msgpackv2 "gopkg.in/vmihailenco/msgpack.v2"
+
+	"github.com/dairongpeng/leona/pkg/json"
+)
+
+// The IDs of the builtin codecs. An encoded record starts with the ID of its codec, the IDs
+// are below 0x80, so that they are not mistaken for the msgpack maps of the version 1 records.
+const (
+	CodecMsgpack  byte = 0x01
+	CodecJSON     byte = 0x02
+	CodecProtobuf byte = 0x03
+)
+
+// Codec encodes the records without the header byte.
+type Codec interface {
+	Marshal(r *Record) ([]byte, error)
+	Unmarshal(data []byte, r *Record) error
+}
+
+type codecEntry struct {
+	name  string
+	codec Codec
+}
+
+var (
+	codecsLock sync.RWMutex
+	codecs     = map[byte]codecEntry{}
+)
+
+func init() {
+	RegisterCodec(CodecMsgpack, "msgpack", msgpackCodec{})
+	RegisterCodec(CodecJSON, "json", jsonCodec{})
+	RegisterCodec(CodecProtobuf, "protobuf", protobufCodec{})
+}
+
+// RegisterCodec registers the codec by its ID and name, it panics if the ID is used or is not below 0x80.
+func RegisterCodec(id byte, name string, codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+
+	if id == 0 || id >= 0x80 {
+		panic(fmt.Sprintf("analytics: codec id %#x of %s must be between 0x01 and 0x7f", id, name))
+	}
+	if e, ok := codecs[id]; ok {
+		panic(fmt.Sprintf("analytics: codec id %#x of %s is used by %s", id, name, e.name))
+	}
+
+	codecs[id] = codecEntry{name: name, codec: codec}
+}
+
+// LookupCodec returns the ID of the codec registered with the name.
+func LookupCodec(name string) (byte, error) {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+
+	for id, e := range codecs {
+		if e.name == name {
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("analytics: unknown codec %q", name)
+}
+
+// Encode encodes the record by the codec, the record is written as the current version if it
+// does not have one.
+func Encode(id byte, r *Record) ([]byte, error) {
+	codecsLock.RLock()
+	e, ok := codecs[id]
+	codecsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("analytics: unknown codec id %#x", id)
+	}
+
+	record := *r
+	if record.Version == 0 {
+		record.Version = SchemaVersion
+	}
+
+	data, err := e.codec.Marshal(&record)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{id}, data...), nil
+}
+
+// Decode decodes the record by the codec of its header byte. The records without a header byte
+// are decoded as the version 1 records.
+func Decode(data []byte) (*Record, error) {
+	if len(data) == 0 {
+		return nil, errors.New("analytics: empty record")
+	}
+
+	codecsLock.RLock()
+	e, ok := codecs[data[0]]
+	codecsLock.RUnlock()
+	if !ok {
+		return decodeV1(data)
+	}
+
+	var r Record
+	if err := e.codec.Unmarshal(data[1:], &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// decodeV1 decodes the records of the version 1, which were encoded by msgpack.v2 in
+// leona-apiserver, or by msgpack v5 in the other producers.
+func decodeV1(data []byte) (*Record, error) {
+	var r Record
+	if err := msgpackv2.Unmarshal(data, &r); err != nil {
+		r = Record{}
+		if errv5 := msgpackv5.Unmarshal(data, &r); errv5 != nil {
+			return nil, fmt.Errorf("analytics: decode version 1 record: %w", err)
+		}
+	}
+	r.Version = 1
+
+	return &r, nil
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(r *Record) ([]byte, error) {
+	return msgpackv5.Marshal(r)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, r *Record) error {
+	return msgpackv5.Unmarshal(data, r)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(r *Record) ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (jsonCodec) Unmarshal(data []byte, r *Record) error {
+	return json.Unmarshal(data, r)
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	msgpackv5 "github.com/vmihailenco/msgpack/v5"
+	msgpackv2 "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func testRecord() *Record {
+	return &Record{
+		ID:         "5b0c7e2e-7c1a-4f4c-9a8e-2f4c2d1b6a01",
+		RequestID:  "ab6f2c3e-0d3b-4a4c-8f1e-6b2a9c7d5e10",
+		TimeStamp:  1635000000,
+		Username:   "admin",
+		Effect:     "allow",
+		Conclusion: "policies allow access",
+		Request:    `{"action":"delete"}`,
+		ClientIP:   "10.0.0.1",
+		Latency:    12,
+		Status:     200,
+		ExpireAt:   time.Unix(1735000000, 0),
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, name := range []string{"msgpack", "json", "protobuf"} {
+		t.Run(name, func(t *testing.T) {
+			id, err := LookupCodec(name)
+			assert.Nil(t, err)
+
+			data, err := Encode(id, testRecord())
+			assert.Nil(t, err)
+			assert.Equal(t, id, data[0])
+
+			r, err := Decode(data)
+			assert.Nil(t, err)
+
+			want := testRecord()
+			want.Version = SchemaVersion
+			assert.True(t, want.ExpireAt.Equal(r.ExpireAt))
+			r.ExpireAt = want.ExpireAt
+			assert.Equal(t, want, r)
+		})
+	}
+}
+
+func TestDecode_V1(t *testing.T) {
+	type v1Record struct {
+		TimeStamp int64
+		Username  string
+		Effect    string
+		ExpireAt  time.Time `json:"expireAt"   bson:"expireAt"`
+	}
+	old := v1Record{TimeStamp: 1635000000, Username: "admin", Effect: "delete", ExpireAt: time.Unix(1735000000, 0)}
+
+	v2, err := msgpackv2.Marshal(&old)
+	assert.Nil(t, err)
+	v5, err := msgpackv5.Marshal(&old)
+	assert.Nil(t, err)
+
+	for _, data := range [][]byte{v2, v5} {
+		r, err := Decode(data)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, r.Version)
+		assert.Equal(t, "admin", r.Username)
+		assert.Equal(t, "delete", r.Effect)
+		assert.Equal(t, int64(1635000000), r.TimeStamp)
+		assert.True(t, old.ExpireAt.Equal(r.ExpireAt))
+	}
+
+	_, err = Decode([]byte{0xc1})
+	assert.NotNil(t, err)
+	_, err = Decode(nil)
+	assert.NotNil(t, err)
+}
+
+func TestDecode_NewerVersion(t *testing.T) {
+	id, _ := LookupCodec("protobuf")
+	data, _ := Encode(id, &Record{Version: SchemaVersion + 1, Username: "admin"})
+	// an unknown field of the newer version
+	data = append(data, 0xa8, 0x01, 0x01)
+
+	r, err := Decode(data)
+	assert.Nil(t, err)
+	assert.Equal(t, SchemaVersion+1, r.Version)
+	assert.Equal(t, "admin", r.Username)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	assert.Panics(t, func() { RegisterCodec(CodecJSON, "json2", jsonCodec{}) })
+	assert.Panics(t, func() { RegisterCodec(0x84, "map", jsonCodec{}) })
+
+	_, err := LookupCodec("xml")
+	assert.NotNil(t, err)
+}
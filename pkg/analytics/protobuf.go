@@ -0,0 +1,173 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the Record message in record.proto.
+const (
+	fieldVersion protowire.Number = iota + 1
+	fieldID
+	fieldRequestID
+	fieldTimeStamp
+	fieldUsername
+	fieldEffect
+	fieldConclusion
+	fieldRequest
+	fieldPolicies
+	fieldDeciders
+	fieldClientIP
+	fieldLatency
+	fieldStatus
+	fieldExpireAt
+)
+
+// protobufCodec encodes the records as the Record message in record.proto, without the
+// generated code.
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(r *Record) ([]byte, error) {
+	var b []byte
+
+	appendVarint := func(num protowire.Number, v int64) {
+		if v != 0 {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(v))
+		}
+	}
+	appendString := func(num protowire.Number, v string) {
+		if v != "" {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendString(b, v)
+		}
+	}
+
+	appendVarint(fieldVersion, int64(r.Version))
+	appendString(fieldID, r.ID)
+	appendString(fieldRequestID, r.RequestID)
+	appendVarint(fieldTimeStamp, r.TimeStamp)
+	appendString(fieldUsername, r.Username)
+	appendString(fieldEffect, r.Effect)
+	appendString(fieldConclusion, r.Conclusion)
+	appendString(fieldRequest, r.Request)
+	appendString(fieldPolicies, r.Policies)
+	appendString(fieldDeciders, r.Deciders)
+	appendString(fieldClientIP, r.ClientIP)
+	appendVarint(fieldLatency, r.Latency)
+	appendVarint(fieldStatus, int64(r.Status))
+	if !r.ExpireAt.IsZero() {
+		appendVarint(fieldExpireAt, r.ExpireAt.UnixNano())
+	}
+
+	return b, nil
+}
+
+func (protobufCodec) Unmarshal(b []byte, r *Record) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("analytics: decode protobuf record: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch {
+		case typ == protowire.VarintType && isVarintField(num):
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return fmt.Errorf("analytics: decode protobuf record: %w", protowire.ParseError(n))
+			}
+			b = b[n:]
+			setVarint(r, num, int64(v))
+		case typ == protowire.BytesType && isStringField(num):
+			v, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return fmt.Errorf("analytics: decode protobuf record: %w", protowire.ParseError(n))
+			}
+			b = b[n:]
+			setString(r, num, v)
+		default:
+			// the fields of the newer versions are skipped
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return fmt.Errorf("analytics: decode protobuf record: %w", protowire.ParseError(n))
+			}
+			b = b[n:]
+		}
+	}
+
+	return nil
+}
+
+func isVarintField(num protowire.Number) bool {
+	switch num {
+	case fieldVersion, fieldTimeStamp, fieldLatency, fieldStatus, fieldExpireAt:
+		return true
+	default:
+		return false
+	}
+}
+
+func isStringField(num protowire.Number) bool {
+	switch num {
+	case fieldID, fieldRequestID, fieldUsername, fieldEffect, fieldConclusion,
+		fieldRequest, fieldPolicies, fieldDeciders, fieldClientIP:
+		return true
+	default:
+		return false
+	}
+}
+
+func setVarint(r *Record, num protowire.Number, v int64) {
+	switch num {
+	case fieldVersion:
+		r.Version = int(v)
+	case fieldTimeStamp:
+		r.TimeStamp = v
+	case fieldLatency:
+		r.Latency = v
+	case fieldStatus:
+		r.Status = int(v)
+	case fieldExpireAt:
+		r.ExpireAt = time.Unix(0, v)
+	}
+}
+
+func setString(r *Record, num protowire.Number, v string) {
+	switch num {
+	case fieldID:
+		r.ID = v
+	case fieldRequestID:
+		r.RequestID = v
+	case fieldUsername:
+		r.Username = v
+	case fieldEffect:
+		r.Effect = v
+	case fieldConclusion:
+		r.Conclusion = v
+	case fieldRequest:
+		r.Request = v
+	case fieldPolicies:
+		r.Policies = v
+	case fieldDeciders:
+		r.Deciders = v
+	case fieldClientIP:
+		r.ClientIP = v
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analytics defines the schema of the analytics records, which are written to redis
+// by leona-apiserver and read by leona-gstash.
+package analytics
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// KeyPrefix is the prefix of the redis keys of the analytics records.
+	KeyPrefix = "analytics-"
+	// KeyName is the name of the redis list the analytics records are appended to. The braces are
+	// the hash tag of Redis Cluster, the keys derived from it by leona-gstash are in the same slot,
+	// so that the records are moved between them atomically.
+	KeyName = "{leona-system-analytics}"
+)
+
+// SchemaVersion is the version of the Record written by this version of leona.
+//
+// Version 1 is the records encoded by msgpack without a codec header, which do not have the
+// ID, RequestID, ClientIP, Latency and Status fields. Version 2 adds them and the codec header.
+const SchemaVersion = 2
+
+// Record encodes the details of an audited request.
+// The new fields must be added to the end of the struct and to all the codecs, and the removed
+// ones must be kept, so that the records of the older versions can still be decoded.
+type Record struct {
+	Version    int       `json:"version"`
+	ID         string    `json:"id"`
+	RequestID  string    `json:"requestID"`
+	TimeStamp  int64     `json:"timestamp"`
+	Username   string    `json:"username"`
+	Effect     string    `json:"effect"`
+	Conclusion string    `json:"conclusion"`
+	Request    string    `json:"request"`
+	Policies   string    `json:"policies"`
+	Deciders   string    `json:"deciders"`
+	ClientIP   string    `json:"clientIP"`
+	Latency    int64     `json:"latency"` // in milliseconds
+	Status     int       `json:"status"`
+	ExpireAt   time.Time `json:"expireAt"  bson:"expireAt"`
+}
+
+// SetExpiry set expiration time to a key.
+func (r *Record) SetExpiry(expiresInSeconds int64) {
+	expiry := time.Duration(expiresInSeconds) * time.Second
+	if expiresInSeconds == 0 {
+		// Expiry is set to 100 years
+		expiry = 24 * 365 * 100 * time.Hour
+	}
+
+	r.ExpireAt = time.Now().Add(expiry)
+}
+
+// GetFieldNames returns all the Record field names.
+func (r *Record) GetFieldNames() []string {
+	val := reflect.ValueOf(r).Elem()
+	fields := []string{}
+
+	for i := 0; i < val.NumField(); i++ {
+		typeField := val.Type().Field(i)
+		fields = append(fields, typeField.Name)
+	}
+
+	return fields
+}
+
+// GetLineValues returns all the line values.
+func (r *Record) GetLineValues() []string {
+	val := reflect.ValueOf(r).Elem()
+	fields := []string{}
+
+	for i := 0; i < val.NumField(); i++ {
+		valueField := val.Field(i)
+		typeField := val.Type().Field(i)
+		var thisVal string
+		switch typeField.Type.String() {
+		case "int":
+			thisVal = strconv.Itoa(int(valueField.Int()))
+		case "int64":
+			thisVal = strconv.Itoa(int(valueField.Int()))
+		case "[]string":
+			tmpVal, _ := valueField.Interface().([]string)
+			thisVal = strings.Join(tmpVal, ";")
+		case "time.Time":
+			tmpVal, _ := valueField.Interface().(time.Time)
+			thisVal = tmpVal.String()
+		case "time.Month":
+			tmpVal, _ := valueField.Interface().(time.Month)
+			thisVal = tmpVal.String()
+		default:
+			thisVal = valueField.String()
+		}
+
+		fields = append(fields, thisVal)
+	}
+
+	return fields
+}
@@ -148,6 +148,13 @@ func WithValidArgs(args cobra.PositionalArgs) Option {
 	}
 }
 
+// WithCommands adds sub commands to the application.
+func WithCommands(cmds ...*Command) Option {
+	return func(a *App) {
+		a.commands = append(a.commands, cmds...)
+	}
+}
+
 // WithDefaultValidArgs set default validation function to valid non-flag arguments.
 func WithDefaultValidArgs() Option {
 	return func(a *App) {
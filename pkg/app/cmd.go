@@ -22,6 +22,9 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dairongpeng/leona/pkg/errors"
 )
 
 // Command is a sub command structure of a cli application.
@@ -99,10 +102,12 @@ func (c *Command) cobraCommand() *cobra.Command {
 		cmd.Run = c.runCommand
 	}
 	if c.options != nil {
-		for _, f := range c.options.Flags().FlagSets {
+		namedFlagSets := c.options.Flags()
+		for _, f := range namedFlagSets.FlagSets {
 			cmd.Flags().AddFlagSet(f)
 		}
 		// c.options.AddFlags(cmd.Flags())
+		addNamedCmdTemplate(cmd, namedFlagSets)
 	}
 	addHelpCommandFlag(c.usage, cmd.Flags())
 
@@ -110,6 +115,13 @@ func (c *Command) cobraCommand() *cobra.Command {
 }
 
 func (c *Command) runCommand(cmd *cobra.Command, args []string) {
+	if c.options != nil {
+		if err := c.applyOptions(cmd); err != nil {
+			fmt.Printf("%v %v\n", color.RedString("Error:"), err)
+			os.Exit(1)
+		}
+	}
+
 	if c.runFunc != nil {
 		if err := c.runFunc(args); err != nil {
 			fmt.Printf("%v %v\n", color.RedString("Error:"), err)
@@ -118,6 +130,24 @@ func (c *Command) runCommand(cmd *cobra.Command, args []string) {
 	}
 }
 
+// applyOptions reads the command options from the flags and the configuration file,
+// then validates them.
+func (c *Command) applyOptions(cmd *cobra.Command) error {
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	if err := viper.Unmarshal(c.options); err != nil {
+		return err
+	}
+
+	if errs := c.options.Validate(); len(errs) != 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
 // AddCommand adds sub command to the application.
 func (a *App) AddCommand(cmd *Command) {
 	a.commands = append(a.commands, cmd)
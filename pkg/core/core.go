@@ -15,11 +15,17 @@
 package core
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/dairongpeng/leona/pkg/errors"
 	"github.com/dairongpeng/leona/pkg/log"
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/runtime"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // ErrResponse defines the return messages when an error occurred.
@@ -35,23 +41,172 @@ type ErrResponse struct {
 
 	// Reference returns the reference document which maybe useful to solve this error.
 	Reference string `json:"reference,omitempty"`
+
+	// Details describes the invalid fields of the request, it is set when the request fails
+	// to pass the validation.
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ObjectTyper populates the type information of an object.
+type ObjectTyper interface {
+	SetTypeMeta(obj interface{})
+}
+
+var typer ObjectTyper
+
+// SetObjectTyper sets the typer used to populate the TypeMeta of the objects written by WriteResponse.
+func SetObjectTyper(t ObjectTyper) {
+	typer = t
 }
 
 // WriteResponse write an error or the response data into http response body.
 // It use errors.ParseCoder to parse any error into errors.Coder
 // errors.Coder contains error code, user-safe error message and http status code.
+// The field errors in the error chain are written as the details of the error.
+// The body is encoded with the serializer negotiated from the `Accept` header, JSON is used if
+// none of the accepted media types is supported.
+// Versioned data is written with its ETag, GET requests whose If-None-Match header matches
+// the ETag are answered with `304 Not Modified`.
 func WriteResponse(c *gin.Context, err error, data interface{}) {
 	if err != nil {
 		log.Errorf("%#+v", err)
 		coder := errors.ParseCoder(err)
-		c.JSON(coder.HTTPStatus(), ErrResponse{
+		writeObject(c, coder.HTTPStatus(), ErrResponse{
 			Code:      coder.Code(),
 			Message:   coder.String(),
 			Reference: coder.Reference(),
+			Details:   ErrorDetails(err),
 		})
 
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
+	if typer != nil {
+		typer.SetTypeMeta(data)
+	}
+
+	if writeValidators(c, data) {
+		c.AbortWithStatus(http.StatusNotModified)
+
+		return
+	}
+
+	writeObject(c, http.StatusOK, data)
+}
+
+// Acceptable reports whether a response can be encoded in one of the media types in the Accept header.
+func Acceptable(accept string) bool {
+	_, ok := runtime.NegotiateMediaType(accept, func(mediaType runtime.MediaType) bool {
+		return mediaType.Matches(runtime.ContentTypeText) || serializable(mediaType)
+	})
+
+	return ok
+}
+
+// serializable reports whether a serializer is registered for the media range.
+func serializable(mediaType runtime.MediaType) bool {
+	_, ok := runtime.DefaultRegistry.SerializerForMediaType(mediaType)
+
+	return ok
+}
+
+// isTable reports whether the media range asks for a Table representation.
+func isTable(mediaType runtime.MediaType) bool {
+	return mediaType.Type == runtime.ContentTypeText ||
+		mediaType.Type == runtime.ContentTypeJSON && mediaType.Params["as"] == runtime.AsTable
+}
+
+// MaxBodyBytes is the largest request body read by ShouldBindBody.
+const MaxBodyBytes = 10 << 20
+
+// ShouldBindBody decodes the request body with the serializer for its `Content-Type`
+// header and validates the result with the gin validator. Bodies larger than MaxBodyBytes
+// are rejected.
+func ShouldBindBody(c *gin.Context, obj interface{}) error {
+	info, ok := runtime.DefaultRegistry.SerializerForContentType(c.ContentType())
+	if !ok {
+		return runtime.NegotiateError{ContentType: c.ContentType()}
+	}
+
+	if c.Request == nil || c.Request.Body == nil {
+		return fmt.Errorf("invalid request")
+	}
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodyBytes))
+	if err != nil {
+		return err
+	}
+
+	if err := info.Serializer.Decode(data, obj); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// writeObject encodes the object with the most preferred media type.
+// Resources implementing metav1.TableConvertor are rendered as a table when
+// `application/json;as=Table` or `text/plain` is preferred.
+func writeObject(c *gin.Context, status int, obj interface{}) {
+	convertor, _ := obj.(metav1.TableConvertor)
+
+	mediaType, ok := runtime.NegotiateMediaType(c.GetHeader("Accept"), func(mediaType runtime.MediaType) bool {
+		return convertor != nil && isTable(mediaType) || serializable(mediaType)
+	})
+	if !ok {
+		// the requests which accept none of the registered media types are rejected by
+		// the negotiation middleware, fall back to JSON for the others.
+		c.JSON(status, obj)
+
+		return
+	}
+
+	switch {
+	case convertor != nil && mediaType.Type == runtime.ContentTypeText:
+		writeTextTable(c, status, convertor)
+	case convertor != nil && isTable(mediaType):
+		info, _ := runtime.DefaultRegistry.SerializerForMediaType(mediaType)
+		encode(c, status, info, convertor.ConvertToTable(tableOptions(c)))
+	default:
+		info, _ := runtime.DefaultRegistry.SerializerForMediaType(mediaType)
+		encode(c, status, info, obj)
+	}
+}
+
+func encode(c *gin.Context, status int, info runtime.SerializerInfo, obj interface{}) {
+	data, err := info.Serializer.Encode(obj)
+	if err != nil {
+		log.Errorf("encode response to %s failed: %s", info.MediaType, err.Error())
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	contentType := info.MediaType
+	if info.MediaType != runtime.ContentTypeProtobuf {
+		contentType += "; charset=utf-8"
+	}
+
+	c.Data(status, contentType, data)
+}
+
+func writeTextTable(c *gin.Context, status int, convertor metav1.TableConvertor) {
+	opts := tableOptions(c)
+
+	var buf bytes.Buffer
+	if err := runtime.PrintTable(&buf, convertor.ConvertToTable(opts), opts.NoHeaders); err != nil {
+		log.Errorf("print table failed: %s", err.Error())
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	c.Data(status, runtime.ContentTypeText+"; charset=utf-8", buf.Bytes())
+}
+
+func tableOptions(c *gin.Context) metav1.TableOptions {
+	var opts metav1.TableOptions
+	_ = c.ShouldBindQuery(&opts)
+
+	return opts
 }
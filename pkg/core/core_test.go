@@ -0,0 +1,189 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+type fakeList struct {
+	Items []string `json:"items"`
+}
+
+func (l *fakeList) ConvertToTable(opts metav1.TableOptions) *metav1.Table {
+	table := &metav1.Table{}
+	if !opts.NoHeaders {
+		table.ColumnDefinitions = []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}}
+	}
+	for _, item := range l.Items {
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: []interface{}{item}})
+	}
+
+	return table
+}
+
+func TestWriteResponse_Table(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		url         string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "default",
+			url:         "/",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"items":["a","b"]}`,
+		},
+		{
+			name:        "json table",
+			url:         "/",
+			accept:      "application/json;as=Table",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"columnDefinitions":[{"name":"Name","type":"string","priority":0}],"rows":[{"cells":["a"]},{"cells":["b"]}]}`,
+		},
+		{
+			name:        "json table without headers",
+			url:         "/?noHeaders=true",
+			accept:      "application/json;as=Table",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"rows":[{"cells":["a"]},{"cells":["b"]}]}`,
+		},
+		{
+			name:        "text",
+			url:         "/",
+			accept:      "text/plain",
+			contentType: "text/plain; charset=utf-8",
+			body:        "NAME\na\nb\n",
+		},
+		{
+			name:        "prefer json",
+			url:         "/",
+			accept:      "text/plain;q=0.5, application/json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"items":["a","b"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", tt.url, nil)
+			c.Request.Header.Set("Accept", tt.accept)
+
+			WriteResponse(c, nil, &fakeList{Items: []string{"a", "b"}})
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestWriteResponse_Serializers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{name: "yaml", accept: "application/yaml", contentType: "application/yaml; charset=utf-8", body: "items:\n- a\n"},
+		{name: "protobuf", accept: "application/x-protobuf", contentType: "application/json; charset=utf-8"},
+		{name: "fallback", accept: "text/html", contentType: "application/json; charset=utf-8", body: `{"items":["a"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/", nil)
+			c.Request.Header.Set("Accept", tt.accept)
+
+			WriteResponse(c, nil, struct {
+				Items []string `json:"items"`
+			}{Items: []string{"a"}})
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAcceptable(t *testing.T) {
+	assert.True(t, Acceptable(""))
+	assert.True(t, Acceptable("text/plain"))
+	assert.True(t, Acceptable("text/html, */*;q=0.8"))
+	assert.True(t, Acceptable("application/yaml"))
+	assert.False(t, Acceptable("text/html"))
+	assert.False(t, Acceptable("application/xml;q=1, application/json;q=0"))
+}
+
+func TestShouldBindBody(t *testing.T) {
+	type request struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		wantErr     bool
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"colin"}`, want: "colin"},
+		{name: "default json", body: `{"name":"colin"}`, want: "colin"},
+		{name: "yaml", contentType: "application/yaml", body: "name: colin\n", want: "colin"},
+		{name: "validation", contentType: "application/json", body: `{}`, wantErr: true},
+		{name: "unsupported", contentType: "application/xml", body: `<name>colin</name>`, wantErr: true},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `{"name":"` + strings.Repeat("a", MaxBodyBytes) + `"}`,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest("POST", "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+
+			var r request
+			err := ShouldBindBody(c, &r)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, r.Name)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/validation"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// ErrorDetail describes an invalid field of the request.
+type ErrorDetail struct {
+	// Field is the path of the field, eg: `metadata.name`.
+	Field string `json:"field"`
+
+	// Type is the type of the error, eg: `FieldValueRequired`.
+	Type string `json:"type"`
+
+	// Value is the invalid value, it is omitted for missing fields.
+	Value interface{} `json:"value,omitempty"`
+
+	// Message describes why the value is invalid.
+	Message string `json:"message"`
+}
+
+// ErrorDetails collects the field errors in the chain of err. The field errors are the
+// field.Error and the aggregates of them returned by the Validate methods, the validation
+// errors of the gin validator, and the type errors of the json decoder.
+func ErrorDetails(err error) []ErrorDetail {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if list := fieldErrors(e); len(list) > 0 {
+			details := make([]ErrorDetail, 0, len(list))
+			for _, fe := range list {
+				details = append(details, newErrorDetail(fe))
+			}
+
+			return details
+		}
+	}
+
+	return nil
+}
+
+func fieldErrors(err error) field.ErrorList {
+	switch e := err.(type) {
+	case *field.Error:
+		return field.ErrorList{e}
+	case validator.ValidationErrors:
+		return validation.FieldErrors(e, nil)
+	case *json.UnmarshalTypeError:
+		return field.ErrorList{field.Invalid(field.NewPath(e.Field), e.Value, "must be of type "+e.Type.String())}
+	case errors.Aggregate:
+		var list field.ErrorList
+		for _, agg := range e.Errors() {
+			list = append(list, fieldErrors(agg)...)
+		}
+
+		return list
+	default:
+		return nil
+	}
+}
+
+func newErrorDetail(fe *field.Error) ErrorDetail {
+	detail := ErrorDetail{
+		Field:   fe.Field,
+		Type:    string(fe.Type),
+		Message: fe.Detail,
+	}
+
+	if detail.Message == "" {
+		detail.Message = fe.Type.String()
+	}
+
+	//nolint:exhaustive
+	switch fe.Type {
+	case field.ErrorTypeRequired, field.ErrorTypeForbidden, field.ErrorTypeInternal:
+	default:
+		detail.Value = fe.BadValue
+	}
+
+	return detail
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+	// report the invalid fields in their json names.
+	_ "github.com/dairongpeng/leona/pkg/validator"
+)
+
+func TestErrorDetails(t *testing.T) {
+	errs := field.ErrorList{
+		field.Required(field.NewPath("metadata", "name"), ""),
+		field.Invalid(field.NewPath("email"), "foo", "must be a valid email address"),
+		field.TooLong(field.NewPath("description"), "xxx", 2),
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want []ErrorDetail
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "no field errors", err: errors.New("boom"), want: nil},
+		{
+			name: "aggregate",
+			err:  errors.WrapC(errs.ToAggregate(), 1, "validation failed"),
+			want: []ErrorDetail{
+				{Field: "metadata.name", Type: "FieldValueRequired", Message: "Required value"},
+				{Field: "email", Type: "FieldValueInvalid", Value: "foo", Message: "must be a valid email address"},
+				{Field: "description", Type: "FieldValueTooLong", Value: "xxx", Message: "must have at most 2 bytes"},
+			},
+		},
+		{
+			name: "single",
+			err:  errors.Wrap(errs[1], "wrapped"),
+			want: []ErrorDetail{
+				{Field: "email", Type: "FieldValueInvalid", Value: "foo", Message: "must be a valid email address"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorDetails(tt.err))
+		})
+	}
+}
+
+func TestWriteResponse_BindingDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		Name     string `json:"name" binding:"required"`
+		Age      int    `json:"age" binding:"omitempty,max=150"`
+		Password string `json:"password" binding:"omitempty,password"`
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []ErrorDetail
+	}{
+		{
+			name: "validation",
+			body: `{"age":200,"password":"secret"}`,
+			want: []ErrorDetail{
+				{Field: "name", Type: "FieldValueRequired", Message: "Required value"},
+				{Field: "age", Type: "FieldValueInvalid", Value: float64(200), Message: "failed on the 'max' validation"},
+				{Field: "password", Type: "FieldValueInvalid", Message: "failed on the 'password' validation"},
+			},
+		},
+		{
+			name: "type",
+			body: `{"name":"colin","age":"old"}`,
+			want: []ErrorDetail{
+				{Field: "age", Type: "FieldValueInvalid", Value: "string", Message: "must be of type int"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			var r request
+			err := ShouldBindBody(c, &r)
+			if !assert.Error(t, err) {
+				return
+			}
+			WriteResponse(c, errors.WrapC(err, 1, err.Error()), nil)
+
+			var resp ErrResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, resp.Details)
+		})
+	}
+}
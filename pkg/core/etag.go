@@ -0,0 +1,134 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// ETag returns the strong entity tag of the response data. The entity tag of an object is
+// its resource version, the one of a list is derived from the total count and the resource
+// versions of the items. Empty string is returned for the data which is not versioned.
+func ETag(data interface{}) string {
+	if value := reflect.ValueOf(data); !value.IsValid() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return ""
+	}
+
+	if accessor, ok := data.(metav1.ObjectMetaAccessor); ok {
+		version := accessor.GetObjectMeta().GetResourceVersion()
+		if version == "" {
+			return ""
+		}
+
+		return strconv.Quote(version)
+	}
+
+	return listETag(data)
+}
+
+// ETagMatch reports whether etag matches one of the entity tags listed in the value of an
+// If-Match or If-None-Match header. The weak comparison ignores the `W/` prefix of the
+// listed entity tags, while the strong comparison never matches them.
+func ETagMatch(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+
+			candidate = candidate[2:]
+		}
+
+		if candidate != "" && candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listETag hashes the resource versions of the items of the list, lists are the objects which
+// have list metadata and an `Items` slice of versioned objects.
+func listETag(data interface{}) string {
+	list, ok := data.(interface{ GetListMeta() metav1.ListInterface })
+	if !ok {
+		return ""
+	}
+
+	items := reflect.Indirect(reflect.ValueOf(data)).FieldByName("Items")
+	if !items.IsValid() || items.Kind() != reflect.Slice {
+		return ""
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(strconv.FormatInt(list.GetListMeta().GetTotalCount(), 10)))
+
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i)
+		if item.Kind() == reflect.Ptr && item.IsNil() {
+			return ""
+		}
+
+		accessor, ok := item.Interface().(metav1.ObjectMetaAccessor)
+		if !ok {
+			return ""
+		}
+
+		version := accessor.GetObjectMeta().GetResourceVersion()
+		if version == "" {
+			return ""
+		}
+
+		hash.Write([]byte("/" + version))
+	}
+
+	return strconv.Quote(hex.EncodeToString(hash.Sum(nil))[:32])
+}
+
+// writeValidators sets the ETag and Last-Modified headers of the versioned response data,
+// and reports whether the request is answered with `304 Not Modified`, which is the case
+// when one of the entity tags listed by the If-None-Match header of a GET request matches.
+func writeValidators(c *gin.Context, data interface{}) bool {
+	etag := ETag(data)
+	if etag == "" {
+		return false
+	}
+
+	c.Header("ETag", etag)
+
+	if accessor, ok := data.(metav1.ObjectMetaAccessor); ok {
+		c.Header("Last-Modified", accessor.GetObjectMeta().GetUpdatedAt().UTC().Format(http.TimeFormat))
+	}
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	return ETagMatch(c.GetHeader("If-None-Match"), etag, true)
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+type fakeObject struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+type fakeObjectList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*fakeObject `json:"items"`
+}
+
+func newFakeObject(id, version uint64, updatedAt time.Time) *fakeObject {
+	return &fakeObject{ObjectMeta: metav1.ObjectMeta{ID: id, Name: "colin", Version: version, UpdatedAt: updatedAt}}
+}
+
+func TestETag(t *testing.T) {
+	updatedAt := time.Date(2021, 6, 1, 8, 0, 0, 123456789, time.UTC)
+	obj := newFakeObject(1, 3, updatedAt)
+
+	assert.Equal(t, `"1-3"`, ETag(obj))
+	assert.Equal(t, ETag(obj), ETag(newFakeObject(1, 3, updatedAt.Add(time.Second))))
+	assert.NotEqual(t, ETag(obj), ETag(newFakeObject(1, 4, updatedAt)))
+	assert.NotEqual(t, ETag(obj), ETag(newFakeObject(2, 3, updatedAt)))
+	assert.Empty(t, ETag(newFakeObject(1, 0, updatedAt)))
+	assert.Empty(t, ETag((*fakeObject)(nil)))
+	assert.Empty(t, ETag(nil))
+	assert.Empty(t, ETag(struct{ Items []string }{}))
+
+	list := &fakeObjectList{ListMeta: metav1.ListMeta{TotalCount: 2}, Items: []*fakeObject{obj, newFakeObject(2, 1, updatedAt)}}
+	etag := ETag(list)
+	assert.Len(t, etag, 34)
+
+	list.Items[1] = newFakeObject(2, 2, updatedAt)
+	assert.NotEqual(t, etag, ETag(list))
+
+	list.Items[1] = newFakeObject(2, 0, updatedAt)
+	assert.Empty(t, ETag(list))
+}
+
+func TestETagMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{name: "empty", header: "", want: false},
+		{name: "any", header: "*", want: true},
+		{name: "strong", header: `"1-2"`, want: true},
+		{name: "list", header: `"1-1", "1-2"`, want: true},
+		{name: "mismatch", header: `"1-1"`, want: false},
+		{name: "weak with strong comparison", header: `W/"1-2"`, want: false},
+		{name: "weak with weak comparison", header: `W/"1-2"`, weak: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ETagMatch(tt.header, `"1-2"`, tt.weak))
+		})
+	}
+}
+
+func TestWriteResponse_Conditional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	obj := newFakeObject(1, 3, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		status      int
+	}{
+		{name: "no condition", method: "GET", status: http.StatusOK},
+		{name: "not modified", method: "GET", ifNoneMatch: `W/"1-3"`, status: http.StatusNotModified},
+		{name: "modified", method: "GET", ifNoneMatch: `"1-1"`, status: http.StatusOK},
+		{name: "update", method: "PUT", ifNoneMatch: `"1-3"`, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(tt.method, "/", nil)
+			c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+
+			WriteResponse(c, nil, obj)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, `"1-3"`, w.Header().Get("ETag"))
+			assert.Equal(t, "Tue, 01 Jun 2021 08:00:00 GMT", w.Header().Get("Last-Modified"))
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
@@ -15,20 +15,30 @@
 package db
 
 import (
+	"errors"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 
 	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/tracing"
 )
 
 const (
 	callBackBeforeName = "core:before"
 	callBackAfterName  = "core:after"
 	startTime          = "_start_time"
+	spanKey            = "_span"
+
+	rowsAffectedKey = attribute.Key("db.rows_affected")
 )
 
-// TracePlugin defines gorm plugin used to trace sql.
+// TracePlugin defines gorm plugin used to trace sql. Every operation is recorded as a
+// child span of the span in the statement context, set by db.WithContext(ctx).
 type TracePlugin struct{}
 
 // Name returns the name of trace plugin.
@@ -39,12 +49,12 @@ func (op *TracePlugin) Name() string {
 // Initialize initialize the trace plugin.
 func (op *TracePlugin) Initialize(db *gorm.DB) (err error) {
 	// 开始前
-	_ = db.Callback().Create().Before("gorm:before_create").Register(callBackBeforeName, before)
-	_ = db.Callback().Query().Before("gorm:query").Register(callBackBeforeName, before)
-	_ = db.Callback().Delete().Before("gorm:before_delete").Register(callBackBeforeName, before)
-	_ = db.Callback().Update().Before("gorm:setup_reflect_value").Register(callBackBeforeName, before)
-	_ = db.Callback().Row().Before("gorm:row").Register(callBackBeforeName, before)
-	_ = db.Callback().Raw().Before("gorm:raw").Register(callBackBeforeName, before)
+	_ = db.Callback().Create().Before("gorm:before_create").Register(callBackBeforeName, before("create"))
+	_ = db.Callback().Query().Before("gorm:query").Register(callBackBeforeName, before("query"))
+	_ = db.Callback().Delete().Before("gorm:before_delete").Register(callBackBeforeName, before("delete"))
+	_ = db.Callback().Update().Before("gorm:setup_reflect_value").Register(callBackBeforeName, before("update"))
+	_ = db.Callback().Row().Before("gorm:row").Register(callBackBeforeName, before("row"))
+	_ = db.Callback().Raw().Before("gorm:raw").Register(callBackBeforeName, before("raw"))
 
 	// 结束后
 	_ = db.Callback().Create().After("gorm:after_create").Register(callBackAfterName, after)
@@ -59,11 +69,26 @@ func (op *TracePlugin) Initialize(db *gorm.DB) (err error) {
 
 var _ gorm.Plugin = &TracePlugin{}
 
-func before(db *gorm.DB) {
-	db.InstanceSet(startTime, time.Now())
+func before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		db.InstanceSet(startTime, time.Now())
+
+		ctx := tracing.FromContext(db.Statement.Context)
+		_, span := tracing.Tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemMySQL, semconv.DBOperationKey.String(operation)),
+		)
+		db.InstanceSet(spanKey, span)
+	}
 }
 
 func after(db *gorm.DB) {
+	if v, ok := db.InstanceGet(spanKey); ok {
+		if span, ok := v.(trace.Span); ok {
+			endSpan(db, span)
+		}
+	}
+
 	_ts, isExist := db.InstanceGet(startTime)
 	if !isExist {
 		return
@@ -74,5 +99,23 @@ func after(db *gorm.DB) {
 		return
 	}
 	// sql := db.Dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...)
-	log.Infof("sql cost time: %fs", time.Since(ts).Seconds())
+	log.L(db.Statement.Context).Debugf("sql cost time: %fs", time.Since(ts).Seconds())
+}
+
+func endSpan(db *gorm.DB, span trace.Span) {
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTableKey.String(db.Statement.Table))
+	}
+	// The statement is recorded with placeholders, the values may be sensitive.
+	if sql := db.Statement.SQL.String(); sql != "" {
+		span.SetAttributes(semconv.DBStatementKey.String(sql))
+	}
+	span.SetAttributes(rowsAffectedKey.Int64(db.Statement.RowsAffected))
+
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
 }
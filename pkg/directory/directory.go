@@ -0,0 +1,340 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package directory authenticates and lists the users of an LDAP directory.
+// The users are authenticated by binding as themselves, and the members of the admin groups are
+// the administrators.
+package directory
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+)
+
+// pageSize is the page size of listing the users.
+const pageSize = 500
+
+// Defined errors.
+var (
+	ErrUserNotFound       = errors.New("the user does not exist in the directory")
+	ErrInvalidCredentials = errors.New("the username or the password is incorrect")
+)
+
+// Config defines the directory and how the users are looked up.
+type Config struct {
+	// URL is the url of the LDAP server, e.g. ldap://ldap.example.com:389 or ldaps://ldap.example.com.
+	URL string
+
+	// InsecureSkipVerify skips verifying the certificate of the ldaps server.
+	InsecureSkipVerify bool
+
+	// BindDN and BindPassword are the credentials of the service account searching the users,
+	// the search is anonymous if BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	// BaseDN is where the users are searched.
+	BaseDN string
+
+	// UserFilter selects the user entries, e.g. (objectClass=person).
+	UserFilter string
+
+	// The attributes of the user entries.
+	UsernameAttribute string
+	EmailAttribute    string
+	NicknameAttribute string
+	GroupAttribute    string
+
+	// AdminGroups are the DNs of the groups whose members are the administrators.
+	AdminGroups []string
+
+	// Timeout is the timeout of connecting to the server and of each request.
+	Timeout time.Duration
+
+	// CacheTTL is how long a successful authentication is cached, the same credentials are not
+	// bound again until it expires. The cached authentication of an user is dropped once Users no
+	// longer lists the user, but the old password of an user still authenticates until it expires.
+	// 0 disables the cache.
+	CacheTTL time.Duration
+}
+
+// Entry is an user of the directory.
+type Entry struct {
+	DN       string
+	Username string
+	Email    string
+	Nickname string
+	Groups   []string
+	IsAdmin  bool
+}
+
+// Conn is the connection to the LDAP server, it is implemented by ldap.Conn.
+type Conn interface {
+	Bind(username, password string) error
+	Search(request *ldap.SearchRequest) (*ldap.SearchResult, error)
+	SearchWithPaging(request *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error)
+	Close()
+}
+
+// Directory authenticates and lists the users of an LDAP directory.
+type Directory struct {
+	config Config
+	dial   func() (Conn, error)
+
+	// the cached authentications keep the keyed digests of the passwords instead of the passwords
+	cacheKey []byte
+	mu       sync.Mutex
+	cache    map[string]cachedAuthentication
+}
+
+// cachedAuthentication is a successful authentication of an user.
+type cachedAuthentication struct {
+	digest    []byte
+	entry     Entry
+	expiresAt time.Time
+}
+
+// New returns a new LDAP directory.
+func New(config Config) *Directory {
+	d := &Directory{config: config, cache: make(map[string]cachedAuthentication)}
+	d.dial = d.dialURL
+
+	d.cacheKey = make([]byte, sha256.Size)
+	if _, err := rand.Read(d.cacheKey); err != nil {
+		// the cache is disabled rather than keyed by a predictable key
+		d.config.CacheTTL = 0
+	}
+
+	return d
+}
+
+// Authenticate binds as the user with the password, and returns the entry of the user.
+// The successful authentications are cached for CacheTTL.
+func (d *Directory) Authenticate(username, password string) (*Entry, error) {
+	// an empty password is an unauthenticated bind, which always succeeds
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if entry := d.cached(username, password); entry != nil {
+		return entry, nil
+	}
+
+	entry, err := d.authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+	d.cacheAuthentication(username, password, entry)
+
+	return entry, nil
+}
+
+// authenticate binds as the user with the password.
+func (d *Directory) authenticate(username, password string) (*Entry, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))", d.config.UserFilter, d.config.UsernameAttribute, ldap.EscapeFilter(username))
+	result, err := conn.Search(d.searchRequest(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d entries of user %q are found in the directory", len(result.Entries), username)
+	}
+
+	entry := d.entry(result.Entries[0])
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Users lists all the users of the directory.
+func (d *Directory) Users() ([]*Entry, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.SearchWithPaging(d.searchRequest(d.config.UserFilter), pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		if entry := d.entry(e); entry.Username != "" {
+			entries = append(entries, entry)
+		}
+	}
+	d.forgetRemoved(entries)
+
+	return entries, nil
+}
+
+// cached returns the entry of the cached authentication of the credentials, it is nil if they were
+// not authenticated in CacheTTL.
+func (d *Directory) cached(username, password string) *Entry {
+	if d.config.CacheTTL <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cached, ok := d.cache[username]
+	if !ok || time.Now().After(cached.expiresAt) || !hmac.Equal(cached.digest, d.digest(password)) {
+		return nil
+	}
+	entry := cached.entry
+
+	return &entry
+}
+
+// cacheAuthentication caches the successful authentication, and drops the expired ones.
+func (d *Directory) cacheAuthentication(username, password string, entry *Entry) {
+	if d.config.CacheTTL <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for name, cached := range d.cache {
+		if now.After(cached.expiresAt) {
+			delete(d.cache, name)
+		}
+	}
+	d.cache[username] = cachedAuthentication{
+		digest:    d.digest(password),
+		entry:     *entry,
+		expiresAt: now.Add(d.config.CacheTTL),
+	}
+}
+
+// forgetRemoved drops the cached authentications of the users removed from the directory.
+func (d *Directory) forgetRemoved(entries []*Entry) {
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		present[entry.Username] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name := range d.cache {
+		if !present[name] {
+			delete(d.cache, name)
+		}
+	}
+}
+
+func (d *Directory) digest(password string) []byte {
+	mac := hmac.New(sha256.New, d.cacheKey)
+	mac.Write([]byte(password))
+
+	return mac.Sum(nil)
+}
+
+// connect connects to the server, and binds as the service account.
+func (d *Directory) connect() (Conn, error) {
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.config.BindDN != "" {
+		if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("bind as %s: %w", d.config.BindDN, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (d *Directory) dialURL() (Conn, error) {
+	conn, err := ldap.DialURL(d.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.config.Timeout}),
+		//nolint: gosec
+		ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: d.config.InsecureSkipVerify}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(d.config.Timeout)
+
+	return conn, nil
+}
+
+func (d *Directory) searchRequest(filter string) *ldap.SearchRequest {
+	attributes := []string{d.config.UsernameAttribute, d.config.EmailAttribute, d.config.NicknameAttribute}
+	if d.config.GroupAttribute != "" {
+		attributes = append(attributes, d.config.GroupAttribute)
+	}
+
+	return ldap.NewSearchRequest(d.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(d.config.Timeout.Seconds()), false, filter, attributes, nil)
+}
+
+func (d *Directory) entry(e *ldap.Entry) *Entry {
+	entry := &Entry{
+		DN:       e.DN,
+		Username: e.GetAttributeValue(d.config.UsernameAttribute),
+		Email:    e.GetAttributeValue(d.config.EmailAttribute),
+		Nickname: e.GetAttributeValue(d.config.NicknameAttribute),
+	}
+	if d.config.GroupAttribute != "" {
+		entry.Groups = e.GetAttributeValues(d.config.GroupAttribute)
+	}
+
+	for _, group := range entry.Groups {
+		for _, admin := range d.config.AdminGroups {
+			// the DNs are case insensitive
+			if strings.EqualFold(group, admin) {
+				entry.IsAdmin = true
+			}
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dairongpeng/leona/pkg/directory"
+	"github.com/dairongpeng/leona/pkg/directory/directorytest"
+)
+
+const (
+	adminsDN  = "cn=admins,ou=groups,dc=example,dc=com"
+	serviceDN = "cn=leona,ou=services,dc=example,dc=com"
+)
+
+func newDirectory(t *testing.T) (*directory.Directory, *directorytest.Server) {
+	t.Helper()
+
+	server := directorytest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.Add(directorytest.Entry{DN: serviceDN, Password: "service"})
+	server.Add(person("alice", "alice-password", adminsDN))
+	server.Add(person("bob", "bob-password", "cn=developers,ou=groups,dc=example,dc=com"))
+	server.Add(directorytest.Entry{
+		DN:         "cn=printer,ou=people,dc=example,dc=com",
+		Attributes: map[string][]string{"objectClass": {"device"}, "cn": {"printer"}},
+	})
+
+	return directory.New(directory.Config{
+		URL:               server.URL,
+		BindDN:            serviceDN,
+		BindPassword:      "service",
+		BaseDN:            "ou=people,dc=example,dc=com",
+		UserFilter:        "(objectClass=person)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		NicknameAttribute: "cn",
+		GroupAttribute:    "memberOf",
+		AdminGroups:       []string{"CN=Admins,OU=Groups,DC=Example,DC=Com"},
+		Timeout:           5 * time.Second,
+	}), server
+}
+
+func person(uid, password string, groups ...string) directorytest.Entry {
+	return directorytest.Entry{
+		DN:       "uid=" + uid + ",ou=people,dc=example,dc=com",
+		Password: password,
+		Attributes: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {uid},
+			"mail":        {uid + "@example.com"},
+			"cn":          {uid + " from ldap"},
+			"memberOf":    groups,
+		},
+	}
+}
+
+func TestDirectory_Authenticate(t *testing.T) {
+	d, _ := newDirectory(t)
+
+	entry, err := d.Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	assert.Equal(t, &directory.Entry{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Username: "alice",
+		Email:    "alice@example.com",
+		Nickname: "alice from ldap",
+		Groups:   []string{adminsDN},
+		IsAdmin:  true,
+	}, entry)
+
+	entry, err = d.Authenticate("bob", "bob-password")
+	require.NoError(t, err)
+	assert.False(t, entry.IsAdmin)
+
+	_, err = d.Authenticate("bob", "alice-password")
+	assert.Equal(t, directory.ErrInvalidCredentials, err)
+
+	_, err = d.Authenticate("bob", "")
+	assert.Equal(t, directory.ErrInvalidCredentials, err)
+
+	_, err = d.Authenticate("carol", "carol-password")
+	assert.Equal(t, directory.ErrUserNotFound, err)
+
+	// the username is escaped in the filter
+	_, err = d.Authenticate("*", "alice-password")
+	assert.Equal(t, directory.ErrUserNotFound, err)
+}
+
+func TestDirectory_AuthenticateCache(t *testing.T) {
+	d, server := newDirectory(t)
+
+	cached := directory.New(directory.Config{
+		URL:               server.URL,
+		BindDN:            serviceDN,
+		BindPassword:      "service",
+		BaseDN:            "ou=people,dc=example,dc=com",
+		UserFilter:        "(objectClass=person)",
+		UsernameAttribute: "uid",
+		Timeout:           5 * time.Second,
+		CacheTTL:          time.Minute,
+	})
+
+	_, err := cached.Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	binds := server.Binds()
+
+	entry, err := cached.Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", entry.Username)
+	assert.Equal(t, binds, server.Binds())
+
+	// the other passwords are still bound
+	_, err = cached.Authenticate("alice", "bob-password")
+	assert.Equal(t, directory.ErrInvalidCredentials, err)
+	assert.Greater(t, server.Binds(), binds)
+
+	// the directory without the cache binds each time
+	binds = server.Binds()
+	_, err = d.Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	_, err = d.Authenticate("alice", "alice-password")
+	require.NoError(t, err)
+	assert.Equal(t, binds+4, server.Binds())
+
+	// the users removed from the directory are no longer authenticated once they are not listed
+	server.Delete("uid=alice,ou=people,dc=example,dc=com")
+	_, err = cached.Users()
+	require.NoError(t, err)
+	_, err = cached.Authenticate("alice", "alice-password")
+	assert.Equal(t, directory.ErrUserNotFound, err)
+}
+
+func TestDirectory_ServiceBind(t *testing.T) {
+	d, server := newDirectory(t)
+
+	server.Add(directorytest.Entry{DN: serviceDN, Password: "rotated"})
+
+	_, err := d.Authenticate("alice", "alice-password")
+	assert.Error(t, err)
+	assert.NotEqual(t, directory.ErrInvalidCredentials, err)
+}
+
+func TestDirectory_Users(t *testing.T) {
+	d, server := newDirectory(t)
+
+	users, err := d.Users()
+	require.NoError(t, err)
+
+	usernames := make([]string, 0, len(users))
+	for _, user := range users {
+		usernames = append(usernames, user.Username)
+	}
+	assert.ElementsMatch(t, []string{"alice", "bob"}, usernames)
+
+	server.Delete("uid=bob,ou=people,dc=example,dc=com")
+
+	users, err = d.Users()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice", users[0].Username)
+}
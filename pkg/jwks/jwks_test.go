@@ -0,0 +1,85 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/json"
+)
+
+func TestKey_RoundTrip(t *testing.T) {
+	for _, alg := range []string{RS256, ES256, EdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			signer, err := GenerateKey(alg)
+			assert.NoError(t, err)
+
+			key, err := NewKey("kid-"+alg, alg, signer.Public())
+			assert.NoError(t, err)
+			assert.Equal(t, "sig", key.Use)
+
+			// the key is published as json
+			data, err := json.Marshal(KeySet{Keys: []Key{key}})
+			assert.NoError(t, err)
+			var set KeySet
+			assert.NoError(t, json.Unmarshal(data, &set))
+
+			got, ok := set.Lookup("kid-" + alg)
+			assert.True(t, ok)
+			assert.Equal(t, alg, got.Algorithm)
+
+			public, err := got.PublicKey()
+			assert.NoError(t, err)
+			assert.Equal(t, signer.Public(), public)
+		})
+	}
+}
+
+func TestKeySet_Lookup(t *testing.T) {
+	set := KeySet{Keys: []Key{{KeyID: "a"}, {KeyID: "b"}}}
+
+	key, ok := set.Lookup("b")
+	assert.True(t, ok)
+	assert.Equal(t, "b", key.KeyID)
+
+	_, ok = set.Lookup("c")
+	assert.False(t, ok)
+}
+
+func TestKey_PublicKey_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		key  Key
+	}{
+		{name: "unknown key type", key: Key{KeyType: "oct"}},
+		{name: "unknown curve", key: Key{KeyType: "EC", Curve: "P-192"}},
+		{name: "point off the curve", key: Key{KeyType: "EC", Curve: "P-256", X: "AQ", Y: "Ag"}},
+		{name: "short ed25519 key", key: Key{KeyType: "OKP", Curve: "Ed25519", X: "AQ"}},
+		{name: "invalid encoding", key: Key{KeyType: "RSA", N: "!", E: "AQAB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.key.PublicKey()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGenerateKey_Unsupported(t *testing.T) {
+	_, err := GenerateKey("HS256")
+	assert.Error(t, err)
+}
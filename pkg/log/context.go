@@ -48,3 +48,15 @@ func FromContext(ctx context.Context) Logger {
 
 	return WithName("Unknown-Context")
 }
+
+// ContextFieldsFunc returns the fields carried by a context, which are added to the logger returned by L.
+type ContextFieldsFunc func(ctx context.Context) []Field
+
+var contextFields []ContextFieldsFunc
+
+// RegisterContextFields registers a function returning the fields logged by L, e.g. the current trace
+// of the request. It lets the packages putting values into the contexts log them without being imported
+// by this package, and must be called during their initialization.
+func RegisterContextFields(fn ContextFieldsFunc) {
+	contextFields = append(contextFields, fn)
+}
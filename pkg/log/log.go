@@ -567,6 +567,11 @@ func (l *zapLogger) L(ctx context.Context) *zapLogger {
 	if watcherName, ok := ctx.Value(KeyWatcherName).(LogContextKey); ok {
 		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyWatcherName.String(), watcherName))
 	}
+	for _, fn := range contextFields {
+		if fields := fn(ctx); len(fields) > 0 {
+			lg.zapLogger = lg.zapLogger.With(fields...)
+		}
+	}
 
 	return lg
 }
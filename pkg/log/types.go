@@ -27,6 +27,8 @@ const (
 	KeyRequestID   LogContextKey = "requestID"
 	KeyUsername    LogContextKey = "username"
 	KeyWatcherName LogContextKey = "watcher"
+	KeyTraceID     LogContextKey = "traceID"
+	KeySpanID      LogContextKey = "spanID"
 )
 
 func (l LogContextKey) String() string {
@@ -0,0 +1,53 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+type fileMailer struct {
+	dir string
+}
+
+// NewFile returns a mailer writing every message into an `.eml` file in the directory.
+func NewFile(dir string) Mailer {
+	return &fileMailer{dir: dir}
+}
+
+// Send writes the message into a new file named after the time and the first recipient.
+func (f *fileMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.dir, 0o750); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s-%s.eml", now.Format("20060102T150405"),
+		strings.NewReplacer("/", "_", "\\", "_").Replace(address(msg.To[0])), uuid.Must(uuid.NewV4()).String()[:8])
+
+	return ioutil.WriteFile(filepath.Join(f.dir, name), msg.Bytes(now), 0o600)
+}
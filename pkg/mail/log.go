@@ -0,0 +1,40 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+type logMailer struct{}
+
+// NewLog returns a mailer only logging the messages.
+func NewLog() Mailer {
+	return logMailer{}
+}
+
+// Send logs the message.
+func (logMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	log.L(ctx).Infof("mail to %s: %s\n%s", strings.Join(msg.To, ", "), msg.Subject, msg.Body)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/mail"
+	"github.com/dairongpeng/leona/pkg/mail/mailtest"
+)
+
+func newMessage() *mail.Message {
+	return &mail.Message{
+		From:    "Leona <noreply@leona.local>",
+		To:      []string{"user1@example.com"},
+		Subject: "Verify your email 验证邮箱",
+		Body:    "Hello user1,\n.leading dot\n",
+	}
+}
+
+func TestSMTP_Send(t *testing.T) {
+	server := mailtest.NewServer()
+	defer server.Close()
+
+	mailer := mail.NewSMTP(mail.SMTPConfig{Host: server.Host, Port: server.Port, Timeout: time.Second})
+	assert.Nil(t, mailer.Send(context.TODO(), newMessage()))
+
+	messages := server.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "noreply@leona.local", messages[0].From)
+		assert.Equal(t, []string{"user1@example.com"}, messages[0].To)
+		assert.Equal(t, "Verify your email 验证邮箱", messages[0].Subject)
+		assert.Equal(t, "Hello user1,\n.leading dot\n", messages[0].Body)
+	}
+}
+
+func TestSMTP_Auth(t *testing.T) {
+	server := mailtest.NewServer()
+	defer server.Close()
+	server.RequireAuth("leona", "secret")
+
+	mailer := mail.NewSMTP(mail.SMTPConfig{Host: server.Host, Port: server.Port, Username: "leona", Password: "wrong"})
+	assert.NotNil(t, mailer.Send(context.TODO(), newMessage()))
+
+	mailer = mail.NewSMTP(mail.SMTPConfig{Host: server.Host, Port: server.Port})
+	assert.NotNil(t, mailer.Send(context.TODO(), newMessage()))
+
+	mailer = mail.NewSMTP(mail.SMTPConfig{Host: server.Host, Port: server.Port, Username: "leona", Password: "secret"})
+	assert.Nil(t, mailer.Send(context.TODO(), newMessage()))
+	assert.Len(t, server.Messages(), 1)
+}
+
+func TestSend_InvalidMessage(t *testing.T) {
+	msg := newMessage()
+	msg.To = []string{"user1@example.com\r\nBcc: user2@example.com"}
+
+	assert.NotNil(t, mail.NewLog().Send(context.TODO(), msg))
+	assert.NotNil(t, mail.NewFile(t.TempDir()).Send(context.TODO(), msg))
+	assert.NotNil(t, mail.NewSMTP(mail.SMTPConfig{Host: "127.0.0.1", Port: 1}).Send(context.TODO(), msg))
+}
+
+func TestFile_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	assert.Nil(t, mail.NewFile(dir).Send(context.TODO(), newMessage()))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-user1@example.com-*.eml"))
+	assert.Nil(t, err)
+	if assert.Len(t, files, 1) {
+		data, err := ioutil.ReadFile(files[0])
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "From: Leona <noreply@leona.local>\r\nTo: user1@example.com\r\n"))
+		assert.True(t, strings.HasSuffix(string(data), "\r\n\r\nHello user1,\r\n.leading dot\r\n"))
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mailtest provides an in-memory SMTP server for testing.
+// It only speaks the EHLO, AUTH PLAIN, MAIL, RCPT, DATA, RSET, NOOP and QUIT commands, and
+// keeps the received messages in memory.
+package mailtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message is a message received by the server.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+
+	// Header is the header of the message.
+	Header mail.Header
+}
+
+// Server is an in-memory SMTP server listening on a system-chosen port on the loopback interface.
+type Server struct {
+	// Host and Port of the server.
+	Host string
+	Port int
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	username string
+	password string
+	messages []Message
+}
+
+// NewServer starts and returns a new server accepting messages without authentication.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("mailtest: failed to listen on a port: " + err.Error())
+	}
+
+	addr := l.Addr().(*net.TCPAddr)
+	s := &Server{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		listener: l,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s
+}
+
+// RequireAuth makes the server only accept messages after authenticating with the credentials.
+func (s *Server) RequireAuth(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.username, s.password = username, password
+}
+
+// Messages returns the messages received.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+// Addr returns the address of the server in the form host:port.
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			defer conn.Close()
+
+			s.handle(conn)
+		}()
+	}
+}
+
+// session is the state of a connection.
+type session struct {
+	authenticated bool
+	from          string
+	to            []string
+}
+
+func (s *Server) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(w, format+"\r\n", args...)
+		_ = w.Flush()
+	}
+
+	s.mu.Lock()
+	username, password := s.username, s.password
+	s.mu.Unlock()
+
+	sess := &session{authenticated: username == ""}
+	reply("220 mailtest ESMTP ready")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], line[i+1:]
+		}
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if username == "" {
+				reply("250 mailtest")
+
+				continue
+			}
+			reply("250-mailtest")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			if sess.authenticated = s.auth(arg, username, password); sess.authenticated {
+				reply("235 2.7.0 Authentication successful")
+			} else {
+				reply("535 5.7.8 Authentication credentials invalid")
+			}
+		case "MAIL":
+			if !sess.authenticated {
+				reply("530 5.7.0 Authentication required")
+
+				continue
+			}
+			sess.from, sess.to = path(arg), nil
+			reply("250 OK")
+		case "RCPT":
+			sess.to = append(sess.to, path(arg))
+			reply("250 OK")
+		case "DATA":
+			if sess.from == "" || len(sess.to) == 0 {
+				reply("503 5.5.1 Bad sequence of commands")
+
+				continue
+			}
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(r)
+			if err != nil {
+				return
+			}
+			if err := s.receive(sess, data); err != nil {
+				reply("554 5.6.0 %s", err.Error())
+			} else {
+				reply("250 OK")
+			}
+			sess.from, sess.to = "", nil
+		case "RSET":
+			sess.from, sess.to = "", nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+
+			return
+		default:
+			reply("502 5.5.2 Command not implemented")
+		}
+	}
+}
+
+// auth checks the AUTH PLAIN initial response, other mechanisms are not supported.
+func (s *Server) auth(arg, username, password string) bool {
+	fields := strings.Fields(arg)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "PLAIN") {
+		return false
+	}
+
+	credentials, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(string(credentials), "\x00")
+
+	return len(parts) == 3 && parts[1] == username && parts[2] == password
+}
+
+func (s *Server) receive(sess *session, data []byte) error {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	body, err := ioutil.ReadAll(msg.Body)
+	if err != nil {
+		return err
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, Message{
+		From:    sess.from,
+		To:      sess.to,
+		Subject: subject,
+		Body:    strings.ReplaceAll(string(body), "\r\n", "\n"),
+		Header:  msg.Header,
+	})
+
+	return nil
+}
+
+// readData reads the message until the line with a single dot, the leading dots are unstuffed.
+func readData(r *bufio.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		if line == ".\r\n" || line == ".\n" {
+			return buf.Bytes(), nil
+		}
+		buf.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+// path returns the address of `FROM:<user@example.com>` or `TO:<user@example.com>`.
+func path(arg string) string {
+	if i := strings.IndexByte(arg, '<'); i >= 0 {
+		if j := strings.IndexByte(arg[i:], '>'); j >= 0 {
+			return arg[i+1 : i+j]
+		}
+	}
+
+	return arg
+}
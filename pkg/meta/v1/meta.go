@@ -15,6 +15,7 @@
 package v1
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/dairongpeng/leona/pkg/scheme"
@@ -33,10 +34,15 @@ type Object interface {
 	SetID(id uint64)
 	GetName() string
 	SetName(name string)
+	GetNamespace() string
+	SetNamespace(namespace string)
 	GetCreatedAt() time.Time
 	SetCreatedAt(createdAt time.Time)
 	GetUpdatedAt() time.Time
 	SetUpdatedAt(updatedAt time.Time)
+	GetVersion() uint64
+	SetVersion(version uint64)
+	GetResourceVersion() string
 }
 
 // ListInterface lets you work with list metadata from any of the versioned or
@@ -89,7 +95,34 @@ func (meta *ObjectMeta) GetID() uint64                    { return meta.ID }
 func (meta *ObjectMeta) SetID(id uint64)                  { meta.ID = id }
 func (meta *ObjectMeta) GetName() string                  { return meta.Name }
 func (meta *ObjectMeta) SetName(name string)              { meta.Name = name }
+func (meta *ObjectMeta) GetNamespace() string             { return meta.Namespace }
+func (meta *ObjectMeta) SetNamespace(namespace string)    { meta.Namespace = namespace }
 func (meta *ObjectMeta) GetCreatedAt() time.Time          { return meta.CreatedAt }
 func (meta *ObjectMeta) SetCreatedAt(createdAt time.Time) { meta.CreatedAt = createdAt }
 func (meta *ObjectMeta) GetUpdatedAt() time.Time          { return meta.UpdatedAt }
 func (meta *ObjectMeta) SetUpdatedAt(updatedAt time.Time) { meta.UpdatedAt = updatedAt }
+func (meta *ObjectMeta) GetVersion() uint64               { return meta.Version }
+func (meta *ObjectMeta) SetVersion(version uint64)        { meta.Version = version }
+
+// GetResourceVersion returns the version of the object, it is derived from the ID and
+// the version of the object, so it changes every time the object is updated, and differs
+// from the one of the object deleted and created again with the same name.
+// Empty for the objects which are not persisted or are exported.
+func (meta *ObjectMeta) GetResourceVersion() string {
+	if meta.Version == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(meta.ID, 10) + "-" + strconv.FormatUint(meta.Version, 10)
+}
+
+// PrepareForExport clears the fields populated by the system, so that the object can be
+// created again in another storage. The namespace is kept, so that the object is imported
+// into the namespace it is exported from.
+func (meta *ObjectMeta) PrepareForExport(opts ExportOptions) {
+	meta.ID = 0
+	meta.InstanceID = ""
+	meta.CreatedAt = time.Time{}
+	meta.UpdatedAt = time.Time{}
+	meta.Version = 0
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Table is a tabular representation of a set of API resources. The server transforms the
+// object into a set of preferred columns for quickly reviewing the objects.
+type Table struct {
+	TypeMeta `json:",inline"`
+	// Standard list metadata.
+	ListMeta `json:",inline"`
+
+	// ColumnDefinitions describes each column in the returned items array. The number of cells per row
+	// will always match the number of column definitions.
+	ColumnDefinitions []TableColumnDefinition `json:"columnDefinitions,omitempty"`
+	// Rows is the list of items in the table.
+	Rows []TableRow `json:"rows"`
+}
+
+// TableColumnDefinition contains information about a column returned in the Table.
+type TableColumnDefinition struct {
+	// Name is a human readable name for the column.
+	Name string `json:"name"`
+	// Type is an OpenAPI type definition for this column, such as number, integer, string, or
+	// boolean.
+	Type string `json:"type"`
+	// Format is an optional OpenAPI type modifier for this column. A format modifies the type and
+	// imposes additional rules, like date or time formatting for a string. The 'name' format is applied
+	// to the primary identifier column which has type 'string' to assist in clients identifying column
+	// is the resource name.
+	Format string `json:"format,omitempty"`
+	// Description is a human readable description of this column.
+	Description string `json:"description,omitempty"`
+	// Priority is an integer defining the relative importance of this column compared to others. Lower
+	// numbers are considered higher priority. Columns that may be omitted in limited space scenarios
+	// should be given a higher priority.
+	Priority int32 `json:"priority"`
+}
+
+// TableRow is an individual row in a table.
+type TableRow struct {
+	// Cells will be as wide as the column definitions array and may contain strings, numbers,
+	// booleans or null. The meaning of each cell is described by the column definition at the same index.
+	Cells []interface{} `json:"cells"`
+}
+
+// TableConvertor is implemented by the resources which can be rendered as a Table.
+type TableConvertor interface {
+	ConvertToTable(opts TableOptions) *Table
+}
@@ -37,6 +37,14 @@ type TypeMeta struct {
 	APIVersion string `json:"apiVersion,omitempty"`
 }
 
+const (
+	// NamespaceDefault means the object is in the default namespace which is applied when not specified by clients.
+	NamespaceDefault = "default"
+
+	// NamespaceAll is the default argument to specify on a context when you want to list or filter resources across all namespaces.
+	NamespaceAll = ""
+)
+
 // ListMeta describes metadata that synthetic resources must have, including lists and
 // various status objects. A resource may have only one of {ObjectMeta, ListMeta}.
 type ListMeta struct {
@@ -72,7 +80,16 @@ type ObjectMeta struct {
 	// definition.
 	// It will be generated automated only if Name is not specified.
 	// Cannot be updated.
-	Name string `json:"name,omitempty" gorm:"column:name;type:varchar(64);not null" validate:"name"`
+	Name string `json:"name,omitempty" gorm:"column:name;type:varchar(64);not null;uniqueIndex:idx_name,priority:2" validate:"name"`
+
+	// Namespace defines the space within which each name must be unique. An empty namespace is
+	// equivalent to the "default" namespace, but "default" is the canonical representation.
+	// Not all objects are required to be scoped to a namespace - the value of this field for
+	// those objects will be empty.
+	//
+	// Must be a DNS_LABEL.
+	// Cannot be updated.
+	Namespace string `json:"namespace,omitempty" gorm:"column:namespace;type:varchar(64);not null;uniqueIndex:idx_name,priority:1" validate:"omitempty,namespace"`
 
 	// Extend store the fields that need to be added, but do not want to add a new table column, will not be stored in db.
 	Extend Extend `json:"extend,omitempty" gorm:"-" validate:"omitempty"`
@@ -97,6 +114,14 @@ type ObjectMeta struct {
 	// Null for lists.
 	UpdatedAt time.Time `json:"updatedAt,omitempty" gorm:"column:updatedAt"`
 
+	// Version is incremented every time the object is updated. The updates and deletes of the object
+	// are conditional on the version they have read, so the concurrent writes never overwrite each other.
+	//
+	// Populated by the system.
+	// Read-only.
+	// Null for lists.
+	Version uint64 `json:"version,omitempty" gorm:"column:version;not null;default:0"`
+
 	// DeletedAt is RFC 3339 date and time at which this resource will be deleted. This
 	// field is set by the server when a graceful deletion is requested by the user, and is not
 	// directly settable by a client.
@@ -133,15 +158,19 @@ type ExportOptions struct {
 
 	// Should this value be exported.  Export strips fields that a user can not specify.
 	// Deprecated. Planned for removal in 1.18.
-	Export bool `json:"export"`
+	Export bool `json:"export" form:"export"`
 	// Should the export be exact.  Exact export maintains cluster-specific fields like 'Namespace'.
 	// Deprecated. Planned for removal in 1.18.
-	Exact bool `json:"exact"`
+	Exact bool `json:"exact" form:"exact"`
 }
 
 // GetOptions is the standard query options to the standard REST get call.
 type GetOptions struct {
 	TypeMeta `json:",inline"`
+
+	// IncludeDisabled, when set, also returns the disabled object, which is not found otherwise.
+	// +optional
+	IncludeDisabled bool `json:"includeDisabled,omitempty"`
 }
 
 // DeleteOptions may be provided when deleting an API object.
@@ -150,6 +179,10 @@ type DeleteOptions struct {
 
 	// +optional
 	Unscoped bool `json:"unscoped"`
+
+	// Version, when set, deletes the object only if its version still equals it.
+	// +optional
+	Version uint64 `json:"version,omitempty"`
 }
 
 // CreateOptions may be provided when creating an API object.
@@ -210,7 +243,7 @@ type TableOptions struct {
 
 	// NoHeaders is only exposed for internal callers. It is not included in our OpenAPI definitions
 	// and may be removed as a field in a future release.
-	NoHeaders bool `json:"-"`
+	NoHeaders bool `json:"-" form:"noHeaders"`
 }
 
 // Extend defines a new type used to store extended fields.
@@ -0,0 +1,246 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidc verifies the ID tokens issued by an OpenID Connect provider. The provider is discovered
+// by its issuer url, and the keys signing the ID tokens are fetched from its JWKS and cached.
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/jwks"
+)
+
+// DiscoveryPath is the path of the discovery document relative to the issuer url.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+const (
+	// DefaultJWKSCacheTTL is how long the fetched keys are cached by default.
+	DefaultJWKSCacheTTL = time.Hour
+
+	// minRefetchInterval limits the fetches caused by the tokens with an unknown kid.
+	minRefetchInterval = 10 * time.Second
+)
+
+// Defined errors.
+var (
+	ErrInvalidIssuer   = errors.New("the issuer of the ID token is invalid")
+	ErrInvalidAudience = errors.New("the ID token is not issued to the client")
+	ErrMissingExpiry   = errors.New("the ID token has no exp claim")
+)
+
+// Discovery is the provider metadata published by the discovery document.
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint         string   `json:"token_endpoint,omitempty"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgorithms     []string `json:"id_token_signing_alg_values_supported,omitempty"`
+}
+
+// Claims are the claims of a verified ID token.
+type Claims map[string]interface{}
+
+// String returns the string claim, it is empty if the claim is absent or not a string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+
+	return s
+}
+
+// Time returns the time of the numeric date claim, it is zero if the claim is absent or not a number.
+func (c Claims) Time(name string) time.Time {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(v), 0)
+}
+
+// Config defines the provider and the client verifying the ID tokens.
+type Config struct {
+	// IssuerURL is the issuer of the provider, the discovery document is served under it.
+	IssuerURL string
+
+	// ClientID is the client the ID tokens must be issued to, it is matched with the aud claim.
+	ClientID string
+
+	// JWKSCacheTTL is how long the fetched keys are used before they are fetched again,
+	// defaults to DefaultJWKSCacheTTL.
+	JWKSCacheTTL time.Duration
+
+	// HTTPClient fetches the discovery document and the keys, defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Verifier verifies the ID tokens issued by the provider to the client.
+// The provider is discovered when the first token is verified.
+type Verifier struct {
+	config Config
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      jwks.KeySet
+	fetchedAt time.Time
+	now       func() time.Time
+}
+
+// NewVerifier returns a new verifier of the ID tokens.
+func NewVerifier(config Config) *Verifier {
+	if config.JWKSCacheTTL <= 0 {
+		config.JWKSCacheTTL = DefaultJWKSCacheTTL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	return &Verifier{config: config, now: time.Now}
+}
+
+// Issuer returns the issuer url of the provider.
+func (v *Verifier) Issuer() string {
+	return v.config.IssuerURL
+}
+
+// Verify verifies the signature, the issuer, the audience and the expiry of the ID token,
+// and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, rawIDToken string) (Claims, error) {
+	discovery, err := v.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(rawIDToken, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Algorithm != "" && key.Algorithm != t.Method.Alg() {
+			return nil, fmt.Errorf("the ID token is signed by %s, but the key `%s` is of %s",
+				t.Method.Alg(), key.KeyID, key.Algorithm)
+		}
+
+		// the key type must match the signing method, a HMAC token is rejected by the public key
+		return key.PublicKey()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, ErrInvalidIssuer
+	}
+	if !claims.VerifyAudience(v.config.ClientID, true) {
+		return nil, ErrInvalidAudience
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrMissingExpiry
+	}
+
+	return Claims(claims), nil
+}
+
+// discover fetches the discovery document of the provider once it is fetched successfully.
+func (v *Verifier) discover(ctx context.Context) (*Discovery, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.discovery != nil {
+		return v.discovery, nil
+	}
+
+	var discovery Discovery
+	if err := v.get(ctx, strings.TrimSuffix(v.config.IssuerURL, "/")+DiscoveryPath, &discovery); err != nil {
+		return nil, err
+	}
+
+	if discovery.Issuer != v.config.IssuerURL {
+		return nil, fmt.Errorf("the discovered issuer %q does not match the issuer url %q",
+			discovery.Issuer, v.config.IssuerURL)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("the provider %q publishes no jwks_uri", discovery.Issuer)
+	}
+	v.discovery = &discovery
+
+	return v.discovery, nil
+}
+
+// key returns the cached key identified by kid, the keys are fetched again when they are older
+// than JWKSCacheTTL, or when kid is unknown because the provider rotated its keys.
+func (v *Verifier) key(ctx context.Context, kid string) (jwks.Key, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	age := v.now().Sub(v.fetchedAt)
+	key, ok := v.lookup(kid)
+	if age >= v.config.JWKSCacheTTL || (!ok && age >= minRefetchInterval) {
+		var keys jwks.KeySet
+		if err := v.get(ctx, v.discovery.JWKSURI, &keys); err != nil {
+			return jwks.Key{}, err
+		}
+		v.keys = keys
+		v.fetchedAt = v.now()
+
+		key, ok = v.lookup(kid)
+	}
+
+	if !ok {
+		return jwks.Key{}, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return key, nil
+}
+
+// lookup returns the key identified by kid, the only key is used if the token has no kid.
+func (v *Verifier) lookup(kid string) (jwks.Key, bool) {
+	if kid == "" && len(v.keys.Keys) == 1 {
+		return v.keys.Keys[0], true
+	}
+
+	return v.keys.Lookup(kid)
+}
+
+func (v *Verifier) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: unexpected status %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/jwks"
+	"github.com/dairongpeng/leona/pkg/oidc"
+	"github.com/dairongpeng/leona/pkg/oidc/oidctest"
+)
+
+const clientID = "leona"
+
+func TestVerifier_Verify(t *testing.T) {
+	issuer := oidctest.NewIssuer(jwks.RS256)
+	defer issuer.Close()
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr error
+	}{
+		{
+			name: "valid",
+			token: func() string {
+				return issuer.IDToken(map[string]interface{}{"aud": clientID, "sub": "alice"})
+			},
+		},
+		{
+			name: "one of the audiences",
+			token: func() string {
+				return issuer.IDToken(map[string]interface{}{"aud": []string{"other", clientID}, "sub": "alice"})
+			},
+		},
+		{
+			name: "another audience",
+			token: func() string {
+				return issuer.IDToken(map[string]interface{}{"aud": "other"})
+			},
+			wantErr: oidc.ErrInvalidAudience,
+		},
+		{
+			name: "another issuer",
+			token: func() string {
+				return issuer.IDToken(map[string]interface{}{"aud": clientID, "iss": "https://evil.example.com"})
+			},
+			wantErr: oidc.ErrInvalidIssuer,
+		},
+		{
+			name: "no expiry",
+			token: func() string {
+				return issuer.IDToken(map[string]interface{}{"aud": clientID, "exp": nil})
+			},
+			wantErr: oidc.ErrMissingExpiry,
+		},
+	}
+
+	v := oidc.NewVerifier(oidc.Config{IssuerURL: issuer.URL, ClientID: clientID})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), tt.token())
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", claims.String("sub"))
+		})
+	}
+}
+
+func TestVerifier_Verify_Rejected(t *testing.T) {
+	issuer := oidctest.NewIssuer(jwks.ES256)
+	defer issuer.Close()
+	other := oidctest.NewIssuer(jwks.ES256)
+	defer other.Close()
+
+	v := oidc.NewVerifier(oidc.Config{IssuerURL: issuer.URL, ClientID: clientID})
+
+	expired := issuer.IDToken(map[string]interface{}{"aud": clientID, "exp": time.Now().Add(-time.Minute).Unix()})
+	_, err := v.Verify(context.Background(), expired)
+	assert.Error(t, err)
+
+	// signed by a key the provider does not publish
+	forged := other.IDToken(map[string]interface{}{"aud": clientID, "iss": issuer.URL})
+	_, err = v.Verify(context.Background(), forged)
+	assert.Error(t, err)
+
+	// signed by the shared secret instead of the key of the provider
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud": clientID, "iss": issuer.URL, "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	hmac.Header["kid"] = "key-1"
+	signed, _ := hmac.SignedString([]byte("secret"))
+	_, err = v.Verify(context.Background(), signed)
+	assert.Error(t, err)
+}
+
+func TestVerifier_Discovery(t *testing.T) {
+	issuer := oidctest.NewIssuer(jwks.EdDSA)
+	defer issuer.Close()
+
+	// the discovered issuer must be the configured issuer url
+	v := oidc.NewVerifier(oidc.Config{IssuerURL: issuer.URL + "/", ClientID: clientID})
+	_, err := v.Verify(context.Background(), issuer.IDToken(map[string]interface{}{"aud": clientID}))
+	assert.Error(t, err)
+
+	v = oidc.NewVerifier(oidc.Config{IssuerURL: "http://127.0.0.1:1", ClientID: clientID})
+	_, err = v.Verify(context.Background(), issuer.IDToken(map[string]interface{}{"aud": clientID}))
+	assert.Error(t, err)
+}
+
+func TestVerifier_KeyCache(t *testing.T) {
+	issuer := oidctest.NewIssuer(jwks.ES256)
+	defer issuer.Close()
+
+	now := time.Now()
+	v := oidc.NewVerifier(oidc.Config{IssuerURL: issuer.URL, ClientID: clientID, JWKSCacheTTL: time.Hour})
+	oidc.SetNow(v, func() time.Time { return now })
+	verify := func() error {
+		_, err := v.Verify(context.Background(), issuer.IDToken(map[string]interface{}{"aud": clientID}))
+
+		return err
+	}
+
+	// the keys are cached
+	assert.NoError(t, verify())
+	assert.NoError(t, verify())
+	assert.Equal(t, 1, issuer.KeyFetches())
+
+	// the rotated key is not fetched too often
+	issuer.Rotate()
+	assert.Error(t, verify())
+	assert.Equal(t, 1, issuer.KeyFetches())
+
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, verify())
+	assert.Equal(t, 2, issuer.KeyFetches())
+
+	// the keys are fetched again after the ttl
+	now = now.Add(time.Hour)
+	assert.NoError(t, verify())
+	assert.Equal(t, 3, issuer.KeyFetches())
+}
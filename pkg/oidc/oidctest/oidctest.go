@@ -0,0 +1,145 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidctest provides an in-process OpenID Connect provider for the tests.
+// It serves the discovery document and the JWKS, and issues the ID tokens signed by its keys.
+package oidctest
+
+import (
+	"crypto"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/dairongpeng/leona/pkg/json"
+	"github.com/dairongpeng/leona/pkg/jwks"
+	"github.com/dairongpeng/leona/pkg/oidc"
+)
+
+// Issuer is a fake OpenID Connect provider, its issuer url is the url of the server.
+type Issuer struct {
+	*httptest.Server
+
+	algorithm string
+
+	mu      sync.Mutex
+	keys    []signingKey
+	fetches int
+}
+
+type signingKey struct {
+	id     string
+	signer crypto.Signer
+}
+
+// NewIssuer starts a fake provider signing the ID tokens by the algorithm.
+// The caller should call Close when finished, to shut it down.
+func NewIssuer(algorithm string) *Issuer {
+	i := &Issuer{algorithm: algorithm}
+	i.Rotate()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidc.DiscoveryPath, i.serveDiscovery)
+	mux.HandleFunc("/keys", i.serveKeys)
+	i.Server = httptest.NewServer(mux)
+
+	return i
+}
+
+// Rotate starts signing the ID tokens with a new key, the former keys are still published.
+func (i *Issuer) Rotate() {
+	signer, err := jwks.GenerateKey(i.algorithm)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: generate key: %v", err))
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.keys = append([]signingKey{{id: fmt.Sprintf("key-%d", len(i.keys)+1), signer: signer}}, i.keys...)
+}
+
+// IDToken issues an ID token with the claims, the iss, iat and exp claims are filled unless they are set.
+// The claims with nil values are removed.
+func (i *Issuer) IDToken(claims map[string]interface{}) string {
+	mapClaims := jwt.MapClaims{
+		"iss": i.URL,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(mapClaims, name)
+
+			continue
+		}
+		mapClaims[name] = value
+	}
+
+	i.mu.Lock()
+	key := i.keys[0]
+	i.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(i.algorithm), mapClaims)
+	token.Header["kid"] = key.id
+	signed, err := token.SignedString(key.signer)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: sign ID token: %v", err))
+	}
+
+	return signed
+}
+
+// KeyFetches returns how many times the JWKS has been fetched.
+func (i *Issuer) KeyFetches() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.fetches
+}
+
+func (i *Issuer) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, oidc.Discovery{
+		Issuer:            i.URL,
+		JWKSURI:           i.URL + "/keys",
+		SigningAlgorithms: []string{i.algorithm},
+	})
+}
+
+func (i *Issuer) serveKeys(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.fetches++
+	set := jwks.KeySet{}
+	for _, k := range i.keys {
+		key, err := jwks.NewKey(k.id, i.algorithm, k.signer.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+		set.Keys = append(set.Keys, key)
+	}
+
+	writeJSON(w, set)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
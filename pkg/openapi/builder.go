@@ -0,0 +1,366 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+)
+
+// Route documents an API route, it is matched with the registered gin route by the method and the path.
+type Route struct {
+	// Method is the http method of the route.
+	Method string
+
+	// Path is the gin path of the route, eg: `/v1/users/:name`.
+	Path string
+
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Query is a struct whose `form` tagged fields are the query parameters.
+	Query interface{}
+
+	// Request is the request body.
+	Request interface{}
+
+	// Response is the body of the successful response.
+	Response interface{}
+
+	// Errors are the error codes the route may respond with.
+	Errors []int
+
+	// Security names the security schemes the route accepts, any of them is sufficient.
+	Security []string
+}
+
+func (r Route) key() string {
+	return routeKey(r.Method, r.Path)
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// Builder builds the OpenAPI document of the routes registered on a gin engine.
+type Builder struct {
+	Info            Info
+	Servers         []Server
+	Tags            []Tag
+	SecuritySchemes map[string]*SecurityScheme
+
+	// MediaTypes are the media types of the request and the response bodies, defaults to `application/json`.
+	MediaTypes []string
+
+	// ErrorResponse is the body of the error responses.
+	ErrorResponse interface{}
+
+	// Exclude are the prefixes of the paths which are not documented.
+	Exclude []string
+
+	// Reflector generates the schemas, a new one is created by each Build if not set.
+	Reflector *Reflector
+
+	routes map[string]Route
+}
+
+// NewBuilder creates a Builder documenting the given routes.
+func NewBuilder(info Info, routes ...Route) *Builder {
+	b := &Builder{Info: info, routes: map[string]Route{}}
+	b.Add(routes...)
+
+	return b
+}
+
+// Add documents the routes, the documentation of the same route is replaced.
+func (b *Builder) Add(routes ...Route) {
+	for _, r := range routes {
+		b.routes[r.key()] = r
+	}
+}
+
+// Build builds the document of the registered routes. The routes which are not documented are
+// published without parameters and bodies, the documented routes which are not registered are ignored.
+func (b *Builder) Build(registered gin.RoutesInfo) *Document {
+	reflector := b.Reflector
+	if reflector == nil {
+		reflector = NewReflector()
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    b.Info,
+		Servers: b.Servers,
+		Tags:    b.Tags,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: b.SecuritySchemes,
+		},
+	}
+
+	for _, info := range b.filter(registered) {
+		route, ok := b.routes[routeKey(info.Method, info.Path)]
+		if !ok {
+			route = Route{Method: info.Method, Path: info.Path}
+		}
+
+		path := ginPathToOpenAPI(info.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		item.set(info.Method, b.operation(reflector, route))
+	}
+
+	doc.Components.Schemas = reflector.Schemas()
+
+	return doc
+}
+
+// Diff compares the registered routes with the documented ones, it returns the registered routes
+// which are not documented and the documented routes which are not registered, as `METHOD path`.
+func (b *Builder) Diff(registered gin.RoutesInfo) (undocumented []string, unregistered []string) {
+	seen := map[string]bool{}
+	for _, info := range b.filter(registered) {
+		key := routeKey(info.Method, info.Path)
+		seen[key] = true
+
+		if _, ok := b.routes[key]; !ok {
+			undocumented = append(undocumented, key)
+		}
+	}
+
+	for key := range b.routes {
+		if !seen[key] {
+			unregistered = append(unregistered, key)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+
+	return undocumented, unregistered
+}
+
+// filter returns the registered routes which are not excluded, sorted by path and method.
+func (b *Builder) filter(registered gin.RoutesInfo) gin.RoutesInfo {
+	routes := make(gin.RoutesInfo, 0, len(registered))
+	for _, info := range registered {
+		if !b.excluded(info.Path) {
+			routes = append(routes, info)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+func (b *Builder) excluded(path string) bool {
+	for _, prefix := range b.Exclude {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Builder) operation(reflector *Reflector, route Route) *Operation {
+	op := &Operation{
+		Tags:        route.Tags,
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(route.Method, route.Path),
+		Parameters:  append(pathParameters(route.Path), queryParameters(reflector, route.Query)...),
+		Responses:   map[string]*Response{},
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: b.content(reflector.Schema(route.Request))}
+	}
+
+	ok := &Response{Description: http.StatusText(http.StatusOK)}
+	if route.Response != nil {
+		ok.Content = b.content(reflector.Schema(route.Response))
+	}
+	op.Responses[strconv.Itoa(http.StatusOK)] = ok
+
+	for status, resp := range b.errorResponses(reflector, route.Errors) {
+		op.Responses[status] = resp
+	}
+
+	for _, name := range route.Security {
+		op.Security = append(op.Security, SecurityRequirement{name: []string{}})
+	}
+
+	return op
+}
+
+// errorResponses groups the error codes by their http status.
+func (b *Builder) errorResponses(reflector *Reflector, codes []int) map[string]*Response {
+	lines := map[int][]string{}
+	for _, code := range codes {
+		coder := errors.ParseCoder(errors.WithCode(code, ""))
+		lines[coder.HTTPStatus()] = append(lines[coder.HTTPStatus()], fmt.Sprintf("- `%d`: %s", code, coder.String()))
+	}
+
+	responses := map[string]*Response{}
+	for status, l := range lines {
+		resp := &Response{Description: http.StatusText(status) + "\n\n" + strings.Join(l, "\n")}
+		if b.ErrorResponse != nil {
+			resp.Content = b.content(reflector.Schema(b.ErrorResponse))
+		}
+		responses[strconv.Itoa(status)] = resp
+	}
+
+	return responses
+}
+
+func (b *Builder) content(schema *Schema) map[string]*MediaType {
+	mediaTypes := b.MediaTypes
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+
+	content := make(map[string]*MediaType, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = &MediaType{Schema: schema}
+	}
+
+	return content
+}
+
+func (item *PathItem) set(method string, op *Operation) {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodHead:
+		item.Head = op
+	}
+}
+
+// ginPathToOpenAPI converts the gin path parameters to the OpenAPI ones, eg: `/users/:name` to `/users/{name}`.
+func ginPathToOpenAPI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// operationID derives an operation id from the method and the path, eg: `getV1UsersName`.
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '.'
+	}) {
+		sb.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+
+	return sb.String()
+}
+
+func pathParameters(path string) []*Parameter {
+	var params []*Parameter
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, &Parameter{
+				Name:     segment[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+
+	return params
+}
+
+// queryParameters returns the `form` tagged fields of the query struct as the query parameters.
+func queryParameters(reflector *Reflector, query interface{}) []*Parameter {
+	if query == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(query)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _ := parseTag(field.Tag.Get("form"))
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous && ft.Kind() == reflect.Struct && name == "" {
+			params = append(params, queryParameters(reflector, reflect.New(ft).Interface())...)
+
+			continue
+		}
+
+		if field.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+
+		schema := reflector.schemaOf(field.Type)
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: applyConstraints(schema, field.Tag),
+			Schema:   schema,
+		})
+	}
+
+	return params
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/errors"
+)
+
+type testCoder struct {
+	code   int
+	status int
+	text   string
+}
+
+func (c testCoder) HTTPStatus() int   { return c.status }
+func (c testCoder) String() string    { return c.text }
+func (c testCoder) Reference() string { return "" }
+func (c testCoder) Code() int         { return c.code }
+
+type testError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type testQuery struct {
+	testMeta
+	Limit *int64 `form:"limit" binding:"omitempty,min=1"`
+	Label string `form:"label"`
+}
+
+func TestBuilder_Build(t *testing.T) {
+	errors.Register(testCoder{code: 990001, status: http.StatusNotFound, text: "Object not found."})
+	errors.Register(testCoder{code: 990002, status: http.StatusNotFound, text: "Parent not found."})
+
+	b := NewBuilder(Info{Title: "test", Version: "v1"},
+		Route{
+			Method:   http.MethodGet,
+			Path:     "/v1/objects/:name",
+			Summary:  "Get an object.",
+			Tags:     []string{"objects"},
+			Query:    testQuery{},
+			Response: &testObject{},
+			Errors:   []int{990001, 990002},
+			Security: []string{"bearer"},
+		},
+		Route{Method: http.MethodPost, Path: "/v1/objects", Request: &testObject{}, Response: &testObject{}},
+		Route{Method: http.MethodDelete, Path: "/v1/gone"},
+	)
+	b.ErrorResponse = testError{}
+	b.MediaTypes = []string{"application/json", "application/yaml"}
+	b.Exclude = []string{"/debug"}
+
+	registered := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/v1/objects/:name"},
+		{Method: http.MethodPost, Path: "/v1/objects"},
+		{Method: http.MethodGet, Path: "/healthz"},
+		{Method: http.MethodGet, Path: "/debug/pprof/"},
+	}
+
+	doc := b.Build(registered)
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Len(t, doc.Paths, 3)
+	assert.NotNil(t, doc.Paths["/healthz"].Get)
+
+	get := doc.Paths["/v1/objects/{name}"].Get
+	if !assert.NotNil(t, get) {
+		return
+	}
+	assert.Equal(t, "getV1ObjectsName", get.OperationID)
+	assert.Equal(t, []SecurityRequirement{{"bearer": []string{}}}, get.Security)
+
+	var params []string
+	for _, p := range get.Parameters {
+		params = append(params, p.In+":"+p.Name)
+	}
+	assert.Equal(t, []string{"path:name", "query:limit", "query:label"}, params)
+	assert.EqualValues(t, 1, *get.Parameters[1].Schema.Minimum)
+
+	assert.Equal(t, RefTo("openapi.testObject"), get.Responses["200"].Content["application/yaml"].Schema)
+	notFound := get.Responses["404"]
+	if assert.NotNil(t, notFound) {
+		assert.Contains(t, notFound.Description, "`990001`: Object not found.")
+		assert.Contains(t, notFound.Description, "`990002`: Parent not found.")
+		assert.Equal(t, RefTo("openapi.testError"), notFound.Content["application/json"].Schema)
+	}
+
+	post := doc.Paths["/v1/objects"].Post
+	assert.True(t, post.RequestBody.Required)
+	assert.Contains(t, doc.Components.Schemas, "openapi.testObject")
+
+	undocumented, unregistered := b.Diff(registered)
+	assert.Equal(t, []string{"GET /healthz"}, undocumented)
+	assert.Equal(t, []string{"DELETE /v1/gone"}, unregistered)
+}
+
+func Test_ginPathToOpenAPI(t *testing.T) {
+	assert.Equal(t, "/v1/namespaces/{ns}/users/{name}", ginPathToOpenAPI("/v1/namespaces/:ns/users/:name"))
+	assert.Equal(t, "/static/{filepath}", ginPathToOpenAPI("/static/*filepath"))
+}
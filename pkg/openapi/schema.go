@@ -0,0 +1,212 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	versionRegexp = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]*)?$`)
+
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Namer names the component schema of a named go type.
+type Namer func(t reflect.Type) string
+
+// DefaultNamer names the schemas with the package name and the type name, eg: `core.ErrResponse`.
+// The parent of the versioned packages is kept, eg: `meta.v1.ObjectMeta`.
+func DefaultNamer(t reflect.Type) string {
+	elems := strings.Split(t.PkgPath(), "/")
+	pkg := elems[len(elems)-1]
+	if len(elems) > 1 && versionRegexp.MatchString(pkg) {
+		pkg = elems[len(elems)-2] + "." + pkg
+	}
+
+	if pkg == "" {
+		return t.Name()
+	}
+
+	return pkg + "." + t.Name()
+}
+
+// fullName names a schema with the whole package path, it is used when the names collide.
+func fullName(t reflect.Type) string {
+	return strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+}
+
+// Reflector generates schemas from go types. The named structs are generated as component schemas
+// and referenced, the embedded and inlined structs are flattened into their parents.
+type Reflector struct {
+	// Namer names the component schemas, DefaultNamer is used if not set.
+	Namer Namer
+
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewReflector creates a Reflector which names the component schemas with DefaultNamer.
+func NewReflector() *Reflector {
+	return &Reflector{
+		Namer:   DefaultNamer,
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Schemas returns the component schemas generated so far.
+func (r *Reflector) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// Schema returns the schema of the type of v, nil is returned if v is nil.
+func (r *Reflector) Schema(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+
+	if t, ok := v.(reflect.Type); ok {
+		return r.schemaOf(t)
+	}
+
+	return r.schemaOf(reflect.TypeOf(v))
+}
+
+func (r *Reflector) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+
+		return RefTo(r.component(t))
+	default:
+		// interface{} and anything else accept any value.
+		return &Schema{}
+	}
+}
+
+// component generates the component schema of a named struct and returns its name.
+func (r *Reflector) component(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	namer := r.Namer
+	if namer == nil {
+		namer = DefaultNamer
+	}
+
+	name := namer(t)
+	if _, ok := r.schemas[name]; ok {
+		name = fullName(t)
+	}
+	// register the name before walking the fields, so recursive types are referenced.
+	r.names[t] = name
+	r.schemas[name] = r.structSchema(t)
+
+	return name
+}
+
+func (r *Reflector) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(s, t)
+
+	return s
+}
+
+// addFields adds the properties of the exported fields of struct t to s.
+func (r *Reflector) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts := parseTag(field.Tag.Get("json"))
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// embedded structs without a json name and `json:",inline"` fields are flattened.
+		if name == "" && ft.Kind() == reflect.Struct && ft != timeType &&
+			(field.Anonymous || strings.Contains(opts, "inline")) {
+			r.addFields(s, ft)
+
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.schemaOf(field.Type)
+		s.Properties[name] = prop
+
+		if applyConstraints(prop, field.Tag) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+
+	return tag, ""
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+	"github.com/dairongpeng/leona/pkg/validation"
+)
+
+type testMeta struct {
+	Name      string    `json:"name,omitempty" validate:"name"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+type testObject struct {
+	Kind     string `json:"kind,omitempty"`
+	testMeta `json:",inline"`
+
+	Email    string            `json:"email" validate:"required,email,min=1,max=100"`
+	Age      int               `json:"age" validate:"omitempty,gte=0,lt=150"`
+	Role     string            `json:"role" binding:"oneof=admin user"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []*testObject     `json:"children,omitempty"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestReflector_Schema(t *testing.T) {
+	r := NewReflector()
+
+	assert.Equal(t, RefTo("openapi.testObject"), r.Schema(&testObject{}))
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, r.Schema([]string{}))
+	assert.Nil(t, r.Schema(nil))
+
+	s := r.Schemas()["openapi.testObject"]
+	if !assert.NotNil(t, s) {
+		return
+	}
+
+	assert.ElementsMatch(t, []string{"kind", "name", "createdAt", "email", "age", "role", "labels", "children"},
+		keys(s.Properties))
+	assert.ElementsMatch(t, []string{"name", "email"}, s.Required)
+
+	assert.Equal(t, validation.QualifiedNamePattern, s.Properties["name"].Pattern)
+	assert.Equal(t, "date-time", s.Properties["createdAt"].Format)
+
+	email := s.Properties["email"]
+	assert.Equal(t, "email", email.Format)
+	assert.EqualValues(t, 1, *email.MinLength)
+	assert.EqualValues(t, 100, *email.MaxLength)
+
+	age := s.Properties["age"]
+	assert.EqualValues(t, 0, *age.Minimum)
+	assert.EqualValues(t, 150, *age.Maximum)
+	assert.True(t, age.ExclusiveMaximum)
+
+	assert.Equal(t, []interface{}{"admin", "user"}, s.Properties["role"].Enum)
+	assert.Equal(t, &Schema{Type: "string"}, s.Properties["labels"].AdditionalProperties)
+	assert.Equal(t, RefTo("openapi.testObject"), s.Properties["children"].Items)
+}
+
+func TestRegisterConstraint(t *testing.T) {
+	RegisterConstraint("even", func(s *Schema, _ string) bool {
+		s.Description = "even"
+
+		return true
+	})
+
+	type even struct {
+		N int `json:"n" validate:"even"`
+	}
+
+	r := NewReflector()
+	r.Schema(even{})
+
+	s := r.Schemas()["openapi.even"]
+	assert.Equal(t, []string{"n"}, s.Required)
+	assert.Equal(t, "even", s.Properties["n"].Description)
+}
+
+func keys(m map[string]*Schema) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+
+	return ks
+}
+
+func TestDefaultNamer(t *testing.T) {
+	assert.Equal(t, "meta.v1.ObjectMeta", DefaultNamer(reflect.TypeOf(metav1.ObjectMeta{})))
+	assert.Equal(t, "openapi.Schema", DefaultNamer(reflect.TypeOf(Schema{})))
+	assert.Equal(t, "time.Duration", DefaultNamer(reflect.TypeOf(time.Second)))
+}
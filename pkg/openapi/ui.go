@@ -0,0 +1,56 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"bytes"
+	_ "embed" // embed the ui page.
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+//go:embed ui/index.html
+var uiPage string
+
+var uiTemplate = template.Must(template.New("ui").Parse(uiPage))
+
+// UIHandler serves a Swagger UI page browsing the document served at specURL.
+// The page loads the Swagger UI assets from assetsURL, which are served by UIAssetsHandler.
+func UIHandler(title, specURL, assetsURL string) gin.HandlerFunc {
+	var buf bytes.Buffer
+	data := map[string]string{"Title": title, "SpecURL": specURL, "AssetsURL": strings.TrimSuffix(assetsURL, "/")}
+	if err := uiTemplate.Execute(&buf, data); err != nil {
+		panic(err)
+	}
+	page := buf.Bytes()
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
+
+// UIAssetsHandler serves the Swagger UI assets embedded in the binary, so that the UI also works
+// without the internet access. The asset is named by the `filepath` parameter of the route.
+func UIAssetsHandler() gin.HandlerFunc {
+	assets := http.FS(swaggerfiles.FS)
+
+	return func(c *gin.Context) {
+		c.FileFromFS(c.Param("filepath"), assets)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dairongpeng/leona/pkg/validation"
+)
+
+// Constraint publishes a validate tag as the constraints of a schema, param is the parameter
+// of the tag, eg: `10` of `max=10`. It reports whether the tag requires the field to be set.
+type Constraint func(s *Schema, param string) bool
+
+var (
+	constraintsLock sync.RWMutex
+	constraints     = map[string]Constraint{
+		"required": func(*Schema, string) bool { return true },
+		"min":      boundConstraint(true, false),
+		"gte":      boundConstraint(true, false),
+		"gt":       boundConstraint(true, true),
+		"max":      boundConstraint(false, false),
+		"lte":      boundConstraint(false, false),
+		"lt":       boundConstraint(false, true),
+		"len": func(s *Schema, param string) bool {
+			boundConstraint(true, false)(s, param)
+
+			return boundConstraint(false, false)(s, param)
+		},
+		"oneof":     oneOfConstraint,
+		"email":     formatConstraint("email"),
+		"url":       formatConstraint("uri"),
+		"uri":       formatConstraint("uri"),
+		"uuid":      formatConstraint("uuid"),
+		"ipv4":      formatConstraint("ipv4"),
+		"ipv6":      formatConstraint("ipv6"),
+		"name":      qualifiedNameConstraint(true),
+		"username":  qualifiedNameConstraint(false),
+		"namespace": patternConstraint(validation.DNS1123LabelPattern, validation.QualifiedNameMaxLength),
+		"description": func(s *Schema, _ string) bool {
+			return boundConstraint(false, false)(s, strconv.Itoa(validation.MaxDescriptionLength))
+		},
+		"password": passwordConstraint,
+	}
+)
+
+// RegisterConstraint registers the constraint of a custom validate tag.
+func RegisterConstraint(tag string, c Constraint) {
+	constraintsLock.Lock()
+	defer constraintsLock.Unlock()
+
+	constraints[tag] = c
+}
+
+// applyConstraints applies the `validate` and `binding` tags of a struct field to its schema,
+// it reports whether the field is required.
+func applyConstraints(s *Schema, tag reflect.StructTag) bool {
+	constraintsLock.RLock()
+	defer constraintsLock.RUnlock()
+
+	var required bool
+	for _, key := range []string{"validate", "binding"} {
+		for _, rule := range strings.Split(tag.Get(key), ",") {
+			// the rules after dive apply to the elements.
+			if rule == "dive" {
+				break
+			}
+
+			name, param := rule, ""
+			if i := strings.Index(rule, "="); i >= 0 {
+				name, param = rule[:i], rule[i+1:]
+			}
+
+			if c, ok := constraints[name]; ok && c(s, param) {
+				required = true
+			}
+		}
+	}
+
+	return required
+}
+
+// boundConstraint bounds the length of strings, the count of items or the value of numbers.
+func boundConstraint(lower, exclusive bool) Constraint {
+	return func(s *Schema, param string) bool {
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false
+		}
+
+		switch s.Type {
+		case "string":
+			n := int64(v)
+			if lower {
+				s.MinLength = &n
+			} else {
+				s.MaxLength = &n
+			}
+		case "array":
+			n := int64(v)
+			if lower {
+				s.MinItems = &n
+			} else {
+				s.MaxItems = &n
+			}
+		case "integer", "number":
+			if lower {
+				s.Minimum, s.ExclusiveMinimum = &v, exclusive
+			} else {
+				s.Maximum, s.ExclusiveMaximum = &v, exclusive
+			}
+		}
+
+		return false
+	}
+}
+
+func oneOfConstraint(s *Schema, param string) bool {
+	for _, v := range strings.Fields(param) {
+		if s.Type == "integer" || s.Type == "number" {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				s.Enum = append(s.Enum, f)
+			}
+
+			continue
+		}
+		s.Enum = append(s.Enum, v)
+	}
+
+	return false
+}
+
+func formatConstraint(format string) Constraint {
+	return func(s *Schema, _ string) bool {
+		s.Format = format
+
+		return false
+	}
+}
+
+func patternConstraint(pattern string, maxLength int) Constraint {
+	return func(s *Schema, _ string) bool {
+		n := int64(maxLength)
+		s.Pattern, s.MaxLength = pattern, &n
+
+		return false
+	}
+}
+
+// qualifiedNameConstraint publishes the name validators, the `name` tag rejects empty names.
+func qualifiedNameConstraint(required bool) Constraint {
+	return func(s *Schema, param string) bool {
+		patternConstraint(validation.QualifiedNamePattern, validation.QualifiedNameMaxLength)(s, param)
+
+		return required
+	}
+}
+
+// passwordConstraint publishes the bounds of the configured password policy.
+func passwordConstraint(s *Schema, _ string) bool {
+	policy := validation.GetPasswordPolicy()
+	min, max := int64(policy.MinLength), int64(policy.MaxLength)
+	s.MinLength, s.MaxLength = &min, &max
+	if s.Description == "" {
+		s.Description = "Must contain upper and lower case letters, numbers and special characters."
+	}
+
+	return false
+}
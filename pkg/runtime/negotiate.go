@@ -16,10 +16,95 @@ package runtime
 
 import (
 	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/dairongpeng/leona/pkg/json"
 )
 
+// Media types known by the negotiator.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeYAML     = "application/yaml"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeText     = "text/plain"
+)
+
+// AsTable is the value of the `as` media type parameter which asks for a Table representation.
+const AsTable = "Table"
+
+// MediaType describes a media range of an Accept header.
+type MediaType struct {
+	// Type is the full type, such as `application/json`.
+	Type string
+	// Params contains the media type parameters except `q`.
+	Params map[string]string
+	// Quality is the relative weight of the media range, defaults to 1.
+	Quality float64
+}
+
+// Matches returns true if the media range accepts the given content type.
+func (m MediaType) Matches(contentType string) bool {
+	if m.Type == "*/*" || m.Type == contentType {
+		return true
+	}
+
+	if strings.HasSuffix(m.Type, "/*") {
+		return strings.HasPrefix(contentType, strings.TrimSuffix(m.Type, "*"))
+	}
+
+	return false
+}
+
+// ParseAccept parses the Accept header into media ranges ordered by their quality,
+// the ones can not be parsed are ignored. An empty header accepts everything.
+func ParseAccept(header string) []MediaType {
+	if strings.TrimSpace(header) == "" {
+		return []MediaType{{Type: "*/*", Params: map[string]string{}, Quality: 1}}
+	}
+
+	var mediaTypes []MediaType
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+			delete(params, "q")
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		mediaTypes = append(mediaTypes, MediaType{Type: mediaType, Params: params, Quality: quality})
+	}
+
+	sort.SliceStable(mediaTypes, func(i, j int) bool {
+		return mediaTypes[i].Quality > mediaTypes[j].Quality
+	})
+
+	return mediaTypes
+}
+
+// NegotiateMediaType returns the most preferred media range of the Accept header which is
+// supported, as reported by the supported function.
+func NegotiateMediaType(accept string, supported func(MediaType) bool) (MediaType, bool) {
+	for _, mediaType := range ParseAccept(accept) {
+		if supported(mediaType) {
+			return mediaType, true
+		}
+	}
+
+	return MediaType{}, false
+}
+
 // NegotiateError is returned when a ClientNegotiator is unable to locate
 // a serializer for the requested operation.
 type NegotiateError struct {
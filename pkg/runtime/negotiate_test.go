@@ -0,0 +1,71 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "empty", header: "", want: []string{"*/*"}},
+		{name: "single", header: "application/json", want: []string{"application/json"}},
+		{
+			name:   "quality",
+			header: "text/plain;q=0.5, application/json;as=Table, */*;q=0.1",
+			want:   []string{"application/json", "text/plain", "*/*"},
+		},
+		{name: "zero quality and invalid", header: "text/plain;q=0, ;;, application/yaml", want: []string{"application/yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, mediaType := range ParseAccept(tt.header) {
+				got = append(got, mediaType.Type)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	mediaTypes := ParseAccept("application/json;as=Table;q=0.9")
+	assert.Equal(t, AsTable, mediaTypes[0].Params["as"])
+	assert.Equal(t, 0.9, mediaTypes[0].Quality)
+}
+
+func TestMediaType_Matches(t *testing.T) {
+	assert.True(t, MediaType{Type: "*/*"}.Matches(ContentTypeJSON))
+	assert.True(t, MediaType{Type: "application/*"}.Matches(ContentTypeJSON))
+	assert.True(t, MediaType{Type: ContentTypeJSON}.Matches(ContentTypeJSON))
+	assert.False(t, MediaType{Type: "text/*"}.Matches(ContentTypeJSON))
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	supported := func(mediaType MediaType) bool {
+		return mediaType.Matches(ContentTypeYAML)
+	}
+
+	got, ok := NegotiateMediaType("application/json, application/yaml;q=0.5", supported)
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeYAML, got.Type)
+
+	_, ok = NegotiateMediaType("application/json", supported)
+	assert.False(t, ok)
+}
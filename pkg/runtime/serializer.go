@@ -0,0 +1,138 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Serializer encodes objects to and decodes objects from a media type.
+type Serializer interface {
+	Encoder
+	Decoder
+}
+
+// SerializerInfo contains information about a registered serializer.
+type SerializerInfo struct {
+	// MediaType is the value that represents this serializer over the wire.
+	MediaType string
+	// Serializer is the individual object serializer for this media type.
+	Serializer Serializer
+}
+
+// SerializerRegistry maps media types to serializers, the first registered one is the default.
+type SerializerRegistry struct {
+	lock        sync.RWMutex
+	serializers []SerializerInfo
+}
+
+// NewSerializerRegistry returns an empty serializer registry.
+func NewSerializerRegistry() *SerializerRegistry {
+	return &SerializerRegistry{}
+}
+
+// Register adds the serializer for the media type, the registered one for the same media type is replaced.
+func (r *SerializerRegistry) Register(mediaType string, serializer Serializer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range r.serializers {
+		if r.serializers[i].MediaType == mediaType {
+			r.serializers[i].Serializer = serializer
+
+			return
+		}
+	}
+
+	r.serializers = append(r.serializers, SerializerInfo{MediaType: mediaType, Serializer: serializer})
+}
+
+// MediaTypes returns the registered media types.
+func (r *SerializerRegistry) MediaTypes() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	mediaTypes := make([]string, 0, len(r.serializers))
+	for _, info := range r.serializers {
+		mediaTypes = append(mediaTypes, info.MediaType)
+	}
+
+	return mediaTypes
+}
+
+// SerializerForMediaType returns the first serializer accepted by the media range.
+func (r *SerializerRegistry) SerializerForMediaType(mediaType MediaType) (SerializerInfo, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, info := range r.serializers {
+		if mediaType.Matches(info.MediaType) {
+			return info, true
+		}
+	}
+
+	return SerializerInfo{}, false
+}
+
+// SerializerForContentType returns the serializer for the Content-Type header of a request,
+// the default serializer is returned if the header is empty.
+func (r *SerializerRegistry) SerializerForContentType(contentType string) (SerializerInfo, bool) {
+	if strings.TrimSpace(contentType) == "" {
+		r.lock.RLock()
+		defer r.lock.RUnlock()
+
+		if len(r.serializers) == 0 {
+			return SerializerInfo{}, false
+		}
+
+		return r.serializers[0], true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || strings.Contains(mediaType, "*") {
+		return SerializerInfo{}, false
+	}
+
+	return r.SerializerForMediaType(MediaType{Type: mediaType})
+}
+
+// Negotiate returns the serializer most preferred by the Accept header.
+// A NegotiateError is returned if none of the media ranges is supported.
+func (r *SerializerRegistry) Negotiate(accept string) (SerializerInfo, error) {
+	var info SerializerInfo
+	_, ok := NegotiateMediaType(accept, func(mediaType MediaType) bool {
+		var found bool
+		info, found = r.SerializerForMediaType(mediaType)
+
+		return found
+	})
+	if !ok {
+		return SerializerInfo{}, NegotiateError{ContentType: accept}
+	}
+
+	return info, nil
+}
+
+// DefaultRegistry is the serializer registry used by the API servers,
+// JSON is the default media type. Protobuf is not registered, the API types are not protobuf messages.
+var DefaultRegistry = NewSerializerRegistry()
+
+//nolint:gochecknoinits
+func init() {
+	DefaultRegistry.Register(ContentTypeJSON, NewJSONSerializer())
+	DefaultRegistry.Register(ContentTypeYAML, NewYAMLSerializer())
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"github.com/ghodss/yaml"
+
+	"github.com/dairongpeng/leona/pkg/json"
+)
+
+type jsonSerializer struct{}
+
+// NewJSONSerializer returns a serializer for `application/json`.
+func NewJSONSerializer() Serializer {
+	return jsonSerializer{}
+}
+
+func (jsonSerializer) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonSerializer) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type yamlSerializer struct{}
+
+// NewYAMLSerializer returns a serializer for `application/yaml`,
+// the objects are converted through JSON so that the json tags are honored.
+func NewYAMLSerializer() Serializer {
+	return yamlSerializer{}
+}
+
+func (yamlSerializer) Encode(v interface{}) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+func (yamlSerializer) Decode(data []byte, v interface{}) error {
+	return yaml.Unmarshal(data, v)
+}
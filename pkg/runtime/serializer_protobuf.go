@@ -0,0 +1,47 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type protobufSerializer struct{}
+
+// NewProtobufSerializer returns a serializer for `application/x-protobuf`.
+// Only the objects implementing proto.Message are serialized.
+func NewProtobufSerializer() Serializer {
+	return protobufSerializer{}
+}
+
+func (protobufSerializer) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a protobuf message", v)
+	}
+
+	return proto.Marshal(m)
+}
+
+func (protobufSerializer) Decode(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a protobuf message", v)
+	}
+
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type object struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestSerializers(t *testing.T) {
+	in := &object{Name: "colin", Count: 3, Tags: []string{"a", "b"}}
+
+	for _, mediaType := range DefaultRegistry.MediaTypes() {
+		t.Run(mediaType, func(t *testing.T) {
+			info, ok := DefaultRegistry.SerializerForContentType(mediaType)
+			assert.True(t, ok)
+
+			data, err := info.Serializer.Encode(in)
+			assert.Nil(t, err)
+
+			out := &object{}
+			assert.Nil(t, info.Serializer.Decode(data, out))
+			assert.Equal(t, in, out)
+		})
+	}
+
+	data, err := NewYAMLSerializer().Encode(in)
+	assert.Nil(t, err)
+	assert.Equal(t, "count: 3\nname: colin\ntags:\n- a\n- b\n", string(data))
+}
+
+func TestProtobufSerializer_Message(t *testing.T) {
+	serializer := NewProtobufSerializer()
+
+	data, err := serializer.Encode(wrapperspb.String("colin"))
+	assert.Nil(t, err)
+
+	out := &wrapperspb.StringValue{}
+	assert.Nil(t, serializer.Decode(data, out))
+	assert.Equal(t, "colin", out.GetValue())
+
+	// the other objects are not serialized
+	_, err = serializer.Encode(&object{Name: "colin"})
+	assert.NotNil(t, err)
+	assert.NotNil(t, serializer.Decode(data, &object{}))
+}
+
+func TestSerializerRegistry(t *testing.T) {
+	registry := NewSerializerRegistry()
+	_, err := registry.Negotiate("application/json")
+	assert.Equal(t, NegotiateError{ContentType: "application/json"}, err)
+
+	registry.Register(ContentTypeJSON, NewJSONSerializer())
+	registry.Register(ContentTypeYAML, NewYAMLSerializer())
+	registry.Register(ContentTypeJSON, NewJSONSerializer())
+	assert.Equal(t, []string{ContentTypeJSON, ContentTypeYAML}, registry.MediaTypes())
+
+	tests := []struct {
+		accept string
+		want   string
+		err    bool
+	}{
+		{accept: "", want: ContentTypeJSON},
+		{accept: "*/*", want: ContentTypeJSON},
+		{accept: "application/yaml", want: ContentTypeYAML},
+		{accept: "application/json;q=0.5, application/yaml", want: ContentTypeYAML},
+		{accept: "text/html, application/*;q=0.8", want: ContentTypeJSON},
+		{accept: "text/html", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			info, err := registry.Negotiate(tt.accept)
+			if tt.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, info.MediaType)
+		})
+	}
+
+	info, ok := registry.SerializerForContentType("")
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeJSON, info.MediaType)
+	info, ok = registry.SerializerForContentType("application/yaml; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeYAML, info.MediaType)
+	_, ok = registry.SerializerForContentType("application/x-protobuf")
+	assert.False(t, ok)
+	_, ok = registry.SerializerForContentType("*/*")
+	assert.False(t, ok)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+// PrintTable writes the table to w as aligned text columns.
+// The header is omitted when the table has no column definitions or noHeaders is set.
+func PrintTable(w io.Writer, table *metav1.Table, noHeaders bool) error {
+	tw := tabwriter.NewWriter(w, 10, 4, 3, ' ', 0)
+
+	if !noHeaders && len(table.ColumnDefinitions) > 0 {
+		names := make([]string, 0, len(table.ColumnDefinitions))
+		for _, column := range table.ColumnDefinitions {
+			names = append(names, strings.ToUpper(column.Name))
+		}
+
+		if _, err := fmt.Fprintln(tw, strings.Join(names, "\t")); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			if cell == nil {
+				cells = append(cells, "<none>")
+
+				continue
+			}
+			cells = append(cells, fmt.Sprintf("%v", cell))
+		}
+
+		if _, err := fmt.Fprintln(tw, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
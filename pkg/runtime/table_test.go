@@ -0,0 +1,42 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "github.com/dairongpeng/leona/pkg/meta/v1"
+)
+
+func TestPrintTable(t *testing.T) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Admin"}},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"admin", true}},
+			{Cells: []interface{}{"colin", nil}},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, PrintTable(&buf, table, false))
+	assert.Equal(t, "NAME      ADMIN\nadmin     true\ncolin     <none>\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, PrintTable(&buf, table, true))
+	assert.Equal(t, "admin     true\ncolin     <none>\n", buf.String())
+}
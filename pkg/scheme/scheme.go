@@ -0,0 +1,188 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheme
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Object is implemented by all the API types which carry their type information,
+// the types embedding metav1.TypeMeta implement it.
+type Object interface {
+	GetObjectKind() ObjectKind
+}
+
+// ConversionFunc converts the in object into the out object, both are pointers to the
+// registered types.
+type ConversionFunc func(in, out interface{}) error
+
+type typePair struct {
+	source reflect.Type
+	dest   reflect.Type
+}
+
+// Scheme defines methods for mapping GroupVersionKinds to Go types and converting objects
+// between the versions of the same kind. The registration is expected to happen during
+// initialization, the lookup methods are safe for concurrent use afterwards.
+type Scheme struct {
+	// gvkToType allows one to figure out the go type of an object with the given version and name.
+	gvkToType map[GroupVersionKind]reflect.Type
+	// typeToGVK allows one to find metadata for a given go object. The first registered one is preferred.
+	typeToGVK map[reflect.Type][]GroupVersionKind
+	// conversionFuncs maps a pair of types to the function converting between them.
+	conversionFuncs map[typePair]ConversionFunc
+}
+
+// NewScheme creates a new Scheme.
+func NewScheme() *Scheme {
+	return &Scheme{
+		gvkToType:       map[GroupVersionKind]reflect.Type{},
+		typeToGVK:       map[reflect.Type][]GroupVersionKind{},
+		conversionFuncs: map[typePair]ConversionFunc{},
+	}
+}
+
+// AddKnownTypes registers all types passed in 'types' as being members of version 'gv'.
+// All objects passed to types should be pointers to structs. The name that go reports for
+// the struct becomes the "kind" field when encoding.
+func (s *Scheme) AddKnownTypes(gv GroupVersion, types ...Object) {
+	for _, obj := range types {
+		t := structType(obj)
+		s.AddKnownTypeWithName(gv.WithKind(t.Name()), obj)
+	}
+}
+
+// AddKnownTypeWithName is like AddKnownTypes, but it lets you specify what this type should
+// be encoded as. It panics if the gvk is registered with another type.
+func (s *Scheme) AddKnownTypeWithName(gvk GroupVersionKind, obj Object) {
+	t := structType(obj)
+	if len(gvk.Version) == 0 {
+		panic(fmt.Sprintf("version is required on all types: %s %v", gvk, t))
+	}
+
+	if oldT, found := s.gvkToType[gvk]; found {
+		if oldT != t {
+			panic(fmt.Sprintf("double registration of different types for %v: old=%v, new=%v", gvk, oldT, t))
+		}
+
+		return
+	}
+
+	s.gvkToType[gvk] = t
+	s.typeToGVK[t] = append(s.typeToGVK[t], gvk)
+}
+
+// ObjectKinds returns all possible group, version, kind of the go object.
+func (s *Scheme) ObjectKinds(obj Object) ([]GroupVersionKind, error) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("all objects must be pointers to structs, got %v", t)
+	}
+
+	gvks, ok := s.typeToGVK[t.Elem()]
+	if !ok {
+		return nil, fmt.Errorf("no kind is registered for the type %v", t.Elem())
+	}
+
+	return gvks, nil
+}
+
+// Recognizes returns true if the scheme is able to handle the provided group, version and kind.
+func (s *Scheme) Recognizes(gvk GroupVersionKind) bool {
+	_, exists := s.gvkToType[gvk]
+
+	return exists
+}
+
+// New returns a new API object of the given version and name, with the type information populated.
+func (s *Scheme) New(gvk GroupVersionKind) (Object, error) {
+	t, exists := s.gvkToType[gvk]
+	if !exists {
+		return nil, fmt.Errorf("no kind %q is registered for version %q", gvk.Kind, gvk.GroupVersion())
+	}
+
+	obj, _ := reflect.New(t).Interface().(Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return obj, nil
+}
+
+// AddConversionFunc registers a function that converts between a and b by passing objects of those
+// types to the provided function. The function *must* accept objects of a and b - this
+// machinery will not enforce any other guarantee.
+func (s *Scheme) AddConversionFunc(a, b interface{}, fn ConversionFunc) {
+	s.conversionFuncs[typePair{source: reflect.TypeOf(a), dest: reflect.TypeOf(b)}] = fn
+}
+
+// Convert will attempt to convert in into out with the registered conversion functions.
+// Both must be pointers.
+func (s *Scheme) Convert(in, out interface{}) error {
+	pair := typePair{source: reflect.TypeOf(in), dest: reflect.TypeOf(out)}
+
+	fn, ok := s.conversionFuncs[pair]
+	if !ok {
+		return fmt.Errorf("converting (%v) to (%v): unknown conversion", pair.source, pair.dest)
+	}
+
+	return fn(in, out)
+}
+
+// ConvertToVersion converts the object into the same kind of the target group version.
+// The type information of the returned object is populated.
+func (s *Scheme) ConvertToVersion(in Object, target GroupVersion) (Object, error) {
+	gvks, err := s.ObjectKinds(in)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := target.WithKind(gvks[0].Kind)
+	out, err := s.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Convert(in, out); err != nil {
+		return nil, err
+	}
+	out.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return out, nil
+}
+
+// SetTypeMeta populates the type information of a registered object with its preferred
+// group, version and kind. Other values are left untouched.
+func (s *Scheme) SetTypeMeta(obj interface{}) {
+	o, ok := obj.(Object)
+	if !ok || reflect.ValueOf(obj).Kind() != reflect.Ptr || reflect.ValueOf(obj).IsNil() {
+		return
+	}
+
+	gvks, err := s.ObjectKinds(o)
+	if err != nil {
+		return
+	}
+
+	o.GetObjectKind().SetGroupVersionKind(gvks[0])
+}
+
+func structType(obj Object) reflect.Type {
+	t := reflect.TypeOf(obj)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("all types must be pointers to structs")
+	}
+
+	return t.Elem()
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheme
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type typeMeta struct {
+	APIVersion string
+	Kind       string
+}
+
+func (t *typeMeta) GetObjectKind() ObjectKind { return t }
+
+func (t *typeMeta) SetGroupVersionKind(gvk GroupVersionKind) {
+	t.APIVersion, t.Kind = gvk.ToAPIVersionAndKind()
+}
+
+func (t *typeMeta) GroupVersionKind() GroupVersionKind {
+	return FromAPIVersionAndKind(t.APIVersion, t.Kind)
+}
+
+type internalObject struct {
+	typeMeta
+	Name string
+}
+
+type externalObject struct {
+	typeMeta
+	FullName string
+}
+
+var (
+	internalGV = GroupVersion{Group: "test", Version: "v1"}
+	externalGV = GroupVersion{Group: "test", Version: "v2"}
+)
+
+func newTestScheme() *Scheme {
+	s := NewScheme()
+	s.AddKnownTypes(internalGV, &internalObject{})
+	s.AddKnownTypeWithName(externalGV.WithKind("internalObject"), &externalObject{})
+	s.AddConversionFunc((*internalObject)(nil), (*externalObject)(nil), func(in, out interface{}) error {
+		out.(*externalObject).FullName = in.(*internalObject).Name
+
+		return nil
+	})
+
+	return s
+}
+
+func TestScheme_Register(t *testing.T) {
+	s := newTestScheme()
+
+	gvks, err := s.ObjectKinds(&internalObject{})
+	assert.Nil(t, err)
+	assert.Equal(t, []GroupVersionKind{internalGV.WithKind("internalObject")}, gvks)
+
+	_, err = s.ObjectKinds(&typeMeta{})
+	assert.NotNil(t, err)
+
+	assert.True(t, s.Recognizes(externalGV.WithKind("internalObject")))
+	assert.False(t, s.Recognizes(externalGV.WithKind("externalObject")))
+
+	obj, err := s.New(externalGV.WithKind("internalObject"))
+	assert.Nil(t, err)
+	assert.IsType(t, &externalObject{}, obj)
+	assert.Equal(t, "test/v2", obj.(*externalObject).APIVersion)
+
+	_, err = s.New(externalGV.WithKind("unknown"))
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() {
+		s.AddKnownTypeWithName(internalGV.WithKind("internalObject"), &externalObject{})
+	})
+	assert.Panics(t, func() {
+		s.AddKnownTypes(GroupVersion{Group: "test"}, &externalObject{})
+	})
+}
+
+func TestScheme_Convert(t *testing.T) {
+	s := newTestScheme()
+
+	out, err := s.ConvertToVersion(&internalObject{Name: "colin"}, externalGV)
+	assert.Nil(t, err)
+	assert.Equal(t, &externalObject{
+		typeMeta: typeMeta{APIVersion: "test/v2", Kind: "internalObject"},
+		FullName: "colin",
+	}, out)
+
+	err = s.Convert(&externalObject{}, &internalObject{})
+	assert.Equal(t, fmt.Errorf("converting (*scheme.externalObject) to (*scheme.internalObject): unknown conversion"), err)
+}
+
+func TestScheme_SetTypeMeta(t *testing.T) {
+	s := newTestScheme()
+
+	obj := &internalObject{}
+	s.SetTypeMeta(obj)
+	assert.Equal(t, typeMeta{APIVersion: "test/v1", Kind: "internalObject"}, obj.typeMeta)
+
+	// unregistered and nil objects are left untouched.
+	s.SetTypeMeta(&typeMeta{})
+	s.SetTypeMeta((*internalObject)(nil))
+	s.SetTypeMeta(nil)
+}
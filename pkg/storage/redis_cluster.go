@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/dairongpeng/leona/pkg/log"
+	"github.com/dairongpeng/leona/pkg/tracing"
 )
 
 // Config defines options for redis cluster.
@@ -126,6 +127,8 @@ type RedisCluster struct {
 	KeyPrefix string
 	HashKeys  bool
 	IsCache   bool
+
+	ctx context.Context
 }
 
 func clusterConnectionIsOpen(cluster RedisCluster) bool {
@@ -242,6 +245,7 @@ func NewRedisClusterPool(isCache bool, config *Config) redis.UniversalClient {
 		log.Info("--> [REDIS] Creating single-node client")
 		client = redis.NewClient(opts.simple())
 	}
+	client.AddHook(tracingHook{})
 
 	return client
 }
@@ -368,8 +372,30 @@ func (r *RedisCluster) Connect() bool {
 	return true
 }
 
+// WithContext returns a copy of the storage manager running the commands in ctx, the
+// commands are traced as the children of the span in ctx. A gin context runs them in
+// the context of its request.
+func (r *RedisCluster) WithContext(ctx context.Context) *RedisCluster {
+	c := *r
+	c.ctx = tracing.FromContext(ctx)
+
+	return &c
+}
+
 func (r *RedisCluster) singleton() redis.UniversalClient {
-	return singleton(r.IsCache)
+	client := singleton(r.IsCache)
+	if r.ctx == nil {
+		return client
+	}
+
+	switch c := client.(type) {
+	case *redis.Client:
+		return c.WithContext(r.ctx)
+	case *redis.ClusterClient:
+		return c.WithContext(r.ctx)
+	default:
+		return client
+	}
 }
 
 func (r *RedisCluster) hashKey(in string) string {
@@ -562,6 +588,22 @@ func (r *RedisCluster) SetRawKey(keyName, session string, timeout time.Duration)
 	return nil
 }
 
+// SetRawKeyIfNotExist sets the value of the given key unless the key exists, it returns whether
+// the value is set.
+func (r *RedisCluster) SetRawKeyIfNotExist(keyName, value string, timeout time.Duration) (bool, error) {
+	if err := r.up(); err != nil {
+		return false, err
+	}
+	ok, err := r.singleton().SetNX(keyName, value, timeout).Result()
+	if err != nil {
+		log.Errorf("Error trying to set value: %s", err.Error())
+
+		return false, err
+	}
+
+	return ok, nil
+}
+
 // Decrement will decrement a key in redis.
 func (r *RedisCluster) Decrement(keyName string) {
 	keyName = r.fixKey(keyName)
@@ -1065,17 +1107,17 @@ func (r *RedisCluster) GetListRange(keyName string, from, to int64) ([]string, e
 	return elements, nil
 }
 
-// AppendToSetPipelined append values to redis pipeline.
-func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) {
+// AppendToSetPipelined append values to redis pipeline, the values are lost if it fails.
+func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) error {
 	if len(values) == 0 {
-		return
+		return nil
 	}
 
 	fixedKey := r.fixKey(key)
 	if err := r.up(); err != nil {
 		log.Debug(err.Error())
 
-		return
+		return err
 	}
 	client := r.singleton()
 
@@ -1086,6 +1128,8 @@ func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) {
 
 	if _, err := pipe.Exec(); err != nil {
 		log.Errorf("Error trying to append to set keys: %s", err.Error())
+
+		return err
 	}
 
 	// if we need to set an expiration time
@@ -1096,6 +1140,8 @@ func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) {
 			_ = r.SetExp(key, time.Duration(storageExpTime)*time.Second)
 		}
 	}
+
+	return nil
 }
 
 // GetSet return key set value.
@@ -1291,6 +1337,170 @@ func (r RedisCluster) GetRollingWindow(keyName string, per int64, pipeline bool)
 	return intVal, result
 }
 
+// takeTokenScript refills the bucket by the time passed since the last take and takes a token from it.
+// The bucket is a hash holding the tokens left and the time of the last take in milliseconds.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local per = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * capacity / per)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * per / capacity)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], per)
+return {allowed, math.floor(tokens), wait}
+`)
+
+// TakeToken takes a token from the token bucket identified by keyName, the bucket holds at most capacity
+// tokens and is refilled with capacity tokens every per. It returns whether a token was taken, the tokens
+// left in the bucket and how long to wait for the next token when the bucket is empty.
+func (r *RedisCluster) TakeToken(keyName string, capacity int64, per time.Duration) (bool, int64, time.Duration, error) {
+	if err := r.up(); err != nil {
+		return false, 0, 0, err
+	}
+	fixedKey := r.fixKey(keyName)
+
+	result, err := takeTokenScript.Run(
+		r.singleton(),
+		[]string{fixedKey},
+		capacity,
+		per.Milliseconds(),
+		time.Now().UnixNano()/int64(time.Millisecond),
+	).Result()
+	if err != nil {
+		log.Errorf("Error trying to take token: %s", err.Error())
+
+		return false, 0, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected token bucket result: %v", result)
+	}
+	allowed, _ := values[0].(int64)
+	tokens, _ := values[1].(int64)
+	wait, _ := values[2].(int64)
+
+	return allowed == 1, tokens, time.Duration(wait) * time.Millisecond, nil
+}
+
+// takeWindowScript drops the hits out of the sliding window and adds the hit when the window is not full,
+// the denied hits are not added. The window is a sorted set of the hits scored by their time in milliseconds.
+var takeWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - period)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], period)
+	count = count + 1
+	allowed = 1
+end
+local reset = period
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if #oldest == 2 then
+	reset = tonumber(oldest[2]) + period - now
+end
+return {allowed, count, reset}
+`)
+
+// TakeWindow adds a hit to the sliding window identified by keyName if the window holds less than limit
+// hits in the last period. It returns whether the hit was added, the hits in the window and how long it
+// takes the oldest hit to leave the window.
+func (r *RedisCluster) TakeWindow(
+	keyName string,
+	limit int64,
+	period time.Duration,
+) (bool, int64, time.Duration, error) {
+	if err := r.up(); err != nil {
+		return false, 0, 0, err
+	}
+	fixedKey := r.fixKey(keyName)
+
+	now := time.Now()
+	result, err := takeWindowScript.Run(
+		r.singleton(),
+		[]string{fixedKey},
+		limit,
+		period.Milliseconds(),
+		now.UnixNano()/int64(time.Millisecond),
+		strconv.FormatInt(now.UnixNano(), 10),
+	).Result()
+	if err != nil {
+		log.Errorf("Error trying to take window: %s", err.Error())
+
+		return false, 0, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected sliding window result: %v", result)
+	}
+	allowed, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+	reset, _ := values[2].(int64)
+
+	return allowed == 1, count, time.Duration(reset) * time.Millisecond, nil
+}
+
+// refundTokenScript puts a token back into the bucket, the bucket never holds more than its capacity.
+var refundTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+if tokens == nil then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(math.min(capacity, tokens + 1)))
+return 1
+`)
+
+// RefundToken puts the token taken by TakeToken back into the bucket identified by keyName.
+func (r *RedisCluster) RefundToken(keyName string, capacity int64) error {
+	if err := r.up(); err != nil {
+		return err
+	}
+
+	if err := refundTokenScript.Run(r.singleton(), []string{r.fixKey(keyName)}, capacity).Err(); err != nil {
+		log.Errorf("Error trying to refund token: %s", err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+// RefundWindow removes the hit added by TakeWindow from the sliding window identified by keyName, the
+// latest hit of the window is removed.
+func (r *RedisCluster) RefundWindow(keyName string) error {
+	if err := r.up(); err != nil {
+		return err
+	}
+
+	if err := r.singleton().ZRemRangeByRank(r.fixKey(keyName), -1, -1).Err(); err != nil {
+		log.Errorf("Error trying to refund window: %s", err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
 // GetKeyPrefix returns storage key prefix.
 func (r *RedisCluster) GetKeyPrefix() string {
 	return r.KeyPrefix
@@ -74,7 +74,7 @@ type Handler interface {
 // AnalyticsHandler defines the interface for analytics.
 type AnalyticsHandler interface {
 	Connect() bool
-	AppendToSetPipelined(string, [][]byte)
+	AppendToSetPipelined(string, [][]byte) error
 	GetAndDeleteSet(string) []interface{}
 	SetExp(string, time.Duration) error // Set key expiration
 	GetExp(string) (int64, error)       // Returns expiry of a key
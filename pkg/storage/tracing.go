@@ -0,0 +1,103 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"strings"
+
+	redis "github.com/go-redis/redis/v7"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dairongpeng/leona/pkg/tracing"
+)
+
+const pipelineLengthKey = attribute.Key("db.redis.pipeline_length")
+
+// tracingHook records the redis commands as spans. Only the commands run in a context
+// carrying a span are recorded, see RedisCluster.WithContext.
+type tracingHook struct{}
+
+var _ redis.Hook = tracingHook{}
+
+func (tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startSpan(ctx, cmd.Name(), cmd.Name()), nil
+}
+
+func (tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endSpan(ctx, cmd.Err())
+
+	return nil
+}
+
+func (tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+	}
+
+	ctx = startSpan(ctx, "pipeline", strings.Join(names, " "))
+	trace.SpanFromContext(ctx).SetAttributes(pipelineLengthKey.Int(len(cmds)))
+
+	return ctx, nil
+}
+
+func (tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+			err = cmdErr
+
+			break
+		}
+	}
+	endSpan(ctx, err)
+
+	return nil
+}
+
+func startSpan(ctx context.Context, name, statement string) context.Context {
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	// The arguments are not recorded, the values may be sensitive.
+	ctx, _ = tracing.Tracer().Start(ctx, "redis."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBStatementKey.String(statement)),
+	)
+
+	return context.WithValue(ctx, spanStartedKey{}, true)
+}
+
+func endSpan(ctx context.Context, err error) {
+	if ctx == nil || ctx.Value(spanStartedKey{}) == nil {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// spanStartedKey marks the contexts carrying a span started by the hook, the span of the
+// caller must not be ended.
+type spanStartedKey struct{}
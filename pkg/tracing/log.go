@@ -0,0 +1,41 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dairongpeng/leona/pkg/log"
+)
+
+func init() {
+	log.RegisterContextFields(logFields)
+}
+
+// logFields returns the IDs of the current trace and span of ctx, so that the logs written by log.L
+// are correlated with the traces.
+func logFields(ctx context.Context) []log.Field {
+	sc := trace.SpanContextFromContext(FromContext(ctx))
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []log.Field{
+		log.String(log.KeyTraceID.String(), sc.TraceID().String()),
+		log.String(log.KeySpanID.String(), sc.SpanID().String()),
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnable      = "tracing.enable"
+	flagServiceName = "tracing.service-name"
+	flagExporter    = "tracing.exporter"
+	flagEndpoint    = "tracing.endpoint"
+	flagInsecure    = "tracing.insecure"
+	flagFile        = "tracing.file"
+	flagSampleRatio = "tracing.sample-ratio"
+
+	// ExporterOTLP exports the spans to an OTLP collector over gRPC.
+	ExporterOTLP = "otlp"
+	// ExporterStdout prints the spans to the standard output.
+	ExporterStdout = "stdout"
+	// ExporterFile appends the spans to a file, for use without a collector.
+	ExporterFile = "file"
+)
+
+// Options contains configuration items related to tracing.
+type Options struct {
+	Enable      bool    `json:"enable"       mapstructure:"enable"`
+	ServiceName string  `json:"service-name" mapstructure:"service-name"`
+	Exporter    string  `json:"exporter"     mapstructure:"exporter"`
+	Endpoint    string  `json:"endpoint"     mapstructure:"endpoint"`
+	Insecure    bool    `json:"insecure"     mapstructure:"insecure"`
+	File        string  `json:"file"         mapstructure:"file"`
+	SampleRatio float64 `json:"sample-ratio" mapstructure:"sample-ratio"`
+}
+
+// NewOptions creates a Options object with default parameters.
+func NewOptions() *Options {
+	return &Options{
+		Enable:      false,
+		Exporter:    ExporterOTLP,
+		Endpoint:    "127.0.0.1:4317",
+		Insecure:    true,
+		SampleRatio: 1,
+	}
+}
+
+// Validate validate the options fields.
+func (o *Options) Validate() []error {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	var errs []error
+
+	switch o.Exporter {
+	case ExporterOTLP:
+		if o.Endpoint == "" {
+			errs = append(errs, fmt.Errorf("--%s is required by the %s exporter", flagEndpoint, ExporterOTLP))
+		}
+	case ExporterStdout:
+	case ExporterFile:
+		if o.File == "" {
+			errs = append(errs, fmt.Errorf("--%s is required by the %s exporter", flagFile, ExporterFile))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("--%s %q must be one of %s, %s or %s",
+			flagExporter, o.Exporter, ExporterOTLP, ExporterStdout, ExporterFile))
+	}
+
+	if o.SampleRatio < 0 || o.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("--%s %v must be between 0 and 1", flagSampleRatio, o.SampleRatio))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags for tracing to the specified FlagSet object.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enable, flagEnable, o.Enable, "Record OpenTelemetry spans of the requests, "+
+		"the W3C trace context is propagated even if disabled.")
+	fs.StringVar(&o.ServiceName, flagServiceName, o.ServiceName, "The service name of the spans, "+
+		"defaults to the name of the component.")
+	fs.StringVar(&o.Exporter, flagExporter, o.Exporter, "Where the spans are exported, one of otlp, stdout or file.")
+	fs.StringVar(&o.Endpoint, flagEndpoint, o.Endpoint, "The gRPC endpoint of the OTLP collector.")
+	fs.BoolVar(&o.Insecure, flagInsecure, o.Insecure, "Connect to the OTLP collector without TLS.")
+	fs.StringVar(&o.File, flagFile, o.File, "The file the spans are appended to by the file exporter.")
+	fs.Float64Var(&o.SampleRatio, flagSampleRatio, o.SampleRatio, "The ratio of the traces sampled, "+
+		"the sampling decision of the caller is respected.")
+}
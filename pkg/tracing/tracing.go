@@ -0,0 +1,162 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records OpenTelemetry spans and propagates the W3C trace context
+// between the leona components.
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dairongpeng/leona/pkg/version"
+)
+
+// InstrumentationName is the name of the tracer of leona.
+const InstrumentationName = "github.com/dairongpeng/leona"
+
+var (
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+	closer   io.Closer
+)
+
+// Init sets the global propagator and, if tracing is enabled, the global tracer provider.
+// serviceName is used unless the options set one.
+func Init(opts *Options, serviceName string) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if opts == nil || !opts.Enable {
+		return nil
+	}
+
+	if opts.ServiceName != "" {
+		serviceName = opts.ServiceName
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	exporter, c, err := newExporter(opts)
+	if err != nil {
+		return err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version.Get().GitVersion),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	provider, closer = tp, c
+
+	return nil
+}
+
+func newExporter(opts *Options) (sdktrace.SpanExporter, io.Closer, error) {
+	switch opts.Exporter {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+		return exporter, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+
+			return nil, nil, err
+		}
+
+		return exporter, f, nil
+	default:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+		if opts.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+
+		// The client connects lazily, a collector which is down does not block the start.
+		exporter, err := otlptracegrpc.New(context.Background(), options...)
+
+		return exporter, nil, err
+	}
+}
+
+// Shutdown flushes the pending spans and stops the tracer provider set by Init.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+
+	err := provider.Shutdown(ctx)
+	if closer != nil {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	provider, closer = nil, nil
+
+	return err
+}
+
+// Tracer returns the tracer of leona from the global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// FromContext returns the context carrying the current span. A gin context does not
+// carry the span itself, the one of its request is returned instead.
+func FromContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	// gin.Context returns its request for the key 0.
+	if req, ok := ctx.Value(0).(*http.Request); ok && req != nil {
+		return req.Context()
+	}
+
+	return ctx
+}
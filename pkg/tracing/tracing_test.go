@@ -0,0 +1,141 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	assert.Nil(t, Init(nil, "test"))
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestOptions_Validate(t *testing.T) {
+	opts := NewOptions()
+	assert.Empty(t, opts.Validate())
+
+	opts.Enable = true
+	assert.Empty(t, opts.Validate())
+
+	opts.Exporter = ExporterFile
+	assert.Len(t, opts.Validate(), 1)
+
+	opts.Exporter = "jaeger"
+	opts.SampleRatio = 2
+	assert.Len(t, opts.Validate(), 2)
+}
+
+func TestInit_File(t *testing.T) {
+	opts := NewOptions()
+	opts.Enable = true
+	opts.Exporter = ExporterFile
+	opts.File = filepath.Join(t.TempDir(), "trace.json")
+
+	assert.Nil(t, Init(opts, "test"))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	_, span := Tracer().Start(context.Background(), "span")
+	span.End()
+
+	assert.Nil(t, Shutdown(context.Background()))
+	assert.FileExists(t, opts.File)
+	assert.Nil(t, Shutdown(context.Background()))
+}
+
+func TestFromContext(t *testing.T) {
+	useRecorder(t)
+
+	ctx, span := Tracer().Start(context.Background(), "request")
+	defer span.End()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(FromContext(c)))
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(FromContext(ctx)))
+	assert.False(t, trace.SpanContextFromContext(FromContext(context.Background())).IsValid())
+}
+
+func TestLogFields(t *testing.T) {
+	useRecorder(t)
+
+	ctx, span := Tracer().Start(context.Background(), "request")
+	defer span.End()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	fields := logFields(c)
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, span.SpanContext().TraceID().String(), fields[0].String)
+		assert.Equal(t, span.SpanContext().SpanID().String(), fields[1].String)
+	}
+	assert.Empty(t, logFields(context.Background()))
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	recorder := useRecorder(t)
+
+	ctx, parent := Tracer().Start(context.Background(), "caller")
+	defer parent.End()
+
+	server := UnaryServerInterceptor()
+	invoker := func(ctx context.Context, method string, req, reply interface{}, _ *grpc.ClientConn,
+		_ ...grpc.CallOption) error {
+		// the outgoing metadata of the client are received by the server
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_, err := server(metadata.NewIncomingContext(context.Background(), md), req,
+			&grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				assert.Equal(t, parent.SpanContext().TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+
+				return nil, nil
+			})
+
+		return err
+	}
+
+	err := UnaryClientInterceptor()(ctx, "/proto.Cache/ListSecrets", nil, nil, nil, invoker)
+	assert.Nil(t, err)
+
+	ended := recorder.Ended()
+	assert.Len(t, ended, 2)
+	assert.Equal(t, "proto.Cache/ListSecrets", ended[0].Name())
+	assert.Equal(t, trace.SpanKindServer, ended[0].SpanKind())
+	assert.Equal(t, trace.SpanKindClient, ended[1].SpanKind())
+	assert.Equal(t, ended[1].SpanContext().SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[1].Parent().SpanID())
+}
@@ -256,6 +256,15 @@ const (
 	maxPassLength = 16
 )
 
+// The patterns and bounds enforced by the custom validate tags, they are published
+// in the OpenAPI document.
+const (
+	QualifiedNamePattern   = "^" + qualifiedNameFmt + "$"
+	QualifiedNameMaxLength = qualifiedNameMaxLength
+	DNS1123LabelPattern    = "^" + dns1123LabelFmt + "$"
+	MaxDescriptionLength   = maxDescriptionLength
+)
+
 // IsValidPassword validate password.
 func IsValidPassword(password string) error {
 	var hasUpper bool
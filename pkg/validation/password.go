@@ -0,0 +1,150 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/dairongpeng/leona/pkg/util/sets"
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+// commonPasswords are the well known passwords which satisfy the default policy, they are always rejected.
+var commonPasswords = []string{
+	"P@ssw0rd", "P@ssword1", "Passw0rd!", "Password1!", "Password123!", "Password@123", "Welcome1!",
+	"Welcome@123", "Admin@123", "Admin123!", "Qwerty123!", "Qwerty@123", "Abc@1234", "Abcd@1234",
+	"Aa123456!", "Aa@123456", "Test@123", "Root@123", "Changeme1!", "Letmein1!",
+}
+
+// PasswordPolicy defines the requirements of the passwords.
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireNumber  bool
+	RequireSpecial bool
+
+	// Blocklist holds the rejected passwords in lower case.
+	Blocklist sets.String
+}
+
+// DefaultPasswordPolicy returns the policy enforced by IsValidPassword, with the common passwords blocked.
+func DefaultPasswordPolicy() *PasswordPolicy {
+	p := &PasswordPolicy{
+		MinLength:      minPassLength,
+		MaxLength:      maxPassLength,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireNumber:  true,
+		RequireSpecial: true,
+		Blocklist:      sets.NewString(),
+	}
+	p.Block(commonPasswords...)
+
+	return p
+}
+
+// Block adds the passwords to the blocklist, they are compared case-insensitively.
+func (p *PasswordPolicy) Block(passwords ...string) {
+	if p.Blocklist == nil {
+		p.Blocklist = sets.NewString()
+	}
+
+	for _, password := range passwords {
+		if password = strings.TrimSpace(password); password != "" {
+			p.Blocklist.Insert(strings.ToLower(password))
+		}
+	}
+}
+
+// Validate checks the password against the policy, the violations are reported on fldPath.
+// The password is never echoed in the errors.
+func (p *PasswordPolicy) Validate(password string, fldPath *field.Path) field.ErrorList {
+	if password == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSpecial bool
+	var length int
+	for _, ch := range password {
+		length++
+		switch {
+		case unicode.IsNumber(ch):
+			hasNumber = true
+		case unicode.IsUpper(ch):
+			hasUpper = true
+		case unicode.IsLower(ch):
+			hasLower = true
+		case unicode.IsPunct(ch) || unicode.IsSymbol(ch):
+			hasSpecial = true
+		}
+	}
+
+	var allErrs field.ErrorList
+	invalid := func(detail string) {
+		allErrs = append(allErrs, field.Invalid(fldPath, nil, detail))
+	}
+
+	if length < p.MinLength || (p.MaxLength > 0 && length > p.MaxLength) {
+		invalid(fmt.Sprintf("password length must be between %d to %d characters long", p.MinLength, p.MaxLength))
+	}
+	if p.RequireLower && !hasLower {
+		invalid("lowercase letter missing")
+	}
+	if p.RequireUpper && !hasUpper {
+		invalid("uppercase letter missing")
+	}
+	if p.RequireNumber && !hasNumber {
+		invalid("at least one numeric character required")
+	}
+	if p.RequireSpecial && !hasSpecial {
+		invalid("special character missing")
+	}
+	if p.Blocklist.Has(strings.ToLower(password)) {
+		invalid("password is too common")
+	}
+
+	return allErrs
+}
+
+var (
+	passwordPolicyLock sync.RWMutex
+	passwordPolicy     = DefaultPasswordPolicy()
+)
+
+// SetPasswordPolicy sets the policy enforced by ValidatePassword.
+func SetPasswordPolicy(p *PasswordPolicy) {
+	passwordPolicyLock.Lock()
+	defer passwordPolicyLock.Unlock()
+
+	passwordPolicy = p
+}
+
+// GetPasswordPolicy returns the policy enforced by ValidatePassword.
+func GetPasswordPolicy() *PasswordPolicy {
+	passwordPolicyLock.RLock()
+	defer passwordPolicyLock.RUnlock()
+
+	return passwordPolicy
+}
+
+// ValidatePassword checks the password against the configured policy.
+func ValidatePassword(password string, fldPath *field.Path) field.ErrorList {
+	return GetPasswordPolicy().Validate(password, fldPath)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 dairongpeng <dairongpeng@foxmail.com>. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dairongpeng/leona/pkg/validation/field"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		details  []string
+	}{
+		{name: "valid", password: "Colin@2021"},
+		{name: "empty", password: "", details: []string{""}},
+		{
+			name:     "weak",
+			password: "colin",
+			details: []string{
+				"password length must be between 8 to 16 characters long",
+				"uppercase letter missing",
+				"at least one numeric character required",
+				"special character missing",
+			},
+		},
+		{name: "common", password: "p@SSw0rd", details: []string{"password is too common"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := DefaultPasswordPolicy().Validate(tt.password, field.NewPath("password"))
+
+			var details []string
+			for _, err := range errs {
+				assert.Equal(t, "password", err.Field)
+				if err.Type == field.ErrorTypeInvalid {
+					assert.Nil(t, err.BadValue)
+				}
+				details = append(details, err.Detail)
+			}
+			assert.Equal(t, tt.details, details)
+		})
+	}
+}
+
+func TestPasswordPolicy_Requirements(t *testing.T) {
+	p := &PasswordPolicy{MinLength: 4, MaxLength: 64}
+	p.Block("letmein")
+
+	assert.Empty(t, p.Validate("correct horse battery staple", nil))
+	assert.NotEmpty(t, p.Validate("LetMeIn", nil))
+	assert.NotEmpty(t, p.Validate("abc", nil))
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 
 	english "github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -41,12 +42,14 @@ type Validator struct {
 // NewValidator creates a new Validator.
 func NewValidator(data interface{}) *Validator {
 	result := validator.New()
+	result.RegisterTagNameFunc(JSONTagName)
 
 	// independent validators
 	result.RegisterValidation("dir", validateDir)                 // nolint: errcheck // no need
 	result.RegisterValidation("file", validateFile)               // nolint: errcheck // no need
 	result.RegisterValidation("description", validateDescription) // nolint: errcheck // no need
 	result.RegisterValidation("name", validateName)               // nolint: errcheck // no need
+	result.RegisterValidation("namespace", validateNamespace)     // nolint: errcheck // no need
 
 	// default translations
 	eng := english.New()
@@ -78,6 +81,10 @@ func NewValidator(data interface{}) *Validator {
 			tag:         "name",
 			translation: "is not a invalid name",
 		},
+		{
+			tag:         "namespace",
+			translation: "{0} must be a DNS-1123 label, but found '{1}'",
+		},
 	}
 	for _, t := range translations {
 		err = result.RegisterTranslation(t.tag, trans, registrationFunc(t.tag, t.translation), translateFunc)
@@ -129,17 +136,62 @@ func (v *Validator) Validate() field.ErrorList {
 		return field.ErrorList{field.Invalid(field.NewPath(""), err.Error(), "")}
 	}
 
-	allErrs := field.ErrorList{}
+	return FieldErrors(err, v.trans)
+}
+
+// JSONTagName names the struct fields after their json tags, so the paths of the
+// validation errors match the fields of the request bodies.
+func JSONTagName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// FieldErrors converts the errors returned by the go-playground validator to field errors.
+// The messages are translated with trans, if it is nil a generic message naming the failed tag is used.
+// The values of the password fields are never echoed.
+func FieldErrors(err error, trans ut.Translator) field.ErrorList {
+	vErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil
+	}
 
-	// collect human-readable errors
-	vErrors, _ := err.(validator.ValidationErrors)
+	allErrs := field.ErrorList{}
 	for _, vErr := range vErrors {
-		allErrs = append(allErrs, field.Invalid(field.NewPath(vErr.Namespace()), vErr.Translate(v.trans), ""))
+		path := field.NewPath(fieldPath(vErr.Namespace()))
+		if vErr.Tag() == "required" {
+			allErrs = append(allErrs, field.Required(path, ""))
+
+			continue
+		}
+
+		detail := fmt.Sprintf("failed on the '%s' validation", vErr.ActualTag())
+		if trans != nil {
+			detail = vErr.Translate(trans)
+		}
+
+		var value interface{} = vErr.Value()
+		if vErr.Tag() == "password" {
+			value = nil
+		}
+		allErrs = append(allErrs, field.Invalid(path, value, detail))
 	}
 
 	return allErrs
 }
 
+// fieldPath trims the name of the validated struct from the namespace of a validation error.
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
+
 // validateDir checks if a given string is an existing directory.
 func validateDir(fl validator.FieldLevel) bool {
 	path := fl.Field().String()
@@ -176,3 +228,13 @@ func validateName(fl validator.FieldLevel) bool {
 
 	return true
 }
+
+// validateNamespace checks if a given namespace is illegal.
+func validateNamespace(fl validator.FieldLevel) bool {
+	namespace := fl.Field().String()
+	if errs := IsDNS1123Label(namespace); len(errs) > 0 {
+		return false
+	}
+
+	return true
+}
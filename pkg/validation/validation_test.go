@@ -170,3 +170,28 @@ func TestConfigValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestValidator_FieldErrors(t *testing.T) {
+	type meta struct {
+		Name string `json:"name,omitempty" validate:"name"`
+	}
+
+	type object struct {
+		Meta  meta   `json:"metadata"`
+		Email string `json:"email" validate:"required,email"`
+		Port  int    `json:"port" validate:"omitempty,max=10"`
+	}
+
+	errs := NewValidator(&object{Meta: meta{Name: "-bad-"}, Port: 11}).Validate()
+	if !assert.Len(t, errs, 3) {
+		return
+	}
+
+	assert.Equal(t, "metadata.name", errs[0].Field)
+	assert.Equal(t, "-bad-", errs[0].BadValue)
+	assert.Equal(t, "email", errs[1].Field)
+	assert.Equal(t, "Required value", errs[1].ErrorBody())
+	assert.Equal(t, "port", errs[2].Field)
+	assert.Equal(t, 11, errs[2].BadValue)
+	assert.Equal(t, "port must be 10 or less", errs[2].Detail)
+}
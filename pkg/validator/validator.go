@@ -31,18 +31,17 @@ func validateUsername(fl validator.FieldLevel) bool {
 	return true
 }
 
-// validatePassword checks if a given password is illegal.
+// validatePassword checks if a given password violates the password policy.
 func validatePassword(fl validator.FieldLevel) bool {
 	password := fl.Field().String()
-	if err := validation.IsValidPassword(password); err != nil {
-		return false
-	}
 
-	return true
+	return len(validation.ValidatePassword(password, nil)) == 0
 }
 
 func init() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// report the paths of the invalid fields in the json names.
+		v.RegisterTagNameFunc(validation.JSONTagName)
 		_ = v.RegisterValidation("username", validateUsername)
 		_ = v.RegisterValidation("password", validatePassword)
 	}